@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -10,13 +11,26 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
 	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/health"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
-	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/postgres"
-	redisCache "github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/tracing"
+	webhooksender "github.com/tyokyo320/rateflow/internal/infrastructure/webhook"
 	httpHandler "github.com/tyokyo320/rateflow/internal/presentation/http"
 	"github.com/tyokyo320/rateflow/internal/presentation/http/handler"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/middleware"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
 
 	_ "github.com/tyokyo320/rateflow/docs" // Import generated swagger docs
 )
@@ -44,8 +58,15 @@ const (
 // @BasePath /
 // @schemes http https
 //
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key issued with `worker apikeys create`; also accepted as a bearer token. Required when AUTH_ENABLED is set.
+//
 // @tag.name rates
 // @tag.description Exchange rate operations
+// @tag.name webhooks
+// @tag.description Webhook subscriptions and deliveries
 // @tag.name health
 // @tag.description Health check operations
 
@@ -67,53 +88,206 @@ func main() {
 		slog.Int("port", cfg.Server.Port),
 	)
 
-	// Initialize database
-	db, err := postgres.NewConnection(cfg.Database, log)
-	if err != nil {
-		log.Error("failed to initialize database", "error", err)
-		os.Exit(1)
-	}
-	sqlDB, err := db.DB()
+	// Initialize tracing; spans are only exported when an OTLP endpoint is configured
+	ctx := context.Background()
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, serviceName, serviceVersion, log)
 	if err != nil {
-		log.Error("failed to get database connection", "error", err)
+		log.Error("failed to initialize tracing", "error", err)
 		os.Exit(1)
 	}
 	defer func() {
-		if err := sqlDB.Close(); err != nil {
-			log.Error("failed to close database", "error", err)
+		flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Error("failed to flush spans", "error", err)
 		}
 	}()
 
-	// Initialize Redis cache
-	cache := redisCache.NewCache(cfg.Redis, log)
+	// Initialize database; the memory driver keeps the rates in process and
+	// opens none
+	var (
+		db       *gorm.DB
+		sqlDB    *sql.DB
+		rateRepo rate.Repository
+	)
+	if cfg.Database.Driver == config.DriverMemory {
+		rateRepo, err = persistence.NewMemoryRateRepository(ctx, cfg.Database.Path, log)
+		if err != nil {
+			log.Error("failed to initialize memory database", "error", err)
+			os.Exit(1)
+		}
+		log.Warn("serving rates from memory; they are lost on exit")
+	} else {
+		db, err = persistence.NewConnection(cfg.Database, log)
+		if err != nil {
+			log.Error("failed to initialize database", "error", err)
+			os.Exit(1)
+		}
+		sqlDB, err = db.DB()
+		if err != nil {
+			log.Error("failed to get database connection", "error", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := sqlDB.Close(); err != nil {
+				log.Error("failed to close database", "error", err)
+			}
+		}()
+		rateRepo = persistence.NewRateRepository(db, log)
+	}
+
+	// Initialize cache (degrades to in-process caching if Redis is unreachable)
+	cache := persistence.NewCache(ctx, cfg.Cache, cfg.Redis, log)
 	defer func() {
 		if err := cache.Close(); err != nil {
-			log.Error("failed to close redis", "error", err)
+			log.Error("failed to close cache", "error", err)
 		}
 	}()
 
-	// Test Redis connection
-	ctx := context.Background()
-	if err := cache.Ping(ctx); err != nil {
-		log.Error("failed to connect to redis", "error", err)
-		os.Exit(1)
+	// Initialize query handlers
+	getLatestHandler := query.NewGetLatestRateHandler(rateRepo, cache, query.CachePolicy{
+		TTL:    cfg.Cache.LatestTTL,
+		Stale:  cfg.Cache.StaleTTL,
+		Jitter: cfg.Cache.TTLJitter,
+	}, log)
+	getByDateHandler := query.NewGetRateByDateHandler(rateRepo, cache, query.CachePolicy{
+		TTL:    cfg.Cache.ByDateTTL,
+		Stale:  cfg.Cache.StaleTTL,
+		Jitter: cfg.Cache.TTLJitter,
+	}, log)
+	listRatesHandler := query.NewListRatesHandler(rateRepo, log)
+	getHistoryHandler := query.NewGetRateHistoryHandler(rateRepo, log)
+
+	// Initialize health checks; only the database is critical, as the API
+	// keeps serving without the cache, fresh data or the provider
+	healthPairs := make([]currency.Pair, 0, len(cfg.Health.Pairs))
+	for _, s := range cfg.Health.Pairs {
+		pair, err := currency.ParsePair(s)
+		if err != nil {
+			log.Error("invalid health check pair", "pair", s, "error", err)
+			os.Exit(1)
+		}
+		healthPairs = append(healthPairs, pair)
 	}
 
-	// Initialize repositories
-	rateRepo := postgres.NewRateRepository(db, log)
+	// Without a database there is none to check, and no worker records its
+	// fetches for the API to read
+	healthRegistry := health.NewRegistry(cfg.Health.Timeout, log)
+	if db != nil {
+		healthRegistry.Register("database", health.DatabaseCheck(sqlDB), true)
+	}
+	healthRegistry.Register("cache", health.CacheCheck(cache), false)
+	healthRegistry.Register("freshness", health.FreshnessCheck(rateRepo, healthPairs, timeutil.NowJST), false)
+	if db != nil {
+		statusRepo := persistence.NewProviderStatusRepository(db, log)
+		for _, name := range cfg.Health.Providers {
+			healthRegistry.Register("provider:"+name, health.ProviderCheck(statusRepo, name), false)
+		}
+	}
 
-	// Initialize query handlers
-	getLatestHandler := query.NewGetLatestRateHandler(rateRepo, cache, log)
-	listRatesHandler := query.NewListRatesHandler(rateRepo, log)
+	// Report the age of the latest rates when scraped, for stale-data alerts
+	prometheus.MustRegister(metrics.NewRateAgeCollector(rateRepo, healthPairs, log))
+
+	// API keys are only checked when authentication is enabled
+	var authenticator *command.AuthenticateHandler
+	if cfg.Auth.Enabled {
+		authenticator = command.NewAuthenticateHandler(persistence.NewAPIKeyRepository(db, log), log)
+		log.Info("api key authentication enabled")
+	}
+
+	// Rate limits are shared through Redis when it is configured
+	var rateLimiter redis.Limiter
+	var rateLimits middleware.RateLimits
+	if cfg.RateLimit.Enabled {
+		rateLimits, err = parseRateLimits(cfg.RateLimit)
+		if err != nil {
+			log.Error("invalid rate limit", "error", err)
+			os.Exit(1)
+		}
+		rateLimiter = persistence.NewRateLimiter(cfg.Redis, log)
+		defer rateLimiter.Close()
+		log.Info("rate limiting enabled", "default", cfg.RateLimit.Default, "routes", cfg.RateLimit.Routes)
+	}
 
 	// Initialize HTTP handlers
-	rateHandler := handler.NewRateHandler(getLatestHandler, listRatesHandler, log)
+	// Responses are only kept out of shared caches when they require an API key
+	httpCachePolicy := handler.HTTPCachePolicy{
+		FetchInterval:        cfg.Cache.FetchInterval,
+		HistoricalMaxAge:     cfg.Cache.HistoricalMaxAge,
+		StaleWhileRevalidate: cfg.Cache.StaleTTL,
+		Private:              cfg.Auth.Enabled,
+		Now:                  timeutil.NowJST,
+	}
+	rateHandler := handler.NewRateHandler(getLatestHandler, getByDateHandler, listRatesHandler, getHistoryHandler, httpCachePolicy, log)
+	analyticsHandler := handler.NewAnalyticsHandler(rateRepo, httpCachePolicy, log)
+	healthHandler := handler.NewHealthHandler(healthRegistry, log)
+
+	// Rates stored by the worker reach stream clients through the event log
+	rateEvents := persistence.NewRateEvents(cfg.Redis, cfg.Stream.Retention, log)
+	defer rateEvents.Close()
+	streamHandler := handler.NewStreamHandler(query.NewStreamRatesHandler(rateEvents, log), cfg.Stream.Heartbeat, log)
+
+	// Webhook deliveries are queued by the worker and sent, and retried, here
+	var webhookHandler *handler.WebhookHandler
+	stopWebhooks := func() {}
+	if cfg.Webhooks.Enabled {
+		webhookRepo := persistence.NewWebhookRepository(db, log)
+		webhookHandler = handler.NewWebhookHandler(webhookRepo, log)
+
+		deliverer := command.NewDeliverWebhooksHandler(
+			webhookRepo,
+			webhooksender.NewSender(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivateTargets, log),
+			webhook.RetryPolicy{
+				MaxAttempts:    cfg.Webhooks.MaxAttempts,
+				InitialBackoff: cfg.Webhooks.InitialBackoff,
+				MaxBackoff:     cfg.Webhooks.MaxBackoff,
+			},
+			cfg.Webhooks.BatchSize,
+			// A poll sends its batch one by one, each within the timeout
+			time.Duration(cfg.Webhooks.BatchSize+1)*cfg.Webhooks.Timeout,
+			log,
+		)
+		webhookCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			runWebhookDeliveries(webhookCtx, deliverer, cfg.Webhooks.PollInterval, log)
+		}()
+		stopWebhooks = func() {
+			cancel()
+			<-done
+		}
+		log.Info("webhooks enabled", "poll_interval", cfg.Webhooks.PollInterval)
+	}
+
+	// Rates the worker quarantined are approved or rejected here; approved
+	// rates are published like fetched ones
+	var suspectHandler *handler.SuspectRateHandler
+	if cfg.Anomaly.Enabled {
+		var webhooks *command.EmitWebhooksHandler
+		if cfg.Webhooks.Enabled {
+			webhooks = command.NewEmitWebhooksHandler(persistence.NewWebhookRepository(db, log), rateRepo, log)
+		}
+		suspectHandler = handler.NewSuspectRateHandler(
+			persistence.NewSuspectRateRepository(db, log), rateRepo, cache, rateEvents, webhooks, log,
+		)
+	}
 
 	// Setup router
 	router := httpHandler.SetupRouter(httpHandler.RouterConfig{
-		RateHandler: rateHandler,
-		Logger:      log,
-		Environment: cfg.Server.Environment,
+		RateHandler:      rateHandler,
+		HealthHandler:    healthHandler,
+		AnalyticsHandler: analyticsHandler,
+		StreamHandler:    streamHandler,
+		WebhookHandler:   webhookHandler,
+		SuspectHandler:   suspectHandler,
+		Logger:           log,
+		ServiceName:      serviceName,
+		Environment:      cfg.Server.Environment,
+		ProblemDetails:   cfg.Server.ProblemDetails,
+		Authenticator:    authenticator,
+		RateLimiter:      rateLimiter,
+		RateLimits:       rateLimits,
 	})
 
 	// Create HTTP server
@@ -124,6 +298,7 @@ func main() {
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
+	srv.RegisterOnShutdown(streamHandler.Shutdown)
 
 	// Start server in a goroutine
 	go func() {
@@ -149,6 +324,55 @@ func main() {
 		log.Error("server forced to shutdown", "error", err)
 		os.Exit(1)
 	}
+	stopWebhooks()
 
 	log.Info("server exited")
 }
+
+// runWebhookDeliveries sends due webhook deliveries every interval until ctx
+// is done. A poll keeps sending batches as long as deliveries are due.
+func runWebhookDeliveries(ctx context.Context, deliverer *command.DeliverWebhooksHandler, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			attempted, err := deliverer.Handle(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error("failed to deliver webhooks", "error", err)
+				}
+				break
+			}
+			if attempted == 0 {
+				break
+			}
+		}
+	}
+}
+
+// parseRateLimits converts the configured limits.
+func parseRateLimits(cfg config.RateLimitConfig) (middleware.RateLimits, error) {
+	limits := middleware.RateLimits{Routes: make(map[string]redis.Limit, len(cfg.Routes))}
+	if cfg.Default != "" {
+		requests, window, err := config.ParseRateLimit(cfg.Default)
+		if err != nil {
+			return limits, err
+		}
+		limits.Default = redis.Limit{Requests: requests, Window: window}
+	}
+	for route, limit := range cfg.Routes {
+		requests, window, err := config.ParseRateLimit(limit)
+		if err != nil {
+			return limits, fmt.Errorf("route %s: %w", route, err)
+		}
+		limits.Routes[route] = redis.Limit{Requests: requests, Window: window}
+	}
+	return limits, nil
+}
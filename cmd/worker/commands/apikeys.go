@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/apikey"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+)
+
+var (
+	apiKeyName   string
+	apiKeyScopes string
+	apiKeyQuota  int64
+)
+
+// apiKeysCmd groups the API key management commands
+var apiKeysCmd = &cobra.Command{
+	Use:   "apikeys",
+	Short: "Manage API keys",
+	Long: `Manage the API keys that clients use to call the API when AUTH_ENABLED is set.
+
+Keys are stored hashed; the secret is printed once when a key is created.
+
+Examples:
+  # Issue a read-only key with 10,000 requests per day
+  worker apikeys create --name partner-team --scopes rates:read --quota 10000
+
+  # Show all keys with today's usage
+  worker apikeys list
+
+  # Revoke a key
+  worker apikeys revoke 2f1c9a4e-...`,
+}
+
+var apiKeysCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key and print its secret",
+	RunE:  runAPIKeysCreate,
+}
+
+var apiKeysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys with their usage today",
+	RunE:  runAPIKeysList,
+}
+
+var apiKeysRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke an API key",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAPIKeysRevoke,
+}
+
+func init() {
+	rootCmd.AddCommand(apiKeysCmd)
+	apiKeysCmd.AddCommand(apiKeysCreateCmd, apiKeysListCmd, apiKeysRevokeCmd)
+
+	apiKeysCreateCmd.Flags().StringVar(&apiKeyName, "name", "", "who the key is issued to, e.g. a partner team")
+	apiKeysCreateCmd.Flags().StringVar(&apiKeyScopes, "scopes", string(apikey.ScopeRatesRead), "comma-separated scopes (rates:read, rates:write, webhooks:manage, admin)")
+	apiKeysCreateCmd.Flags().Int64Var(&apiKeyQuota, "quota", 0, "requests allowed per UTC day (0 for unlimited)")
+	apiKeysCreateCmd.MarkFlagRequired("name")
+}
+
+// openAPIKeys connects to the database and returns the API key repository.
+// The returned function closes the connection.
+func openAPIKeys() (apikey.Repository, *slog.Logger, func(), error) {
+	if configPath != "" {
+		os.Setenv("CONFIG_PATH", configPath)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("load config: %w", err)
+	}
+
+	if verbose {
+		cfg.Logger.Level = "debug"
+	}
+	log := logger.New(cfg.Logger)
+	log = logger.WithContext(log, serviceName, serviceVersion)
+
+	db, err := persistence.NewConnection(cfg.Database, log)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("initialize database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("get database connection: %w", err)
+	}
+
+	return persistence.NewAPIKeyRepository(db, log), log, func() { sqlDB.Close() }, nil
+}
+
+func runAPIKeysCreate(cmd *cobra.Command, args []string) error {
+	var scopes []apikey.Scope
+	for s := range strings.SplitSeq(apiKeyScopes, ",") {
+		scope, err := apikey.ParseScope(s)
+		if err != nil {
+			return err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	keys, log, closeDB, err := openAPIKeys()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	key, secret, err := command.NewCreateAPIKeyHandler(keys, log).Handle(context.Background(), command.CreateAPIKeyCommand{
+		Name:       apiKeyName,
+		Scopes:     scopes,
+		DailyQuota: apiKeyQuota,
+	})
+	if err != nil {
+		return fmt.Errorf("create api key: %w", err)
+	}
+
+	fmt.Printf("ID:     %s\n", key.ID)
+	fmt.Printf("Name:   %s\n", key.Name)
+	fmt.Printf("Scopes: %s\n", apiKeyScopes)
+	fmt.Printf("Quota:  %s\n", formatQuota(key.DailyQuota))
+	fmt.Printf("\nAPI key (shown only once, store it now):\n\n  %s\n\n", secret)
+	return nil
+}
+
+func runAPIKeysList(cmd *cobra.Command, args []string) error {
+	keys, log, closeDB, err := openAPIKeys()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	list, err := query.NewListAPIKeysHandler(keys, log).Handle(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tUSED TODAY\tQUOTA\tLAST USED\tSTATUS")
+	for _, key := range list {
+		lastUsed, status := "-", "active"
+		if key.LastUsedAt != nil {
+			lastUsed = key.LastUsedAt.Local().Format(time.DateTime)
+		}
+		if key.RevokedAt != nil {
+			status = "revoked " + key.RevokedAt.Local().Format(time.DateOnly)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s…\t%s\t%d\t%s\t%s\t%s\n",
+			key.ID, key.Name, key.Prefix, strings.Join(key.Scopes, ","),
+			key.UsedToday, formatQuota(key.DailyQuota), lastUsed, status,
+		)
+	}
+	return w.Flush()
+}
+
+func runAPIKeysRevoke(cmd *cobra.Command, args []string) error {
+	keys, log, closeDB, err := openAPIKeys()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if err := command.NewRevokeAPIKeyHandler(keys, log).Handle(context.Background(), command.RevokeAPIKeyCommand{ID: args[0]}); err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	fmt.Printf("API key %s revoked\n", args[0])
+	return nil
+}
+
+func formatQuota(quota int64) string {
+	if quota == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d/day", quota)
+}
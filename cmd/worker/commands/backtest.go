@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+var (
+	backtestPair    string
+	backtestHorizon int
+	backtestFolds   int
+	backtestHistory int
+	backtestEnd     string
+)
+
+// backtestCmd represents the backtest command
+var backtestCmd = &cobra.Command{
+	Use:   "backtest",
+	Short: "Evaluate the forecast models on stored history",
+	Long: `Evaluate the forecast models served at /api/v1/rates/forecast on the stored
+rates of a pair.
+
+Each model forecasts the last --folds windows of --horizon rates from the rates
+before them, as if it had been asked at the time. The mean absolute error and
+the mean absolute percentage error against the actual rates are reported per
+model; a model worse than the drift baseline is not worth its complexity.
+
+Examples:
+  # Evaluate 30-rate forecasts of CNY/JPY over the last 10 windows
+  worker backtest --pair CNY/JPY
+
+  # Evaluate weekly forecasts over 2023
+  worker backtest --pair USD/JPY --horizon 5 --folds 50 --end 2023-12-31`,
+	RunE: runBacktest,
+}
+
+func init() {
+	rootCmd.AddCommand(backtestCmd)
+
+	backtestCmd.Flags().StringVar(&backtestPair, "pair", "", "currency pair to evaluate (e.g., CNY/JPY)")
+	backtestCmd.Flags().IntVar(&backtestHorizon, "horizon", 30, "number of rates forecast from each origin")
+	backtestCmd.Flags().IntVar(&backtestFolds, "folds", 10, "number of windows evaluated")
+	backtestCmd.Flags().IntVar(&backtestHistory, "history", 730, "number of rates up to the end date to evaluate on")
+	backtestCmd.Flags().StringVar(&backtestEnd, "end", "", "last date of the history (YYYY-MM-DD, default: today)")
+	backtestCmd.MarkFlagRequired("pair")
+}
+
+func runBacktest(cmd *cobra.Command, args []string) error {
+	pair, err := currency.ParsePair(backtestPair)
+	if err != nil {
+		return fmt.Errorf("invalid currency pair: %w", err)
+	}
+	if backtestHorizon < 1 || backtestFolds < 1 || backtestHistory < 1 {
+		return fmt.Errorf("horizon, folds and history must be positive")
+	}
+	end := timeutil.Today()
+	if backtestEnd != "" {
+		if end, err = timeutil.ParseDate(backtestEnd); err != nil {
+			return fmt.Errorf("invalid end date: %w", err)
+		}
+	}
+
+	// Load configuration
+	if configPath != "" {
+		os.Setenv("CONFIG_PATH", configPath)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if verbose {
+		cfg.Logger.Level = "debug"
+	}
+	log := logger.New(cfg.Logger)
+	log = logger.WithContext(log, serviceName, serviceVersion)
+
+	db, err := persistence.NewConnection(cfg.Database, log)
+	if err != nil {
+		return fmt.Errorf("initialize database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("get database connection: %w", err)
+	}
+	defer sqlDB.Close()
+
+	results, err := query.NewBacktestForecastsHandler(persistence.NewRateRepository(db, log), log).Handle(context.Background(), query.BacktestForecastsQuery{
+		Pair:    pair,
+		Horizon: backtestHorizon,
+		Folds:   backtestFolds,
+		History: backtestHistory,
+		End:     end,
+	})
+	if err != nil {
+		return fmt.Errorf("backtest: %w", err)
+	}
+
+	fmt.Printf("Backtest of %s: %d-rate forecasts up to %s\n\n", pair, backtestHorizon, end.Format(time.DateOnly))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tFOLDS\tFORECASTS\tMAE\tMAPE")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.6f\t%.3f%%\n", r.Model, r.Folds, r.Forecasts, r.MAE, r.MAPE)
+	}
+	return w.Flush()
+}
@@ -7,10 +7,13 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"gorm.io/gorm"
 
 	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/postgres"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
 )
 
 var (
@@ -27,6 +30,8 @@ var cleanCmd = &cobra.Command{
 	Long: `Clean (delete) exchange rate data from database based on various criteria.
 
 This is useful for removing incorrect data or data from specific date ranges.
+The revision history of the deleted rates is deleted with them, so point-in-time
+queries (asOf) no longer return them for any time.
 
 Examples:
   # Delete all JPY/USD data (dry-run first)
@@ -71,7 +76,11 @@ func runClean(cmd *cobra.Command, args []string) error {
 		cfg.Logger.Level = "debug"
 	}
 	log := logger.New(cfg.Logger)
-	log = logger.WithContext(log, "rateflow-worker", "1.5.3")
+	log = logger.WithContext(log, serviceName, serviceVersion)
+	defer pushMetrics(cfg, log, "rateflow_worker_clean")
+
+	ctx, endTracing := startTracing(cfg, log, "worker clean")
+	defer endTracing()
 
 	if cleanDryRun {
 		log.Warn("DRY RUN MODE - no data will be deleted")
@@ -85,7 +94,7 @@ func runClean(cmd *cobra.Command, args []string) error {
 	)
 
 	// Initialize database
-	db, err := postgres.NewConnection(cfg.Database, log)
+	db, err := persistence.NewConnection(cfg.Database, log)
 	if err != nil {
 		return fmt.Errorf("initialize database: %w", err)
 	}
@@ -95,17 +104,17 @@ func runClean(cmd *cobra.Command, args []string) error {
 	}
 	defer sqlDB.Close()
 
-	// Build query
-	query := db.Table("rates")
-
-	// Apply filters
+	// Build the filters; dates are compared as YYYY-MM-DD like the stored ones
+	var filters []func(*gorm.DB) *gorm.DB
 	if cleanPair != "" {
 		// Parse pair
 		parts := splitPair(cleanPair)
 		if len(parts) != 2 {
 			return fmt.Errorf("invalid pair format: %s", cleanPair)
 		}
-		query = query.Where("base_currency = ? AND quote_currency = ?", parts[0], parts[1])
+		filters = append(filters, func(q *gorm.DB) *gorm.DB {
+			return q.Where("base_currency = ? AND quote_currency = ?", parts[0], parts[1])
+		})
 		log.Info("filtering by pair", "base", parts[0], "quote", parts[1])
 	}
 
@@ -114,7 +123,9 @@ func runClean(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("invalid before date: %w", err)
 		}
-		query = query.Where("effective_date < ?", beforeDate)
+		filters = append(filters, func(q *gorm.DB) *gorm.DB {
+			return q.Where("effective_date < ?", timeutil.FormatDate(beforeDate))
+		})
 		log.Info("filtering before date", "date", cleanBefore)
 	}
 
@@ -123,13 +134,16 @@ func runClean(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("invalid after date: %w", err)
 		}
-		query = query.Where("effective_date > ?", afterDate)
+		filters = append(filters, func(q *gorm.DB) *gorm.DB {
+			return q.Where("effective_date > ?", timeutil.FormatDate(afterDate))
+		})
 		log.Info("filtering after date", "date", cleanAfter)
 	}
+	scope := func(q *gorm.DB) *gorm.DB { return q.Scopes(filters...) }
 
 	// Count affected rows first
 	var count int64
-	if err := query.Count(&count).Error; err != nil {
+	if err := db.WithContext(ctx).Model(&postgres.RateModel{}).Scopes(scope).Count(&count).Error; err != nil {
 		return fmt.Errorf("count rows: %w", err)
 	}
 
@@ -146,7 +160,7 @@ func runClean(cmd *cobra.Command, args []string) error {
 	}
 
 	// Confirm deletion
-	fmt.Printf("\n⚠️  WARNING: About to delete %d rows from database!\n", count)
+	fmt.Printf("\n⚠️  WARNING: About to delete %d rows from database, with their revision history!\n", count)
 	fmt.Printf("Filters:\n")
 	if cleanPair != "" {
 		fmt.Printf("  - Pair: %s\n", cleanPair)
@@ -170,13 +184,14 @@ func runClean(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("deletion cancelled")
 	}
 
-	// Delete rows
-	result := query.Delete(&postgres.RateModel{})
-	if result.Error != nil {
-		return fmt.Errorf("delete rows: %w", result.Error)
+	// Delete rows; their revisions go with them, so as-of reads no longer
+	// return the deleted rates for any point in time
+	deleted, err := postgres.DeleteRates(db.WithContext(ctx), scope)
+	if err != nil {
+		return fmt.Errorf("delete rows: %w", err)
 	}
 
-	log.Info("rows deleted successfully", "count", result.RowsAffected)
+	log.Info("rows deleted successfully", "count", deleted)
 
 	return nil
 }
@@ -13,8 +13,8 @@ import (
 	"github.com/tyokyo320/rateflow/internal/domain/currency"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
-	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/postgres"
-	redisCache "github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/provider/unionpay"
 )
 
@@ -76,7 +76,11 @@ func runFetch(cmd *cobra.Command, args []string) error {
 		cfg.Logger.Level = "debug"
 	}
 	log := logger.New(cfg.Logger)
-	log = logger.WithContext(log, "rateflow-worker", "1.5.3")
+	log = logger.WithContext(log, serviceName, serviceVersion)
+	defer pushMetrics(cfg, log, "rateflow_worker_fetch")
+
+	ctx, endTracing := startTracing(cfg, log, "worker fetch")
+	defer endTracing()
 
 	log.Info("starting fetch command",
 		slog.String("pair", fetchPair),
@@ -90,7 +94,7 @@ func runFetch(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize database
-	db, err := postgres.NewConnection(cfg.Database, log)
+	db, err := persistence.NewConnection(cfg.Database, log)
 	if err != nil {
 		return fmt.Errorf("initialize database: %w", err)
 	}
@@ -100,28 +104,33 @@ func runFetch(cmd *cobra.Command, args []string) error {
 	}
 	defer sqlDB.Close()
 
-	// Initialize Redis cache
-	cache := redisCache.NewCache(cfg.Redis, log)
+	// Initialize cache (degrades to in-process caching if Redis is unreachable)
+	cache := persistence.NewCache(ctx, cfg.Cache, cfg.Redis, log)
 	defer cache.Close()
 
-	// Test Redis connection
-	ctx := context.Background()
-	if err := cache.Ping(ctx); err != nil {
-		log.Warn("redis connection failed, continuing without cache", "error", err)
-	}
+	// Stored rates are announced to the API's stream subscribers
+	events := persistence.NewRateEvents(cfg.Redis, cfg.Stream.Retention, log)
+	defer events.Close()
 
-	// Initialize repository
-	rateRepo := postgres.NewRateRepository(db, log)
+	// Initialize repositories
+	rateRepo := persistence.NewRateRepository(db, log)
+	statusRepo := persistence.NewProviderStatusRepository(db, log)
 
 	// Initialize provider
 	var provider any
 	switch fetchProvider {
 	case "unionpay":
-		provider = unionpay.NewClient(log)
+		provider = metrics.InstrumentProvider(unionpay.NewClient(log))
 	default:
 		return fmt.Errorf("unknown provider: %s", fetchProvider)
 	}
 
+	// Implausible rates are held for review instead of stored
+	screen, err := newRateScreen(cfg, db, rateRepo, log)
+	if err != nil {
+		return err
+	}
+
 	// Initialize command handler
 	fetchHandler := command.NewFetchRateHandler(
 		rateRepo,
@@ -133,7 +142,11 @@ func runFetch(cmd *cobra.Command, args []string) error {
 			SupportsMulti() bool
 			FetchMulti(ctx context.Context, pairs []currency.Pair, date time.Time) (map[string]float64, error)
 		}),
+		statusRepo,
 		cache,
+		events,
+		newWebhookEmitter(cfg, db, rateRepo, log),
+		screen,
 		log,
 	)
 
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -15,8 +14,8 @@ import (
 	"github.com/tyokyo320/rateflow/internal/domain/provider"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
-	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/postgres"
-	redisCache "github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/provider/unionpay"
 )
 
@@ -83,7 +82,11 @@ func runFetchMatrix(cmd *cobra.Command, args []string) error {
 		cfg.Logger.Level = "debug"
 	}
 	log := logger.New(cfg.Logger)
-	log = logger.WithContext(log, "rateflow-worker", "1.5.3")
+	log = logger.WithContext(log, serviceName, serviceVersion)
+	defer pushMetrics(cfg, log, "rateflow_worker_fetch_matrix")
+
+	ctx, endTracing := startTracing(cfg, log, "worker fetch-matrix")
+	defer endTracing()
 
 	// Parse currencies
 	currencyList := strings.Split(strings.ToUpper(strings.ReplaceAll(matrixCurrencies, " ", "")), ",")
@@ -128,7 +131,7 @@ func runFetchMatrix(cmd *cobra.Command, args []string) error {
 	log.Info("generated currency pairs", "count", len(pairs))
 
 	// Initialize database
-	db, err := postgres.NewConnection(cfg.Database, log)
+	db, err := persistence.NewConnection(cfg.Database, log)
 	if err != nil {
 		return fmt.Errorf("initialize database: %w", err)
 	}
@@ -138,22 +141,32 @@ func runFetchMatrix(cmd *cobra.Command, args []string) error {
 	}
 	defer sqlDB.Close()
 
-	// Initialize Redis cache
-	cache := redisCache.NewCache(cfg.Redis, log)
+	// Initialize cache (degrades to in-process caching if Redis is unreachable)
+	cache := persistence.NewCache(ctx, cfg.Cache, cfg.Redis, log)
 	defer cache.Close()
 
+	// Stored rates are announced to the API's stream subscribers
+	events := persistence.NewRateEvents(cfg.Redis, cfg.Stream.Retention, log)
+	defer events.Close()
+
 	// Initialize provider
 	var prov provider.Provider
 	switch matrixProvider {
 	case "unionpay":
-		prov = unionpay.NewClient(log)
+		prov = metrics.InstrumentProvider(unionpay.NewClient(log))
 	default:
 		return fmt.Errorf("unknown provider: %s", matrixProvider)
 	}
 
-	// Initialize repository and handler
-	rateRepo := postgres.NewRateRepository(db, log)
-	handler := command.NewFetchRateHandler(rateRepo, prov, cache, log)
+	// Initialize repositories and handler
+	rateRepo := persistence.NewRateRepository(db, log)
+	statusRepo := persistence.NewProviderStatusRepository(db, log)
+	webhooks := newWebhookEmitter(cfg, db, rateRepo, log)
+	screen, err := newRateScreen(cfg, db, rateRepo, log)
+	if err != nil {
+		return err
+	}
+	handler := command.NewFetchRateHandler(rateRepo, prov, statusRepo, cache, events, webhooks, screen, log)
 
 	// Determine dates to fetch
 	var dates []time.Time
@@ -189,7 +202,6 @@ func runFetchMatrix(cmd *cobra.Command, args []string) error {
 	log.Info("fetching rates", "pairs", len(pairs), "dates", len(dates), "total_operations", len(pairs)*len(dates))
 
 	// Fetch rates
-	ctx := context.Background()
 	successCount := 0
 	errorCount := 0
 	skippedCount := 0
@@ -1,9 +1,32 @@
 package commands
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
 	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/tracing"
 )
 
+const (
+	serviceName    = "rateflow-worker"
+	serviceVersion = "1.5.3"
+)
+
+// pushTimeout bounds pushing metrics and flushing spans at the end of a run.
+const pushTimeout = 10 * time.Second
+
 var (
 	configPath string
 	verbose    bool
@@ -30,3 +53,80 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "config file path (default: use environment variables)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose logging")
 }
+
+// pushMetrics pushes the metrics of a batch run to the configured Pushgateway.
+// A failed push is logged and does not fail the run.
+func pushMetrics(cfg *config.Config, log *slog.Logger, job string) {
+	if cfg.Metrics.PushgatewayURL == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+
+	if err := metrics.Push(ctx, cfg.Metrics.PushgatewayURL, job); err != nil {
+		log.Warn("failed to push metrics", "url", cfg.Metrics.PushgatewayURL, "error", err)
+		return
+	}
+	log.Debug("metrics pushed", "url", cfg.Metrics.PushgatewayURL, "job", job)
+}
+
+// startTracing sets up tracing for a worker run and starts the span covering
+// it, so that the database, cache and provider spans of the run share a trace.
+// The returned function ends the span and flushes the exporter. Tracing
+// failures are logged and do not fail the run.
+func startTracing(cfg *config.Config, log *slog.Logger, name string) (context.Context, func()) {
+	ctx := context.Background()
+	shutdown, err := tracing.Setup(ctx, cfg.Tracing, serviceName, serviceVersion, log)
+	if err != nil {
+		log.Warn("failed to set up tracing", "error", err)
+		shutdown = func(context.Context) error { return nil }
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, name)
+	return ctx, func() {
+		span.End()
+
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Warn("failed to flush spans", "error", err)
+		}
+	}
+}
+
+// newWebhookEmitter returns the handler queuing webhook deliveries of the rates
+// a run stores, or nil when webhooks are disabled. The API sends them.
+func newWebhookEmitter(cfg *config.Config, db *gorm.DB, rateRepo rate.Repository, log *slog.Logger) *command.EmitWebhooksHandler {
+	if !cfg.Webhooks.Enabled {
+		return nil
+	}
+	return command.NewEmitWebhooksHandler(persistence.NewWebhookRepository(db, log), rateRepo, log)
+}
+
+// newRateScreen returns the screen quarantining implausible fetched rates for
+// review, or nil when screening is disabled. The API releases or discards them.
+func newRateScreen(cfg *config.Config, db *gorm.DB, rateRepo rate.Repository, log *slog.Logger) (*command.RateScreen, error) {
+	if !cfg.Anomaly.Enabled {
+		return nil, nil
+	}
+
+	pairLimits := make(map[currency.Pair]float64, len(cfg.Anomaly.PairJumpPercent))
+	for name, limit := range cfg.Anomaly.PairJumpPercent {
+		pair, err := currency.ParsePair(name)
+		if err != nil {
+			return nil, fmt.Errorf("anomaly jump limit: %w", err)
+		}
+		pairLimits[pair] = limit
+	}
+
+	policy := anomaly.Policy{
+		Lookback:            cfg.Anomaly.Lookback,
+		MinHistory:          cfg.Anomaly.MinHistory,
+		ZScore:              cfg.Anomaly.ZScore,
+		MaxJumpPercent:      cfg.Anomaly.MaxJumpPercent,
+		PairJumpPercent:     pairLimits,
+		ReciprocalTolerance: cfg.Anomaly.ReciprocalTolerance,
+	}
+	return command.NewRateScreen(rateRepo, persistence.NewSuspectRateRepository(db, log), policy, log), nil
+}
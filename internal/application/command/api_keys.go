@@ -0,0 +1,141 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tyokyo320/rateflow/internal/domain/apikey"
+)
+
+// CreateAPIKeyCommand represents a command to issue an API key.
+type CreateAPIKeyCommand struct {
+	Name       string
+	Scopes     []apikey.Scope
+	DailyQuota int64 // 0 means unlimited
+}
+
+// CreateAPIKeyHandler handles the create API key command.
+type CreateAPIKeyHandler struct {
+	keys   apikey.Repository
+	logger *slog.Logger
+}
+
+// NewCreateAPIKeyHandler creates a new create API key command handler.
+func NewCreateAPIKeyHandler(keys apikey.Repository, logger *slog.Logger) *CreateAPIKeyHandler {
+	return &CreateAPIKeyHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// Handle issues the key and returns it with its secret.
+// The secret is not stored and cannot be retrieved later.
+func (h *CreateAPIKeyHandler) Handle(ctx context.Context, cmd CreateAPIKeyCommand) (*apikey.Key, string, error) {
+	key, secret, err := apikey.New(cmd.Name, cmd.Scopes, cmd.DailyQuota, time.Now())
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := h.keys.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("save api key: %w", err)
+	}
+
+	h.logger.InfoContext(ctx, "api key created",
+		"id", key.ID,
+		"name", key.Name,
+		"scopes", key.Scopes,
+		"daily_quota", key.DailyQuota,
+	)
+	return key, secret, nil
+}
+
+// RevokeAPIKeyCommand represents a command to revoke an API key.
+type RevokeAPIKeyCommand struct {
+	ID string
+}
+
+// RevokeAPIKeyHandler handles the revoke API key command.
+type RevokeAPIKeyHandler struct {
+	keys   apikey.Repository
+	logger *slog.Logger
+}
+
+// NewRevokeAPIKeyHandler creates a new revoke API key command handler.
+func NewRevokeAPIKeyHandler(keys apikey.Repository, logger *slog.Logger) *RevokeAPIKeyHandler {
+	return &RevokeAPIKeyHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// Handle revokes the key. Requests made with it are rejected from then on.
+func (h *RevokeAPIKeyHandler) Handle(ctx context.Context, cmd RevokeAPIKeyCommand) error {
+	if _, err := uuid.Parse(cmd.ID); err != nil {
+		return apikey.ErrKeyNotFound{ID: cmd.ID}
+	}
+
+	if err := h.keys.Revoke(ctx, cmd.ID, time.Now()); err != nil {
+		return err
+	}
+
+	h.logger.InfoContext(ctx, "api key revoked", "id", cmd.ID)
+	return nil
+}
+
+// AuthenticateCommand represents a request made with an API key.
+type AuthenticateCommand struct {
+	Secret string
+	Scope  apikey.Scope // scope the request requires
+}
+
+// AuthenticateHandler checks API keys and counts their usage.
+type AuthenticateHandler struct {
+	keys   apikey.Repository
+	logger *slog.Logger
+}
+
+// NewAuthenticateHandler creates a new authenticate command handler.
+func NewAuthenticateHandler(keys apikey.Repository, logger *slog.Logger) *AuthenticateHandler {
+	return &AuthenticateHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// Handle returns the key the secret belongs to. It only reads the key; usage
+// is counted by RecordUsage once the request is otherwise accepted.
+//
+// Unknown and revoked keys fail with ErrKeyNotFound and ErrKeyRevoked, keys
+// without the scope with ErrScopeDenied.
+func (h *AuthenticateHandler) Handle(ctx context.Context, cmd AuthenticateCommand) (*apikey.Key, error) {
+	key, err := h.keys.FindByHash(ctx, apikey.Hash(cmd.Secret))
+	if err != nil {
+		return nil, err
+	}
+	if !key.Active() {
+		return nil, apikey.ErrKeyRevoked{ID: key.ID}
+	}
+	if !key.Allows(cmd.Scope) {
+		return key, apikey.ErrScopeDenied{Scope: cmd.Scope}
+	}
+	return key, nil
+}
+
+// RecordUsage counts a request of key to route and returns the key's requests
+// so far today. Once the daily quota is used up the request fails with
+// ErrQuotaExceeded and is not counted.
+func (h *AuthenticateHandler) RecordUsage(ctx context.Context, key *apikey.Key, route string) (int64, error) {
+	used, err := h.keys.RecordUsage(ctx, key.ID, route, time.Now(), key.DailyQuota)
+	if errors.As(err, new(apikey.ErrQuotaExceeded)) {
+		return used, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("record api key usage: %w", err)
+	}
+	return used, nil
+}
@@ -2,6 +2,7 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -10,6 +11,8 @@ import (
 	"github.com/tyokyo320/rateflow/internal/domain/provider"
 	"github.com/tyokyo320/rateflow/internal/domain/rate"
 	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/pkg/genericrepo"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
 )
 
 // FetchRateCommand represents a command to fetch and store an exchange rate.
@@ -20,24 +23,34 @@ type FetchRateCommand struct {
 
 // FetchRateHandler handles the fetch rate command.
 type FetchRateHandler struct {
-	rateRepo rate.Repository
-	provider provider.Provider
-	cache    *redis.Cache
-	logger   *slog.Logger
+	rateRepo  rate.Repository
+	provider  provider.Provider
+	statuses  provider.StatusRepository
+	screen    *RateScreen
+	publisher ratePublisher
+	logger    *slog.Logger
 }
 
 // NewFetchRateHandler creates a new fetch rate command handler.
+// webhooks may be nil, in which case no webhooks are emitted, and screen may
+// be nil, in which case every fetched rate is stored.
 func NewFetchRateHandler(
 	rateRepo rate.Repository,
 	provider provider.Provider,
-	cache *redis.Cache,
+	statuses provider.StatusRepository,
+	cache redis.CacheInterface,
+	events rate.EventLog,
+	webhooks *EmitWebhooksHandler,
+	screen *RateScreen,
 	logger *slog.Logger,
 ) *FetchRateHandler {
 	return &FetchRateHandler{
-		rateRepo: rateRepo,
-		provider: provider,
-		cache:    cache,
-		logger:   logger,
+		rateRepo:  rateRepo,
+		provider:  provider,
+		statuses:  statuses,
+		screen:    screen,
+		publisher: newRatePublisher(cache, events, webhooks, logger),
+		logger:    logger,
 	}
 }
 
@@ -49,23 +62,32 @@ func (h *FetchRateHandler) Handle(ctx context.Context, cmd FetchRateCommand) err
 		"provider", h.provider.Name(),
 	)
 
-	// Check if rate already exists
-	exists, err := h.rateRepo.ExistsByPairAndDate(ctx, cmd.Pair, cmd.Date)
-	if err != nil {
-		h.logger.Error("failed to check if rate exists", "error", err)
-		return fmt.Errorf("check rate existence: %w", err)
+	// A rate from this provider is fetched again so that restatements are
+	// recorded as revisions; one from another source is left alone
+	source := rate.Source(h.provider.Name())
+	stored, err := storedRate(ctx, h.rateRepo, cmd.Pair, cmd.Date, source)
+	if err != nil && !errors.As(err, new(rate.ErrRateNotFound)) {
+		h.logger.Error("failed to find stored rate", "error", err)
+		return fmt.Errorf("find stored rate: %w", err)
 	}
-
-	if exists {
-		h.logger.Info("rate already exists, skipping",
-			"pair", cmd.Pair.String(),
-			"date", cmd.Date.Format("2006-01-02"),
-		)
-		return nil
+	if stored == nil {
+		exists, err := h.rateRepo.ExistsByPairAndDate(ctx, cmd.Pair, cmd.Date)
+		if err != nil {
+			h.logger.Error("failed to check if rate exists", "error", err)
+			return fmt.Errorf("check rate existence: %w", err)
+		}
+		if exists {
+			h.logger.Info("rate from another source already exists, skipping",
+				"pair", cmd.Pair.String(),
+				"date", cmd.Date.Format("2006-01-02"),
+			)
+			return nil
+		}
 	}
 
 	// Fetch rate from provider
 	rateValue, err := h.provider.FetchRate(ctx, cmd.Pair, cmd.Date)
+	h.recordOutcome(ctx, err)
 	if err != nil {
 		h.logger.Error("failed to fetch rate from provider",
 			"error", err,
@@ -75,38 +97,152 @@ func (h *FetchRateHandler) Handle(ctx context.Context, cmd FetchRateCommand) err
 		return fmt.Errorf("fetch rate from provider: %w", err)
 	}
 
+	if stored != nil && stored.HasValue(rateValue) {
+		h.logger.Info("rate unchanged, skipping",
+			"pair", cmd.Pair.String(),
+			"date", cmd.Date.Format("2006-01-02"),
+		)
+		return nil
+	}
+
 	// Create rate entity
 	r, err := rate.NewRate(
 		cmd.Pair,
 		rateValue,
 		cmd.Date,
-		rate.Source(h.provider.Name()),
+		source,
 	)
 	if err != nil {
 		h.logger.Error("failed to create rate entity", "error", err)
 		return fmt.Errorf("create rate entity: %w", err)
 	}
 
-	// Save to repository
+	// Hold back rates that do not fit the pair's history until reviewed
+	if h.screen != nil {
+		suspect, err := h.screen.Screen(ctx, r)
+		if err != nil {
+			h.logger.Error("failed to screen rate", "error", err)
+			return fmt.Errorf("screen rate: %w", err)
+		}
+		if suspect != nil {
+			h.logger.Warn("rate quarantined for review",
+				"suspect_id", suspect.ID,
+				"pair", r.Pair().String(),
+				"rate", r.Value(),
+				"date", r.EffectiveDate().Format("2006-01-02"),
+				"status", suspect.Status,
+			)
+			return nil
+		}
+	}
+
+	// Save to repository, attributing the change to the worker and provider.
+	// A restated value revises the stored rate, which keeps its ID.
+	reason := "provider fetch"
+	if stored != nil {
+		reason = "provider restatement"
+	}
+	ctx = rate.WithChangeInfo(ctx, rate.ChangeInfo{
+		Actor:   rate.ActorWorker,
+		ActorID: h.provider.Name(),
+		Reason:  reason,
+	})
 	if err := h.rateRepo.Create(ctx, r); err != nil {
 		h.logger.Error("failed to save rate", "error", err)
 		return fmt.Errorf("save rate: %w", err)
 	}
 
-	h.logger.Info("rate fetched and saved successfully",
-		"id", r.ID(),
-		"pair", r.Pair().String(),
-		"rate", r.Value(),
-		"date", r.EffectiveDate().Format("2006-01-02"),
+	if stored != nil {
+		h.logger.Info("rate restated by provider",
+			"id", stored.ID(),
+			"pair", r.Pair().String(),
+			"previous", stored.Value(),
+			"rate", r.Value(),
+			"date", r.EffectiveDate().Format("2006-01-02"),
+		)
+		if r, err = storedRate(ctx, h.rateRepo, cmd.Pair, cmd.Date, source); err != nil {
+			h.logger.Error("failed to find restated rate", "error", err)
+			return fmt.Errorf("find restated rate: %w", err)
+		}
+	} else {
+		h.logger.Info("rate fetched and saved successfully",
+			"id", r.ID(),
+			"pair", r.Pair().String(),
+			"rate", r.Value(),
+			"date", r.EffectiveDate().Format("2006-01-02"),
+		)
+	}
+
+	h.publisher.publish(ctx, r)
+
+	return nil
+}
+
+// storedRate returns the stored rate of pair from source on date.
+// Returns rate.ErrRateNotFound if there is none.
+func storedRate(ctx context.Context, repo rate.Repository, pair currency.Pair, date time.Time, source rate.Source) (*rate.Rate, error) {
+	rates, err := repo.FindAll(ctx,
+		genericrepo.Where(
+			genericrepo.Eq(rate.FieldBaseCurrency, pair.Base().String()),
+			genericrepo.Eq(rate.FieldQuoteCurrency, pair.Quote().String()),
+			genericrepo.Eq(rate.FieldEffectiveDate, timeutil.FormatDate(date)),
+			genericrepo.Eq(rate.FieldSource, string(source)),
+		),
+		genericrepo.WithLimit(1),
 	)
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, rate.ErrRateNotFound{}
+	}
+	return rates[0], nil
+}
 
-	// Invalidate cache for this pair
-	cacheKey := fmt.Sprintf("latest:%s", cmd.Pair.String())
-	if err := h.cache.Delete(ctx, cacheKey); err != nil {
-		h.logger.Warn("failed to invalidate cache", "error", err, "key", cacheKey)
+// recordOutcome stores the outcome of a provider fetch for health reporting.
+// Failing to record it must not fail the fetch itself.
+func (h *FetchRateHandler) recordOutcome(ctx context.Context, fetchErr error) {
+	if err := h.statuses.Record(ctx, h.provider.Name(), time.Now(), fetchErr); err != nil {
+		h.logger.Warn("failed to record provider status", "provider", h.provider.Name(), "error", err)
 	}
+}
 
-	return nil
+// ratePublisher announces newly stored rates: it invalidates the cached
+// figures of their pair, publishes them to the rate stream and emits webhooks.
+type ratePublisher struct {
+	keys     *redis.Keys
+	events   rate.EventLog
+	webhooks *EmitWebhooksHandler // optional
+	logger   *slog.Logger
+}
+
+func newRatePublisher(cache redis.CacheInterface, events rate.EventLog, webhooks *EmitWebhooksHandler, logger *slog.Logger) ratePublisher {
+	return ratePublisher{
+		keys:     redis.NewKeys(cache, logger),
+		events:   events,
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// publish announces r. The rate is stored either way, so failures are only logged.
+func (p ratePublisher) publish(ctx context.Context, r *rate.Rate) {
+	// Invalidate everything cached for this pair, in both directions
+	if err := p.keys.Invalidate(ctx, r.Pair()); err != nil {
+		p.logger.Warn("failed to invalidate cache", "error", err, "pair", r.Pair().String())
+	}
+
+	// Announce the rate to stream subscribers
+	if err := p.events.Publish(ctx, rate.NewStoredEvent(r)); err != nil {
+		p.logger.Warn("failed to publish rate event", "error", err, "pair", r.Pair().String())
+	}
+
+	// Queue webhook deliveries; they are sent, and retried, by the API
+	if p.webhooks != nil {
+		if _, err := p.webhooks.Handle(ctx, EmitWebhooksCommand{Rate: r}); err != nil {
+			p.logger.Warn("failed to emit webhooks", "error", err, "pair", r.Pair().String())
+		}
+	}
 }
 
 // FetchRateResult contains the result of fetching a rate.
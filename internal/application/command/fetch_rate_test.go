@@ -0,0 +1,129 @@
+package command_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+// valueProvider answers every fetch with its current value.
+type valueProvider struct {
+	value float64
+	calls int
+}
+
+func (p *valueProvider) Name() string { return "unionpay" }
+
+func (p *valueProvider) FetchRate(context.Context, currency.Pair, time.Time) (float64, error) {
+	p.calls++
+	return p.value, nil
+}
+
+func (p *valueProvider) FetchLatest(context.Context, currency.Pair) (float64, error) {
+	return p.value, nil
+}
+
+func (p *valueProvider) SupportedPairs() []currency.Pair { return nil }
+
+func (p *valueProvider) SupportsMulti() bool { return false }
+
+func (p *valueProvider) FetchMulti(context.Context, []currency.Pair, time.Time) (map[string]float64, error) {
+	return nil, nil
+}
+
+func TestFetchRateHandler_Restatement(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+
+	db, err := persistence.NewConnection(config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "rateflow.db"),
+	}, log)
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	rateRepo := persistence.NewRateRepository(db, log)
+	events := memory.NewRateEvents(100, log)
+
+	prov := &valueProvider{value: 20.50}
+	handler := command.NewFetchRateHandler(rateRepo, prov, persistence.NewProviderStatusRepository(db, log),
+		memory.NewCache(100, log), events, nil, nil, log)
+
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	fetch := func() {
+		t.Helper()
+		if err := handler.Handle(ctx, command.FetchRateCommand{Pair: pair, Date: date}); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+
+	fetch()
+	first, err := rateRepo.FindByPairAndDate(ctx, pair, date)
+	if err != nil {
+		t.Fatalf("FindByPairAndDate() error = %v", err)
+	}
+
+	// An unchanged value is not stored again
+	fetch()
+
+	// The provider restates the rate
+	stream, err := events.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	prov.value = 20.56
+	fetch()
+
+	if prov.calls != 3 {
+		t.Errorf("provider called %d times, want every fetch to reach it", prov.calls)
+	}
+	restated, err := rateRepo.FindByPairAndDate(ctx, pair, date)
+	if err != nil {
+		t.Fatalf("FindByPairAndDate() error = %v", err)
+	}
+	if restated.ID() != first.ID() || restated.Value() != 20.56 {
+		t.Fatalf("restated rate = %s %v, want %s 20.56", restated.ID(), restated.Value(), first.ID())
+	}
+
+	revisions, err := rateRepo.FindRevisions(ctx, first.ID())
+	if err != nil {
+		t.Fatalf("FindRevisions() error = %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("got %d revisions, want 2", len(revisions))
+	}
+	if r := revisions[1]; r.OldValue == nil || *r.OldValue != 20.50 || r.NewValue != 20.56 ||
+		r.Actor != rate.ActorWorker || r.ActorID != "unionpay" {
+		t.Errorf("restatement revision = %+v, want 20.50 to 20.56 by the unionpay worker", r)
+	}
+
+	// What was displayed before the restatement can still be answered
+	before, err := rateRepo.FindByPairAndDateAsOf(ctx, pair, date, revisions[1].CreatedAt.Add(-time.Nanosecond))
+	if err != nil || before.Value() != 20.50 {
+		t.Errorf("rate as of before the restatement = %v, %v, want 20.50", before, err)
+	}
+
+	select {
+	case e := <-stream:
+		if e.RateID != first.ID() {
+			t.Errorf("published event of rate %s, want %s", e.RateID, first.ID())
+		}
+	case <-time.After(time.Second):
+		t.Error("restated rate was not published")
+	}
+}
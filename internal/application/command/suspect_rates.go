@@ -0,0 +1,245 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/pkg/genericrepo"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// RateScreen compares fetched rates with the recent history of their pair
+// and quarantines the implausible ones for review.
+type RateScreen struct {
+	rateRepo rate.Repository
+	suspects anomaly.Repository
+	policy   anomaly.Policy
+	logger   *slog.Logger
+}
+
+// NewRateScreen creates a rate screen checking rates against policy.
+func NewRateScreen(rateRepo rate.Repository, suspects anomaly.Repository, policy anomaly.Policy, logger *slog.Logger) *RateScreen {
+	return &RateScreen{
+		rateRepo: rateRepo,
+		suspects: suspects,
+		policy:   policy,
+		logger:   logger,
+	}
+}
+
+// Screen returns the suspect holding back r, which is not stored yet, or nil
+// if r may be stored. A rate under review, or rejected before with the same
+// value, stays held back without being quarantined again.
+func (s *RateScreen) Screen(ctx context.Context, r *rate.Rate) (*anomaly.Suspect, error) {
+	earlier, err := s.suspects.FindByRate(ctx, r.Pair(), r.EffectiveDate(), r.Source())
+	if err != nil {
+		return nil, fmt.Errorf("find suspect rates: %w", err)
+	}
+	for _, suspect := range earlier {
+		if suspect.Status == anomaly.StatusPending {
+			return suspect, nil
+		}
+		if suspect.Holds(r.Value()) {
+			if suspect.Status == anomaly.StatusRejected {
+				return suspect, nil
+			}
+			return nil, nil
+		}
+	}
+
+	history, err := s.history(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("load rate history: %w", err)
+	}
+	findings := s.policy.Check(r.Pair(), r.Value(), history)
+	if len(findings) == 0 {
+		return nil, nil
+	}
+
+	var previous *float64
+	if len(history) > 0 {
+		previous = &history[len(history)-1]
+	}
+	suspect := anomaly.NewSuspect(r, findings, previous, time.Now())
+	if err := s.suspects.Create(ctx, suspect); err != nil {
+		return nil, fmt.Errorf("save suspect rate: %w", err)
+	}
+
+	for _, f := range findings {
+		metrics.SuspectRates.WithLabelValues(r.Pair().String(), string(f.Kind)).Inc()
+	}
+	return suspect, nil
+}
+
+// history returns the values of the rates of r's pair before its date, up to
+// the policy's lookback, oldest first.
+func (s *RateScreen) history(ctx context.Context, r *rate.Rate) ([]float64, error) {
+	rates, err := s.rateRepo.FindAll(ctx,
+		genericrepo.Where(
+			genericrepo.Eq(rate.FieldBaseCurrency, r.Pair().Base().String()),
+			genericrepo.Eq(rate.FieldQuoteCurrency, r.Pair().Quote().String()),
+			genericrepo.Lt(rate.FieldEffectiveDate, timeutil.FormatDate(r.EffectiveDate())),
+		),
+		genericrepo.WithOrderBy("effective_date DESC, id DESC"),
+		genericrepo.WithLimit(max(s.policy.Lookback, 1)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float64, len(rates))
+	for i, stored := range rates {
+		values[len(rates)-1-i] = stored.Value()
+	}
+	return values, nil
+}
+
+// ApproveSuspectRateCommand represents a command to release a quarantined rate.
+type ApproveSuspectRateCommand struct {
+	ID       string
+	Reviewer string
+	Note     string
+}
+
+// ApproveSuspectRateHandler handles the approve suspect rate command.
+type ApproveSuspectRateHandler struct {
+	rateRepo  rate.Repository
+	suspects  anomaly.Repository
+	publisher ratePublisher
+	logger    *slog.Logger
+}
+
+// NewApproveSuspectRateHandler creates a new approve suspect rate command handler.
+// webhooks may be nil, in which case no webhooks are emitted.
+func NewApproveSuspectRateHandler(
+	rateRepo rate.Repository,
+	suspects anomaly.Repository,
+	cache redis.CacheInterface,
+	events rate.EventLog,
+	webhooks *EmitWebhooksHandler,
+	logger *slog.Logger,
+) *ApproveSuspectRateHandler {
+	return &ApproveSuspectRateHandler{
+		rateRepo:  rateRepo,
+		suspects:  suspects,
+		publisher: newRatePublisher(cache, events, webhooks, logger),
+		logger:    logger,
+	}
+}
+
+// Handle stores the quarantined rate, attributed to the reviewer, and
+// publishes it like a fetched rate.
+func (h *ApproveSuspectRateHandler) Handle(ctx context.Context, cmd ApproveSuspectRateCommand) (*anomaly.Suspect, error) {
+	suspect, err := findSuspect(ctx, h.suspects, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	if suspect.Status != anomaly.StatusPending {
+		return nil, anomaly.ErrAlreadyReviewed{ID: suspect.ID, Status: suspect.Status}
+	}
+
+	r, err := suspect.Rate()
+	if err != nil {
+		return nil, fmt.Errorf("restore suspect rate: %w", err)
+	}
+
+	reason := "approved suspect rate"
+	if cmd.Note != "" {
+		reason += ": " + cmd.Note
+	}
+	ctx = rate.WithChangeInfo(ctx, rate.ChangeInfo{
+		Actor:   rate.ActorManual,
+		ActorID: cmd.Reviewer,
+		Reason:  reason,
+	})
+	if err := h.rateRepo.Create(ctx, r); err != nil {
+		return nil, fmt.Errorf("save rate: %w", err)
+	}
+
+	// A rate stored meanwhile for the same date and source was revised
+	// instead, keeping its ID
+	stored, err := storedRate(ctx, h.rateRepo, r.Pair(), r.EffectiveDate(), r.Source())
+	if err != nil {
+		return nil, fmt.Errorf("find stored rate: %w", err)
+	}
+
+	if err := suspect.Approve(stored.ID(), cmd.Reviewer, cmd.Note, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := h.suspects.Update(ctx, suspect); err != nil {
+		return nil, fmt.Errorf("save review: %w", err)
+	}
+
+	h.logger.InfoContext(ctx, "suspect rate approved",
+		"id", suspect.ID,
+		"rate_id", stored.ID(),
+		"pair", suspect.Pair.String(),
+		"rate", suspect.Value,
+		"reviewer", cmd.Reviewer,
+	)
+
+	h.publisher.publish(ctx, stored)
+	return suspect, nil
+}
+
+// RejectSuspectRateCommand represents a command to discard a quarantined rate.
+type RejectSuspectRateCommand struct {
+	ID       string
+	Reviewer string
+	Note     string
+}
+
+// RejectSuspectRateHandler handles the reject suspect rate command.
+type RejectSuspectRateHandler struct {
+	suspects anomaly.Repository
+	logger   *slog.Logger
+}
+
+// NewRejectSuspectRateHandler creates a new reject suspect rate command handler.
+func NewRejectSuspectRateHandler(suspects anomaly.Repository, logger *slog.Logger) *RejectSuspectRateHandler {
+	return &RejectSuspectRateHandler{
+		suspects: suspects,
+		logger:   logger,
+	}
+}
+
+// Handle discards the quarantined rate. The provider's later answers for the
+// same date are screened again unless they repeat the rejected value.
+func (h *RejectSuspectRateHandler) Handle(ctx context.Context, cmd RejectSuspectRateCommand) (*anomaly.Suspect, error) {
+	suspect, err := findSuspect(ctx, h.suspects, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := suspect.Reject(cmd.Reviewer, cmd.Note, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := h.suspects.Update(ctx, suspect); err != nil {
+		return nil, fmt.Errorf("save review: %w", err)
+	}
+
+	h.logger.InfoContext(ctx, "suspect rate rejected",
+		"id", suspect.ID,
+		"pair", suspect.Pair.String(),
+		"rate", suspect.Value,
+		"reviewer", cmd.Reviewer,
+	)
+	return suspect, nil
+}
+
+// findSuspect returns the suspect with the given ID, treating malformed IDs
+// as unknown.
+func findSuspect(ctx context.Context, suspects anomaly.Repository, id string) (*anomaly.Suspect, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, anomaly.ErrSuspectNotFound{ID: id}
+	}
+	return suspects.Find(ctx, id)
+}
@@ -0,0 +1,413 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+)
+
+// CreateWebhookCommand represents a command to subscribe a URL to rates of a pair.
+type CreateWebhookCommand struct {
+	URL         string
+	Pair        currency.Pair
+	Condition   webhook.Condition
+	Description string
+}
+
+// CreateWebhookHandler handles the create webhook command.
+type CreateWebhookHandler struct {
+	webhooks webhook.Repository
+	logger   *slog.Logger
+}
+
+// NewCreateWebhookHandler creates a new create webhook command handler.
+func NewCreateWebhookHandler(webhooks webhook.Repository, logger *slog.Logger) *CreateWebhookHandler {
+	return &CreateWebhookHandler{
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// Handle creates the subscription. Its signing secret is only returned here.
+func (h *CreateWebhookHandler) Handle(ctx context.Context, cmd CreateWebhookCommand) (*webhook.Subscription, error) {
+	s, err := webhook.NewSubscription(cmd.URL, cmd.Pair, cmd.Condition, cmd.Description, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.webhooks.CreateSubscription(ctx, s); err != nil {
+		return nil, fmt.Errorf("save webhook: %w", err)
+	}
+
+	h.logger.InfoContext(ctx, "webhook created",
+		"id", s.ID,
+		"pair", s.Pair.String(),
+		"condition", s.Condition.Kind,
+	)
+	return s, nil
+}
+
+// UpdateWebhookCommand represents a command to change a subscription.
+// Nil fields are left unchanged.
+type UpdateWebhookCommand struct {
+	ID          string
+	URL         *string
+	Condition   *webhook.Condition
+	Description *string
+	Active      *bool
+}
+
+// UpdateWebhookHandler handles the update webhook command.
+type UpdateWebhookHandler struct {
+	webhooks webhook.Repository
+	logger   *slog.Logger
+}
+
+// NewUpdateWebhookHandler creates a new update webhook command handler.
+func NewUpdateWebhookHandler(webhooks webhook.Repository, logger *slog.Logger) *UpdateWebhookHandler {
+	return &UpdateWebhookHandler{
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// Handle applies the changes and returns the updated subscription.
+func (h *UpdateWebhookHandler) Handle(ctx context.Context, cmd UpdateWebhookCommand) (*webhook.Subscription, error) {
+	s, err := findSubscription(ctx, h.webhooks, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	url, condition, active := s.URL, s.Condition, s.Active
+	if cmd.URL != nil {
+		url = *cmd.URL
+	}
+	if cmd.Condition != nil {
+		condition = *cmd.Condition
+	}
+	if cmd.Active != nil {
+		active = *cmd.Active
+	}
+	if err := s.Change(url, condition, active, time.Now()); err != nil {
+		return nil, err
+	}
+	if cmd.Description != nil {
+		s.Description = *cmd.Description
+	}
+
+	if err := h.webhooks.UpdateSubscription(ctx, s); err != nil {
+		return nil, err
+	}
+
+	h.logger.InfoContext(ctx, "webhook updated", "id", s.ID, "active", s.Active)
+	return s, nil
+}
+
+// DeleteWebhookCommand represents a command to delete a subscription.
+type DeleteWebhookCommand struct {
+	ID string
+}
+
+// DeleteWebhookHandler handles the delete webhook command.
+type DeleteWebhookHandler struct {
+	webhooks webhook.Repository
+	logger   *slog.Logger
+}
+
+// NewDeleteWebhookHandler creates a new delete webhook command handler.
+func NewDeleteWebhookHandler(webhooks webhook.Repository, logger *slog.Logger) *DeleteWebhookHandler {
+	return &DeleteWebhookHandler{
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// Handle deletes the subscription together with its delivery log.
+func (h *DeleteWebhookHandler) Handle(ctx context.Context, cmd DeleteWebhookCommand) error {
+	if _, err := uuid.Parse(cmd.ID); err != nil {
+		return webhook.ErrSubscriptionNotFound{ID: cmd.ID}
+	}
+
+	if err := h.webhooks.DeleteSubscription(ctx, cmd.ID); err != nil {
+		return err
+	}
+
+	h.logger.InfoContext(ctx, "webhook deleted", "id", cmd.ID)
+	return nil
+}
+
+// RedeliverWebhookCommand represents a command to send an event again.
+type RedeliverWebhookCommand struct {
+	SubscriptionID string
+	DeliveryID     string
+}
+
+// RedeliverWebhookHandler handles the redeliver webhook command.
+type RedeliverWebhookHandler struct {
+	webhooks webhook.Repository
+	logger   *slog.Logger
+}
+
+// NewRedeliverWebhookHandler creates a new redeliver webhook command handler.
+func NewRedeliverWebhookHandler(webhooks webhook.Repository, logger *slog.Logger) *RedeliverWebhookHandler {
+	return &RedeliverWebhookHandler{
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// Handle queues a new delivery of the delivery's event, due immediately, and
+// returns it. Deliveries of any status can be redelivered.
+func (h *RedeliverWebhookHandler) Handle(ctx context.Context, cmd RedeliverWebhookCommand) (*webhook.Delivery, error) {
+	if _, err := findSubscription(ctx, h.webhooks, cmd.SubscriptionID); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(cmd.DeliveryID); err != nil {
+		return nil, webhook.ErrDeliveryNotFound{ID: cmd.DeliveryID}
+	}
+
+	original, err := h.webhooks.FindDelivery(ctx, cmd.DeliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.SubscriptionID != cmd.SubscriptionID {
+		return nil, webhook.ErrDeliveryNotFound{ID: cmd.DeliveryID}
+	}
+
+	delivery := original.Redeliver(time.Now())
+	if err := h.webhooks.CreateDeliveries(ctx, []*webhook.Delivery{delivery}); err != nil {
+		return nil, fmt.Errorf("save webhook delivery: %w", err)
+	}
+
+	h.logger.InfoContext(ctx, "webhook redelivery queued",
+		"subscription_id", cmd.SubscriptionID,
+		"delivery_id", delivery.ID,
+		"original_delivery_id", original.ID,
+	)
+	return delivery, nil
+}
+
+// previousDayLookback is how far back the previous rate of a new rate is
+// looked for, so that Mondays compare with the Friday before.
+const previousDayLookback = 7
+
+// EmitWebhooksCommand represents a newly stored rate to notify subscriptions of.
+type EmitWebhooksCommand struct {
+	Rate *rate.Rate
+}
+
+// EmitWebhooksHandler queues the webhook deliveries of newly stored rates.
+type EmitWebhooksHandler struct {
+	webhooks webhook.Repository
+	rateRepo rate.Repository
+	logger   *slog.Logger
+}
+
+// NewEmitWebhooksHandler creates a new emit webhooks command handler.
+func NewEmitWebhooksHandler(webhooks webhook.Repository, rateRepo rate.Repository, logger *slog.Logger) *EmitWebhooksHandler {
+	return &EmitWebhooksHandler{
+		webhooks: webhooks,
+		rateRepo: rateRepo,
+		logger:   logger,
+	}
+}
+
+// Handle queues a delivery for every active subscription to the rate's pair,
+// in either direction, whose condition the rate matches, and returns their
+// number. The deliveries are sent by DeliverWebhooksHandler.
+//
+// Movements and crossings compare with the most recent rate of the pair
+// stored for the preceding week; without one they do not match.
+func (h *EmitWebhooksHandler) Handle(ctx context.Context, cmd EmitWebhooksCommand) (int, error) {
+	r := cmd.Rate
+	subscriptions, err := h.webhooks.FindActiveSubscriptions(ctx, r.Pair())
+	if err != nil {
+		return 0, fmt.Errorf("find webhooks: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return 0, nil
+	}
+
+	previous, err := h.previousValue(ctx, r)
+	if err != nil {
+		return 0, fmt.Errorf("find previous rate: %w", err)
+	}
+
+	now := time.Now()
+	var deliveries []*webhook.Delivery
+	for _, s := range subscriptions {
+		payload, ok := s.Observe(r, previous, now)
+		if !ok {
+			continue
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return 0, fmt.Errorf("encode webhook payload: %w", err)
+		}
+		deliveries = append(deliveries, webhook.NewDelivery(s.ID, payload.ID, payload.Type, body, now))
+	}
+
+	if err := h.webhooks.CreateDeliveries(ctx, deliveries); err != nil {
+		return 0, fmt.Errorf("save webhook deliveries: %w", err)
+	}
+
+	if len(deliveries) > 0 {
+		h.logger.InfoContext(ctx, "webhook deliveries queued",
+			"pair", r.Pair().String(),
+			"date", r.EffectiveDate().Format("2006-01-02"),
+			"deliveries", len(deliveries),
+		)
+	}
+	return len(deliveries), nil
+}
+
+// previousValue returns the value of the latest rate of r's pair before its
+// effective date within the lookback, or nil if there is none.
+func (h *EmitWebhooksHandler) previousValue(ctx context.Context, r *rate.Rate) (*float64, error) {
+	end := r.EffectiveDate().AddDate(0, 0, -1)
+	start := r.EffectiveDate().AddDate(0, 0, -previousDayLookback)
+
+	rates, err := h.rateRepo.FindByDateRange(ctx, r.Pair(), start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *rate.Rate
+	for _, candidate := range rates {
+		if previous == nil || candidate.EffectiveDate().After(previous.EffectiveDate()) {
+			previous = candidate
+		}
+	}
+	if previous == nil {
+		return nil, nil
+	}
+	value := previous.Value()
+	return &value, nil
+}
+
+// Webhook delivery outcomes, as counted in the metrics.
+const (
+	deliverySucceeded = "succeeded"
+	deliveryRetrying  = "retrying"
+	deliveryFailed    = "failed"
+)
+
+// DeliverWebhooksHandler sends due webhook deliveries.
+type DeliverWebhooksHandler struct {
+	webhooks  webhook.Repository
+	sender    webhook.Sender
+	retry     webhook.RetryPolicy
+	batchSize int
+	lease     time.Duration
+	logger    *slog.Logger
+}
+
+// NewDeliverWebhooksHandler creates a new deliver webhooks command handler.
+// Each run attempts up to batchSize deliveries; lease must exceed the time a
+// run may take, as other processes retry the deliveries once it has passed.
+func NewDeliverWebhooksHandler(
+	webhooks webhook.Repository,
+	sender webhook.Sender,
+	retry webhook.RetryPolicy,
+	batchSize int,
+	lease time.Duration,
+	logger *slog.Logger,
+) *DeliverWebhooksHandler {
+	return &DeliverWebhooksHandler{
+		webhooks:  webhooks,
+		sender:    sender,
+		retry:     retry,
+		batchSize: batchSize,
+		lease:     lease,
+		logger:    logger,
+	}
+}
+
+// Handle attempts the due deliveries and returns the number attempted.
+// Failed attempts are retried with exponential backoff until the retry policy
+// gives up; deliveries of disabled subscriptions fail without an attempt.
+func (h *DeliverWebhooksHandler) Handle(ctx context.Context) (int, error) {
+	due, err := h.webhooks.ClaimDueDeliveries(ctx, time.Now(), h.lease, h.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+
+	subscriptions := make(map[string]*webhook.Subscription)
+	attempted := 0
+	for _, d := range due {
+		s, ok := subscriptions[d.SubscriptionID]
+		if !ok {
+			s, err = h.webhooks.FindSubscription(ctx, d.SubscriptionID)
+			if errors.As(err, new(webhook.ErrSubscriptionNotFound)) {
+				continue // deleted together with its deliveries
+			}
+			if err != nil {
+				return attempted, fmt.Errorf("find webhook: %w", err)
+			}
+			subscriptions[d.SubscriptionID] = s
+		}
+
+		if !s.Active {
+			d.Fail(0, "webhook disabled", time.Now(), webhook.RetryPolicy{})
+		} else {
+			h.attempt(ctx, s, d)
+			attempted++
+		}
+
+		if err := h.webhooks.UpdateDelivery(ctx, d); err != nil {
+			return attempted, fmt.Errorf("save webhook delivery: %w", err)
+		}
+	}
+
+	return attempted, nil
+}
+
+// attempt sends d and records the outcome on it.
+func (h *DeliverWebhooksHandler) attempt(ctx context.Context, s *webhook.Subscription, d *webhook.Delivery) {
+	status, err := h.sender.Send(ctx, s, d)
+	if err == nil {
+		d.Succeed(status, time.Now())
+		metrics.WebhookDeliveries.WithLabelValues(deliverySucceeded).Inc()
+		return
+	}
+
+	d.Fail(status, err.Error(), time.Now(), h.retry)
+	if d.Status == webhook.DeliveryFailed {
+		metrics.WebhookDeliveries.WithLabelValues(deliveryFailed).Inc()
+		h.logger.WarnContext(ctx, "webhook delivery failed, giving up",
+			"delivery_id", d.ID,
+			"subscription_id", s.ID,
+			"attempts", d.Attempts,
+			"error", err,
+		)
+		return
+	}
+
+	metrics.WebhookDeliveries.WithLabelValues(deliveryRetrying).Inc()
+	h.logger.InfoContext(ctx, "webhook delivery failed, retrying",
+		"delivery_id", d.ID,
+		"subscription_id", s.ID,
+		"attempts", d.Attempts,
+		"next_attempt_at", d.NextAttemptAt,
+		"error", err,
+	)
+}
+
+// findSubscription returns the subscription with the given ID, treating
+// malformed IDs as unknown.
+func findSubscription(ctx context.Context, webhooks webhook.Repository, id string) (*webhook.Subscription, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, webhook.ErrSubscriptionNotFound{ID: id}
+	}
+	return webhooks.FindSubscription(ctx, id)
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+// SuspectRateResponse represents a rate from a provider quarantined for review.
+type SuspectRateResponse struct {
+	ID            string            `json:"id"`
+	Pair          string            `json:"pair"`
+	Rate          float64           `json:"rate"`
+	EffectiveDate time.Time         `json:"effectiveDate"`
+	Source        string            `json:"source"`
+	Previous      *float64          `json:"previous,omitempty"` // the pair's rate before, which it was compared with
+	Findings      []AnomalyResponse `json:"findings"`
+	Status        string            `json:"status"` // pending, approved, rejected
+	CreatedAt     time.Time         `json:"createdAt"`
+	ReviewedAt    *time.Time        `json:"reviewedAt,omitempty"`
+	ReviewedBy    string            `json:"reviewedBy,omitempty"`
+	Note          string            `json:"note,omitempty"`
+	RateID        string            `json:"rateId,omitempty"` // the stored rate, once approved
+}
+
+// AnomalyResponse represents an anomaly found in a rate.
+type AnomalyResponse struct {
+	Kind   string `json:"kind"` // zscore, jump, reciprocal
+	Detail string `json:"detail"`
+}
+
+// ReviewRequest represents the body of requests approving or rejecting a suspect rate.
+type ReviewRequest struct {
+	Note string `json:"note"` // the reviewer's reason
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// APIKeyResponse represents an API key with its usage on the current UTC day.
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	DailyQuota int64      `json:"dailyQuota"`
+	UsedToday  int64      `json:"usedToday"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
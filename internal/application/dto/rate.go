@@ -34,3 +34,154 @@ type HistoryRequest struct {
 	StartDate string `json:"startDate" binding:"required"`
 	EndDate   string `json:"endDate" binding:"required"`
 }
+
+// RevisionResponse represents a single value change in a rate's history.
+type RevisionResponse struct {
+	ID        string    `json:"id"`
+	OldValue  *float64  `json:"oldValue"`
+	NewValue  float64   `json:"newValue"`
+	Source    string    `json:"source"`
+	Actor     string    `json:"actor"`
+	ActorID   string    `json:"actorId,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RateHistoryResponse represents a rate together with its revision history.
+type RateHistoryResponse struct {
+	Rate      *RateResponse       `json:"rate"`
+	Revisions []*RevisionResponse `json:"revisions"`
+}
+
+// RateEventResponse represents a newly stored rate in the rate stream.
+type RateEventResponse struct {
+	ID   string        `json:"id"` // event ID to resume the stream after
+	Rate *RateResponse `json:"rate"`
+}
+
+// RatePointResponse represents the rate of a pair on a date.
+type RatePointResponse struct {
+	Date time.Time `json:"date"`
+	Rate float64   `json:"rate"`
+}
+
+// RateStatsResponse represents the statistics of a pair over a window.
+type RateStatsResponse struct {
+	Pair          string            `json:"pair"`
+	Count         int               `json:"count"`
+	Min           RatePointResponse `json:"min"`
+	Max           RatePointResponse `json:"max"`
+	Mean          float64           `json:"mean"`
+	Median        float64           `json:"median"`
+	StdDev        float64           `json:"stdDev"` // sample standard deviation
+	First         RatePointResponse `json:"first"`
+	Last          RatePointResponse `json:"last"`
+	Change        float64           `json:"change"`        // last minus first
+	ChangePercent float64           `json:"changePercent"` // change relative to first
+	Volatility    *float64          `json:"volatility"`    // annualised volatility of log returns; null for fewer than three rates
+}
+
+// RatePeriodResponse represents the aggregate of the rates of one period.
+type RatePeriodResponse struct {
+	Start     time.Time `json:"start"` // first day of the period
+	End       time.Time `json:"end"`   // last day of the period
+	Open      float64   `json:"open"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Close     float64   `json:"close"`     // period-end value: the last rate of the period
+	CloseDate time.Time `json:"closeDate"` // effective date of the close
+	Average   float64   `json:"average"`
+	Count     int       `json:"count"`
+}
+
+// RateAggregateResponse represents the aggregates of a pair per period.
+type RateAggregateResponse struct {
+	Pair     string                `json:"pair"`
+	Interval string                `json:"interval"`
+	Periods  []*RatePeriodResponse `json:"periods"`
+}
+
+// TimeSeries represents the values of a pair at the dates of a TimeSeriesResponse.
+type TimeSeries struct {
+	Pair   string     `json:"pair"`
+	Values []*float64 `json:"values"` // null where there is no value
+}
+
+// TimeSeriesResponse represents aligned time series of several pairs in
+// columns: the i-th value of every series belongs to the i-th date.
+type TimeSeriesResponse struct {
+	Start    string        `json:"start"`
+	End      string        `json:"end"`
+	StepDays int           `json:"stepDays"`
+	Fill     string        `json:"fill"`
+	Dates    []string      `json:"dates"`
+	Series   []*TimeSeries `json:"series"`
+}
+
+// IndicatorPointResponse represents the value of an indicator at a rate.
+type IndicatorPointResponse struct {
+	Date  time.Time `json:"date"`
+	Rate  float64   `json:"rate"`
+	Value *float64  `json:"value"`           // null until enough rates precede the point
+	Upper *float64  `json:"upper,omitempty"` // upper Bollinger band
+	Lower *float64  `json:"lower,omitempty"` // lower Bollinger band
+}
+
+// IndicatorResponse represents a technical indicator of a pair over a window.
+type IndicatorResponse struct {
+	Pair      string                    `json:"pair"`
+	Indicator string                    `json:"indicator"`
+	Window    int                       `json:"window"`
+	Points    []*IndicatorPointResponse `json:"points"`
+}
+
+// ComparisonSeries represents the movement of one pair of a RateComparisonResponse.
+type ComparisonSeries struct {
+	Pair        string     `json:"pair"`
+	Values      []*float64 `json:"values"`      // rates normalised to 100 at the first one; null before it
+	Performance *float64   `json:"performance"` // percent change from the first to the last rate; null without rates
+}
+
+// RateComparisonResponse represents several pairs of one base compared over a
+// window. The i-th value of every series belongs to the i-th date, and row i
+// and column j of Correlation belong to series i and j.
+type RateComparisonResponse struct {
+	Base        string              `json:"base"`
+	Start       string              `json:"start"`
+	End         string              `json:"end"`
+	Dates       []string            `json:"dates"`
+	Series      []*ComparisonSeries `json:"series"`
+	Correlation [][]*float64        `json:"correlation"` // Pearson correlation of daily log returns; null with too few shared returns
+}
+
+// ForecastPointResponse represents the forecast of a pair on a future date.
+type ForecastPointResponse struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+	Lower float64   `json:"lower"` // lower bound of the prediction interval
+	Upper float64   `json:"upper"` // upper bound of the prediction interval
+}
+
+// ForecastResponse represents forecasts of a pair by a model fitted to its
+// history.
+type ForecastResponse struct {
+	Pair       string                   `json:"pair"`
+	Model      string                   `json:"model"`
+	Horizon    int                      `json:"horizon"`
+	Level      float64                  `json:"level"`   // coverage of the prediction intervals in percent
+	History    int                      `json:"history"` // number of rates the model was fitted to
+	LastDate   time.Time                `json:"lastDate"`
+	LastRate   float64                  `json:"lastRate"`
+	Parameters map[string]float64       `json:"parameters"` // fitted parameters of the model
+	Points     []*ForecastPointResponse `json:"points"`
+}
+
+// BacktestResponse represents the accuracy of a forecast model on the history
+// of a pair.
+type BacktestResponse struct {
+	Model     string  `json:"model"`
+	Folds     int     `json:"folds"`     // forecast origins evaluated
+	Forecasts int     `json:"forecasts"` // forecasts compared with actual rates
+	MAE       float64 `json:"mae"`       // mean absolute error
+	MAPE      float64 `json:"mape"`      // mean absolute percentage error, in percent
+}
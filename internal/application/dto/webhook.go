@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookRequest represents the body of requests creating or changing a webhook.
+// Omitted fields keep their value when a webhook is changed.
+type WebhookRequest struct {
+	URL         *string  `json:"url"`
+	Pair        *string  `json:"pair"`      // only when creating
+	Condition   *string  `json:"condition"` // new_rate, change, cross
+	Threshold   *float64 `json:"threshold"` // percent for change, a rate for cross
+	Description *string  `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookResponse represents a webhook subscription.
+// The signing secret is only included when the webhook is created.
+type WebhookResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Pair        string    `json:"pair"`
+	Condition   string    `json:"condition"`
+	Threshold   *float64  `json:"threshold,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// WebhookDeliveryResponse represents an entry of a webhook's delivery log.
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"` // only while pending
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt,omitempty"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,83 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// AggregateRatesQuery represents a query for the aggregates of a pair per period.
+type AggregateRatesQuery struct {
+	Pair     currency.Pair
+	Interval rate.Interval
+	Start    *time.Time // optional earliest effective date
+	End      *time.Time // optional latest effective date
+	AsOf     *time.Time // optional transaction time to aggregate the rates at
+}
+
+// AggregateRatesHandler handles aggregating the rates of a pair per period.
+type AggregateRatesHandler struct {
+	rateRepo rate.Repository
+	logger   *slog.Logger
+}
+
+// NewAggregateRatesHandler creates a new handler.
+func NewAggregateRatesHandler(
+	rateRepo rate.Repository,
+	logger *slog.Logger,
+) *AggregateRatesHandler {
+	return &AggregateRatesHandler{
+		rateRepo: rateRepo,
+		logger:   logger,
+	}
+}
+
+// Handle executes the query. The periods are aggregated by the repository; a
+// window without rates has no periods.
+func (h *AggregateRatesHandler) Handle(ctx context.Context, query AggregateRatesQuery) (*dto.RateAggregateResponse, error) {
+	w := seriesWindow{Pair: query.Pair, Start: query.Start, End: query.End, AsOf: query.AsOf}
+
+	inverted, err := storedDirection(ctx, h.rateRepo, w)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to count rates for aggregation", "error", err, "pair", query.Pair.String())
+		return nil, err
+	}
+	stored := query.Pair
+	if inverted {
+		stored = query.Pair.Inverse()
+	}
+
+	periods, err := h.rateRepo.Aggregate(ctx, query.Interval, w.options(stored)...)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*dto.RatePeriodResponse, 0, len(periods))
+	for _, p := range periods {
+		if inverted {
+			inverse := p.Inverse()
+			p = &inverse
+		}
+		items = append(items, &dto.RatePeriodResponse{
+			Start:     p.Start,
+			End:       p.End,
+			Open:      p.Open,
+			High:      p.High,
+			Low:       p.Low,
+			Close:     p.Close,
+			CloseDate: p.CloseDate,
+			Average:   p.Average,
+			Count:     p.Count,
+		})
+	}
+
+	return &dto.RateAggregateResponse{
+		Pair:     query.Pair.String(),
+		Interval: string(query.Interval),
+		Periods:  items,
+	}, nil
+}
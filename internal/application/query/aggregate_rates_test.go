@@ -0,0 +1,58 @@
+package query_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+func TestAggregateRatesHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+	repo := memory.NewRateRepository(log)
+
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	seedRate(t, repo, pair, day(2), 20)
+	seedRate(t, repo, pair, day(31), 25)
+	seedRate(t, repo, pair, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), 24)
+	handler := query.NewAggregateRatesHandler(repo, log)
+
+	direct, err := handler.Handle(ctx, query.AggregateRatesQuery{Pair: pair, Interval: rate.IntervalMonth})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if len(direct.Periods) != 2 {
+		t.Fatalf("got %d periods, want 2", len(direct.Periods))
+	}
+	jan := direct.Periods[0]
+	if jan.Open != 20 || jan.Close != 25 || jan.Average != 22.5 || jan.Count != 2 || jan.End.Day() != 31 {
+		t.Errorf("January = %+v, want open 20, close 25 and average 22.5 over 2 rates", jan)
+	}
+
+	// The inverse pair averages the inverse rates, and high and low swap
+	inverse, err := handler.Handle(ctx, query.AggregateRatesQuery{Pair: pair.Inverse(), Interval: rate.IntervalMonth})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	inv := inverse.Periods[0]
+	if inverse.Pair != "JPY/CNY" || inv.Open != 1.0/20 || inv.Close != 1.0/25 || inv.High != 1.0/20 || inv.Low != 1.0/25 {
+		t.Errorf("inverse January = %+v, want the inverted rates", inv)
+	}
+	if want := (1.0/20 + 1.0/25) / 2; math.Abs(inv.Average-want) > 1e-12 {
+		t.Errorf("inverse average = %v, want %v", inv.Average, want)
+	}
+
+	// A window without rates has no periods
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	empty, err := handler.Handle(ctx, query.AggregateRatesQuery{Pair: pair, Interval: rate.IntervalMonth, Start: &start})
+	if err != nil || len(empty.Periods) != 0 {
+		t.Errorf("Handle() after the last rate = %+v, %v; want no periods", empty, err)
+	}
+}
@@ -0,0 +1,149 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+)
+
+// refreshTimeout bounds a background refresh of a stale cache entry.
+const refreshTimeout = 10 * time.Second
+
+// cacheResult records on the query's span whether the result came from the cache.
+const cacheResult = attribute.Key("cache.result")
+
+// CachePolicy controls how long the results of a query type are cached.
+type CachePolicy struct {
+	TTL    time.Duration // how long a cached result is fresh
+	Stale  time.Duration // how long after TTL a result may still be served while it is refreshed
+	Jitter float64       // fraction of TTL by which each entry's lifetime is randomly varied, e.g. 0.1 for ±10%
+}
+
+// Default cache policies of the query types.
+var (
+	DefaultLatestCachePolicy = CachePolicy{TTL: 5 * time.Minute, Stale: time.Minute, Jitter: 0.1}
+	DefaultByDateCachePolicy = CachePolicy{TTL: time.Hour, Stale: 5 * time.Minute, Jitter: 0.1}
+)
+
+// jitteredTTL returns the TTL varied by up to ±Jitter, so that entries
+// written together do not all expire at the same moment.
+func (p CachePolicy) jitteredTTL() time.Duration {
+	jitter := min(max(p.Jitter, 0), 1)
+	if jitter == 0 || p.TTL <= 0 {
+		return p.TTL
+	}
+	return p.TTL + time.Duration(float64(p.TTL)*jitter*(2*rand.Float64()-1))
+}
+
+// cachedQuery wraps the cache-miss path of a query handler.
+//
+// Concurrent misses for the same key are coalesced, so only one of them
+// queries the repository. Every result is also written under a stale key
+// that lives Stale longer than the fresh one: once the fresh entry expires,
+// the stale copy is served immediately while a single background load
+// refreshes both entries.
+type cachedQuery[T any] struct {
+	name   string // query label of the cache metrics
+	cache  redis.CacheInterface
+	policy CachePolicy
+	group  singleflight.Group
+	logger *slog.Logger
+}
+
+func newCachedQuery[T any](name string, cache redis.CacheInterface, policy CachePolicy, logger *slog.Logger) *cachedQuery[T] {
+	return &cachedQuery[T]{
+		name:   name,
+		cache:  cache,
+		policy: policy,
+		logger: logger,
+	}
+}
+
+// get returns the cached result for key, or loads and caches it.
+func (q *cachedQuery[T]) get(ctx context.Context, key string, load func(context.Context) (*T, error)) (*T, error) {
+	var cached T
+	if err := q.cache.Get(ctx, key, &cached); err == nil {
+		q.logger.DebugContext(ctx, "cache hit", "key", key)
+		metrics.CacheRequests.WithLabelValues(q.name, metrics.CacheHit).Inc()
+		trace.SpanFromContext(ctx).SetAttributes(cacheResult.String(metrics.CacheHit))
+		return &cached, nil
+	}
+
+	if q.policy.Stale > 0 {
+		var stale T
+		if err := q.cache.Get(ctx, staleKey(key), &stale); err == nil {
+			q.logger.DebugContext(ctx, "serving stale cache entry", "key", key)
+			metrics.CacheRequests.WithLabelValues(q.name, metrics.CacheStale).Inc()
+			trace.SpanFromContext(ctx).SetAttributes(cacheResult.String(metrics.CacheStale))
+			q.refresh(ctx, key, load)
+			return &stale, nil
+		}
+	}
+
+	q.logger.DebugContext(ctx, "cache miss", "key", key)
+	metrics.CacheRequests.WithLabelValues(q.name, metrics.CacheMiss).Inc()
+	trace.SpanFromContext(ctx).SetAttributes(cacheResult.String(metrics.CacheMiss))
+
+	// The shared load must not be cancelled when the request that started it goes away
+	loadCtx := context.WithoutCancel(ctx)
+	v, err, shared := q.group.Do(key, func() (any, error) {
+		return q.load(loadCtx, key, load)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if shared {
+		q.logger.DebugContext(ctx, "coalesced cache miss", "key", key)
+	}
+
+	return v.(*T), nil
+}
+
+// refresh reloads key in the background unless a load is already running.
+func (q *cachedQuery[T]) refresh(ctx context.Context, key string, load func(context.Context) (*T, error)) {
+	base := context.WithoutCancel(ctx)
+
+	// DoChan runs the load in its own goroutine; nobody waits for the result
+	q.group.DoChan(key, func() (any, error) {
+		refreshCtx, cancel := context.WithTimeout(base, refreshTimeout)
+		defer cancel()
+		result, err := q.load(refreshCtx, key, load)
+		if err != nil {
+			q.logger.WarnContext(base, "failed to refresh stale cache entry", "key", key, "error", err)
+		}
+		return result, err
+	})
+}
+
+// load runs the query and caches its result under the fresh and stale keys.
+func (q *cachedQuery[T]) load(ctx context.Context, key string, load func(context.Context) (*T, error)) (*T, error) {
+	result, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := q.policy.jitteredTTL()
+	if err := q.cache.Set(ctx, key, result, ttl); err != nil {
+		q.logger.WarnContext(ctx, "failed to cache result", "key", key, "error", err)
+	}
+	if q.policy.Stale > 0 {
+		if err := q.cache.Set(ctx, staleKey(key), result, ttl+q.policy.Stale); err != nil {
+			q.logger.WarnContext(ctx, "failed to cache stale copy", "key", key, "error", err)
+		}
+	}
+
+	return result, nil
+}
+
+// staleKey returns the key of the stale copy of a cache entry.
+func staleKey(key string) string {
+	return "stale:" + key
+}
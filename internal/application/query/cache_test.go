@@ -0,0 +1,127 @@
+package query_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+func TestGetLatestRateHandler_CoalescesMisses(t *testing.T) {
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	testRate, _ := rate.NewRate(pair, 20.0, time.Now(), rate.SourceUnionPay)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	repo := &mockRateRepository{
+		findLatestFunc: func(ctx context.Context, p currency.Pair) (*rate.Rate, error) {
+			calls.Add(1)
+			<-release
+			return testRate, nil
+		},
+	}
+
+	log := logger.NewNoop()
+	handler := query.NewGetLatestRateHandler(repo, memory.NewCache(0, log), query.DefaultLatestCachePolicy, log)
+
+	const requests = 10
+	var wg sync.WaitGroup
+	results := make(chan *dto.RateResponse, requests)
+	for range requests {
+		wg.Go(func() {
+			result, err := handler.Handle(context.Background(), query.GetLatestRateQuery{Pair: pair})
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+			results <- result
+		})
+	}
+
+	// Give every request time to join the in-flight load before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 repository call, got %d", n)
+	}
+	for result := range results {
+		if result.Rate != 20.0 {
+			t.Errorf("expected rate 20.0, got %f", result.Rate)
+		}
+	}
+}
+
+func TestGetLatestRateHandler_ServesStaleWhileRevalidating(t *testing.T) {
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	oldRate, _ := rate.NewRate(pair, 20.0, time.Now(), rate.SourceUnionPay)
+	newRate, _ := rate.NewRate(pair, 21.0, time.Now(), rate.SourceUnionPay)
+
+	var calls atomic.Int32
+	refreshed := make(chan struct{})
+	repo := &mockRateRepository{
+		findLatestFunc: func(ctx context.Context, p currency.Pair) (*rate.Rate, error) {
+			if calls.Add(1) == 1 {
+				return oldRate, nil
+			}
+			defer close(refreshed)
+			return newRate, nil
+		},
+	}
+
+	log := logger.NewNoop()
+	cache := memory.NewCache(0, log)
+	policy := query.CachePolicy{TTL: 20 * time.Millisecond, Stale: time.Minute}
+	handler := query.NewGetLatestRateHandler(repo, cache, policy, log)
+	q := query.GetLatestRateQuery{Pair: pair}
+
+	if _, err := handler.Handle(context.Background(), q); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Let the fresh entry expire; the stale copy is still cached
+	time.Sleep(30 * time.Millisecond)
+
+	result, err := handler.Handle(context.Background(), q)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Rate != 20.0 {
+		t.Errorf("expected stale rate 20.0, got %f", result.Rate)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stale entry to be refreshed in the background")
+	}
+
+	// The refresh writes the cache after the repository returns
+	deadline := time.Now().Add(time.Second)
+	for {
+		result, err = handler.Handle(context.Background(), q)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.Rate == 21.0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if result.Rate != 21.0 {
+		t.Errorf("expected refreshed rate 21.0, got %f", result.Rate)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 repository calls, got %d", n)
+	}
+}
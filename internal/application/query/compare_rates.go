@@ -0,0 +1,195 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/pkg/analytics"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// CompareRatesQuery represents a query comparing the pairs of one base
+// currency with several quote currencies over a window.
+type CompareRatesQuery struct {
+	Base   currency.Code
+	Quotes []currency.Code
+	Start  time.Time
+	End    time.Time
+	AsOf   *time.Time // optional transaction time to read the rates at
+}
+
+// CompareRatesHandler handles comparing pairs of one base currency.
+type CompareRatesHandler struct {
+	rateRepo rate.Repository
+	logger   *slog.Logger
+}
+
+// NewCompareRatesHandler creates a new handler.
+func NewCompareRatesHandler(
+	rateRepo rate.Repository,
+	logger *slog.Logger,
+) *CompareRatesHandler {
+	return &CompareRatesHandler{
+		rateRepo: rateRepo,
+		logger:   logger,
+	}
+}
+
+// Handle executes the query. The dates of the response are those with a rate
+// of any pair; a pair without a rate on a date carries its previous rate
+// forward.
+func (h *CompareRatesHandler) Handle(ctx context.Context, query CompareRatesQuery) (*dto.RateComparisonResponse, error) {
+	start, end := dateOf(query.Start), dateOf(query.End)
+
+	pairs := make([]currency.Pair, len(query.Quotes))
+	daily := make([]map[time.Time]float64, len(query.Quotes))
+	var dates []time.Time
+	for i, quote := range query.Quotes {
+		pair, err := currency.NewPair(query.Base, quote)
+		if err != nil {
+			return nil, err
+		}
+		pairs[i] = pair
+
+		daily[i], err = h.dailyRates(ctx, pair, start, end, query.AsOf)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to compare rates", "error", err, "pair", pair.String())
+			return nil, err
+		}
+		for date := range daily[i] {
+			dates = append(dates, date)
+		}
+	}
+	slices.SortFunc(dates, time.Time.Compare)
+	dates = slices.CompactFunc(dates, time.Time.Equal)
+
+	series := make([]*dto.ComparisonSeries, len(pairs))
+	returns := make([][]*float64, len(pairs))
+	for i, pair := range pairs {
+		series[i] = normalise(pair, daily[i], dates)
+		returns[i] = logReturns(daily[i], dates)
+	}
+
+	formatted := make([]string, len(dates))
+	for i, date := range dates {
+		formatted[i] = timeutil.FormatDate(date)
+	}
+
+	return &dto.RateComparisonResponse{
+		Base:        query.Base.String(),
+		Start:       timeutil.FormatDate(start),
+		End:         timeutil.FormatDate(end),
+		Dates:       formatted,
+		Series:      series,
+		Correlation: correlationMatrix(returns),
+	}, nil
+}
+
+// dailyRates returns one rate of pair per effective date in the window, keyed
+// by date, read from the direction chosen by preferInverse.
+func (h *CompareRatesHandler) dailyRates(ctx context.Context, pair currency.Pair, start, end time.Time, asOf *time.Time) (map[time.Time]float64, error) {
+	find := func(p currency.Pair) ([]*rate.Rate, error) {
+		if asOf != nil {
+			return h.rateRepo.FindByDateRangeAsOf(ctx, p, start, end, *asOf)
+		}
+		return h.rateRepo.FindByDateRange(ctx, p, start, end)
+	}
+
+	rates, err := find(pair)
+	if err != nil {
+		return nil, err
+	}
+	inverse, err := find(pair.Inverse())
+	if err != nil {
+		return nil, err
+	}
+	inverted := preferInverse(int64(len(rates)), int64(len(inverse)))
+	if inverted {
+		rates = inverse
+	}
+
+	values := make(map[time.Time]float64, len(rates))
+	for _, r := range rates {
+		date := dateOf(r.EffectiveDate())
+		if _, ok := values[date]; ok {
+			continue // another source's rate of the same date
+		}
+		value := r.Value()
+		if inverted {
+			value = r.Pair().ConvertRate(value)
+		}
+		values[date] = value
+	}
+	return values, nil
+}
+
+// normalise returns the series of pair on dates, normalised to 100 at its
+// first rate, with the percent performance from its first to its last rate.
+func normalise(pair currency.Pair, daily map[time.Time]float64, dates []time.Time) *dto.ComparisonSeries {
+	values := make([]*float64, len(dates))
+	var first, last *float64
+	for i, date := range dates {
+		if value, ok := daily[date]; ok {
+			last = &value
+			if first == nil {
+				first = last
+			}
+		}
+		if last != nil {
+			normalised := *last / *first * 100
+			values[i] = &normalised
+		}
+	}
+
+	var performance *float64
+	if first != nil {
+		change := (*last / *first - 1) * 100
+		performance = &change
+	}
+	return &dto.ComparisonSeries{Pair: pair.String(), Values: values, Performance: performance}
+}
+
+// logReturns returns the log return of each date against the date before it,
+// or nil where the pair lacks a rate on either date.
+func logReturns(daily map[time.Time]float64, dates []time.Time) []*float64 {
+	returns := make([]*float64, len(dates))
+	for i := 1; i < len(dates); i++ {
+		previous, hasPrevious := daily[dates[i-1]]
+		current, hasCurrent := daily[dates[i]]
+		if hasPrevious && hasCurrent {
+			r := math.Log(current / previous)
+			returns[i] = &r
+		}
+	}
+	return returns
+}
+
+// correlationMatrix returns the Pearson correlations of each two series of
+// returns over the dates on which both have one.
+func correlationMatrix(returns [][]*float64) [][]*float64 {
+	matrix := make([][]*float64, len(returns))
+	for i := range matrix {
+		matrix[i] = make([]*float64, len(returns))
+	}
+
+	for i := range returns {
+		for j := i; j < len(returns); j++ {
+			var x, y []float64
+			for k := range returns[i] {
+				if returns[i][k] != nil && returns[j][k] != nil {
+					x, y = append(x, *returns[i][k]), append(y, *returns[j][k])
+				}
+			}
+			if correlation, ok := analytics.Correlation(x, y); ok {
+				matrix[i][j], matrix[j][i] = &correlation, &correlation
+			}
+		}
+	}
+	return matrix
+}
@@ -0,0 +1,92 @@
+package query_test
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+func TestCompareRatesHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+	repo := memory.NewRateRepository(log)
+
+	// Both pairs are stored against JPY, so JPY/CNY and JPY/USD are inverted.
+	// CNY has no rate on Jan 3.
+	seedRates(t, repo, currency.MustNewPair(currency.CNY, currency.JPY), day(1), 20, 25, 0, 20, 16)
+	seedRates(t, repo, currency.MustNewPair(currency.USD, currency.JPY), day(1), 100, 125, 110, 100, 80)
+	handler := query.NewCompareRatesHandler(repo, log)
+
+	near := func(got *float64, want float64) bool { return got != nil && math.Abs(*got-want) < 1e-9 }
+	nearAll := func(got []*float64, want []float64) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if !near(got[i], want[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	t.Run("whole window", func(t *testing.T) {
+		result, err := handler.Handle(ctx, query.CompareRatesQuery{
+			Base:   currency.JPY,
+			Quotes: []currency.Code{currency.CNY, currency.USD, currency.GBP},
+			Start:  day(1),
+			End:    day(31),
+		})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+
+		wantDates := []string{"2024-01-01", "2024-01-02", "2024-01-03", "2024-01-04", "2024-01-05"}
+		if !slices.Equal(result.Dates, wantDates) || len(result.Series) != 3 {
+			t.Fatalf("dates = %v with %d series, want %v with 3", result.Dates, len(result.Series), wantDates)
+		}
+
+		cny, usd, gbp := result.Series[0], result.Series[1], result.Series[2]
+		if cny.Pair != "JPY/CNY" || !nearAll(cny.Values, []float64{100, 80, 80, 100, 125}) || !near(cny.Performance, 25) {
+			t.Errorf("JPY/CNY = %s %v %v, want the previous value on Jan 3 and 25%% performance", cny.Pair, cny.Values, cny.Performance)
+		}
+		if !nearAll(usd.Values, []float64{100, 80, 100 / 1.1, 100, 125}) || !near(usd.Performance, 25) {
+			t.Errorf("JPY/USD = %v %v, want the inverted rates normalised to 100", usd.Values, usd.Performance)
+		}
+		if slices.ContainsFunc(gbp.Values, func(v *float64) bool { return v != nil }) || gbp.Performance != nil {
+			t.Errorf("JPY/GBP = %v %v, want no values", gbp.Values, gbp.Performance)
+		}
+
+		// The returns of Jan 2 and 5 are shared by CNY and USD and identical
+		c := result.Correlation
+		if !near(c[0][0], 1) || !near(c[0][1], 1) || !near(c[1][0], 1) || !near(c[1][1], 1) {
+			t.Errorf("correlation of CNY and USD = %v %v %v %v, want 1", c[0][0], c[0][1], c[1][0], c[1][1])
+		}
+		if c[0][2] != nil || c[2][1] != nil || c[2][2] != nil {
+			t.Errorf("correlation with GBP = %v %v %v, want null", c[0][2], c[2][1], c[2][2])
+		}
+	})
+
+	t.Run("window", func(t *testing.T) {
+		result, err := handler.Handle(ctx, query.CompareRatesQuery{
+			Base:   currency.JPY,
+			Quotes: []currency.Code{currency.CNY},
+			Start:  day(2),
+			End:    day(4),
+		})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		// Without USD, Jan 3 has no rate of any pair
+		cny := result.Series[0]
+		if !slices.Equal(result.Dates, []string{"2024-01-02", "2024-01-04"}) || !nearAll(cny.Values, []float64{100, 125}) || !near(cny.Performance, 25) || result.Correlation[0][0] != nil {
+			t.Errorf("dates = %v, performance = %v; want JPY/CNY normalised to Jan 2 without correlation", result.Dates, cny.Performance)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/pkg/analytics"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// ForecastModel is a statistical model rates are forecast with.
+type ForecastModel string
+
+// Supported forecast models.
+const (
+	ForecastHolt  ForecastModel = "holt"  // Holt's linear exponential smoothing
+	ForecastDrift ForecastModel = "drift" // random walk with drift, the baseline
+)
+
+// ForecastModels lists the supported forecast models.
+var ForecastModels = []ForecastModel{ForecastHolt, ForecastDrift}
+
+// MinHistory returns the fewest rates the model can be fitted to.
+func (m ForecastModel) MinHistory() int {
+	if m == ForecastHolt {
+		return analytics.HoltMinValues
+	}
+	return analytics.DriftMinValues
+}
+
+// fitter returns the function fitting the model.
+func (m ForecastModel) fitter() analytics.Fitter {
+	if m == ForecastHolt {
+		return analytics.FitHoltModel
+	}
+	return analytics.FitDriftModel
+}
+
+// GetForecastQuery represents a query for forecasts of a pair.
+type GetForecastQuery struct {
+	Pair    currency.Pair
+	Model   ForecastModel
+	Horizon int        // number of rates to forecast
+	Level   float64    // coverage of the prediction intervals, e.g. 0.95
+	History int        // number of rates up to End the model is fitted to
+	End     time.Time  // last date of the history
+	AsOf    *time.Time // optional transaction time to read the rates at
+}
+
+// GetForecastHandler handles forecasting the rates of a pair.
+type GetForecastHandler struct {
+	rateRepo rate.Repository
+	logger   *slog.Logger
+}
+
+// NewGetForecastHandler creates a new handler.
+func NewGetForecastHandler(
+	rateRepo rate.Repository,
+	logger *slog.Logger,
+) *GetForecastHandler {
+	return &GetForecastHandler{
+		rateRepo: rateRepo,
+		logger:   logger,
+	}
+}
+
+// Handle executes the query. It fits the model to the last rates up to the
+// end date and returns one forecast per following rate date, with the
+// fitted parameters. Returns rate.ErrInsufficientHistory if the pair has too
+// few rates for the model.
+func (h *GetForecastHandler) Handle(ctx context.Context, query GetForecastQuery) (*dto.ForecastResponse, error) {
+	history, err := forecastHistory(ctx, h.rateRepo, query.Pair, query.History, query.End, query.AsOf)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load forecast history", "error", err, "pair", query.Pair.String())
+		return nil, err
+	}
+
+	values := make([]float64, len(history))
+	for i, o := range history {
+		values[i] = o.Value
+	}
+	model, err := query.Model.fitter()(values)
+	if err != nil {
+		return nil, insufficientHistory(query.Pair, err)
+	}
+
+	last := history[len(history)-1]
+	forecasts := model.Forecast(query.Horizon, query.Level)
+	dates := forecastDates(history, query.Horizon)
+	points := make([]*dto.ForecastPointResponse, len(forecasts))
+	for i, f := range forecasts {
+		points[i] = &dto.ForecastPointResponse{Date: dates[i], Value: f.Value, Lower: f.Lower, Upper: f.Upper}
+	}
+
+	return &dto.ForecastResponse{
+		Pair:       query.Pair.String(),
+		Model:      string(query.Model),
+		Horizon:    query.Horizon,
+		Level:      query.Level * 100,
+		History:    len(history),
+		LastDate:   last.Date,
+		LastRate:   last.Value,
+		Parameters: modelParameters(model),
+		Points:     points,
+	}, nil
+}
+
+// BacktestForecastsQuery represents a query for the accuracy of the forecast
+// models on the history of a pair.
+type BacktestForecastsQuery struct {
+	Pair    currency.Pair
+	Horizon int        // number of rates forecast from each origin
+	Folds   int        // number of forecast origins, one horizon apart
+	History int        // number of rates up to End the models are evaluated on
+	End     time.Time  // last date of the history
+	AsOf    *time.Time // optional transaction time to read the rates at
+}
+
+// BacktestForecastsHandler handles evaluating the forecast models.
+type BacktestForecastsHandler struct {
+	rateRepo rate.Repository
+	logger   *slog.Logger
+}
+
+// NewBacktestForecastsHandler creates a new handler.
+func NewBacktestForecastsHandler(
+	rateRepo rate.Repository,
+	logger *slog.Logger,
+) *BacktestForecastsHandler {
+	return &BacktestForecastsHandler{
+		rateRepo: rateRepo,
+		logger:   logger,
+	}
+}
+
+// Handle executes the query. Each model forecasts the last folds windows of
+// horizon rates from the rates before them; the errors against the actual
+// rates are reported per model. Returns rate.ErrInsufficientHistory if the
+// history is too short to evaluate a model once.
+func (h *BacktestForecastsHandler) Handle(ctx context.Context, query BacktestForecastsQuery) ([]*dto.BacktestResponse, error) {
+	history, err := forecastHistory(ctx, h.rateRepo, query.Pair, query.History, query.End, query.AsOf)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load backtest history", "error", err, "pair", query.Pair.String())
+		return nil, err
+	}
+
+	values := make([]float64, len(history))
+	for i, o := range history {
+		values[i] = o.Value
+	}
+
+	results := make([]*dto.BacktestResponse, len(ForecastModels))
+	for i, model := range ForecastModels {
+		acc, err := analytics.Backtest(values, model.fitter(), query.Horizon, query.Folds)
+		if err != nil {
+			return nil, insufficientHistory(query.Pair, err)
+		}
+		results[i] = &dto.BacktestResponse{
+			Model:     string(model),
+			Folds:     acc.Folds,
+			Forecasts: acc.Forecasts,
+			MAE:       acc.MAE,
+			MAPE:      acc.MAPE,
+		}
+	}
+	return results, nil
+}
+
+// forecastHistory returns the last n rates of pair up to end, one per date in
+// the requested direction, oldest first.
+func forecastHistory(ctx context.Context, repo rate.Repository, pair currency.Pair, n int, end time.Time, asOf *time.Time) ([]observation, error) {
+	w := seriesWindow{Pair: pair, AsOf: asOf}
+	inverted, err := storedDirection(ctx, repo, w)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rates before the day after end; several sources may share a date
+	after := dateOf(end).AddDate(0, 0, 1)
+	w.Start = &after
+	before, err := observationsBefore(ctx, repo, w, inverted, n)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]observation, 0, len(before))
+	for _, o := range before {
+		if k := len(history); k > 0 && history[k-1].Date.Equal(o.Date) {
+			history[k-1] = o
+			continue
+		}
+		history = append(history, o)
+	}
+	return history, nil
+}
+
+// forecastDates returns the horizon dates following the history. Weekends are
+// skipped unless the history has rates on weekends.
+func forecastDates(history []observation, horizon int) []time.Time {
+	weekends := false
+	for _, o := range history {
+		if timeutil.IsWeekend(o.Date) {
+			weekends = true
+			break
+		}
+	}
+
+	dates := make([]time.Time, horizon)
+	date := history[len(history)-1].Date
+	for i := range dates {
+		date = date.AddDate(0, 0, 1)
+		for !weekends && timeutil.IsWeekend(date) {
+			date = date.AddDate(0, 0, 1)
+		}
+		dates[i] = date
+	}
+	return dates
+}
+
+// modelParameters returns the fitted parameters of model and the standard
+// deviation of its one-step errors.
+func modelParameters(model analytics.Model) map[string]float64 {
+	switch m := model.(type) {
+	case *analytics.Holt:
+		return map[string]float64{
+			"alpha": m.Alpha,
+			"beta":  m.Beta,
+			"level": m.Level,
+			"trend": m.Trend,
+			"sigma": math.Sqrt(m.Variance),
+		}
+	case *analytics.Drift:
+		return map[string]float64{
+			"drift": m.Drift,
+			"sigma": math.Sqrt(m.Variance),
+		}
+	default:
+		return nil
+	}
+}
+
+// insufficientHistory translates a model's complaint about too short a series.
+func insufficientHistory(pair currency.Pair, err error) error {
+	var short analytics.ErrInsufficientData
+	if errors.As(err, &short) {
+		return rate.ErrInsufficientHistory{Pair: pair.String(), Have: short.Have, Need: short.Need}
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+func TestGetForecastHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+	repo := memory.NewRateRepository(log)
+
+	// Two weeks of weekday rates rising by 0.5 a day from 20 on Monday 1 January
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	seedRates(t, repo, pair, day(1), 20, 20.5, 21, 21.5, 22, 0, 0, 22.5, 23, 23.5, 24, 24.5)
+	handler := query.NewGetForecastHandler(repo, log)
+
+	tests := []struct {
+		name        string
+		query       query.GetForecastQuery
+		wantHistory int
+		wantDates   []string
+		wantValues  []float64
+	}{
+		{
+			name:        "drift skips the weekend",
+			query:       query.GetForecastQuery{Pair: pair, Model: query.ForecastDrift, Horizon: 3, Level: 0.95, History: 100, End: day(14)},
+			wantHistory: 10,
+			wantDates:   []string{"2024-01-15", "2024-01-16", "2024-01-17"},
+			wantValues:  []float64{25, 25.5, 26},
+		},
+		{
+			name:        "holt on the last rates up to end",
+			query:       query.GetForecastQuery{Pair: pair, Model: query.ForecastHolt, Horizon: 2, Level: 0.8, History: 4, End: day(5)},
+			wantHistory: 4,
+			wantDates:   []string{"2024-01-08", "2024-01-09"},
+			wantValues:  []float64{22.5, 23},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			if result.History != tt.wantHistory || len(result.Points) != len(tt.wantValues) {
+				t.Fatalf("Handle() history %d with %d points, want %d with %d",
+					result.History, len(result.Points), tt.wantHistory, len(tt.wantValues))
+			}
+			for i, p := range result.Points {
+				if timeutil.FormatDate(p.Date) != tt.wantDates[i] || math.Abs(p.Value-tt.wantValues[i]) > 1e-6 {
+					t.Errorf("point %d = %s %v, want %s %v", i, timeutil.FormatDate(p.Date), p.Value, tt.wantDates[i], tt.wantValues[i])
+				}
+				// An exactly linear history leaves no uncertainty
+				if math.Abs(p.Upper-p.Lower) > 1e-6 {
+					t.Errorf("point %d interval [%v, %v], want none", i, p.Lower, p.Upper)
+				}
+			}
+		})
+	}
+
+	t.Run("inverse pair", func(t *testing.T) {
+		result, err := handler.Handle(ctx, query.GetForecastQuery{Pair: pair.Inverse(), Model: query.ForecastDrift, Horizon: 1, Level: 0.95, History: 10, End: day(12)})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if result.Pair != "JPY/CNY" || math.Abs(result.LastRate-1/24.5) > 1e-12 || result.Parameters["drift"] >= 0 {
+			t.Errorf("Handle() = %+v, want the falling inverse rates", result)
+		}
+	})
+
+	t.Run("insufficient history", func(t *testing.T) {
+		_, err := handler.Handle(ctx, query.GetForecastQuery{Pair: pair, Model: query.ForecastHolt, Horizon: 1, Level: 0.95, History: 100, End: day(2)})
+		var short rate.ErrInsufficientHistory
+		if !errors.As(err, &short) || short.Have != 2 || short.Need != 4 {
+			t.Errorf("Handle() error = %v, want 2 of 4 rates", err)
+		}
+	})
+}
+
+func TestBacktestForecastsHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+	repo := memory.NewRateRepository(log)
+
+	pair := currency.MustNewPair(currency.USD, currency.JPY)
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	seedRates(t, repo, pair, start, 150, 151, 152, 153, 154, 155, 156, 157, 158, 159, 160, 161)
+	handler := query.NewBacktestForecastsHandler(repo, log)
+
+	results, err := handler.Handle(ctx, query.BacktestForecastsQuery{Pair: pair, Horizon: 2, Folds: 3, History: 100, End: start.AddDate(0, 0, 30)})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if len(results) != len(query.ForecastModels) {
+		t.Fatalf("Handle() returned %d models, want %d", len(results), len(query.ForecastModels))
+	}
+	for i, r := range results {
+		if r.Model != string(query.ForecastModels[i]) || r.Folds != 3 || r.Forecasts != 6 || r.MAE > 1e-6 || r.MAPE > 1e-6 {
+			t.Errorf("result %d = %+v, want exact forecasts over 3 folds", i, r)
+		}
+	}
+
+	_, err = handler.Handle(ctx, query.BacktestForecastsQuery{Pair: pair, Horizon: 2, Folds: 3, History: 100, End: start.AddDate(0, 0, -1)})
+	if !errors.As(err, new(rate.ErrInsufficientHistory)) {
+		t.Errorf("Handle() without history error = %v, want ErrInsufficientHistory", err)
+	}
+}
@@ -0,0 +1,164 @@
+package query
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/pkg/analytics"
+	"github.com/tyokyo320/rateflow/pkg/stream"
+)
+
+// Indicator is a technical indicator computed over a rolling window of rates.
+type Indicator string
+
+// Supported indicators.
+const (
+	IndicatorSMA        Indicator = "sma"        // simple moving average
+	IndicatorEMA        Indicator = "ema"        // exponential moving average
+	IndicatorBollinger  Indicator = "bollinger"  // simple moving average with bands two standard deviations around it
+	IndicatorRSI        Indicator = "rsi"        // Wilder's relative strength index
+	IndicatorROC        Indicator = "roc"        // rate of change in percent
+	IndicatorVolatility Indicator = "volatility" // annualised rolling volatility of log returns
+)
+
+// Indicators lists the supported indicators.
+var Indicators = []Indicator{
+	IndicatorSMA, IndicatorEMA, IndicatorBollinger, IndicatorRSI, IndicatorROC, IndicatorVolatility,
+}
+
+// bollingerWidth is the width of Bollinger bands in standard deviations.
+const bollingerWidth = 2
+
+// MinWindow returns the smallest window the indicator is defined for.
+func (i Indicator) MinWindow() int {
+	if i == IndicatorVolatility {
+		return 2 // a sample standard deviation needs two returns
+	}
+	return 1
+}
+
+// GetIndicatorsQuery represents a query for an indicator of a pair over a window.
+type GetIndicatorsQuery struct {
+	Pair      currency.Pair
+	Indicator Indicator
+	Window    int // number of rates, or of changes between them, the indicator spans
+	Start     time.Time
+	End       time.Time
+	AsOf      *time.Time // optional transaction time to read the rates at
+}
+
+// GetIndicatorsHandler handles computing indicators of a pair.
+type GetIndicatorsHandler struct {
+	rateRepo rate.Repository
+	logger   *slog.Logger
+}
+
+// NewGetIndicatorsHandler creates a new handler.
+func NewGetIndicatorsHandler(
+	rateRepo rate.Repository,
+	logger *slog.Logger,
+) *GetIndicatorsHandler {
+	return &GetIndicatorsHandler{
+		rateRepo: rateRepo,
+		logger:   logger,
+	}
+}
+
+// Handle executes the query. It returns one point per rate in the window,
+// oldest first. The rates just before the window warm the indicator up, so
+// values are null only when the pair has too short a history.
+func (h *GetIndicatorsHandler) Handle(ctx context.Context, query GetIndicatorsQuery) (*dto.IndicatorResponse, error) {
+	start, end := dateOf(query.Start), dateOf(query.End)
+	w := seriesWindow{Pair: query.Pair, Start: &start, End: &end, AsOf: query.AsOf}
+
+	inputs, warmup, err := h.inputs(ctx, w, query.Window)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to compute indicator", "error", err, "pair", query.Pair.String(), "indicator", query.Indicator)
+		return nil, err
+	}
+
+	points := make([]*dto.IndicatorPointResponse, len(inputs))
+	for i, p := range inputs {
+		points[i] = &dto.IndicatorPointResponse{Date: p.Time, Rate: p.Value}
+	}
+
+	// Indicators yield one value per input after their warm-up, so their
+	// values belong to the last inputs
+	values := compute(query.Indicator, stream.FromSlice(inputs), query.Window)
+	offset := len(inputs) - len(values)
+	for i, v := range values {
+		points[offset+i].Value, points[offset+i].Upper, points[offset+i].Lower = v.value, v.upper, v.lower
+	}
+
+	return &dto.IndicatorResponse{
+		Pair:      query.Pair.String(),
+		Indicator: string(query.Indicator),
+		Window:    query.Window,
+		Points:    points[warmup:],
+	}, nil
+}
+
+// inputs returns the rates of the window in the requested direction preceded
+// by up to n rates before it, oldest first, and the number of those.
+func (h *GetIndicatorsHandler) inputs(ctx context.Context, w seriesWindow, n int) ([]analytics.Point, int, error) {
+	inverted, err := storedDirection(ctx, h.rateRepo, w)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	before, err := observationsBefore(ctx, h.rateRepo, w, inverted, n)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	points := make([]analytics.Point, 0, len(before))
+	for _, o := range before {
+		points = append(points, analytics.Point{Time: o.Date, Value: o.Value})
+	}
+	for o, err := range observations(ctx, h.rateRepo, w, inverted) {
+		if err != nil {
+			return nil, 0, err
+		}
+		points = append(points, analytics.Point{Time: o.Date, Value: o.Value})
+	}
+
+	return points, len(before), nil
+}
+
+// indicatorValue is a value of an indicator, with bands for Bollinger bands.
+type indicatorValue struct {
+	value, upper, lower *float64
+}
+
+// compute returns the values of the indicator over points.
+func compute(indicator Indicator, points iter.Seq[analytics.Point], n int) []indicatorValue {
+	if indicator == IndicatorBollinger {
+		return stream.Collect(stream.Map(analytics.Bollinger(points, n, bollingerWidth), func(b analytics.Band) indicatorValue {
+			return indicatorValue{value: &b.Middle, upper: &b.Upper, lower: &b.Lower}
+		}))
+	}
+
+	var values iter.Seq[analytics.Point]
+	switch indicator {
+	case IndicatorSMA:
+		values = analytics.SMA(points, n)
+	case IndicatorEMA:
+		values = analytics.EMA(points, n)
+	case IndicatorRSI:
+		values = analytics.RSI(points, n)
+	case IndicatorROC:
+		values = analytics.ROC(points, n)
+	case IndicatorVolatility:
+		values = analytics.RollingVolatility(points, n)
+	default:
+		return nil
+	}
+	return stream.Collect(stream.Map(values, func(p analytics.Point) indicatorValue {
+		return indicatorValue{value: &p.Value}
+	}))
+}
@@ -0,0 +1,91 @@
+package query_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+func TestGetIndicatorsHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+	repo := memory.NewRateRepository(log)
+
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	seedRates(t, repo, pair, day(1), 10, 20, 40, 20, 10)
+	handler := query.NewGetIndicatorsHandler(repo, log)
+
+	f := func(v float64) *float64 { return &v }
+	equal := func(got, want *float64) bool {
+		if got == nil || want == nil {
+			return got == want
+		}
+		return math.Abs(*got-*want) < 1e-9
+	}
+
+	tests := []struct {
+		name      string
+		query     query.GetIndicatorsQuery
+		wantRates []float64
+		want      []*float64
+	}{
+		{
+			name:      "sma over the whole history",
+			query:     query.GetIndicatorsQuery{Pair: pair, Indicator: query.IndicatorSMA, Window: 2, Start: day(1), End: day(5)},
+			wantRates: []float64{10, 20, 40, 20, 10},
+			want:      []*float64{nil, f(15), f(30), f(30), f(15)},
+		},
+		{
+			name:      "sma warmed up by rates before the window",
+			query:     query.GetIndicatorsQuery{Pair: pair, Indicator: query.IndicatorSMA, Window: 3, Start: day(4), End: day(5)},
+			wantRates: []float64{20, 10},
+			want:      []*float64{f(80.0 / 3), f(70.0 / 3)},
+		},
+		{
+			name:      "roc of the inverse pair",
+			query:     query.GetIndicatorsQuery{Pair: pair.Inverse(), Indicator: query.IndicatorROC, Window: 1, Start: day(2), End: day(3)},
+			wantRates: []float64{0.05, 0.025},
+			want:      []*float64{f(-50), f(-50)},
+		},
+		{
+			name:      "empty window",
+			query:     query.GetIndicatorsQuery{Pair: pair, Indicator: query.IndicatorEMA, Window: 2, Start: day(10), End: day(20)},
+			wantRates: []float64{},
+			want:      []*float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			if result.Pair != tt.query.Pair.String() || len(result.Points) != len(tt.want) {
+				t.Fatalf("result = %+v, want %d points of %s", result, len(tt.want), tt.query.Pair)
+			}
+			for i, p := range result.Points {
+				if math.Abs(p.Rate-tt.wantRates[i]) > 1e-12 || !equal(p.Value, tt.want[i]) {
+					t.Errorf("point %d = %+v, want %v at rate %v", i, p, tt.want[i], tt.wantRates[i])
+				}
+			}
+		})
+	}
+
+	t.Run("bollinger bands", func(t *testing.T) {
+		result, err := handler.Handle(ctx, query.GetIndicatorsQuery{Pair: pair, Indicator: query.IndicatorBollinger, Window: 2, Start: day(5), End: day(5)})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		// 20 and 10 have a population standard deviation of 5
+		p := result.Points[0]
+		if !equal(p.Value, f(15)) || !equal(p.Upper, f(25)) || !equal(p.Lower, f(5)) {
+			t.Errorf("point = %+v, want 15 between 5 and 25", p)
+		}
+	})
+}
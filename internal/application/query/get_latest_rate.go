@@ -2,10 +2,11 @@ package query
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/tyokyo320/rateflow/internal/application/dto"
 	"github.com/tyokyo320/rateflow/internal/domain/currency"
 	"github.com/tyokyo320/rateflow/internal/domain/rate"
@@ -15,12 +16,14 @@ import (
 // GetLatestRateQuery represents a query for the latest exchange rate.
 type GetLatestRateQuery struct {
 	Pair currency.Pair
+	AsOf *time.Time // optional transaction time; bypasses the cache when set
 }
 
 // GetLatestRateHandler handles getting the latest exchange rate.
 type GetLatestRateHandler struct {
 	rateRepo rate.Repository
-	cache    redis.CacheInterface
+	keys     *redis.Keys
+	cached   *cachedQuery[dto.RateResponse]
 	logger   *slog.Logger
 }
 
@@ -28,70 +31,81 @@ type GetLatestRateHandler struct {
 func NewGetLatestRateHandler(
 	rateRepo rate.Repository,
 	cache redis.CacheInterface,
+	policy CachePolicy,
 	logger *slog.Logger,
 ) *GetLatestRateHandler {
 	return &GetLatestRateHandler{
 		rateRepo: rateRepo,
-		cache:    cache,
+		keys:     redis.NewKeys(cache, logger),
+		cached:   newCachedQuery[dto.RateResponse]("latest", cache, policy, logger),
 		logger:   logger,
 	}
 }
 
 // Handle executes the query.
-func (h *GetLatestRateHandler) Handle(ctx context.Context, query GetLatestRateQuery) (*dto.RateResponse, error) {
-	// Try cache first
-	cacheKey := fmt.Sprintf("latest:%s", query.Pair.String())
-	var cached dto.RateResponse
-
-	if err := h.cache.Get(ctx, cacheKey, &cached); err == nil {
-		h.logger.Debug("cache hit", "key", cacheKey)
-		return &cached, nil
+func (h *GetLatestRateHandler) Handle(ctx context.Context, query GetLatestRateQuery) (_ *dto.RateResponse, err error) {
+	ctx, span := startSpan(ctx, "query.GetLatestRate", query.Pair, attribute.Bool("rate.as_of", query.AsOf != nil))
+	defer func() { endSpan(span, err) }()
+
+	// Historical snapshots are not cached
+	if query.AsOf != nil {
+		return h.handleAsOf(ctx, query.Pair, *query.AsOf)
 	}
 
-	// Cache miss - query database
-	h.logger.Debug("cache miss", "key", cacheKey)
+	cacheKey := h.keys.Key(ctx, query.Pair, "latest")
+	return h.cached.get(ctx, cacheKey, func(ctx context.Context) (*dto.RateResponse, error) {
+		return h.find(ctx, query.Pair)
+	})
+}
 
-	r, err := h.rateRepo.FindLatest(ctx, query.Pair)
+// find queries the latest rate, falling back to the inverse pair.
+func (h *GetLatestRateHandler) find(ctx context.Context, pair currency.Pair) (*dto.RateResponse, error) {
+	r, err := h.rateRepo.FindLatest(ctx, pair)
+	if err == nil {
+		return h.toDTO(r), nil
+	}
 
 	// If not found, try inverse pair
+	inversePair := pair.Inverse()
+	inverseFallback(ctx, inversePair)
+	h.logger.DebugContext(ctx, "trying inverse pair",
+		"original_pair", pair.String(),
+		"inverse_pair", inversePair.String(),
+	)
+
+	r, err = h.rateRepo.FindLatest(ctx, inversePair)
 	if err != nil {
-		h.logger.Debug("trying inverse pair",
-			"original_pair", query.Pair.String(),
-			"inverse_pair", query.Pair.Inverse().String(),
+		h.logger.ErrorContext(ctx, "failed to find latest rate for both directions",
+			"error", err,
+			"pair", pair.String(),
+			"inverse_pair", inversePair.String(),
 		)
+		return nil, err
+	}
 
-		inversePair := query.Pair.Inverse()
-		r, err = h.rateRepo.FindLatest(ctx, inversePair)
-
-		if err != nil {
-			h.logger.Error("failed to find latest rate for both directions",
-				"error", err,
-				"pair", query.Pair.String(),
-				"inverse_pair", inversePair.String(),
-			)
-			return nil, err
-		}
-
-		// Found inverse rate - convert it
-		result := h.toDTOInverted(r, query.Pair)
-
-		// Cache the result
-		if err := h.cache.Set(ctx, cacheKey, result, 5*time.Minute); err != nil {
-			h.logger.Warn("failed to cache result", "error", err)
-		}
+	// Found inverse rate - convert it
+	return h.toDTOInverted(r, pair), nil
+}
 
-		return result, nil
+// handleAsOf finds the latest rate as it was known at asOf, falling back to the inverse pair.
+func (h *GetLatestRateHandler) handleAsOf(ctx context.Context, pair currency.Pair, asOf time.Time) (*dto.RateResponse, error) {
+	r, err := h.rateRepo.FindLatestAsOf(ctx, pair, asOf)
+	if err == nil {
+		return h.toDTO(r), nil
 	}
 
-	// Convert to DTO
-	result := h.toDTO(r)
-
-	// Cache the result
-	if err := h.cache.Set(ctx, cacheKey, result, 5*time.Minute); err != nil {
-		h.logger.Warn("failed to cache result", "error", err)
+	inverseFallback(ctx, pair.Inverse())
+	r, err = h.rateRepo.FindLatestAsOf(ctx, pair.Inverse(), asOf)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to find latest rate as of time for both directions",
+			"error", err,
+			"pair", pair.String(),
+			"as_of", asOf,
+		)
+		return nil, err
 	}
 
-	return result, nil
+	return h.toDTOInverted(r, pair), nil
 }
 
 func (h *GetLatestRateHandler) toDTO(r *rate.Rate) *dto.RateResponse {
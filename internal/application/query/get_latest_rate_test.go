@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"iter"
+	"strings"
 	"testing"
 	"time"
 
@@ -48,6 +49,26 @@ func (m *mockRateRepository) DeleteOlderThan(ctx context.Context, date time.Time
 	return 0, errors.New("not implemented")
 }
 
+func (m *mockRateRepository) FindByPairAndDateAsOf(ctx context.Context, pair currency.Pair, date, asOf time.Time) (*rate.Rate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockRateRepository) FindLatestAsOf(ctx context.Context, pair currency.Pair, asOf time.Time) (*rate.Rate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockRateRepository) FindByDateRangeAsOf(ctx context.Context, pair currency.Pair, start, end, asOf time.Time) ([]*rate.Rate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockRateRepository) FindRevisions(ctx context.Context, rateID string) ([]*rate.Revision, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockRateRepository) Aggregate(ctx context.Context, interval rate.Interval, opts ...genericrepo.QueryOption) ([]*rate.Period, error) {
+	return nil, errors.New("not implemented")
+}
+
 // Implement genericrepo.Repository[*rate.Rate] methods
 func (m *mockRateRepository) Create(ctx context.Context, entity *rate.Rate) error {
 	return errors.New("not implemented")
@@ -159,7 +180,7 @@ func TestGetLatestRateHandler_CacheHit(t *testing.T) {
 	}
 
 	log := logger.NewNoop()
-	handler := query.NewGetLatestRateHandler(repo, cache, log)
+	handler := query.NewGetLatestRateHandler(repo, cache, query.DefaultLatestCachePolicy, log)
 
 	// Execute query
 	result, err := handler.Handle(context.Background(), query.GetLatestRateQuery{
@@ -191,11 +212,23 @@ func TestGetLatestRateHandler_CacheMiss(t *testing.T) {
 			return errors.New("cache miss")
 		},
 		setFunc: func(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+			// The pair's namespace version is started on the first lookup
+			if strings.HasPrefix(key, "ns:") {
+				return nil
+			}
+
 			// Verify we're caching the result
 			if _, ok := value.(*dto.RateResponse); !ok {
 				t.Error("expected RateResponse to be cached")
 			}
-			if ttl != 5*time.Minute {
+			switch {
+			case !strings.HasSuffix(key, ":CNY/JPY:latest"):
+				t.Errorf("unexpected cache key %s", key)
+			case strings.HasPrefix(key, "stale:"):
+				if ttl != 6*time.Minute {
+					t.Errorf("expected 6 minute TTL for stale copy, got %v", ttl)
+				}
+			case ttl != 5*time.Minute:
 				t.Errorf("expected 5 minute TTL, got %v", ttl)
 			}
 			return nil
@@ -216,7 +249,8 @@ func TestGetLatestRateHandler_CacheMiss(t *testing.T) {
 	}
 
 	log := logger.NewNoop()
-	handler := query.NewGetLatestRateHandler(repo, cache, log)
+	policy := query.CachePolicy{TTL: 5 * time.Minute, Stale: time.Minute}
+	handler := query.NewGetLatestRateHandler(repo, cache, policy, log)
 
 	// Execute query
 	result, err := handler.Handle(context.Background(), query.GetLatestRateQuery{
@@ -257,7 +291,7 @@ func TestGetLatestRateHandler_RepositoryError(t *testing.T) {
 	}
 
 	log := logger.NewNoop()
-	handler := query.NewGetLatestRateHandler(repo, cache, log)
+	handler := query.NewGetLatestRateHandler(repo, cache, query.DefaultLatestCachePolicy, log)
 
 	// Execute query
 	result, err := handler.Handle(context.Background(), query.GetLatestRateQuery{
@@ -0,0 +1,113 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// GetRateByDateQuery represents a query for the exchange rate on a specific date.
+type GetRateByDateQuery struct {
+	Pair currency.Pair
+	Date time.Time
+	AsOf *time.Time // optional transaction time to reconstruct the rate at
+}
+
+// GetRateByDateHandler handles getting the exchange rate for a specific date.
+type GetRateByDateHandler struct {
+	rateRepo rate.Repository
+	keys     *redis.Keys
+	cached   *cachedQuery[dto.RateResponse]
+	logger   *slog.Logger
+}
+
+// NewGetRateByDateHandler creates a new handler.
+func NewGetRateByDateHandler(
+	rateRepo rate.Repository,
+	cache redis.CacheInterface,
+	policy CachePolicy,
+	logger *slog.Logger,
+) *GetRateByDateHandler {
+	return &GetRateByDateHandler{
+		rateRepo: rateRepo,
+		keys:     redis.NewKeys(cache, logger),
+		cached:   newCachedQuery[dto.RateResponse]("by_date", cache, policy, logger),
+		logger:   logger,
+	}
+}
+
+// Handle executes the query, falling back to the inverse pair when the
+// requested direction is not stored. Historical snapshots are not cached.
+func (h *GetRateByDateHandler) Handle(ctx context.Context, query GetRateByDateQuery) (_ *dto.RateResponse, err error) {
+	ctx, span := startSpan(ctx, "query.GetRateByDate", query.Pair,
+		attribute.String("rate.date", timeutil.FormatDate(query.Date)),
+		attribute.Bool("rate.as_of", query.AsOf != nil),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if query.AsOf != nil {
+		return h.lookup(ctx, query)
+	}
+
+	cacheKey := h.keys.Key(ctx, query.Pair, "bydate", timeutil.FormatDate(query.Date))
+	return h.cached.get(ctx, cacheKey, func(ctx context.Context) (*dto.RateResponse, error) {
+		return h.lookup(ctx, query)
+	})
+}
+
+// lookup queries the rate for the date in either direction.
+func (h *GetRateByDateHandler) lookup(ctx context.Context, query GetRateByDateQuery) (*dto.RateResponse, error) {
+	r, err := h.find(ctx, query.Pair, query)
+	if err == nil {
+		return &dto.RateResponse{
+			ID:            r.ID(),
+			Pair:          r.Pair().String(),
+			BaseCurrency:  r.Pair().Base().String(),
+			QuoteCurrency: r.Pair().Quote().String(),
+			Rate:          r.Value(),
+			EffectiveDate: r.EffectiveDate(),
+			Source:        string(r.Source()),
+			CreatedAt:     r.CreatedAt(),
+			UpdatedAt:     r.UpdatedAt(),
+		}, nil
+	}
+
+	inversePair := query.Pair.Inverse()
+	inverseFallback(ctx, inversePair)
+	r, err = h.find(ctx, inversePair, query)
+	if err != nil {
+		h.logger.DebugContext(ctx, "rate not found for date in either direction",
+			"error", err,
+			"pair", query.Pair.String(),
+			"date", query.Date,
+		)
+		return nil, err
+	}
+
+	return &dto.RateResponse{
+		ID:            r.ID(),
+		Pair:          query.Pair.String(),
+		BaseCurrency:  query.Pair.Base().String(),
+		QuoteCurrency: query.Pair.Quote().String(),
+		Rate:          r.Pair().ConvertRate(r.Value()),
+		EffectiveDate: r.EffectiveDate(),
+		Source:        string(r.Source()),
+		CreatedAt:     r.CreatedAt(),
+		UpdatedAt:     r.UpdatedAt(),
+	}, nil
+}
+
+func (h *GetRateByDateHandler) find(ctx context.Context, pair currency.Pair, query GetRateByDateQuery) (*rate.Rate, error) {
+	if query.AsOf != nil {
+		return h.rateRepo.FindByPairAndDateAsOf(ctx, pair, query.Date, *query.AsOf)
+	}
+	return h.rateRepo.FindByPairAndDate(ctx, pair, query.Date)
+}
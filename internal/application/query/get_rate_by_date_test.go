@@ -0,0 +1,82 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+)
+
+type mockByDateRepository struct {
+	mockRateRepository
+	rates map[string]*rate.Rate // keyed by pair string
+	asOfs []time.Time
+}
+
+func (m *mockByDateRepository) FindByPairAndDate(ctx context.Context, pair currency.Pair, date time.Time) (*rate.Rate, error) {
+	if r, ok := m.rates[pair.String()]; ok {
+		return r, nil
+	}
+	return nil, rate.ErrRateNotFound{}
+}
+
+func (m *mockByDateRepository) FindByPairAndDateAsOf(ctx context.Context, pair currency.Pair, date, asOf time.Time) (*rate.Rate, error) {
+	m.asOfs = append(m.asOfs, asOf)
+	return m.FindByPairAndDate(ctx, pair, date)
+}
+
+func TestGetRateByDateHandler(t *testing.T) {
+	date := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC)
+	cnyJpy := currency.MustNewPair(currency.CNY, currency.JPY)
+	jpyCny := cnyJpy.Inverse()
+
+	direct, _ := rate.NewRate(cnyJpy, 20.0, date, rate.SourceUnionPay)
+	inverse, _ := rate.NewRate(jpyCny, 0.05, date, rate.SourceUnionPay)
+
+	tests := []struct {
+		name     string
+		stored   *rate.Rate
+		asOf     *time.Time
+		wantRate float64
+		wantAsOf bool
+	}{
+		{name: "direct pair", stored: direct, wantRate: 20.0},
+		{name: "inverse pair", stored: inverse, wantRate: 20.0},
+		{name: "direct pair as of", stored: direct, asOf: &asOf, wantRate: 20.0, wantAsOf: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockByDateRepository{
+				rates: map[string]*rate.Rate{tt.stored.Pair().String(): tt.stored},
+			}
+			handler := query.NewGetRateByDateHandler(repo, &mockCache{}, query.DefaultByDateCachePolicy, logger.NewNoop())
+
+			result, err := handler.Handle(context.Background(), query.GetRateByDateQuery{
+				Pair: cnyJpy,
+				Date: date,
+				AsOf: tt.asOf,
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Pair != "CNY/JPY" {
+				t.Errorf("expected pair CNY/JPY, got %s", result.Pair)
+			}
+			if result.Rate != tt.wantRate {
+				t.Errorf("expected rate %v, got %v", tt.wantRate, result.Rate)
+			}
+			if tt.wantAsOf && (len(repo.asOfs) == 0 || !repo.asOfs[0].Equal(asOf)) {
+				t.Errorf("expected as-of lookup at %v, got %v", asOf, repo.asOfs)
+			}
+			if !tt.wantAsOf && len(repo.asOfs) > 0 {
+				t.Error("expected current-state lookup without asOf")
+			}
+		})
+	}
+}
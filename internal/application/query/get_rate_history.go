@@ -0,0 +1,77 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// GetRateHistoryQuery represents a query for the revision history of a rate.
+type GetRateHistoryQuery struct {
+	RateID string
+}
+
+// GetRateHistoryHandler handles getting the revision history of a rate.
+type GetRateHistoryHandler struct {
+	rateRepo rate.Repository
+	logger   *slog.Logger
+}
+
+// NewGetRateHistoryHandler creates a new handler.
+func NewGetRateHistoryHandler(
+	rateRepo rate.Repository,
+	logger *slog.Logger,
+) *GetRateHistoryHandler {
+	return &GetRateHistoryHandler{
+		rateRepo: rateRepo,
+		logger:   logger,
+	}
+}
+
+// Handle executes the query.
+func (h *GetRateHistoryHandler) Handle(ctx context.Context, query GetRateHistoryQuery) (*dto.RateHistoryResponse, error) {
+	r, err := h.rateRepo.FindByID(ctx, query.RateID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := h.rateRepo.FindRevisions(ctx, query.RateID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to find rate revisions",
+			"error", err,
+			"rate_id", query.RateID,
+		)
+		return nil, err
+	}
+
+	items := make([]*dto.RevisionResponse, 0, len(revisions))
+	for _, rev := range revisions {
+		items = append(items, &dto.RevisionResponse{
+			ID:        rev.ID,
+			OldValue:  rev.OldValue,
+			NewValue:  rev.NewValue,
+			Source:    string(rev.Source),
+			Actor:     string(rev.Actor),
+			ActorID:   rev.ActorID,
+			Reason:    rev.Reason,
+			CreatedAt: rev.CreatedAt,
+		})
+	}
+
+	return &dto.RateHistoryResponse{
+		Rate: &dto.RateResponse{
+			ID:            r.ID(),
+			Pair:          r.Pair().String(),
+			BaseCurrency:  r.Pair().Base().String(),
+			QuoteCurrency: r.Pair().Quote().String(),
+			Rate:          r.Value(),
+			EffectiveDate: r.EffectiveDate(),
+			Source:        string(r.Source()),
+			CreatedAt:     r.CreatedAt(),
+			UpdatedAt:     r.UpdatedAt(),
+		},
+		Revisions: items,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+)
+
+type mockHistoryRepository struct {
+	mockRateRepository
+	findByIDFunc      func(ctx context.Context, id string) (*rate.Rate, error)
+	findRevisionsFunc func(ctx context.Context, rateID string) ([]*rate.Revision, error)
+}
+
+func (m *mockHistoryRepository) FindByID(ctx context.Context, id string) (*rate.Rate, error) {
+	if m.findByIDFunc != nil {
+		return m.findByIDFunc(ctx, id)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockHistoryRepository) FindRevisions(ctx context.Context, rateID string) ([]*rate.Revision, error) {
+	if m.findRevisionsFunc != nil {
+		return m.findRevisionsFunc(ctx, rateID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func TestGetRateHistoryHandler_Success(t *testing.T) {
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	now := time.Now()
+	r, _ := rate.NewRate(pair, 20.5, now, rate.SourceUnionPay)
+
+	oldValue := 20.0
+	repo := &mockHistoryRepository{
+		findByIDFunc: func(ctx context.Context, id string) (*rate.Rate, error) {
+			return r, nil
+		},
+		findRevisionsFunc: func(ctx context.Context, rateID string) ([]*rate.Revision, error) {
+			if rateID != r.ID() {
+				t.Errorf("expected rate ID %s, got %s", r.ID(), rateID)
+			}
+			return []*rate.Revision{
+				{ID: "rev-1", RateID: rateID, NewValue: 20.0, Source: rate.SourceUnionPay, Actor: rate.ActorWorker, CreatedAt: now.Add(-time.Hour)},
+				{ID: "rev-2", RateID: rateID, OldValue: &oldValue, NewValue: 20.5, Source: rate.SourceUnionPay, Actor: rate.ActorManual, ActorID: "alice", Reason: "provider restatement", CreatedAt: now},
+			}, nil
+		},
+	}
+
+	handler := query.NewGetRateHistoryHandler(repo, logger.NewNoop())
+
+	result, err := handler.Handle(context.Background(), query.GetRateHistoryQuery{RateID: r.ID()})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Rate.Rate != 20.5 {
+		t.Errorf("expected current rate 20.5, got %f", result.Rate.Rate)
+	}
+	if len(result.Revisions) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(result.Revisions))
+	}
+	if result.Revisions[0].OldValue != nil {
+		t.Error("expected initial revision to have no old value")
+	}
+	if got := result.Revisions[1]; got.OldValue == nil || *got.OldValue != 20.0 || got.Actor != "manual" {
+		t.Errorf("unexpected correction revision: %+v", got)
+	}
+}
+
+func TestGetRateHistoryHandler_NotFound(t *testing.T) {
+	repo := &mockHistoryRepository{
+		findByIDFunc: func(ctx context.Context, id string) (*rate.Rate, error) {
+			return nil, rate.ErrRateNotFound{ID: id}
+		},
+		findRevisionsFunc: func(ctx context.Context, rateID string) ([]*rate.Revision, error) {
+			t.Error("revisions should not be loaded for a missing rate")
+			return nil, nil
+		},
+	}
+
+	handler := query.NewGetRateHistoryHandler(repo, logger.NewNoop())
+
+	result, err := handler.Handle(context.Background(), query.GetRateHistoryQuery{RateID: "missing"})
+
+	var notFound rate.ErrRateNotFound
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected ErrRateNotFound, got %v", err)
+	}
+	if result != nil {
+		t.Error("expected nil result on error")
+	}
+}
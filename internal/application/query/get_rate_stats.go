@@ -0,0 +1,145 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// daysPerYear annualises volatility by calendar days, as rates are published
+// on every day the provider works rather than on exchange trading days.
+const daysPerYear = 365.25
+
+// GetRateStatsQuery represents a query for the statistics of a pair over a window.
+type GetRateStatsQuery struct {
+	Pair  currency.Pair
+	Start *time.Time // optional earliest effective date
+	End   *time.Time // optional latest effective date
+	AsOf  *time.Time // optional transaction time to compute the statistics at
+}
+
+// GetRateStatsHandler handles computing the statistics of a pair.
+type GetRateStatsHandler struct {
+	rateRepo rate.Repository
+	logger   *slog.Logger
+}
+
+// NewGetRateStatsHandler creates a new handler.
+func NewGetRateStatsHandler(
+	rateRepo rate.Repository,
+	logger *slog.Logger,
+) *GetRateStatsHandler {
+	return &GetRateStatsHandler{
+		rateRepo: rateRepo,
+		logger:   logger,
+	}
+}
+
+// Handle executes the query. The rates are streamed from the repository, so
+// only their values are held in memory, for the median.
+// It returns rate.ErrRateNotFound when the window contains no rates.
+func (h *GetRateStatsHandler) Handle(ctx context.Context, query GetRateStatsQuery) (*dto.RateStatsResponse, error) {
+	w := seriesWindow{Pair: query.Pair, Start: query.Start, End: query.End, AsOf: query.AsOf}
+
+	inverted, err := storedDirection(ctx, h.rateRepo, w)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to count rates for stats", "error", err, "pair", query.Pair.String())
+		return nil, err
+	}
+
+	var (
+		values      []float64
+		first, last observation
+		low, high   observation
+		sum         float64
+		returns     []float64
+	)
+	for o, err := range observations(ctx, h.rateRepo, w, inverted) {
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to stream rates for stats", "error", err, "pair", query.Pair.String())
+			return nil, err
+		}
+
+		if len(values) == 0 {
+			first, low, high = o, o, o
+		} else {
+			returns = append(returns, math.Log(o.Value/last.Value))
+		}
+		if o.Value < low.Value {
+			low = o
+		}
+		if o.Value > high.Value {
+			high = o
+		}
+		last = o
+		sum += o.Value
+		values = append(values, o.Value)
+	}
+
+	if len(values) == 0 {
+		return nil, rate.ErrRateNotFound{}
+	}
+
+	n := float64(len(values))
+	mean := sum / n
+	stats := &dto.RateStatsResponse{
+		Pair:          query.Pair.String(),
+		Count:         len(values),
+		Min:           dto.RatePointResponse{Date: low.Date, Rate: low.Value},
+		Max:           dto.RatePointResponse{Date: high.Date, Rate: high.Value},
+		Mean:          mean,
+		Median:        median(values),
+		StdDev:        stdDev(values, mean),
+		First:         dto.RatePointResponse{Date: first.Date, Rate: first.Value},
+		Last:          dto.RatePointResponse{Date: last.Date, Rate: last.Value},
+		Change:        last.Value - first.Value,
+		ChangePercent: (last.Value - first.Value) / first.Value * 100,
+	}
+
+	// Volatility needs at least two returns, and returns over some days
+	if days := last.Date.Sub(first.Date).Hours() / 24; len(returns) >= 2 && days > 0 {
+		periodsPerYear := float64(len(returns)) / days * daysPerYear
+		volatility := stdDev(returns, mean64(returns)) * math.Sqrt(periodsPerYear)
+		stats.Volatility = &volatility
+	}
+
+	return stats, nil
+}
+
+// median returns the median of values, reordering them.
+func median(values []float64) float64 {
+	slices.Sort(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// mean64 returns the arithmetic mean of values.
+func mean64(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev returns the sample standard deviation of values around mean, or 0 for
+// fewer than two values.
+func stdDev(values []float64, mean float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(squares / float64(len(values)-1))
+}
@@ -0,0 +1,130 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+func TestGetRateStatsHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+	repo := memory.NewRateRepository(log)
+
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	seedRates(t, repo, pair, day(1), 20, 21, 19, 22)
+	handler := query.NewGetRateStatsHandler(repo, log)
+
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+
+	t.Run("whole history", func(t *testing.T) {
+		stats, err := handler.Handle(ctx, query.GetRateStatsQuery{Pair: pair})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+
+		if stats.Count != 4 || stats.Min.Rate != 19 || !stats.Min.Date.Equal(day(3)) || stats.Max.Rate != 22 || !stats.Max.Date.Equal(day(4)) {
+			t.Errorf("count, min and max = %d, %+v, %+v; want 4, 19 on Jan 3 and 22 on Jan 4", stats.Count, stats.Min, stats.Max)
+		}
+		if !near(stats.Mean, 20.5) || !near(stats.Median, 20.5) || !near(stats.StdDev, math.Sqrt(5.0/3)) {
+			t.Errorf("mean, median and stddev = %v, %v, %v; want 20.5, 20.5, %v", stats.Mean, stats.Median, stats.StdDev, math.Sqrt(5.0/3))
+		}
+		if stats.First.Rate != 20 || stats.Last.Rate != 22 || !near(stats.Change, 2) || !near(stats.ChangePercent, 10) {
+			t.Errorf("first, last and change = %+v, %+v, %v (%v%%); want 20, 22, 2 (10%%)", stats.First, stats.Last, stats.Change, stats.ChangePercent)
+		}
+
+		// Daily returns annualise by the days of a year
+		returns := []float64{math.Log(21.0 / 20), math.Log(19.0 / 21), math.Log(22.0 / 19)}
+		var mean, squares float64
+		for _, r := range returns {
+			mean += r / 3
+		}
+		for _, r := range returns {
+			squares += (r - mean) * (r - mean)
+		}
+		want := math.Sqrt(squares/2) * math.Sqrt(365.25)
+		if stats.Volatility == nil || !near(*stats.Volatility, want) {
+			t.Errorf("Volatility = %v, want %v", stats.Volatility, want)
+		}
+	})
+
+	t.Run("inverse pair", func(t *testing.T) {
+		stats, err := handler.Handle(ctx, query.GetRateStatsQuery{Pair: pair.Inverse()})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if stats.Pair != "JPY/CNY" || !near(stats.Min.Rate, 1.0/22) || !stats.Min.Date.Equal(day(4)) || !near(stats.First.Rate, 1.0/20) {
+			t.Errorf("stats = %+v, want the inverted rates of CNY/JPY", stats)
+		}
+	})
+
+	t.Run("window", func(t *testing.T) {
+		start, end := day(2), day(3)
+		stats, err := handler.Handle(ctx, query.GetRateStatsQuery{Pair: pair, Start: &start, End: &end})
+		if err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+		if stats.Count != 2 || stats.First.Rate != 21 || stats.Last.Rate != 19 || stats.Volatility != nil {
+			t.Errorf("stats = %+v, want the 2 rates of Jan 2 and 3 without volatility", stats)
+		}
+	})
+
+	t.Run("empty window", func(t *testing.T) {
+		start := day(10)
+		_, err := handler.Handle(ctx, query.GetRateStatsQuery{Pair: pair, Start: &start})
+		var notFound rate.ErrRateNotFound
+		if !errors.As(err, &notFound) {
+			t.Errorf("Handle() error = %v, want ErrRateNotFound", err)
+		}
+	})
+}
+
+func TestGetRateStatsHandler_DirectionAgreesWithList(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+	repo := memory.NewRateRepository(log)
+
+	// Ten days stored as CNY/JPY and fifteen as JPY/CNY
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	seedRates(t, repo, pair, day(1), slices.Repeat([]float64{20}, 10)...)
+	seedRates(t, repo, pair.Inverse(), day(1), slices.Repeat([]float64{0.04}, 15)...)
+	stats := query.NewGetRateStatsHandler(repo, log)
+	list := query.NewListRatesHandler(repo, log)
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		wantRate   float64 // in CNY/JPY
+	}{
+		{"enough direct rates", day(1), day(15), 20},
+		{"few direct rates", day(8), day(15), 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := stats.Handle(ctx, query.GetRateStatsQuery{Pair: pair, Start: &tt.start, End: &tt.end})
+			if err != nil {
+				t.Fatalf("stats Handle() error = %v", err)
+			}
+			l, err := list.Handle(ctx, query.ListRatesQuery{Pair: pair, Page: 1, PageSize: 100, StartDate: &tt.start, EndDate: &tt.end})
+			if err != nil {
+				t.Fatalf("list Handle() error = %v", err)
+			}
+			if len(l.Items) == 0 || math.Abs(l.Items[0].Rate-tt.wantRate) > 1e-9 {
+				t.Fatalf("list = %+v, want rates of %v", l.Items, tt.wantRate)
+			}
+			if s.Count != len(l.Items) || math.Abs(s.Mean-tt.wantRate) > 1e-9 {
+				t.Errorf("stats of %d rates with mean %v, want the %d listed rates of %v", s.Count, s.Mean, len(l.Items), tt.wantRate)
+			}
+		})
+	}
+}
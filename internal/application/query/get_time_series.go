@@ -0,0 +1,169 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// FillMode determines the values of the steps of a time series without rates.
+type FillMode string
+
+// Supported fill modes.
+const (
+	FillNone     FillMode = "none"     // leave the step empty
+	FillPrevious FillMode = "previous" // carry the last known rate forward, also from before the window
+	FillLinear   FillMode = "linear"   // interpolate between the surrounding rates of the window
+)
+
+// GetTimeSeriesQuery represents a query for aligned time series of several pairs.
+type GetTimeSeriesQuery struct {
+	Pairs    []currency.Pair
+	Start    time.Time
+	End      time.Time
+	StepDays int // length of a step in days; each step covers [date, date+StepDays)
+	Fill     FillMode
+	AsOf     *time.Time // optional transaction time to read the rates at
+}
+
+// Steps returns the number of steps of the series.
+func (q GetTimeSeriesQuery) Steps() int {
+	if q.StepDays <= 0 || q.End.Before(q.Start) {
+		return 0
+	}
+	return int(dateOf(q.End).Sub(dateOf(q.Start)).Hours()/24)/q.StepDays + 1
+}
+
+// GetTimeSeriesHandler handles building the time series of several pairs.
+type GetTimeSeriesHandler struct {
+	rateRepo rate.Repository
+	logger   *slog.Logger
+}
+
+// NewGetTimeSeriesHandler creates a new handler.
+func NewGetTimeSeriesHandler(
+	rateRepo rate.Repository,
+	logger *slog.Logger,
+) *GetTimeSeriesHandler {
+	return &GetTimeSeriesHandler{
+		rateRepo: rateRepo,
+		logger:   logger,
+	}
+}
+
+// Handle executes the query. Each series holds one value per step, the last
+// rate within the step, so all series share the dates of the response.
+// Callers bound the number of steps; see GetTimeSeriesQuery.Steps.
+func (h *GetTimeSeriesHandler) Handle(ctx context.Context, query GetTimeSeriesQuery) (*dto.TimeSeriesResponse, error) {
+	start, end := dateOf(query.Start), dateOf(query.End)
+	steps := query.Steps()
+
+	dates := make([]string, steps)
+	for i := range dates {
+		dates[i] = timeutil.FormatDate(start.AddDate(0, 0, i*query.StepDays))
+	}
+
+	series := make([]*dto.TimeSeries, 0, len(query.Pairs))
+	for _, pair := range query.Pairs {
+		w := seriesWindow{Pair: pair, Start: &start, End: &end, AsOf: query.AsOf}
+		values, err := h.series(ctx, w, query, steps)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to build time series", "error", err, "pair", pair.String())
+			return nil, err
+		}
+		series = append(series, &dto.TimeSeries{Pair: pair.String(), Values: values})
+	}
+
+	return &dto.TimeSeriesResponse{
+		Start:    timeutil.FormatDate(start),
+		End:      timeutil.FormatDate(end),
+		StepDays: query.StepDays,
+		Fill:     string(query.Fill),
+		Dates:    dates,
+		Series:   series,
+	}, nil
+}
+
+// series returns the values of one pair per step, filled as the query asks.
+func (h *GetTimeSeriesHandler) series(ctx context.Context, w seriesWindow, query GetTimeSeriesQuery, steps int) ([]*float64, error) {
+	inverted, err := storedDirection(ctx, h.rateRepo, w)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*float64, steps)
+	for o, err := range observations(ctx, h.rateRepo, w, inverted) {
+		if err != nil {
+			return nil, err
+		}
+		step := int(dateOf(o.Date).Sub(*w.Start).Hours()/24) / query.StepDays
+		if step >= 0 && step < steps {
+			value := o.Value
+			values[step] = &value
+		}
+	}
+
+	switch query.Fill {
+	case FillPrevious:
+		previous, err := h.lastBefore(ctx, w, inverted)
+		if err != nil {
+			return nil, err
+		}
+		fillPrevious(values, previous)
+	case FillLinear:
+		fillLinear(values)
+	}
+
+	return values, nil
+}
+
+// lastBefore returns the last rate of the window's pair before its start, in
+// the requested direction, or nil if there is none.
+func (h *GetTimeSeriesHandler) lastBefore(ctx context.Context, w seriesWindow, inverted bool) (*float64, error) {
+	before, err := observationsBefore(ctx, h.rateRepo, w, inverted, 1)
+	if err != nil || len(before) == 0 {
+		return nil, err
+	}
+	return &before[0].Value, nil
+}
+
+// fillPrevious fills empty steps with the value of the step before them,
+// starting from previous.
+func fillPrevious(values []*float64, previous *float64) {
+	for i, v := range values {
+		if v == nil {
+			values[i] = previous
+		}
+		previous = values[i]
+	}
+}
+
+// fillLinear fills empty steps between two values by linear interpolation.
+// Steps before the first and after the last value stay empty.
+func fillLinear(values []*float64) {
+	last := -1
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if last >= 0 {
+			from, to := *values[last], *v
+			for j := last + 1; j < i; j++ {
+				interpolated := from + (to-from)*float64(j-last)/float64(i-last)
+				values[j] = &interpolated
+			}
+		}
+		last = i
+	}
+}
+
+// dateOf returns the calendar date of t at midnight UTC.
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
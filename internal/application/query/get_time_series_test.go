@@ -0,0 +1,84 @@
+package query_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+func TestGetTimeSeriesHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+	repo := memory.NewRateRepository(log)
+
+	// The CNY/JPY rate of Jan 1 is before the window
+	cnyJpy := currency.MustNewPair(currency.CNY, currency.JPY)
+	usdJpy := currency.MustNewPair(currency.USD, currency.JPY)
+	seedRates(t, repo, cnyJpy, day(1), 19, 0, 20, 0, 0, 23)
+	seedRate(t, repo, usdJpy, day(4), 150)
+	handler := query.NewGetTimeSeriesHandler(repo, log)
+
+	format := func(values []*float64) string {
+		s := make([]string, len(values))
+		for i, v := range values {
+			s[i] = "-"
+			if v != nil {
+				s[i] = fmt.Sprintf("%.4g", *v)
+			}
+		}
+		return fmt.Sprint(s)
+	}
+
+	tests := []struct {
+		name  string
+		pairs []currency.Pair
+		step  int
+		fill  query.FillMode
+		dates string
+		want  []string
+	}{
+		{"none", []currency.Pair{cnyJpy, usdJpy}, 1, query.FillNone,
+			"[2024-01-02 2024-01-03 2024-01-04 2024-01-05 2024-01-06 2024-01-07]",
+			[]string{"[- 20 - - 23 -]", "[- - 150 - - -]"}},
+		{"previous", []currency.Pair{cnyJpy}, 1, query.FillPrevious,
+			"[2024-01-02 2024-01-03 2024-01-04 2024-01-05 2024-01-06 2024-01-07]",
+			[]string{"[19 20 20 20 23 23]"}},
+		{"linear", []currency.Pair{cnyJpy}, 1, query.FillLinear,
+			"[2024-01-02 2024-01-03 2024-01-04 2024-01-05 2024-01-06 2024-01-07]",
+			[]string{"[- 20 21 22 23 -]"}},
+		{"inverse pair", []currency.Pair{cnyJpy.Inverse()}, 1, query.FillNone,
+			"[2024-01-02 2024-01-03 2024-01-04 2024-01-05 2024-01-06 2024-01-07]",
+			[]string{"[- 0.05 - - 0.04348 -]"}},
+		{"two-day steps", []currency.Pair{cnyJpy}, 2, query.FillNone,
+			"[2024-01-02 2024-01-04 2024-01-06]",
+			[]string{"[20 - 23]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(ctx, query.GetTimeSeriesQuery{
+				Pairs:    tt.pairs,
+				Start:    day(2),
+				End:      day(7),
+				StepDays: tt.step,
+				Fill:     tt.fill,
+			})
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			if got := fmt.Sprint(result.Dates); got != tt.dates {
+				t.Errorf("Dates = %s, want %s", got, tt.dates)
+			}
+			for i, series := range result.Series {
+				if got := format(series.Values); got != tt.want[i] {
+					t.Errorf("%s values = %s, want %s", series.Pair, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
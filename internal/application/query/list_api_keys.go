@@ -0,0 +1,66 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/apikey"
+)
+
+// ListAPIKeysHandler handles listing API keys with their usage today.
+type ListAPIKeysHandler struct {
+	keys   apikey.Repository
+	logger *slog.Logger
+}
+
+// NewListAPIKeysHandler creates a new handler.
+func NewListAPIKeysHandler(keys apikey.Repository, logger *slog.Logger) *ListAPIKeysHandler {
+	return &ListAPIKeysHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// Handle executes the query.
+func (h *ListAPIKeysHandler) Handle(ctx context.Context) ([]dto.APIKeyResponse, error) {
+	keys, err := h.keys.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+
+	today := time.Now().UTC()
+	responses := make([]dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		usage, err := h.keys.UsageOn(ctx, key.ID, today)
+		if err != nil {
+			return nil, fmt.Errorf("read usage of api key %s: %w", key.ID, err)
+		}
+
+		var used int64
+		for _, u := range usage {
+			used += u.Count
+		}
+
+		scopes := make([]string, len(key.Scopes))
+		for j, scope := range key.Scopes {
+			scopes[j] = string(scope)
+		}
+
+		responses[i] = dto.APIKeyResponse{
+			ID:         key.ID,
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			Scopes:     scopes,
+			DailyQuota: key.DailyQuota,
+			UsedToday:  used,
+			CreatedAt:  key.CreatedAt,
+			LastUsedAt: key.LastUsedAt,
+			RevokedAt:  key.RevokedAt,
+		}
+	}
+
+	return responses, nil
+}
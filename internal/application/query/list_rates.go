@@ -9,6 +9,7 @@ import (
 	"github.com/tyokyo320/rateflow/internal/domain/currency"
 	"github.com/tyokyo320/rateflow/internal/domain/rate"
 	"github.com/tyokyo320/rateflow/pkg/genericrepo"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
 )
 
 // ListRatesQuery represents a query for listing rates with pagination.
@@ -18,12 +19,18 @@ type ListRatesQuery struct {
 	PageSize  int
 	StartDate *time.Time
 	EndDate   *time.Time
+	AsOf      *time.Time          // optional transaction time to reconstruct the list at
+	Cursor    *genericrepo.Cursor // optional keyset position; replaces Page when set
+	Sources   []rate.Source       // optional; matches any of the given sources
+	MinRate   *float64            // optional inclusive lower bound in the requested direction
+	MaxRate   *float64            // optional inclusive upper bound in the requested direction
 }
 
 // ListRatesResult contains the paginated list of rates.
 type ListRatesResult struct {
 	Items      []*dto.RateResponse    `json:"items"`
 	Pagination genericrepo.Pagination `json:"pagination"`
+	NextCursor string                 `json:"nextCursor,omitempty"`
 }
 
 // ListRatesHandler handles listing exchange rates.
@@ -45,37 +52,27 @@ func NewListRatesHandler(
 
 // Handle executes the query.
 func (h *ListRatesHandler) Handle(ctx context.Context, query ListRatesQuery) (*ListRatesResult, error) {
-	// If date range is specified, use FindByDateRange instead of generic query
-	if query.StartDate != nil && query.EndDate != nil {
-		return h.handleDateRangeQuery(ctx, query)
-	}
-
 	// Build query options
 	opts := []genericrepo.QueryOption{
-		genericrepo.WithFilter("base_currency", query.Pair.Base().String()),
-		genericrepo.WithFilter("quote_currency", query.Pair.Quote().String()),
-		genericrepo.WithOrderBy("effective_date DESC"),
-		genericrepo.WithPagination(query.Page, query.PageSize),
+		genericrepo.Where(listConditions(query, query.Pair, false)...),
+		genericrepo.WithOrderBy("effective_date DESC, id DESC"),
 	}
+	opts = append(opts, pageOptions(query)...)
 
 	// Get rates - try direct query first
-	rates, err := h.rateRepo.FindAll(ctx, opts...)
+	rates, err := h.findAll(ctx, query.AsOf, opts...)
 	needsInversion := false
 	directCount := int64(0)
 
 	// Count direct results
 	if err == nil && len(rates) > 0 {
-		countOpts := []genericrepo.QueryOption{
-			genericrepo.WithFilter("base_currency", query.Pair.Base().String()),
-			genericrepo.WithFilter("quote_currency", query.Pair.Quote().String()),
-		}
-		directCount, _ = h.rateRepo.Count(ctx, countOpts...)
+		directCount, _ = h.count(ctx, query.AsOf, genericrepo.Where(listConditions(query, query.Pair, false)...))
 	}
 
-	// Try inverse pair if: 1) error occurred, 2) no results, OR 3) very few results (< 10)
+	// Try inverse pair if: 1) error occurred, 2) no results, OR 3) very few results
 	// This handles cases where one direction has much more data than the other
-	if err != nil || len(rates) == 0 || directCount < 10 {
-		h.logger.Debug("trying inverse pair for list",
+	if err != nil || len(rates) == 0 || directCount < minDirectRates {
+		h.logger.DebugContext(ctx, "trying inverse pair for list",
 			"original_pair", query.Pair.String(),
 			"inverse_pair", query.Pair.Inverse().String(),
 			"direct_count", directCount,
@@ -83,27 +80,22 @@ func (h *ListRatesHandler) Handle(ctx context.Context, query ListRatesQuery) (*L
 
 		inversePair := query.Pair.Inverse()
 		inverseOpts := []genericrepo.QueryOption{
-			genericrepo.WithFilter("base_currency", inversePair.Base().String()),
-			genericrepo.WithFilter("quote_currency", inversePair.Quote().String()),
-			genericrepo.WithOrderBy("effective_date DESC"),
-			genericrepo.WithPagination(query.Page, query.PageSize),
+			genericrepo.Where(listConditions(query, inversePair, true)...),
+			genericrepo.WithOrderBy("effective_date DESC, id DESC"),
 		}
+		inverseOpts = append(inverseOpts, pageOptions(query)...)
 
-		inverseRates, inverseErr := h.rateRepo.FindAll(ctx, inverseOpts...)
+		inverseRates, inverseErr := h.findAll(ctx, query.AsOf, inverseOpts...)
 
 		// Count inverse results
 		inverseCount := int64(0)
 		if inverseErr == nil && len(inverseRates) > 0 {
-			inverseCountOpts := []genericrepo.QueryOption{
-				genericrepo.WithFilter("base_currency", inversePair.Base().String()),
-				genericrepo.WithFilter("quote_currency", inversePair.Quote().String()),
-			}
-			inverseCount, _ = h.rateRepo.Count(ctx, inverseCountOpts...)
+			inverseCount, _ = h.count(ctx, query.AsOf, genericrepo.Where(listConditions(query, inversePair, true)...))
 		}
 
 		// Use inverse data if it has more records
-		if inverseErr == nil && inverseCount > directCount {
-			h.logger.Debug("using inverse pair data",
+		if inverseErr == nil && preferInverse(directCount, inverseCount) {
+			h.logger.DebugContext(ctx, "using inverse pair data",
 				"direct_count", directCount,
 				"inverse_count", inverseCount,
 			)
@@ -112,7 +104,7 @@ func (h *ListRatesHandler) Handle(ctx context.Context, query ListRatesQuery) (*L
 		} else if err != nil {
 			// If direct query failed and inverse also failed, return error
 			if inverseErr != nil {
-				h.logger.Error("failed to list rates for both directions",
+				h.logger.ErrorContext(ctx, "failed to list rates for both directions",
 					"error", err,
 					"inverse_error", inverseErr,
 					"pair", query.Pair.String(),
@@ -128,22 +120,13 @@ func (h *ListRatesHandler) Handle(ctx context.Context, query ListRatesQuery) (*L
 	// Get total count (use inverse if needed)
 	var total int64
 	if needsInversion {
-		inversePair := query.Pair.Inverse()
-		countOpts := []genericrepo.QueryOption{
-			genericrepo.WithFilter("base_currency", inversePair.Base().String()),
-			genericrepo.WithFilter("quote_currency", inversePair.Quote().String()),
-		}
-		total, err = h.rateRepo.Count(ctx, countOpts...)
+		total, err = h.count(ctx, query.AsOf, genericrepo.Where(listConditions(query, query.Pair.Inverse(), true)...))
 	} else {
-		countOpts := []genericrepo.QueryOption{
-			genericrepo.WithFilter("base_currency", query.Pair.Base().String()),
-			genericrepo.WithFilter("quote_currency", query.Pair.Quote().String()),
-		}
-		total, err = h.rateRepo.Count(ctx, countOpts...)
+		total, err = h.count(ctx, query.AsOf, genericrepo.Where(listConditions(query, query.Pair, false)...))
 	}
 
 	if err != nil {
-		h.logger.Error("failed to count rates", "error", err)
+		h.logger.ErrorContext(ctx, "failed to count rates", "error", err)
 		return nil, err
 	}
 
@@ -165,12 +148,119 @@ func (h *ListRatesHandler) Handle(ctx context.Context, query ListRatesQuery) (*L
 			PageSize: query.PageSize,
 			Total:    total,
 		},
+		NextCursor: nextCursor(rates, query.PageSize),
 	}
 	result.Pagination.CalculateTotalPages()
 
 	return result, nil
 }
 
+// minDirectRates is the number of rates of the requested pair from which it is
+// read as requested, however many rates its inverse has.
+const minDirectRates = 10
+
+// preferInverse reports whether rates are read from the inverse of the
+// requested pair, given the number of rates of each. Every query that reads
+// either direction uses this rule, so that they agree on the direction of a
+// window.
+func preferInverse(direct, inverse int64) bool {
+	return direct < minDirectRates && inverse > direct
+}
+
+// listConditions builds the filter conditions of the query for the given stored pair.
+// When the stored pair is the inverse of the requested one, the rate bounds are
+// inverted too: a requested rate x corresponds to a stored value of 1/x.
+func listConditions(query ListRatesQuery, pair currency.Pair, inverted bool) []genericrepo.Filter {
+	filters := []genericrepo.Filter{
+		genericrepo.Eq(rate.FieldBaseCurrency, pair.Base().String()),
+		genericrepo.Eq(rate.FieldQuoteCurrency, pair.Quote().String()),
+	}
+
+	if query.StartDate != nil {
+		filters = append(filters, genericrepo.Gte(rate.FieldEffectiveDate, timeutil.FormatDate(*query.StartDate)))
+	}
+	if query.EndDate != nil {
+		filters = append(filters, genericrepo.Lte(rate.FieldEffectiveDate, timeutil.FormatDate(*query.EndDate)))
+	}
+
+	if len(query.Sources) > 0 {
+		sources := make([]string, len(query.Sources))
+		for i, source := range query.Sources {
+			sources[i] = string(source)
+		}
+		filters = append(filters, genericrepo.In(rate.FieldSource, sources...))
+	}
+
+	minRate, maxRate := query.MinRate, query.MaxRate
+	if inverted {
+		minRate, maxRate = reciprocal(query.MaxRate), reciprocal(query.MinRate)
+	}
+	if minRate != nil {
+		filters = append(filters, genericrepo.Gte(rate.FieldValue, *minRate))
+	}
+	if maxRate != nil {
+		filters = append(filters, genericrepo.Lte(rate.FieldValue, *maxRate))
+	}
+
+	return filters
+}
+
+// reciprocal returns 1/v, or nil when v is nil or not positive.
+func reciprocal(v *float64) *float64 {
+	if v == nil || *v <= 0 {
+		return nil
+	}
+	inv := 1 / *v
+	return &inv
+}
+
+// pageOptions returns the pagination options for the query:
+// keyset pagination when a cursor is given, offset pagination otherwise.
+func pageOptions(query ListRatesQuery) []genericrepo.QueryOption {
+	if query.Cursor != nil {
+		return []genericrepo.QueryOption{
+			genericrepo.WithLimit(query.PageSize),
+			genericrepo.WithCursor(*query.Cursor),
+		}
+	}
+	return []genericrepo.QueryOption{
+		genericrepo.WithPagination(query.Page, query.PageSize),
+	}
+}
+
+// nextCursor returns the encoded cursor after the last rate of a full page.
+// A short page is the last one, so no cursor is returned.
+func nextCursor(rates []*rate.Rate, pageSize int) string {
+	if len(rates) == 0 || len(rates) < pageSize {
+		return ""
+	}
+	return cursorOf(rates[len(rates)-1]).Encode()
+}
+
+// cursorOf returns the keyset position of a rate.
+func cursorOf(r *rate.Rate) genericrepo.Cursor {
+	return genericrepo.Cursor{
+		SortKey: timeutil.FormatDate(r.EffectiveDate()),
+		ID:      r.ID(),
+	}
+}
+
+// findAll queries the repository, reading at the query's transaction time if set.
+func (h *ListRatesHandler) findAll(ctx context.Context, asOf *time.Time, opts ...genericrepo.QueryOption) ([]*rate.Rate, error) {
+	if asOf != nil {
+		opts = append(opts, genericrepo.WithAsOf(*asOf))
+	}
+	return h.rateRepo.FindAll(ctx, opts...)
+}
+
+// count counts matching rates, reading at the query's transaction time if set.
+func (h *ListRatesHandler) count(ctx context.Context, asOf *time.Time, opts ...genericrepo.QueryOption) (int64, error) {
+	if asOf != nil {
+		opts = append(opts, genericrepo.WithAsOf(*asOf))
+	}
+	return h.rateRepo.Count(ctx, opts...)
+}
+
 func (h *ListRatesHandler) toDTO(r *rate.Rate) *dto.RateResponse {
 	return &dto.RateResponse{
 		ID:            r.ID(),
@@ -201,89 +291,3 @@ func (h *ListRatesHandler) toDTOInverted(r *rate.Rate, requestedPair currency.Pa
 		UpdatedAt:     r.UpdatedAt(),
 	}
 }
-
-// handleDateRangeQuery handles queries with specific date ranges.
-func (h *ListRatesHandler) handleDateRangeQuery(ctx context.Context, query ListRatesQuery) (*ListRatesResult, error) {
-	// Try direct pair first
-	rates, err := h.rateRepo.FindByDateRange(ctx, query.Pair, *query.StartDate, *query.EndDate)
-	needsInversion := false
-
-	// Count direct results
-	directCount := int64(len(rates))
-
-	// Try inverse pair if no results or very few results
-	if err != nil || len(rates) == 0 || directCount < 10 {
-		h.logger.Debug("trying inverse pair for date range query",
-			"original_pair", query.Pair.String(),
-			"inverse_pair", query.Pair.Inverse().String(),
-			"direct_count", directCount,
-		)
-
-		inversePair := query.Pair.Inverse()
-		inverseRates, inverseErr := h.rateRepo.FindByDateRange(ctx, inversePair, *query.StartDate, *query.EndDate)
-		inverseCount := int64(len(inverseRates))
-
-		// Use inverse data if it has more records
-		if inverseErr == nil && inverseCount > directCount {
-			h.logger.Debug("using inverse pair data for date range",
-				"direct_count", directCount,
-				"inverse_count", inverseCount,
-			)
-			rates = inverseRates
-			needsInversion = true
-		} else if err != nil {
-			// If direct query failed and inverse also failed, return error
-			if inverseErr != nil {
-				h.logger.Error("failed to query date range for both directions",
-					"error", err,
-					"inverse_error", inverseErr,
-					"pair", query.Pair.String(),
-				)
-				return nil, err
-			}
-			// Direct failed but inverse succeeded
-			rates = inverseRates
-			needsInversion = true
-		}
-	}
-
-	// FindByDateRange already returns in descending order (most recent first)
-	// No need to reverse - data is already sorted correctly
-
-	// Apply pagination manually
-	total := int64(len(rates))
-	startIdx := (query.Page - 1) * query.PageSize
-	endIdx := startIdx + query.PageSize
-
-	if startIdx >= len(rates) {
-		rates = []*rate.Rate{}
-	} else {
-		if endIdx > len(rates) {
-			endIdx = len(rates)
-		}
-		rates = rates[startIdx:endIdx]
-	}
-
-	// Convert to DTOs
-	items := make([]*dto.RateResponse, 0, len(rates))
-	for _, r := range rates {
-		if needsInversion {
-			items = append(items, h.toDTOInverted(r, query.Pair))
-		} else {
-			items = append(items, h.toDTO(r))
-		}
-	}
-
-	// Build pagination info
-	result := &ListRatesResult{
-		Items: items,
-		Pagination: genericrepo.Pagination{
-			Page:     query.Page,
-			PageSize: query.PageSize,
-			Total:    total,
-		},
-	}
-	result.Pagination.CalculateTotalPages()
-
-	return result, nil
-}
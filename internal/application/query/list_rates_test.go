@@ -200,3 +200,48 @@ func TestListRatesHandler_EmptyResult(t *testing.T) {
 		t.Errorf("expected total 0, got %d", result.Pagination.Total)
 	}
 }
+
+func TestListRatesHandler_Cursor(t *testing.T) {
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	now := time.Now()
+
+	rate1, _ := rate.NewRate(pair, 20.0, now, rate.SourceUnionPay)
+	rate2, _ := rate.NewRate(pair, 20.5, now.Add(-24*time.Hour), rate.SourceUnionPay)
+	cursor := genericrepo.Cursor{SortKey: "2025-01-31", ID: "previous-last-id"}
+
+	repo := &mockListRatesRepository{
+		findAllFunc: func(ctx context.Context, opts ...genericrepo.QueryOption) ([]*rate.Rate, error) {
+			cfg := genericrepo.BuildQueryConfig(opts...)
+			if cfg.Cursor == nil || *cfg.Cursor != cursor {
+				t.Errorf("expected cursor %+v, got %+v", cursor, cfg.Cursor)
+			}
+			if cfg.Offset != 0 {
+				t.Errorf("expected no offset with cursor, got %d", cfg.Offset)
+			}
+			return []*rate.Rate{rate1, rate2}, nil
+		},
+		countFunc: func(ctx context.Context, opts ...genericrepo.QueryOption) (int64, error) {
+			return 100, nil
+		},
+	}
+
+	handler := query.NewListRatesHandler(repo, logger.NewNoop())
+
+	result, err := handler.Handle(context.Background(), query.ListRatesQuery{
+		Pair:     pair,
+		Page:     1,
+		PageSize: 2,
+		Cursor:   &cursor,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	next, err := genericrepo.DecodeCursor(result.NextCursor)
+	if err != nil {
+		t.Fatalf("expected decodable next cursor, got %v", err)
+	}
+	if next.ID != rate2.ID() {
+		t.Errorf("expected next cursor at last item %s, got %s", rate2.ID(), next.ID)
+	}
+}
@@ -0,0 +1,83 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+)
+
+// ListSuspectRatesQuery represents a query for quarantined rates.
+type ListSuspectRatesQuery struct {
+	Status anomaly.Status // empty for any status
+	Limit  int
+}
+
+// ListSuspectRatesHandler handles listing and reading quarantined rates.
+type ListSuspectRatesHandler struct {
+	suspects anomaly.Repository
+	logger   *slog.Logger
+}
+
+// NewListSuspectRatesHandler creates a new handler.
+func NewListSuspectRatesHandler(suspects anomaly.Repository, logger *slog.Logger) *ListSuspectRatesHandler {
+	return &ListSuspectRatesHandler{
+		suspects: suspects,
+		logger:   logger,
+	}
+}
+
+// Handle returns the most recent suspect rates with the status, newest first.
+func (h *ListSuspectRatesHandler) Handle(ctx context.Context, query ListSuspectRatesQuery) ([]dto.SuspectRateResponse, error) {
+	suspects, err := h.suspects.List(ctx, query.Status, query.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list suspect rates: %w", err)
+	}
+
+	responses := make([]dto.SuspectRateResponse, len(suspects))
+	for i, s := range suspects {
+		responses[i] = SuspectRateResponse(s)
+	}
+	return responses, nil
+}
+
+// Get returns a suspect rate.
+func (h *ListSuspectRatesHandler) Get(ctx context.Context, id string) (dto.SuspectRateResponse, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return dto.SuspectRateResponse{}, anomaly.ErrSuspectNotFound{ID: id}
+	}
+
+	s, err := h.suspects.Find(ctx, id)
+	if err != nil {
+		return dto.SuspectRateResponse{}, err
+	}
+	return SuspectRateResponse(s), nil
+}
+
+// SuspectRateResponse converts a suspect rate to its response.
+func SuspectRateResponse(s *anomaly.Suspect) dto.SuspectRateResponse {
+	findings := make([]dto.AnomalyResponse, len(s.Findings))
+	for i, f := range s.Findings {
+		findings[i] = dto.AnomalyResponse{Kind: string(f.Kind), Detail: f.Detail}
+	}
+
+	return dto.SuspectRateResponse{
+		ID:            s.ID,
+		Pair:          s.Pair.String(),
+		Rate:          s.Value,
+		EffectiveDate: s.EffectiveDate,
+		Source:        string(s.Source),
+		Previous:      s.Previous,
+		Findings:      findings,
+		Status:        string(s.Status),
+		CreatedAt:     s.CreatedAt,
+		ReviewedAt:    s.ReviewedAt,
+		ReviewedBy:    s.ReviewedBy,
+		Note:          s.Note,
+		RateID:        s.RateID,
+	}
+}
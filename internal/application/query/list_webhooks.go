@@ -0,0 +1,138 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+)
+
+// ListWebhooksHandler handles listing and reading webhook subscriptions.
+type ListWebhooksHandler struct {
+	webhooks webhook.Repository
+	logger   *slog.Logger
+}
+
+// NewListWebhooksHandler creates a new handler.
+func NewListWebhooksHandler(webhooks webhook.Repository, logger *slog.Logger) *ListWebhooksHandler {
+	return &ListWebhooksHandler{
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// Handle returns all subscriptions, oldest first.
+func (h *ListWebhooksHandler) Handle(ctx context.Context) ([]dto.WebhookResponse, error) {
+	subscriptions, err := h.webhooks.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+
+	responses := make([]dto.WebhookResponse, len(subscriptions))
+	for i, s := range subscriptions {
+		responses[i] = WebhookResponse(s, false)
+	}
+	return responses, nil
+}
+
+// Get returns a subscription.
+func (h *ListWebhooksHandler) Get(ctx context.Context, id string) (dto.WebhookResponse, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return dto.WebhookResponse{}, webhook.ErrSubscriptionNotFound{ID: id}
+	}
+
+	s, err := h.webhooks.FindSubscription(ctx, id)
+	if err != nil {
+		return dto.WebhookResponse{}, err
+	}
+	return WebhookResponse(s, false), nil
+}
+
+// ListWebhookDeliveriesQuery represents a query for a webhook's delivery log.
+type ListWebhookDeliveriesQuery struct {
+	SubscriptionID string
+	Limit          int
+}
+
+// ListWebhookDeliveriesHandler handles reading the delivery log of a webhook.
+type ListWebhookDeliveriesHandler struct {
+	webhooks webhook.Repository
+	logger   *slog.Logger
+}
+
+// NewListWebhookDeliveriesHandler creates a new handler.
+func NewListWebhookDeliveriesHandler(webhooks webhook.Repository, logger *slog.Logger) *ListWebhookDeliveriesHandler {
+	return &ListWebhookDeliveriesHandler{
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// Handle returns the most recent deliveries of the subscription, newest first.
+func (h *ListWebhookDeliveriesHandler) Handle(ctx context.Context, query ListWebhookDeliveriesQuery) ([]dto.WebhookDeliveryResponse, error) {
+	if _, err := uuid.Parse(query.SubscriptionID); err != nil {
+		return nil, webhook.ErrSubscriptionNotFound{ID: query.SubscriptionID}
+	}
+	if _, err := h.webhooks.FindSubscription(ctx, query.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := h.webhooks.ListDeliveries(ctx, query.SubscriptionID, query.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+
+	responses := make([]dto.WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		responses[i] = WebhookDeliveryResponse(d)
+	}
+	return responses, nil
+}
+
+// WebhookResponse converts a subscription to its response, including the
+// signing secret only if withSecret is set.
+func WebhookResponse(s *webhook.Subscription, withSecret bool) dto.WebhookResponse {
+	resp := dto.WebhookResponse{
+		ID:          s.ID,
+		URL:         s.URL,
+		Pair:        s.Pair.String(),
+		Condition:   string(s.Condition.Kind),
+		Description: s.Description,
+		Active:      s.Active,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+	if s.Condition.Kind != webhook.ConditionNewRate {
+		threshold := s.Condition.Threshold
+		resp.Threshold = &threshold
+	}
+	if withSecret {
+		resp.Secret = s.Secret
+	}
+	return resp
+}
+
+// WebhookDeliveryResponse converts a delivery to its response.
+func WebhookDeliveryResponse(d *webhook.Delivery) dto.WebhookDeliveryResponse {
+	resp := dto.WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		LastAttemptAt:  d.LastAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		Payload:        d.Payload,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == webhook.DeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	return resp
+}
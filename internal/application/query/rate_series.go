@@ -0,0 +1,128 @@
+package query
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/pkg/genericrepo"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// seriesWindow selects the rates of a pair between two effective dates.
+type seriesWindow struct {
+	Pair  currency.Pair
+	Start *time.Time // optional earliest effective date
+	End   *time.Time // optional latest effective date
+	AsOf  *time.Time // optional transaction time to read the rates at
+}
+
+// observation is a rate of a series in the requested direction.
+type observation struct {
+	Date  time.Time
+	Value float64
+}
+
+// conditions returns the filters selecting the window's rates stored as pair.
+func (w seriesWindow) conditions(pair currency.Pair) genericrepo.QueryOption {
+	filters := []genericrepo.Filter{
+		genericrepo.Eq(rate.FieldBaseCurrency, pair.Base().String()),
+		genericrepo.Eq(rate.FieldQuoteCurrency, pair.Quote().String()),
+	}
+	if w.Start != nil {
+		filters = append(filters, genericrepo.Gte(rate.FieldEffectiveDate, timeutil.FormatDate(*w.Start)))
+	}
+	if w.End != nil {
+		filters = append(filters, genericrepo.Lte(rate.FieldEffectiveDate, timeutil.FormatDate(*w.End)))
+	}
+	return genericrepo.Where(filters...)
+}
+
+// options returns the query options of the window for rates stored as pair.
+func (w seriesWindow) options(pair currency.Pair, opts ...genericrepo.QueryOption) []genericrepo.QueryOption {
+	opts = append(opts, w.conditions(pair))
+	if w.AsOf != nil {
+		opts = append(opts, genericrepo.WithAsOf(*w.AsOf))
+	}
+	return opts
+}
+
+// storedDirection reports whether the window's rates are read from the inverse
+// pair, by the rule of preferInverse applied to the rates in the window.
+func storedDirection(ctx context.Context, repo rate.Repository, w seriesWindow) (bool, error) {
+	direct, err := repo.Count(ctx, w.options(w.Pair)...)
+	if err != nil {
+		return false, err
+	}
+	inverse, err := repo.Count(ctx, w.options(w.Pair.Inverse())...)
+	if err != nil {
+		return false, err
+	}
+	return preferInverse(direct, inverse), nil
+}
+
+// observations streams the rates of the window in the requested direction,
+// oldest first, without loading them all at once.
+func observations(ctx context.Context, repo rate.Repository, w seriesWindow, inverted bool) iter.Seq2[observation, error] {
+	stored := w.Pair
+	if inverted {
+		stored = w.Pair.Inverse()
+	}
+
+	return func(yield func(observation, error) bool) {
+		for r, err := range repo.StreamWithError(ctx, w.options(stored, genericrepo.WithOrderBy("effective_date ASC"))...) {
+			if err != nil {
+				yield(observation{}, err)
+				return
+			}
+
+			value := r.Value()
+			if inverted {
+				value = r.Pair().ConvertRate(value)
+			}
+			if !yield(observation{Date: r.EffectiveDate(), Value: value}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// observationsBefore returns up to n rates of the window's pair before its
+// start in the requested direction, oldest first. Callers use them to seed
+// fills and indicators at the start of the window.
+func observationsBefore(ctx context.Context, repo rate.Repository, w seriesWindow, inverted bool, n int) ([]observation, error) {
+	stored := w.Pair
+	if inverted {
+		stored = w.Pair.Inverse()
+	}
+
+	opts := []genericrepo.QueryOption{
+		genericrepo.Where(
+			genericrepo.Eq(rate.FieldBaseCurrency, stored.Base().String()),
+			genericrepo.Eq(rate.FieldQuoteCurrency, stored.Quote().String()),
+			genericrepo.Lt(rate.FieldEffectiveDate, timeutil.FormatDate(*w.Start)),
+		),
+		genericrepo.WithOrderBy("effective_date DESC, id DESC"),
+		genericrepo.WithLimit(n),
+	}
+	if w.AsOf != nil {
+		opts = append(opts, genericrepo.WithAsOf(*w.AsOf))
+	}
+
+	rates, err := repo.FindAll(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]observation, len(rates))
+	for i, r := range rates {
+		value := r.Value()
+		if inverted {
+			value = r.Pair().ConvertRate(value)
+		}
+		result[len(rates)-1-i] = observation{Date: r.EffectiveDate(), Value: value}
+	}
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// day returns the given day of January 2024, where most series fixtures start.
+func day(d int) time.Time {
+	return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+// seedRates stores values of pair on consecutive days from first. A zero
+// value leaves its day without a rate.
+func seedRates(t *testing.T, repo rate.Repository, pair currency.Pair, first time.Time, values ...float64) {
+	t.Helper()
+	for i, value := range values {
+		if value == 0 {
+			continue
+		}
+		seedRate(t, repo, pair, first.AddDate(0, 0, i), value)
+	}
+}
+
+// seedRate stores value of pair on date.
+func seedRate(t *testing.T, repo rate.Repository, pair currency.Pair, date time.Time, value float64) {
+	t.Helper()
+	r, err := rate.NewRate(pair, value, date, rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	if err := repo.Create(context.Background(), r); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+}
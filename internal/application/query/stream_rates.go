@@ -0,0 +1,129 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// StreamRatesQuery represents a query following newly stored rates.
+type StreamRatesQuery struct {
+	Pairs       []currency.Pair // pairs to follow in either direction; all pairs when empty
+	LastEventID string          // resume after this event; empty to only follow new events
+}
+
+// StreamRatesHandler handles following newly stored rates.
+type StreamRatesHandler struct {
+	events rate.EventLog
+	logger *slog.Logger
+}
+
+// NewStreamRatesHandler creates a new handler.
+func NewStreamRatesHandler(events rate.EventLog, logger *slog.Logger) *StreamRatesHandler {
+	return &StreamRatesHandler{
+		events: events,
+		logger: logger,
+	}
+}
+
+// Handle executes the query. The returned channel delivers the rates of the
+// requested pairs, in the requested direction, until ctx is done.
+//
+// When resuming, the retained events after LastEventID are delivered first.
+// The subscription starts before they are read, so no event published in
+// between is lost; events delivered by both are only delivered once.
+func (h *StreamRatesHandler) Handle(ctx context.Context, query StreamRatesQuery) (<-chan dto.RateEventResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	live, err := h.events.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	var missed []rate.Event
+	if query.LastEventID != "" {
+		if missed, err = h.events.After(ctx, query.LastEventID); err != nil {
+			cancel()
+			return nil, err
+		}
+		h.logger.DebugContext(ctx, "resuming rate stream", "last_event_id", query.LastEventID, "missed", len(missed))
+	}
+
+	out := make(chan dto.RateEventResponse, 16)
+	go func() {
+		defer cancel()
+		defer close(out)
+
+		send := func(event rate.Event) bool {
+			for _, response := range matchEvent(event, query.Pairs) {
+				select {
+				case out <- response:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		replayed := make(map[string]bool, len(missed))
+		for _, event := range missed {
+			replayed[event.ID] = true
+			if !send(event) {
+				return
+			}
+		}
+		for event := range live {
+			if replayed[event.ID] {
+				continue
+			}
+			if !send(event) {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// matchEvent returns the event as a response for each requested pair it is a
+// rate of, inverting it for pairs requested in the other direction.
+func matchEvent(event rate.Event, pairs []currency.Pair) []dto.RateEventResponse {
+	stored, err := currency.ParsePair(event.Pair)
+	if err != nil {
+		return nil
+	}
+	if len(pairs) == 0 {
+		return []dto.RateEventResponse{eventResponse(event, stored, event.Value)}
+	}
+
+	var responses []dto.RateEventResponse
+	for _, pair := range pairs {
+		switch pair {
+		case stored:
+			responses = append(responses, eventResponse(event, pair, event.Value))
+		case stored.Inverse():
+			responses = append(responses, eventResponse(event, pair, stored.ConvertRate(event.Value)))
+		}
+	}
+	return responses
+}
+
+func eventResponse(event rate.Event, pair currency.Pair, value float64) dto.RateEventResponse {
+	return dto.RateEventResponse{
+		ID: event.ID,
+		Rate: &dto.RateResponse{
+			ID:            event.RateID,
+			Pair:          pair.String(),
+			BaseCurrency:  pair.Base().String(),
+			QuoteCurrency: pair.Quote().String(),
+			Rate:          value,
+			EffectiveDate: event.EffectiveDate,
+			Source:        string(event.Source),
+			CreatedAt:     event.StoredAt,
+			UpdatedAt:     event.StoredAt,
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+)
+
+// mockEventLog replays missed events and then delivers live ones.
+type mockEventLog struct {
+	rate.EventLog
+	missed []rate.Event
+	live   []rate.Event
+}
+
+func (m *mockEventLog) After(ctx context.Context, id string) ([]rate.Event, error) {
+	return m.missed, nil
+}
+
+func (m *mockEventLog) Subscribe(ctx context.Context) (<-chan rate.Event, error) {
+	ch := make(chan rate.Event, len(m.live))
+	for _, event := range m.live {
+		ch <- event
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestStreamRatesHandler_Resume(t *testing.T) {
+	// Event 3 was published while the subscription was starting, so both the
+	// replay and the subscription contain it
+	log := &mockEventLog{
+		missed: []rate.Event{
+			{ID: "2", Pair: "JPY/CNY", Value: 0.05},
+			{ID: "3", Pair: "CNY/JPY", Value: 21},
+		},
+		live: []rate.Event{
+			{ID: "3", Pair: "CNY/JPY", Value: 21},
+			{ID: "4", Pair: "USD/JPY", Value: 150},
+			{ID: "5", Pair: "CNY/JPY", Value: 22},
+		},
+	}
+	handler := query.NewStreamRatesHandler(log, logger.NewNoop())
+
+	events, err := handler.Handle(context.Background(), query.StreamRatesQuery{
+		Pairs:       []currency.Pair{currency.MustNewPair(currency.CNY, currency.JPY)},
+		LastEventID: "1",
+	})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	var got []string
+	var rates []float64
+	timeout := time.After(time.Second)
+	for len(got) < 3 {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("stream ended after %v", got)
+			}
+			got = append(got, event.ID)
+			rates = append(rates, event.Rate.Rate)
+			if event.Rate.Pair != "CNY/JPY" {
+				t.Errorf("event %s pair = %s, want CNY/JPY", event.ID, event.Rate.Pair)
+			}
+		case <-timeout:
+			t.Fatalf("received %v, want 3 events", got)
+		}
+	}
+
+	if got[0] != "2" || got[1] != "3" || got[2] != "5" {
+		t.Errorf("event IDs = %v, want [2 3 5]", got)
+	}
+	if rates[0] != 20 {
+		t.Errorf("inverse rate = %v, want 20", rates[0])
+	}
+	if event, ok := <-events; ok {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
@@ -0,0 +1,37 @@
+package query
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/tracing"
+)
+
+// startSpan starts the span of a query about pair.
+func startSpan(ctx context.Context, name string, pair currency.Pair, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("rate.pair", pair.String()))
+	return tracing.Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, marking it failed when err is not nil.
+// A rate that does not exist is an expected outcome rather than a failure.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.As(err, new(rate.ErrRateNotFound)) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// inverseFallback records on the span in ctx that the inverse pair was queried.
+func inverseFallback(ctx context.Context, inverse currency.Pair) {
+	trace.SpanFromContext(ctx).AddEvent("inverse pair fallback",
+		trace.WithAttributes(attribute.String("rate.inverse_pair", inverse.String())),
+	)
+}
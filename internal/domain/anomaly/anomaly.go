@@ -0,0 +1,115 @@
+// Package anomaly detects implausible rates from providers, such as inverted
+// rates or rates with a misplaced decimal point, and holds them for review
+// before they are published.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+)
+
+// Kind is the kind of anomaly a rate shows.
+type Kind string
+
+const (
+	KindZScore     Kind = "zscore"     // far from the mean of the recent rates
+	KindJump       Kind = "jump"       // moved too much from the previous rate
+	KindReciprocal Kind = "reciprocal" // close to the inverse of the previous rate
+)
+
+// Finding is an anomaly of a rate, with a description for reviewers.
+type Finding struct {
+	Kind   Kind   `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// Policy sets the limits beyond which a rate is suspect. A zero limit
+// disables its check.
+type Policy struct {
+	Lookback            int                       // recent rates the new one is compared with
+	MinHistory          int                       // recent rates the z-score check needs
+	ZScore              float64                   // distance from the recent mean, in sample standard deviations
+	MaxJumpPercent      float64                   // change from the previous rate, in percent
+	PairJumpPercent     map[currency.Pair]float64 // MaxJumpPercent of individual pairs, in either direction
+	ReciprocalTolerance float64                   // relative distance from the inverse of the previous rate
+}
+
+// DefaultPolicy catches inverted rates and misplaced decimal points while
+// letting through the moves of volatile days.
+var DefaultPolicy = Policy{
+	Lookback:            30,
+	MinHistory:          10,
+	ZScore:              8,
+	MaxJumpPercent:      10,
+	ReciprocalTolerance: 0.02,
+}
+
+// JumpLimit returns the largest change in percent allowed for pair.
+func (p Policy) JumpLimit(pair currency.Pair) float64 {
+	if limit, ok := p.PairJumpPercent[pair]; ok {
+		return limit
+	}
+	if limit, ok := p.PairJumpPercent[pair.Inverse()]; ok {
+		return limit
+	}
+	return p.MaxJumpPercent
+}
+
+// Check returns the anomalies of a new rate of pair, given the pair's recent
+// rates oldest first. A rate without history has none.
+func (p Policy) Check(pair currency.Pair, value float64, history []float64) []Finding {
+	if len(history) == 0 {
+		return nil
+	}
+	previous := history[len(history)-1]
+
+	var findings []Finding
+	if p.ZScore > 0 && len(history) >= max(p.MinHistory, 2) {
+		mean, stdDev := meanStdDev(history)
+		// Flat histories, such as of pegged currencies, leave it to the jump check
+		if stdDev > 0 {
+			if z := math.Abs(value-mean) / stdDev; z > p.ZScore {
+				findings = append(findings, Finding{
+					Kind:   KindZScore,
+					Detail: fmt.Sprintf("%.1f standard deviations from the mean %.6g of the last %d rates", z, mean, len(history)),
+				})
+			}
+		}
+	}
+
+	if limit := p.JumpLimit(pair); limit > 0 {
+		if change := (value - previous) / previous * 100; math.Abs(change) > limit {
+			findings = append(findings, Finding{
+				Kind:   KindJump,
+				Detail: fmt.Sprintf("changed %+.2f%% from the previous rate %.6g, more than %g%%", change, previous, limit),
+			})
+		}
+	}
+
+	// A rate near its own inverse, close to 1, cannot tell the directions apart
+	if tol := p.ReciprocalTolerance; tol > 0 && math.Abs(value*previous-1) <= tol && math.Abs(value/previous-1) > tol {
+		findings = append(findings, Finding{
+			Kind:   KindReciprocal,
+			Detail: fmt.Sprintf("close to %.6g, the inverse of the previous rate %.6g", 1/previous, previous),
+		})
+	}
+
+	return findings
+}
+
+// meanStdDev returns the mean and sample standard deviation of values.
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
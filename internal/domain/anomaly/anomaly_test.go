@@ -0,0 +1,90 @@
+package anomaly_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	cnyJpy := currency.MustNewPair(currency.CNY, currency.JPY)
+	usdJpy := currency.MustNewPair(currency.USD, currency.JPY)
+
+	// Daily CNY/JPY rates wobbling around 20.5
+	history := []float64{20.40, 20.55, 20.48, 20.62, 20.51, 20.45, 20.58, 20.60, 20.49, 20.53, 20.57, 20.50}
+
+	policy := anomaly.DefaultPolicy
+	policy.PairJumpPercent = map[currency.Pair]float64{currency.MustNewPair(currency.JPY, currency.USD): 2}
+
+	tests := []struct {
+		name    string
+		pair    currency.Pair
+		value   float64
+		history []float64
+		want    []anomaly.Kind
+	}{
+		{"ordinary move", cnyJpy, 20.66, history, nil},
+		{"no history", cnyJpy, 0.0487, nil, nil},
+		{"inverted rate", cnyJpy, 1 / 20.5, history, []anomaly.Kind{anomaly.KindZScore, anomaly.KindJump, anomaly.KindReciprocal}},
+		{"missing decimal", cnyJpy, 2050, history, []anomaly.Kind{anomaly.KindZScore, anomaly.KindJump}},
+		{"outlier within jump limit", cnyJpy, 21.9, history, []anomaly.Kind{anomaly.KindZScore}},
+		{"jump with short history", cnyJpy, 24, []float64{20.5, 20.6}, []anomaly.Kind{anomaly.KindJump}},
+		{"flat history", cnyJpy, 20.6, []float64{20.5, 20.5, 20.5, 20.5, 20.5, 20.5, 20.5, 20.5, 20.5, 20.5}, nil},
+		{"pair limit of inverse", usdJpy, 153, []float64{149}, []anomaly.Kind{anomaly.KindJump}},
+		{"within pair limit", usdJpy, 151, []float64{149}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := policy.Check(tt.pair, tt.value, tt.history)
+			var got []anomaly.Kind
+			for _, f := range findings {
+				if f.Detail == "" {
+					t.Errorf("finding %s without detail", f.Kind)
+				}
+				got = append(got, f.Kind)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuspect_Review(t *testing.T) {
+	now := time.Now()
+	r, err := rate.NewRate(currency.MustNewPair(currency.CNY, currency.JPY), 0.0487, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	findings := []anomaly.Finding{{Kind: anomaly.KindReciprocal, Detail: "inverted"}}
+
+	s := anomaly.NewSuspect(r, findings, nil, now)
+	if s.Status != anomaly.StatusPending || s.ID == "" {
+		t.Fatalf("NewSuspect() = %+v, want a pending suspect with an ID", s)
+	}
+	held, err := s.Rate()
+	if err != nil || held.Value() != r.Value() || !held.EffectiveDate().Equal(r.EffectiveDate()) {
+		t.Fatalf("Rate() = %v, %v, want the quarantined rate", held, err)
+	}
+
+	if err := s.Reject("ops", "inverted by the provider", now); err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if s.Status != anomaly.StatusRejected || s.ReviewedBy != "ops" || s.ReviewedAt == nil {
+		t.Errorf("Reject() left %+v", s)
+	}
+
+	var reviewed anomaly.ErrAlreadyReviewed
+	if err := s.Approve("rate-1", "ops", "", now); !errors.As(err, &reviewed) {
+		t.Errorf("Approve() after Reject() error = %v, want ErrAlreadyReviewed", err)
+	}
+	if s.RateID != "" {
+		t.Errorf("Approve() after Reject() set RateID %q", s.RateID)
+	}
+}
@@ -0,0 +1,22 @@
+package anomaly
+
+import "fmt"
+
+// ErrSuspectNotFound indicates that a suspect rate was not found.
+type ErrSuspectNotFound struct {
+	ID string
+}
+
+func (e ErrSuspectNotFound) Error() string {
+	return fmt.Sprintf("suspect rate not found: %s", e.ID)
+}
+
+// ErrAlreadyReviewed indicates that a suspect rate was approved or rejected before.
+type ErrAlreadyReviewed struct {
+	ID     string
+	Status Status
+}
+
+func (e ErrAlreadyReviewed) Error() string {
+	return fmt.Sprintf("suspect rate %s was already %s", e.ID, e.Status)
+}
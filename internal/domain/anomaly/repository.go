@@ -0,0 +1,31 @@
+package anomaly
+
+import (
+	"context"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// Repository stores suspect rates awaiting or after review.
+type Repository interface {
+	// Create stores a new suspect.
+	Create(ctx context.Context, s *Suspect) error
+
+	// Find returns a suspect.
+	// Returns ErrSuspectNotFound if it does not exist.
+	Find(ctx context.Context, id string) (*Suspect, error)
+
+	// FindByRate returns the suspects of the rate of pair on date from
+	// source, newest first.
+	FindByRate(ctx context.Context, pair currency.Pair, date time.Time, source rate.Source) ([]*Suspect, error)
+
+	// List returns up to limit suspects with status, or with any status if it
+	// is empty, newest first.
+	List(ctx context.Context, status Status, limit int) ([]*Suspect, error)
+
+	// Update stores the review of a suspect.
+	// Returns ErrSuspectNotFound if it does not exist.
+	Update(ctx context.Context, s *Suspect) error
+}
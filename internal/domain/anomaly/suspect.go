@@ -0,0 +1,96 @@
+package anomaly
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// Status is the review state of a suspect rate.
+type Status string
+
+const (
+	StatusPending  Status = "pending"  // held until reviewed
+	StatusApproved Status = "approved" // stored and published as a rate
+	StatusRejected Status = "rejected" // discarded
+)
+
+// valuePrecision is the precision at which rate values are stored.
+const valuePrecision = 1e-10
+
+// Statuses lists the known statuses.
+var Statuses = []Status{StatusPending, StatusApproved, StatusRejected}
+
+// Suspect is a rate from a provider quarantined because of its anomalies.
+// It is neither stored as a rate nor published until a reviewer approves it.
+type Suspect struct {
+	ID            string
+	Pair          currency.Pair
+	Value         float64
+	EffectiveDate time.Time
+	Source        rate.Source
+	Previous      *float64 // the pair's rate before, which the value was compared with
+	Findings      []Finding
+	Status        Status
+	CreatedAt     time.Time
+	ReviewedAt    *time.Time
+	ReviewedBy    string // who approved or rejected it, e.g. an API key name
+	Note          string // the reviewer's reason
+	RateID        string // the rate stored on approval
+}
+
+// NewSuspect quarantines r, which is not stored yet, for findings.
+func NewSuspect(r *rate.Rate, findings []Finding, previous *float64, now time.Time) *Suspect {
+	return &Suspect{
+		ID:            uuid.New().String(),
+		Pair:          r.Pair(),
+		Value:         r.Value(),
+		EffectiveDate: r.EffectiveDate(),
+		Source:        r.Source(),
+		Previous:      previous,
+		Findings:      findings,
+		Status:        StatusPending,
+		CreatedAt:     now,
+	}
+}
+
+// Rate returns the rate the suspect holds back.
+func (s *Suspect) Rate() (*rate.Rate, error) {
+	return rate.NewRate(s.Pair, s.Value, s.EffectiveDate, s.Source)
+}
+
+// Holds reports whether the suspect holds value, at the precision rates are stored at.
+func (s *Suspect) Holds(value float64) bool {
+	return math.Abs(s.Value-value) < valuePrecision
+}
+
+// Approve marks the suspect as released as the stored rate rateID.
+// Returns ErrAlreadyReviewed unless it is pending.
+func (s *Suspect) Approve(rateID, reviewer, note string, now time.Time) error {
+	if err := s.review(StatusApproved, reviewer, note, now); err != nil {
+		return err
+	}
+	s.RateID = rateID
+	return nil
+}
+
+// Reject marks the suspect as discarded.
+// Returns ErrAlreadyReviewed unless it is pending.
+func (s *Suspect) Reject(reviewer, note string, now time.Time) error {
+	return s.review(StatusRejected, reviewer, note, now)
+}
+
+func (s *Suspect) review(status Status, reviewer, note string, now time.Time) error {
+	if s.Status != StatusPending {
+		return ErrAlreadyReviewed{ID: s.ID, Status: s.Status}
+	}
+	s.Status = status
+	s.ReviewedAt = &now
+	s.ReviewedBy = reviewer
+	s.Note = note
+	return nil
+}
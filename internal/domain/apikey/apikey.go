@@ -0,0 +1,115 @@
+// Package apikey provides the API key aggregate used to authenticate API clients.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope is a permission granted to an API key.
+type Scope string
+
+const (
+	ScopeRatesRead      Scope = "rates:read"
+	ScopeRatesWrite     Scope = "rates:write"
+	ScopeWebhooksManage Scope = "webhooks:manage"
+	ScopeAdmin          Scope = "admin" // implies every other scope
+)
+
+// Scopes lists the known scopes.
+var Scopes = []Scope{ScopeRatesRead, ScopeRatesWrite, ScopeWebhooksManage, ScopeAdmin}
+
+// ParseScope parses a scope name.
+func ParseScope(s string) (Scope, error) {
+	scope := Scope(strings.TrimSpace(s))
+	if !slices.Contains(Scopes, scope) {
+		return "", ErrInvalidKey{reason: "unknown scope " + s}
+	}
+	return scope, nil
+}
+
+const (
+	// secretPrefix marks RateFlow API keys, so that leaked keys are easy to recognise.
+	secretPrefix = "rfk_"
+	// displayLength is the number of leading secret characters stored in clear
+	// to tell keys apart, e.g. in `worker apikeys list`.
+	displayLength = 12
+)
+
+// Key is an API key issued to a client.
+// Only the SHA-256 hash of the secret is kept; the secret itself is shown once
+// when the key is created.
+type Key struct {
+	ID         string
+	Name       string // who the key was issued to, e.g. a partner team
+	Prefix     string // leading characters of the secret
+	Hash       string // hex-encoded SHA-256 of the secret
+	Scopes     []Scope
+	DailyQuota int64 // requests allowed per UTC day; 0 means unlimited
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// New creates a key and returns it together with its secret.
+func New(name string, scopes []Scope, dailyQuota int64, now time.Time) (*Key, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", ErrInvalidKey{reason: "name is required"}
+	}
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidKey{reason: "at least one scope is required"}
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return nil, "", ErrInvalidKey{reason: "unknown scope " + string(scope)}
+		}
+	}
+	if dailyQuota < 0 {
+		return nil, "", ErrInvalidKey{reason: "daily quota must not be negative"}
+	}
+
+	// 128 random bits, base32-encoded
+	secret := secretPrefix + rand.Text()
+
+	return &Key{
+		ID:         uuid.New().String(),
+		Name:       name,
+		Prefix:     secret[:displayLength],
+		Hash:       Hash(secret),
+		Scopes:     slices.Compact(slices.Sorted(slices.Values(scopes))),
+		DailyQuota: dailyQuota,
+		CreatedAt:  now,
+	}, secret, nil
+}
+
+// Hash returns the hash under which the key with the given secret is stored.
+// The secrets are random, so a fast unsalted hash is sufficient and lets keys
+// be looked up by hash.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Active reports whether the key has not been revoked.
+func (k *Key) Active() bool {
+	return k.RevokedAt == nil
+}
+
+// Allows reports whether the key grants scope.
+func (k *Key) Allows(scope Scope) bool {
+	return slices.Contains(k.Scopes, ScopeAdmin) || slices.Contains(k.Scopes, scope)
+}
+
+// Usage counts the requests made with a key to a route on a UTC day.
+type Usage struct {
+	KeyID string
+	Day   time.Time
+	Route string
+	Count int64
+}
@@ -0,0 +1,75 @@
+package apikey_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/apikey"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyName string
+		scopes  []apikey.Scope
+		quota   int64
+		wantErr bool
+	}{
+		{"valid key", "partner", []apikey.Scope{apikey.ScopeRatesRead}, 1000, false},
+		{"unlimited quota", "partner", []apikey.Scope{apikey.ScopeAdmin}, 0, false},
+		{"missing name", " ", []apikey.Scope{apikey.ScopeRatesRead}, 0, true},
+		{"no scopes", "partner", nil, 0, true},
+		{"unknown scope", "partner", []apikey.Scope{"rates:delete"}, 0, true},
+		{"negative quota", "partner", []apikey.Scope{apikey.ScopeRatesRead}, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, secret, err := apikey.New(tt.keyName, tt.scopes, tt.quota, time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if key.Hash != apikey.Hash(secret) || strings.Contains(key.Hash, secret) {
+				t.Error("key does not store the hash of its secret")
+			}
+			if !strings.HasPrefix(secret, key.Prefix) {
+				t.Errorf("Prefix = %q, want a prefix of the secret", key.Prefix)
+			}
+		})
+	}
+
+	_, first, _ := apikey.New("partner", []apikey.Scope{apikey.ScopeRatesRead}, 0, time.Now())
+	_, second, _ := apikey.New("partner", []apikey.Scope{apikey.ScopeRatesRead}, 0, time.Now())
+	if first == second {
+		t.Error("two keys share a secret")
+	}
+}
+
+func TestKey_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []apikey.Scope
+		scope  apikey.Scope
+		want   bool
+	}{
+		{"granted scope", []apikey.Scope{apikey.ScopeRatesRead}, apikey.ScopeRatesRead, true},
+		{"other scope", []apikey.Scope{apikey.ScopeRatesRead}, apikey.ScopeRatesWrite, false},
+		{"write does not imply read", []apikey.Scope{apikey.ScopeRatesWrite}, apikey.ScopeRatesRead, false},
+		{"admin implies read", []apikey.Scope{apikey.ScopeAdmin}, apikey.ScopeRatesRead, true},
+		{"admin implies write", []apikey.Scope{apikey.ScopeAdmin}, apikey.ScopeRatesWrite, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &apikey.Key{Scopes: tt.scopes}
+			if got := key.Allows(tt.scope); got != tt.want {
+				t.Errorf("Allows(%s) = %v, want %v", tt.scope, got, tt.want)
+			}
+		})
+	}
+}
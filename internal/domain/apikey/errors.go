@@ -0,0 +1,51 @@
+package apikey
+
+import "fmt"
+
+// ErrInvalidKey represents a domain validation error for API keys.
+type ErrInvalidKey struct {
+	reason string
+}
+
+func (e ErrInvalidKey) Error() string {
+	return fmt.Sprintf("invalid api key: %s", e.reason)
+}
+
+// ErrKeyNotFound indicates that an API key was not found.
+type ErrKeyNotFound struct {
+	ID string
+}
+
+func (e ErrKeyNotFound) Error() string {
+	if e.ID != "" {
+		return fmt.Sprintf("api key not found: %s", e.ID)
+	}
+	return "api key not found"
+}
+
+// ErrKeyRevoked indicates that a revoked API key was used.
+type ErrKeyRevoked struct {
+	ID string
+}
+
+func (e ErrKeyRevoked) Error() string {
+	return fmt.Sprintf("api key revoked: %s", e.ID)
+}
+
+// ErrScopeDenied indicates that an API key lacks the scope an operation requires.
+type ErrScopeDenied struct {
+	Scope Scope
+}
+
+func (e ErrScopeDenied) Error() string {
+	return fmt.Sprintf("api key lacks scope %s", e.Scope)
+}
+
+// ErrQuotaExceeded indicates that an API key has used up its daily quota.
+type ErrQuotaExceeded struct {
+	Quota int64
+}
+
+func (e ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("daily quota of %d requests exceeded", e.Quota)
+}
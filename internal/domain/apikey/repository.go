@@ -0,0 +1,32 @@
+package apikey
+
+import (
+	"context"
+	"time"
+)
+
+// Repository stores API keys and their usage.
+type Repository interface {
+	// Create stores a new key.
+	Create(ctx context.Context, key *Key) error
+
+	// FindByHash returns the key whose secret has the given hash.
+	// Returns ErrKeyNotFound if there is none.
+	FindByHash(ctx context.Context, hash string) (*Key, error)
+
+	// List returns all keys, including revoked ones, oldest first.
+	List(ctx context.Context) ([]*Key, error)
+
+	// Revoke marks the key as revoked at the given time.
+	// Returns ErrKeyNotFound if the key does not exist.
+	Revoke(ctx context.Context, id string, at time.Time) error
+
+	// RecordUsage counts a request made with the key to route at the given time
+	// and returns the number of requests made with the key on that UTC day,
+	// including this one. With a quota, a request beyond quota requests on the
+	// day is not counted and fails with ErrQuotaExceeded.
+	RecordUsage(ctx context.Context, id, route string, at time.Time, quota int64) (int64, error)
+
+	// UsageOn returns the usage of the key per route on the UTC day of day.
+	UsageOn(ctx context.Context, id string, day time.Time) ([]Usage, error)
+}
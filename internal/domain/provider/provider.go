@@ -3,6 +3,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/tyokyo320/rateflow/internal/domain/currency"
@@ -30,9 +31,22 @@ type Provider interface {
 	FetchMulti(ctx context.Context, pairs []currency.Pair, date time.Time) (map[string]float64, error)
 }
 
+// ErrorKind classifies provider errors, e.g. for metrics and alerting.
+type ErrorKind string
+
+// Provider error kinds.
+const (
+	ErrorKindUnknown     ErrorKind = "unknown"
+	ErrorKindUnavailable ErrorKind = "unavailable" // the provider could not be reached or failed
+	ErrorKindNoData      ErrorKind = "no_data"     // the provider has no data for the requested date or pair
+	ErrorKindInvalid     ErrorKind = "invalid"     // the provider responded with data that could not be used
+	ErrorKindUnsupported ErrorKind = "unsupported" // the operation is not supported by the provider
+)
+
 // ProviderError represents an error from a provider.
 type ProviderError struct {
 	ProviderName string
+	Kind         ErrorKind
 	Message      string
 	Err          error
 }
@@ -48,11 +62,27 @@ func (e *ProviderError) Unwrap() error {
 	return e.Err
 }
 
-// NewProviderError creates a new ProviderError.
+// NewProviderError creates a new ProviderError of unknown kind.
 func NewProviderError(providerName, message string, err error) *ProviderError {
+	return NewProviderErrorKind(providerName, ErrorKindUnknown, message, err)
+}
+
+// NewProviderErrorKind creates a new ProviderError of the given kind.
+func NewProviderErrorKind(providerName string, kind ErrorKind, message string, err error) *ProviderError {
 	return &ProviderError{
 		ProviderName: providerName,
+		Kind:         kind,
 		Message:      message,
 		Err:          err,
 	}
 }
+
+// KindOf returns the kind of a provider error in err's chain.
+// Errors that are not provider errors are of unknown kind.
+func KindOf(err error) ErrorKind {
+	var perr *ProviderError
+	if errors.As(err, &perr) && perr.Kind != "" {
+		return perr.Kind
+	}
+	return ErrorKindUnknown
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Status records the outcome of the most recent fetches from a provider.
+type Status struct {
+	Provider            string
+	LastAttemptAt       time.Time
+	LastSuccessAt       *time.Time // nil until a fetch has succeeded
+	LastError           string     // empty when the last attempt succeeded
+	ConsecutiveFailures int
+}
+
+// Healthy reports whether the last attempt succeeded.
+func (s *Status) Healthy() bool {
+	return s.ConsecutiveFailures == 0
+}
+
+// StatusRepository stores the fetch outcomes of providers.
+// The worker records them and the API reads them to report provider reachability.
+type StatusRepository interface {
+	// Record stores the outcome of a fetch attempt made at the given time.
+	// A nil fetchErr records a success.
+	Record(ctx context.Context, providerName string, at time.Time, fetchErr error) error
+
+	// FindStatus returns the status of a provider.
+	// Returns ErrStatusNotFound if no fetch has been recorded.
+	FindStatus(ctx context.Context, providerName string) (*Status, error)
+}
+
+// ErrStatusNotFound indicates that no fetch has been recorded for a provider.
+type ErrStatusNotFound struct {
+	Provider string
+}
+
+func (e ErrStatusNotFound) Error() string {
+	return fmt.Sprintf("no fetch recorded for provider %s", e.Provider)
+}
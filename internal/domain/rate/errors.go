@@ -42,3 +42,32 @@ type ErrStaleRate struct {
 func (e ErrStaleRate) Error() string {
 	return fmt.Sprintf("rate is stale: %s old", e.Age)
 }
+
+// ErrInvalidEventID indicates that an event ID does not identify a position in the event log.
+type ErrInvalidEventID struct {
+	ID string
+}
+
+func (e ErrInvalidEventID) Error() string {
+	return fmt.Sprintf("invalid event id: %s", e.ID)
+}
+
+// ErrInvalidInterval indicates an interval that rates cannot be aggregated over.
+type ErrInvalidInterval struct {
+	Interval string
+}
+
+func (e ErrInvalidInterval) Error() string {
+	return fmt.Sprintf("invalid interval: %q", e.Interval)
+}
+
+// ErrInsufficientHistory indicates that a pair has too few rates for a computation.
+type ErrInsufficientHistory struct {
+	Pair string
+	Have int
+	Need int
+}
+
+func (e ErrInsufficientHistory) Error() string {
+	return fmt.Sprintf("insufficient history for %s: %d rates, need at least %d", e.Pair, e.Have, e.Need)
+}
@@ -0,0 +1,47 @@
+package rate
+
+import (
+	"context"
+	"time"
+)
+
+// Event announces that a rate was stored.
+type Event struct {
+	ID            string    `json:"id,omitempty"` // position in the event log, assigned when published
+	RateID        string    `json:"rateId"`
+	Pair          string    `json:"pair"`
+	Value         float64   `json:"value"`
+	EffectiveDate time.Time `json:"effectiveDate"`
+	Source        Source    `json:"source"`
+	StoredAt      time.Time `json:"storedAt"`
+}
+
+// NewStoredEvent creates the event announcing that r was stored.
+func NewStoredEvent(r *Rate) Event {
+	return Event{
+		RateID:        r.ID(),
+		Pair:          r.Pair().String(),
+		Value:         r.Value(),
+		EffectiveDate: r.EffectiveDate(),
+		Source:        r.Source(),
+		StoredAt:      r.UpdatedAt(),
+	}
+}
+
+// EventLog publishes rate events to subscribers in other processes and retains
+// the most recent ones, so that subscribers can resume after a disconnect.
+type EventLog interface {
+	// Publish appends event to the log and delivers it to the subscribers.
+	Publish(ctx context.Context, event Event) error
+
+	// After returns the retained events published after the event with the
+	// given ID, oldest first. Events older than the retention are lost.
+	After(ctx context.Context, id string) ([]Event, error)
+
+	// Subscribe delivers the events published from now on until ctx is done,
+	// when the channel is closed.
+	Subscribe(ctx context.Context) (<-chan Event, error)
+
+	// Close releases the log's resources.
+	Close() error
+}
@@ -0,0 +1,91 @@
+package rate
+
+import (
+	"strings"
+	"time"
+)
+
+// Interval is the length of the periods rates are aggregated over.
+type Interval string
+
+// Supported intervals. Weeks start on Monday, as in ISO 8601.
+const (
+	IntervalWeek    Interval = "week"
+	IntervalMonth   Interval = "month"
+	IntervalQuarter Interval = "quarter"
+	IntervalYear    Interval = "year"
+)
+
+// Intervals lists the supported intervals.
+var Intervals = []Interval{IntervalWeek, IntervalMonth, IntervalQuarter, IntervalYear}
+
+// ParseInterval parses an interval name, case-insensitively.
+func ParseInterval(s string) (Interval, error) {
+	interval := Interval(strings.ToLower(strings.TrimSpace(s)))
+	for _, supported := range Intervals {
+		if interval == supported {
+			return interval, nil
+		}
+	}
+	return "", ErrInvalidInterval{Interval: s}
+}
+
+// Truncate returns the first day of the period containing date.
+func (i Interval) Truncate(date time.Time) time.Time {
+	year, month, day := date.Date()
+	switch i {
+	case IntervalWeek:
+		offset := (int(date.Weekday()) + 6) % 7 // days since Monday
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, date.Location())
+	case IntervalQuarter:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, date.Location())
+	case IntervalYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, date.Location())
+	default:
+		return time.Date(year, month, 1, 0, 0, 0, 0, date.Location())
+	}
+}
+
+// Last returns the last day of the period starting on start.
+func (i Interval) Last(start time.Time) time.Time {
+	switch i {
+	case IntervalWeek:
+		return start.AddDate(0, 0, 6)
+	case IntervalQuarter:
+		return start.AddDate(0, 3, -1)
+	case IntervalYear:
+		return start.AddDate(1, 0, -1)
+	default:
+		return start.AddDate(0, 1, -1)
+	}
+}
+
+// Period is the aggregate of the rates of a pair within one interval.
+type Period struct {
+	Start          time.Time // first day of the period
+	End            time.Time // last day of the period
+	Open           float64   // first rate of the period
+	High           float64
+	Low            float64
+	Close          float64   // last rate of the period, i.e. its period-end value
+	CloseDate      time.Time // effective date of Close
+	Average        float64
+	InverseAverage float64 // average of the inverse rates, which is not the inverse of Average
+	Count          int
+}
+
+// Inverse returns the period of the inverse pair.
+func (p Period) Inverse() Period {
+	return Period{
+		Start:          p.Start,
+		End:            p.End,
+		Open:           1 / p.Open,
+		High:           1 / p.Low,
+		Low:            1 / p.High,
+		Close:          1 / p.Close,
+		CloseDate:      p.CloseDate,
+		Average:        p.InverseAverage,
+		InverseAverage: p.Average,
+		Count:          p.Count,
+	}
+}
@@ -0,0 +1,51 @@
+package rate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+func TestInterval_Truncate(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+
+	tests := []struct {
+		interval  rate.Interval
+		date      string
+		wantStart string
+		wantLast  string
+	}{
+		{rate.IntervalWeek, "2024-01-03", "2024-01-01", "2024-01-07"},
+		{rate.IntervalWeek, "2024-01-07", "2024-01-01", "2024-01-07"}, // Sunday ends the week
+		{rate.IntervalWeek, "2024-03-01", "2024-02-26", "2024-03-03"},
+		{rate.IntervalMonth, "2024-02-29", "2024-02-01", "2024-02-29"},
+		{rate.IntervalQuarter, "2024-06-30", "2024-04-01", "2024-06-30"},
+		{rate.IntervalQuarter, "2024-12-05", "2024-10-01", "2024-12-31"},
+		{rate.IntervalYear, "2024-07-15", "2024-01-01", "2024-12-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.interval)+" "+tt.date, func(t *testing.T) {
+			start := tt.interval.Truncate(date(tt.date))
+			if got := start.Format(time.DateOnly); got != tt.wantStart {
+				t.Errorf("Truncate() = %s, want %s", got, tt.wantStart)
+			}
+			if got := tt.interval.Last(start).Format(time.DateOnly); got != tt.wantLast {
+				t.Errorf("Last() = %s, want %s", got, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	if got, err := rate.ParseInterval(" Month "); err != nil || got != rate.IntervalMonth {
+		t.Errorf("ParseInterval(Month) = %q, %v; want month", got, err)
+	}
+	if _, err := rate.ParseInterval("day"); err == nil {
+		t.Error("ParseInterval(day) succeeded, want ErrInvalidInterval")
+	}
+}
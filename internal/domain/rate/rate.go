@@ -3,6 +3,7 @@ package rate
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -118,6 +119,16 @@ func (r *Rate) UpdateValue(newValue float64) error {
 	return nil
 }
 
+// ValuePrecision is the smallest difference between rate values that storage
+// preserves; the value columns are decimal(20,10).
+const ValuePrecision = 1e-10
+
+// HasValue reports whether the rate equals value at ValuePrecision, i.e.
+// whether storing value would leave the rate unchanged.
+func (r *Rate) HasValue(value float64) bool {
+	return math.Abs(r.value-value) < ValuePrecision
+}
+
 // IsStale checks if the rate data is considered stale.
 func (r *Rate) IsStale(threshold time.Duration) bool {
 	return time.Since(r.updatedAt) > threshold
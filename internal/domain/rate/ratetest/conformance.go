@@ -0,0 +1,743 @@
+// Package ratetest provides a conformance test suite for rate.Repository
+// implementations, so that every storage backend behaves the same way.
+package ratetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/pkg/genericrepo"
+)
+
+// Factory returns a new, empty repository for a single test.
+type Factory func(t *testing.T) rate.Repository
+
+var (
+	cnyJpy = currency.MustNewPair(currency.CNY, currency.JPY)
+	usdJpy = currency.MustNewPair(currency.USD, currency.JPY)
+	eurUsd = currency.MustNewPair(currency.EUR, currency.USD)
+)
+
+// RunRepositoryTests runs the conformance suite against repositories created by newRepo.
+func RunRepositoryTests(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo rate.Repository)
+	}{
+		{"CreateAndFindByID", testCreateAndFindByID},
+		{"FindByIDNotFound", testFindByIDNotFound},
+		{"CreateUpsertsByKey", testCreateUpsertsByKey},
+		{"CreateSameValueKeepsHistory", testCreateSameValueKeepsHistory},
+		{"Update", testUpdate},
+		{"UpdateNotFound", testUpdateNotFound},
+		{"DeleteAndExists", testDeleteAndExists},
+		{"PairQueries", testPairQueries},
+		{"DeleteOlderThan", testDeleteOlderThan},
+		{"FindAllFilters", testFindAllFilters},
+		{"FindAllInvalidFilter", testFindAllInvalidFilter},
+		{"FindAllOrderingAndPagination", testFindAllOrderingAndPagination},
+		{"FindAllCursor", testFindAllCursor},
+		{"Stream", testStream},
+		{"AsOf", testAsOf},
+		{"Aggregate", testAggregate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func testCreateAndFindByID(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	r := mustCreate(t, repo, cnyJpy, 20.5, day(1), rate.SourceUnionPay)
+
+	got, err := repo.FindByID(ctx, r.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+
+	if got.ID() != r.ID() {
+		t.Errorf("ID = %q, want %q", got.ID(), r.ID())
+	}
+	if !got.Pair().Equal(cnyJpy) {
+		t.Errorf("Pair = %v, want %v", got.Pair(), cnyJpy)
+	}
+	if got.Value() != 20.5 {
+		t.Errorf("Value = %v, want 20.5", got.Value())
+	}
+	if !sameDay(got.EffectiveDate(), day(1)) {
+		t.Errorf("EffectiveDate = %v, want %v", got.EffectiveDate(), day(1))
+	}
+	if got.Source() != rate.SourceUnionPay {
+		t.Errorf("Source = %q, want %q", got.Source(), rate.SourceUnionPay)
+	}
+
+	revisions, err := repo.FindRevisions(ctx, r.ID())
+	if err != nil {
+		t.Fatalf("FindRevisions() error = %v", err)
+	}
+	if len(revisions) != 1 || !revisions[0].IsInitial() || revisions[0].NewValue != 20.5 {
+		t.Errorf("FindRevisions() = %+v, want one initial revision with value 20.5", revisions)
+	}
+}
+
+func testFindByIDNotFound(t *testing.T, repo rate.Repository) {
+	_, err := repo.FindByID(context.Background(), "00000000-0000-0000-0000-000000000000")
+
+	var notFound rate.ErrRateNotFound
+	if !errors.As(err, &notFound) {
+		t.Errorf("FindByID() error = %v, want ErrRateNotFound", err)
+	}
+}
+
+func testCreateUpsertsByKey(t *testing.T, repo rate.Repository) {
+	ctx := rate.WithChangeInfo(context.Background(), rate.ChangeInfo{
+		Actor:   rate.ActorManual,
+		ActorID: "alice",
+		Reason:  "correction",
+	})
+	first := mustCreate(t, repo, cnyJpy, 20.5, day(1), rate.SourceUnionPay)
+
+	second, err := rate.NewRate(cnyJpy, 20.7, day(1), rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	if err := repo.Create(ctx, second); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	count, err := repo.Count(ctx)
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Count() = %d, want 1", count)
+	}
+
+	got, err := repo.FindByID(ctx, first.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Value() != 20.7 {
+		t.Errorf("Value = %v, want 20.7", got.Value())
+	}
+
+	revisions, err := repo.FindRevisions(ctx, first.ID())
+	if err != nil {
+		t.Fatalf("FindRevisions() error = %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("len(FindRevisions()) = %d, want 2", len(revisions))
+	}
+
+	last := revisions[1]
+	if last.OldValue == nil || *last.OldValue != 20.5 || last.NewValue != 20.7 {
+		t.Errorf("revision values = %v -> %v, want 20.5 -> 20.7", last.OldValue, last.NewValue)
+	}
+	if last.Actor != rate.ActorManual || last.ActorID != "alice" || last.Reason != "correction" {
+		t.Errorf("revision change info = %q/%q/%q, want manual/alice/correction", last.Actor, last.ActorID, last.Reason)
+	}
+
+	// A different source is a different rate
+	mustCreate(t, repo, cnyJpy, 20.6, day(1), rate.SourceECB)
+	if count, _ := repo.Count(ctx); count != 2 {
+		t.Errorf("Count() after other source = %d, want 2", count)
+	}
+}
+
+func testCreateSameValueKeepsHistory(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	first := mustCreate(t, repo, cnyJpy, 20.5, day(1), rate.SourceUnionPay)
+	mustCreate(t, repo, cnyJpy, 20.5, day(1), rate.SourceUnionPay)
+
+	revisions, err := repo.FindRevisions(ctx, first.ID())
+	if err != nil {
+		t.Fatalf("FindRevisions() error = %v", err)
+	}
+	if len(revisions) != 1 {
+		t.Errorf("len(FindRevisions()) = %d, want 1", len(revisions))
+	}
+}
+
+func testUpdate(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	r := mustCreate(t, repo, cnyJpy, 20.5, day(1), rate.SourceUnionPay)
+
+	if err := r.UpdateValue(21); err != nil {
+		t.Fatalf("UpdateValue() error = %v", err)
+	}
+	if err := repo.Update(ctx, r); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, r.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Value() != 21 {
+		t.Errorf("Value = %v, want 21", got.Value())
+	}
+
+	revisions, err := repo.FindRevisions(ctx, r.ID())
+	if err != nil {
+		t.Fatalf("FindRevisions() error = %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Errorf("len(FindRevisions()) = %d, want 2", len(revisions))
+	}
+}
+
+func testUpdateNotFound(t *testing.T, repo rate.Repository) {
+	r, err := rate.NewRate(cnyJpy, 20.5, day(1), rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+
+	err = repo.Update(context.Background(), r)
+
+	var notFound rate.ErrRateNotFound
+	if !errors.As(err, &notFound) {
+		t.Errorf("Update() error = %v, want ErrRateNotFound", err)
+	}
+}
+
+func testDeleteAndExists(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	r := mustCreate(t, repo, cnyJpy, 20.5, day(1), rate.SourceUnionPay)
+
+	if exists, err := repo.Exists(ctx, r.ID()); err != nil || !exists {
+		t.Fatalf("Exists() = %v, %v, want true", exists, err)
+	}
+
+	if err := repo.Delete(ctx, r.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if exists, err := repo.Exists(ctx, r.ID()); err != nil || exists {
+		t.Errorf("Exists() after delete = %v, %v, want false", exists, err)
+	}
+	if revisions, err := repo.FindRevisions(ctx, r.ID()); err != nil || len(revisions) != 0 {
+		t.Errorf("FindRevisions() after delete = %d, %v, want none", len(revisions), err)
+	}
+}
+
+func testPairQueries(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		mustCreate(t, repo, cnyJpy, 20+float64(i)/10, day(i), rate.SourceUnionPay)
+	}
+	mustCreate(t, repo, usdJpy, 150, day(2), rate.SourceUnionPay)
+
+	latest, err := repo.FindLatest(ctx, cnyJpy)
+	if err != nil {
+		t.Fatalf("FindLatest() error = %v", err)
+	}
+	if !sameDay(latest.EffectiveDate(), day(5)) {
+		t.Errorf("FindLatest() date = %v, want %v", latest.EffectiveDate(), day(5))
+	}
+
+	if _, err := repo.FindLatest(ctx, eurUsd); !isNotFound(err) {
+		t.Errorf("FindLatest() for empty pair error = %v, want ErrRateNotFound", err)
+	}
+
+	byDate, err := repo.FindByPairAndDate(ctx, cnyJpy, day(3))
+	if err != nil {
+		t.Fatalf("FindByPairAndDate() error = %v", err)
+	}
+	if byDate.Value() != 20.3 {
+		t.Errorf("FindByPairAndDate() value = %v, want 20.3", byDate.Value())
+	}
+
+	if _, err := repo.FindByPairAndDate(ctx, cnyJpy, day(9)); !isNotFound(err) {
+		t.Errorf("FindByPairAndDate() for missing date error = %v, want ErrRateNotFound", err)
+	}
+
+	inRange, err := repo.FindByDateRange(ctx, cnyJpy, day(2), day(4))
+	if err != nil {
+		t.Fatalf("FindByDateRange() error = %v", err)
+	}
+	if want := []time.Time{day(4), day(3), day(2)}; !sameDays(inRange, want) {
+		t.Errorf("FindByDateRange() dates = %v, want %v (newest first, inclusive)", datesOf(inRange), want)
+	}
+
+	byPairs, err := repo.FindByPairs(ctx, []currency.Pair{cnyJpy, usdJpy, eurUsd})
+	if err != nil {
+		t.Fatalf("FindByPairs() error = %v", err)
+	}
+	if len(byPairs) != 2 {
+		t.Errorf("len(FindByPairs()) = %d, want 2", len(byPairs))
+	}
+
+	if exists, err := repo.ExistsByPairAndDate(ctx, usdJpy, day(2)); err != nil || !exists {
+		t.Errorf("ExistsByPairAndDate() = %v, %v, want true", exists, err)
+	}
+	if exists, err := repo.ExistsByPairAndDate(ctx, usdJpy, day(3)); err != nil || exists {
+		t.Errorf("ExistsByPairAndDate() for missing date = %v, %v, want false", exists, err)
+	}
+}
+
+func testDeleteOlderThan(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	var oldest *rate.Rate
+	for i := 1; i <= 5; i++ {
+		r := mustCreate(t, repo, cnyJpy, 20, day(i), rate.SourceUnionPay)
+		if i == 1 {
+			oldest = r
+		}
+	}
+	pause()
+	beforePurge := time.Now()
+
+	deleted, err := repo.DeleteOlderThan(ctx, day(3))
+	if err != nil {
+		t.Fatalf("DeleteOlderThan() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteOlderThan() = %d, want 2", deleted)
+	}
+
+	if count, _ := repo.Count(ctx); count != 3 {
+		t.Errorf("Count() = %d, want 3", count)
+	}
+
+	// Purged rates take their history with them
+	if revisions, err := repo.FindRevisions(ctx, oldest.ID()); err != nil || len(revisions) != 0 {
+		t.Errorf("FindRevisions() of a purged rate = %d, %v, want none", len(revisions), err)
+	}
+	if count, err := repo.Count(ctx, genericrepo.WithAsOf(beforePurge)); err != nil || count != 3 {
+		t.Errorf("Count() as of before the purge = %d, %v, want the 3 stored rates", count, err)
+	}
+}
+
+func testFindAllFilters(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	for i := 1; i <= 6; i++ {
+		source := rate.SourceUnionPay
+		if i%2 == 0 {
+			source = rate.SourceECB
+		}
+		mustCreate(t, repo, cnyJpy, 20+float64(i), day(i), source)
+	}
+	mustCreate(t, repo, usdJpy, 150, day(1), rate.SourceUnionPay)
+
+	pairFilter := []genericrepo.Filter{
+		genericrepo.Eq(rate.FieldBaseCurrency, "CNY"),
+		genericrepo.Eq(rate.FieldQuoteCurrency, "JPY"),
+	}
+
+	tests := []struct {
+		name string
+		opts []genericrepo.QueryOption
+		want int
+	}{
+		{
+			name: "no filter",
+			want: 7,
+		},
+		{
+			name: "equality map filter",
+			opts: []genericrepo.QueryOption{genericrepo.WithFilter(rate.FieldBaseCurrency, "USD")},
+			want: 1,
+		},
+		{
+			name: "date range",
+			opts: []genericrepo.QueryOption{
+				genericrepo.Where(pairFilter...),
+				genericrepo.Where(
+					genericrepo.Gte(rate.FieldEffectiveDate, "2024-01-02"),
+					genericrepo.Lte(rate.FieldEffectiveDate, "2024-01-04"),
+				),
+			},
+			want: 3,
+		},
+		{
+			name: "between values",
+			opts: []genericrepo.QueryOption{genericrepo.Where(genericrepo.Between(rate.FieldValue, 22, 24))},
+			want: 3,
+		},
+		{
+			name: "source in",
+			opts: []genericrepo.QueryOption{
+				genericrepo.Where(pairFilter...),
+				genericrepo.Where(genericrepo.In(rate.FieldSource, "ecb", "openexchange")),
+			},
+			want: 3,
+		},
+		{
+			name: "empty in",
+			opts: []genericrepo.QueryOption{genericrepo.Where(genericrepo.In[string](rate.FieldSource))},
+			want: 0,
+		},
+		{
+			name: "or group",
+			opts: []genericrepo.QueryOption{genericrepo.Where(genericrepo.Or(
+				genericrepo.Gt(rate.FieldValue, 100),
+				genericrepo.Lt(rate.FieldValue, 22),
+			))},
+			want: 2,
+		},
+		{
+			name: "not equal",
+			opts: []genericrepo.QueryOption{genericrepo.Where(genericrepo.Ne(rate.FieldSource, "unionpay"))},
+			want: 3,
+		},
+		{
+			name: "like",
+			opts: []genericrepo.QueryOption{genericrepo.Where(genericrepo.Like(rate.FieldSource, "union%"))},
+			want: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := repo.FindAll(ctx, tt.opts...)
+			if err != nil {
+				t.Fatalf("FindAll() error = %v", err)
+			}
+			if len(rates) != tt.want {
+				t.Errorf("len(FindAll()) = %d, want %d", len(rates), tt.want)
+			}
+
+			count, err := repo.Count(ctx, tt.opts...)
+			if err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			if count != int64(tt.want) {
+				t.Errorf("Count() = %d, want %d", count, tt.want)
+			}
+		})
+	}
+}
+
+func testFindAllInvalidFilter(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	mustCreate(t, repo, cnyJpy, 20, day(1), rate.SourceUnionPay)
+
+	var invalid genericrepo.ErrInvalidFilter
+
+	_, err := repo.FindAll(ctx, genericrepo.WithFilter("value; DROP TABLE exchange_rates", 1))
+	if !errors.As(err, &invalid) {
+		t.Errorf("FindAll() with unknown field error = %v, want ErrInvalidFilter", err)
+	}
+
+	_, err = repo.FindAll(ctx, genericrepo.WithOrderBy("unknown DESC"))
+	if !errors.As(err, &invalid) {
+		t.Errorf("FindAll() with unknown order field error = %v, want ErrInvalidFilter", err)
+	}
+}
+
+func testFindAllOrderingAndPagination(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	values := []float64{23, 21, 25, 22, 24}
+	for i, v := range values {
+		mustCreate(t, repo, cnyJpy, v, day(i+1), rate.SourceUnionPay)
+	}
+
+	rates, err := repo.FindAll(ctx, genericrepo.WithOrderBy("value ASC"))
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if got := valuesOf(rates); fmt.Sprint(got) != fmt.Sprint([]float64{21, 22, 23, 24, 25}) {
+		t.Errorf("FindAll() ordered values = %v, want ascending", got)
+	}
+
+	page, err := repo.FindAll(ctx,
+		genericrepo.WithOrderBy("effective_date DESC"),
+		genericrepo.WithPagination(2, 2),
+	)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if want := []time.Time{day(3), day(2)}; !sameDays(page, want) {
+		t.Errorf("FindAll() page 2 dates = %v, want %v", datesOf(page), want)
+	}
+
+	past, err := repo.FindAll(ctx, genericrepo.WithPagination(4, 2))
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(past) != 0 {
+		t.Errorf("len(FindAll()) past the end = %d, want 0", len(past))
+	}
+}
+
+func testFindAllCursor(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	for i := 1; i <= 7; i++ {
+		mustCreate(t, repo, cnyJpy, 20, day(i), rate.SourceUnionPay)
+	}
+
+	var (
+		dates  []time.Time
+		cursor *genericrepo.Cursor
+	)
+	for range 10 {
+		opts := []genericrepo.QueryOption{
+			genericrepo.WithOrderBy("effective_date DESC, id DESC"),
+			genericrepo.WithLimit(3),
+		}
+		if cursor != nil {
+			opts = append(opts, genericrepo.WithCursor(*cursor))
+		}
+
+		page, err := repo.FindAll(ctx, opts...)
+		if err != nil {
+			t.Fatalf("FindAll() error = %v", err)
+		}
+		dates = append(dates, datesOf(page)...)
+		if len(page) < 3 {
+			break
+		}
+
+		last := page[len(page)-1]
+		cursor = &genericrepo.Cursor{SortKey: last.EffectiveDate().Format("2006-01-02"), ID: last.ID()}
+	}
+
+	want := []time.Time{day(7), day(6), day(5), day(4), day(3), day(2), day(1)}
+	if fmt.Sprint(formatDays(dates)) != fmt.Sprint(formatDays(want)) {
+		t.Errorf("paged dates = %v, want %v", formatDays(dates), formatDays(want))
+	}
+}
+
+func testStream(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	for i := 1; i <= 7; i++ {
+		mustCreate(t, repo, cnyJpy, 20, day(i), rate.SourceUnionPay)
+	}
+	mustCreate(t, repo, usdJpy, 150, day(1), rate.SourceUnionPay)
+
+	var ascending []time.Time
+	for r, err := range repo.StreamWithError(ctx,
+		genericrepo.WithFilter(rate.FieldBaseCurrency, "CNY"),
+		genericrepo.WithBatchSize(3),
+	) {
+		if err != nil {
+			t.Fatalf("StreamWithError() error = %v", err)
+		}
+		ascending = append(ascending, r.EffectiveDate())
+	}
+	want := []time.Time{day(1), day(2), day(3), day(4), day(5), day(6), day(7)}
+	if fmt.Sprint(formatDays(ascending)) != fmt.Sprint(formatDays(want)) {
+		t.Errorf("streamed dates = %v, want %v", formatDays(ascending), formatDays(want))
+	}
+
+	var descending []time.Time
+	for r := range repo.Stream(ctx,
+		genericrepo.WithFilter(rate.FieldBaseCurrency, "CNY"),
+		genericrepo.WithOrderBy("effective_date DESC"),
+		genericrepo.WithBatchSize(2),
+	) {
+		descending = append(descending, r.EffectiveDate())
+		if len(descending) == 4 {
+			break
+		}
+	}
+	want = []time.Time{day(7), day(6), day(5), day(4)}
+	if fmt.Sprint(formatDays(descending)) != fmt.Sprint(formatDays(want)) {
+		t.Errorf("streamed dates = %v, want %v", formatDays(descending), formatDays(want))
+	}
+}
+
+func testAsOf(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	beforeCreate := time.Now()
+	pause()
+
+	r := mustCreate(t, repo, cnyJpy, 20.5, day(1), rate.SourceUnionPay)
+	pause()
+	afterCreate := time.Now()
+	pause()
+
+	mustCreate(t, repo, cnyJpy, 20.9, day(1), rate.SourceUnionPay)
+
+	current, err := repo.FindLatest(ctx, cnyJpy)
+	if err != nil {
+		t.Fatalf("FindLatest() error = %v", err)
+	}
+	if current.Value() != 20.9 {
+		t.Errorf("FindLatest() value = %v, want 20.9", current.Value())
+	}
+
+	past, err := repo.FindLatestAsOf(ctx, cnyJpy, afterCreate)
+	if err != nil {
+		t.Fatalf("FindLatestAsOf() error = %v", err)
+	}
+	if past.ID() != r.ID() || past.Value() != 20.5 {
+		t.Errorf("FindLatestAsOf() = %s/%v, want %s/20.5", past.ID(), past.Value(), r.ID())
+	}
+
+	byDate, err := repo.FindByPairAndDateAsOf(ctx, cnyJpy, day(1), afterCreate)
+	if err != nil {
+		t.Fatalf("FindByPairAndDateAsOf() error = %v", err)
+	}
+	if byDate.Value() != 20.5 {
+		t.Errorf("FindByPairAndDateAsOf() value = %v, want 20.5", byDate.Value())
+	}
+
+	inRange, err := repo.FindByDateRangeAsOf(ctx, cnyJpy, day(1), day(2), afterCreate)
+	if err != nil {
+		t.Fatalf("FindByDateRangeAsOf() error = %v", err)
+	}
+	if len(inRange) != 1 || inRange[0].Value() != 20.5 {
+		t.Errorf("FindByDateRangeAsOf() values = %v, want [20.5]", valuesOf(inRange))
+	}
+
+	if _, err := repo.FindLatestAsOf(ctx, cnyJpy, beforeCreate); !isNotFound(err) {
+		t.Errorf("FindLatestAsOf() before creation error = %v, want ErrRateNotFound", err)
+	}
+
+	// Filters apply to the value known at asOf
+	filtered, err := repo.FindAll(ctx,
+		genericrepo.WithAsOf(afterCreate),
+		genericrepo.Where(genericrepo.Lt(rate.FieldValue, 20.7)),
+	)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(filtered) != 1 {
+		t.Errorf("len(FindAll()) as of = %d, want 1", len(filtered))
+	}
+
+	count, err := repo.Count(ctx, genericrepo.WithAsOf(beforeCreate))
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Count() before creation = %d, want 0", count)
+	}
+}
+
+// day returns the n-th day of January 2024.
+func testAggregate(t *testing.T, repo rate.Repository) {
+	ctx := context.Background()
+	feb1 := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+	apr2 := time.Date(2024, time.April, 2, 0, 0, 0, 0, time.UTC)
+
+	// 2024-01-01 is a Monday and 2024-01-07 a Sunday
+	mustCreate(t, repo, cnyJpy, 20, day(1), rate.SourceUnionPay)
+	mustCreate(t, repo, cnyJpy, 22, day(7), rate.SourceUnionPay)
+	mustCreate(t, repo, cnyJpy, 21, day(8), rate.SourceUnionPay)
+	mustCreate(t, repo, cnyJpy, 19, feb1, rate.SourceUnionPay)
+	mustCreate(t, repo, cnyJpy, 23, apr2, rate.SourceUnionPay)
+	mustCreate(t, repo, usdJpy, 150, day(1), rate.SourceUnionPay)
+
+	cny := genericrepo.Where(genericrepo.Eq(rate.FieldBaseCurrency, "CNY"))
+	tests := []struct {
+		interval rate.Interval
+		starts   []string
+		counts   []int
+	}{
+		{rate.IntervalWeek, []string{"2024-01-01", "2024-01-08", "2024-01-29", "2024-04-01"}, []int{2, 1, 1, 1}},
+		{rate.IntervalMonth, []string{"2024-01-01", "2024-02-01", "2024-04-01"}, []int{3, 1, 1}},
+		{rate.IntervalQuarter, []string{"2024-01-01", "2024-04-01"}, []int{4, 1}},
+		{rate.IntervalYear, []string{"2024-01-01"}, []int{5}},
+	}
+	for _, tt := range tests {
+		periods, err := repo.Aggregate(ctx, tt.interval, cny)
+		if err != nil {
+			t.Fatalf("Aggregate(%s) error = %v", tt.interval, err)
+		}
+		starts := make([]time.Time, len(periods))
+		counts := make([]int, len(periods))
+		for i, p := range periods {
+			starts[i], counts[i] = p.Start, p.Count
+		}
+		if fmt.Sprint(formatDays(starts)) != fmt.Sprint(tt.starts) || fmt.Sprint(counts) != fmt.Sprint(tt.counts) {
+			t.Errorf("Aggregate(%s) = periods %v with %v rates, want %v with %v", tt.interval, formatDays(starts), counts, tt.starts, tt.counts)
+		}
+	}
+
+	months, err := repo.Aggregate(ctx, rate.IntervalMonth, cny)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	jan := months[0]
+	if jan.Open != 20 || jan.High != 22 || jan.Low != 20 || jan.Close != 21 || !sameDay(jan.CloseDate, day(8)) || !sameDay(jan.End, day(31)) {
+		t.Errorf("January = %+v, want open 20, high 22, low 20 and close 21 on Jan 8", jan)
+	}
+	if math.Abs(jan.Average-21) > 1e-9 || math.Abs(jan.InverseAverage-(1.0/20+1.0/22+1.0/21)/3) > 1e-9 {
+		t.Errorf("January averages = %v and %v, want 21 and the average of the inverse rates", jan.Average, jan.InverseAverage)
+	}
+
+	// Filters apply before grouping
+	filtered, err := repo.Aggregate(ctx, rate.IntervalMonth, cny,
+		genericrepo.Where(genericrepo.Gte(rate.FieldEffectiveDate, "2024-01-07")))
+	if err != nil {
+		t.Fatalf("Aggregate() filtered error = %v", err)
+	}
+	if len(filtered) != 3 || filtered[0].Count != 2 || filtered[0].Open != 22 {
+		t.Errorf("filtered January = %+v, want 2 rates opening at 22", filtered[0])
+	}
+
+	var invalid rate.ErrInvalidInterval
+	if _, err := repo.Aggregate(ctx, "day; DROP TABLE exchange_rates", cny); !errors.As(err, &invalid) {
+		t.Errorf("Aggregate() with unknown interval error = %v, want ErrInvalidInterval", err)
+	}
+}
+
+func day(n int) time.Time {
+	return time.Date(2024, time.January, n, 0, 0, 0, 0, time.UTC)
+}
+
+// pause separates timestamps clearly enough for databases with microsecond precision.
+func pause() {
+	time.Sleep(5 * time.Millisecond)
+}
+
+func mustCreate(t *testing.T, repo rate.Repository, pair currency.Pair, value float64, date time.Time, source rate.Source) *rate.Rate {
+	t.Helper()
+
+	r, err := rate.NewRate(pair, value, date, source)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	if err := repo.Create(context.Background(), r); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return r
+}
+
+func isNotFound(err error) bool {
+	var notFound rate.ErrRateNotFound
+	return errors.As(err, &notFound)
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+func sameDays(rates []*rate.Rate, want []time.Time) bool {
+	return fmt.Sprint(formatDays(datesOf(rates))) == fmt.Sprint(formatDays(want))
+}
+
+func datesOf(rates []*rate.Rate) []time.Time {
+	dates := make([]time.Time, len(rates))
+	for i, r := range rates {
+		dates[i] = r.EffectiveDate()
+	}
+	return dates
+}
+
+func formatDays(dates []time.Time) []string {
+	days := make([]string, len(dates))
+	for i, d := range dates {
+		days[i] = d.Format("2006-01-02")
+	}
+	return days
+}
+
+func valuesOf(rates []*rate.Rate) []float64 {
+	values := make([]float64, len(rates))
+	for i, r := range rates {
+		values[i] = r.Value()
+	}
+	return values
+}
@@ -9,6 +9,19 @@ import (
 	"github.com/tyokyo320/rateflow/pkg/genericrepo"
 )
 
+// Field names of the rate aggregate that repositories accept in
+// genericrepo filters and orderings. Any other field is rejected.
+const (
+	FieldID            = "id"
+	FieldBaseCurrency  = "base_currency"
+	FieldQuoteCurrency = "quote_currency"
+	FieldValue         = "value"
+	FieldEffectiveDate = "effective_date"
+	FieldSource        = "source"
+	FieldCreatedAt     = "created_at"
+	FieldUpdatedAt     = "updated_at"
+)
+
 // Repository defines the persistence interface for Rate entities.
 // This follows the repository pattern from DDD.
 type Repository interface {
@@ -32,6 +45,24 @@ type Repository interface {
 	// ExistsByPairAndDate checks if a rate exists for a specific pair and date.
 	ExistsByPairAndDate(ctx context.Context, pair currency.Pair, date time.Time) (bool, error)
 
-	// DeleteOlderThan deletes rates older than the specified date.
+	// DeleteOlderThan deletes rates older than the specified date. Their
+	// revisions are deleted with them, as is the case for Delete, so purged
+	// rates are absent from as-of reads at every point in time.
 	DeleteOlderThan(ctx context.Context, date time.Time) (int64, error)
+
+	// FindByPairAndDateAsOf finds a rate for a pair and date as it was known at asOf.
+	FindByPairAndDateAsOf(ctx context.Context, pair currency.Pair, date, asOf time.Time) (*Rate, error)
+
+	// FindLatestAsOf finds the most recent rate for a pair as it was known at asOf.
+	FindLatestAsOf(ctx context.Context, pair currency.Pair, asOf time.Time) (*Rate, error)
+
+	// FindByDateRangeAsOf finds rates for a pair within a date range as they were known at asOf.
+	FindByDateRangeAsOf(ctx context.Context, pair currency.Pair, start, end, asOf time.Time) ([]*Rate, error)
+
+	// FindRevisions returns the value history of a rate, oldest first.
+	FindRevisions(ctx context.Context, rateID string) ([]*Revision, error)
+
+	// Aggregate returns the aggregates of the rates matching opts per period of
+	// interval, oldest first. Only filters and AsOf of opts apply.
+	Aggregate(ctx context.Context, interval Interval, opts ...genericrepo.QueryOption) ([]*Period, error)
 }
@@ -0,0 +1,63 @@
+// Package rate provides the revision history for the rate aggregate.
+package rate
+
+import (
+	"context"
+	"time"
+)
+
+// Actor identifies who or what caused a change to a rate value.
+type Actor string
+
+const (
+	ActorWorker Actor = "worker" // scheduled or manual worker fetch runs
+	ActorManual Actor = "manual" // corrections entered by a user
+	ActorImport Actor = "import" // bulk imports from files or other systems
+)
+
+// Revision is an append-only record of a change to a rate's value.
+// The first revision of a rate has a nil OldValue and records the initial value.
+type Revision struct {
+	ID        string
+	RateID    string
+	OldValue  *float64
+	NewValue  float64
+	Source    Source
+	Actor     Actor
+	ActorID   string // optional identifier of the actor, e.g. a username
+	Reason    string
+	CreatedAt time.Time
+}
+
+// IsInitial reports whether the revision records the initial value of a rate.
+func (r *Revision) IsInitial() bool {
+	return r.OldValue == nil
+}
+
+// ChangeInfo describes the origin of a write to the rate repository.
+// It is carried through the context because the generic repository
+// signatures have no room for audit metadata.
+type ChangeInfo struct {
+	Actor   Actor
+	ActorID string
+	Reason  string
+}
+
+type changeInfoKey struct{}
+
+// WithChangeInfo returns a context that carries the given change information.
+func WithChangeInfo(ctx context.Context, info ChangeInfo) context.Context {
+	return context.WithValue(ctx, changeInfoKey{}, info)
+}
+
+// ChangeInfoFromContext returns the change information stored in the context.
+// Writes without explicit change information are attributed to the worker.
+func ChangeInfoFromContext(ctx context.Context) ChangeInfo {
+	if info, ok := ctx.Value(changeInfoKey{}).(ChangeInfo); ok {
+		if info.Actor == "" {
+			info.Actor = ActorWorker
+		}
+		return info
+	}
+	return ChangeInfo{Actor: ActorWorker}
+}
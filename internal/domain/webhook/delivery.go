@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryStatus is the state of a delivery.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"   // waiting for its next attempt
+	DeliverySucceeded DeliveryStatus = "succeeded" // the receiver responded with 2xx
+	DeliveryFailed    DeliveryStatus = "failed"    // every attempt failed
+)
+
+// Delivery is an event sent, or to be sent, to a subscription.
+// Deliveries are kept as the subscription's delivery log.
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	EventID        string
+	EventType      EventType
+	Payload        []byte // JSON body, signed when sent
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastAttemptAt  *time.Time
+	ResponseStatus int    // HTTP status of the last attempt; 0 if there was no response
+	LastError      string // why the last attempt failed
+	CreatedAt      time.Time
+}
+
+// NewDelivery creates a pending delivery of an event, due now.
+func NewDelivery(subscriptionID, eventID string, eventType EventType, payload []byte, now time.Time) *Delivery {
+	return &Delivery{
+		ID:             uuid.New().String(),
+		SubscriptionID: subscriptionID,
+		EventID:        eventID,
+		EventType:      eventType,
+		Payload:        payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+}
+
+// Redeliver creates a new pending delivery of the same event, due now.
+// The original delivery stays in the log unchanged.
+func (d *Delivery) Redeliver(now time.Time) *Delivery {
+	return NewDelivery(d.SubscriptionID, d.EventID, d.EventType, d.Payload, now)
+}
+
+// Succeed records a successful attempt.
+func (d *Delivery) Succeed(status int, at time.Time) {
+	d.Attempts++
+	d.Status = DeliverySucceeded
+	d.LastAttemptAt = &at
+	d.ResponseStatus = status
+	d.LastError = ""
+}
+
+// Fail records a failed attempt and schedules the next one according to
+// policy, or marks the delivery failed once its attempts are used up.
+func (d *Delivery) Fail(status int, reason string, at time.Time, policy RetryPolicy) {
+	d.Attempts++
+	d.LastAttemptAt = &at
+	d.ResponseStatus = status
+	d.LastError = reason
+
+	if d.Attempts >= policy.MaxAttempts {
+		d.Status = DeliveryFailed
+		return
+	}
+	d.NextAttemptAt = at.Add(policy.Backoff(d.Attempts))
+}
+
+// RetryPolicy controls how failed deliveries are retried.
+type RetryPolicy struct {
+	MaxAttempts    int           // attempts per delivery, including the first
+	InitialBackoff time.Duration // delay after the first failed attempt
+	MaxBackoff     time.Duration // upper bound of the delay
+}
+
+// DefaultRetryPolicy retries for about a day before giving up.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    10,
+	InitialBackoff: 30 * time.Second,
+	MaxBackoff:     6 * time.Hour,
+}
+
+// Backoff returns the delay after the given number of failed attempts.
+// It doubles with every attempt, up to MaxBackoff.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempts && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
@@ -0,0 +1,30 @@
+package webhook
+
+import "fmt"
+
+// ErrInvalidSubscription represents a domain validation error for webhook subscriptions.
+type ErrInvalidSubscription struct {
+	reason string
+}
+
+func (e ErrInvalidSubscription) Error() string {
+	return fmt.Sprintf("invalid webhook: %s", e.reason)
+}
+
+// ErrSubscriptionNotFound indicates that a webhook subscription was not found.
+type ErrSubscriptionNotFound struct {
+	ID string
+}
+
+func (e ErrSubscriptionNotFound) Error() string {
+	return fmt.Sprintf("webhook not found: %s", e.ID)
+}
+
+// ErrDeliveryNotFound indicates that a webhook delivery was not found.
+type ErrDeliveryNotFound struct {
+	ID string
+}
+
+func (e ErrDeliveryNotFound) Error() string {
+	return fmt.Sprintf("webhook delivery not found: %s", e.ID)
+}
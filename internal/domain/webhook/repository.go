@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+)
+
+// Repository stores webhook subscriptions and their deliveries.
+type Repository interface {
+	// CreateSubscription stores a new subscription.
+	CreateSubscription(ctx context.Context, s *Subscription) error
+
+	// FindSubscription returns a subscription.
+	// Returns ErrSubscriptionNotFound if it does not exist.
+	FindSubscription(ctx context.Context, id string) (*Subscription, error)
+
+	// ListSubscriptions returns all subscriptions, oldest first.
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+
+	// FindActiveSubscriptions returns the active subscriptions to pair or its inverse.
+	FindActiveSubscriptions(ctx context.Context, pair currency.Pair) ([]*Subscription, error)
+
+	// UpdateSubscription stores the changes to a subscription.
+	// Returns ErrSubscriptionNotFound if it does not exist.
+	UpdateSubscription(ctx context.Context, s *Subscription) error
+
+	// DeleteSubscription deletes a subscription and its deliveries.
+	// Returns ErrSubscriptionNotFound if it does not exist.
+	DeleteSubscription(ctx context.Context, id string) error
+
+	// CreateDeliveries stores new deliveries.
+	CreateDeliveries(ctx context.Context, deliveries []*Delivery) error
+
+	// FindDelivery returns a delivery.
+	// Returns ErrDeliveryNotFound if it does not exist.
+	FindDelivery(ctx context.Context, id string) (*Delivery, error)
+
+	// ListDeliveries returns up to limit deliveries of a subscription, newest first.
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*Delivery, error)
+
+	// ClaimDueDeliveries returns up to limit pending deliveries due at now,
+	// oldest first, and postpones them by lease, so that other processes do
+	// not attempt them at the same time.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+
+	// UpdateDelivery stores the outcome of a delivery attempt.
+	UpdateDelivery(ctx context.Context, d *Delivery) error
+}
+
+// Sender sends deliveries to their subscriptions.
+type Sender interface {
+	// Send posts the signed payload of d to s and returns the HTTP status of
+	// the response, or 0 if there was none. Non-2xx responses are errors.
+	Send(ctx context.Context, s *Subscription, d *Delivery) (int, error)
+}
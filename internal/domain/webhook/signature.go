@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of webhook requests.
+const (
+	SignatureHeader = "X-RateFlow-Signature" // "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+	EventHeader     = "X-RateFlow-Event"     // event type
+	DeliveryHeader  = "X-RateFlow-Delivery"  // delivery ID
+)
+
+// Sign returns the signature header of body sent at timestamp.
+// The HMAC-SHA256 covers "<unix seconds>.<body>", so a captured request cannot
+// be replayed with a different timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + signature(secret, unix, body)
+}
+
+// Verify checks a signature header created by Sign. Signatures older than
+// tolerance are rejected; a tolerance of 0 accepts any age.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix, sig string
+	for part := range strings.SplitSeq(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			sig = value
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || sig == "" {
+		return fmt.Errorf("malformed signature header %q", header)
+	}
+	if tolerance > 0 && now.Sub(time.Unix(seconds, 0)) > tolerance {
+		return fmt.Errorf("signature expired")
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, unix, body))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func signature(secret, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,218 @@
+// Package webhook provides webhook subscriptions that notify external systems
+// of new rates, and the deliveries of their events.
+package webhook
+
+import (
+	"crypto/rand"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// ConditionKind selects which new rates a subscription is notified of.
+type ConditionKind string
+
+const (
+	ConditionNewRate ConditionKind = "new_rate" // every new rate of the pair
+	ConditionChange  ConditionKind = "change"   // rates moving more than Threshold percent from the previous day
+	ConditionCross   ConditionKind = "cross"    // rates crossing Threshold from either side
+)
+
+// ConditionKinds lists the known condition kinds.
+var ConditionKinds = []ConditionKind{ConditionNewRate, ConditionChange, ConditionCross}
+
+// Condition decides whether a new rate is notified.
+type Condition struct {
+	Kind      ConditionKind
+	Threshold float64 // percent for ConditionChange, a rate for ConditionCross
+}
+
+// Validate checks the condition's kind and threshold.
+func (c Condition) Validate() error {
+	switch c.Kind {
+	case ConditionNewRate:
+		return nil
+	case ConditionChange, ConditionCross:
+		if c.Threshold <= 0 || math.IsInf(c.Threshold, 0) || math.IsNaN(c.Threshold) {
+			return ErrInvalidSubscription{reason: "threshold must be a positive number"}
+		}
+		return nil
+	default:
+		return ErrInvalidSubscription{reason: "unknown condition " + string(c.Kind)}
+	}
+}
+
+// Matches reports whether a new rate of value is notified, given the rate of
+// the previous day, if any. Movements and crossings need a previous rate.
+func (c Condition) Matches(value float64, previous *float64) bool {
+	switch c.Kind {
+	case ConditionNewRate:
+		return true
+	case ConditionChange:
+		if previous == nil || *previous == 0 {
+			return false
+		}
+		return math.Abs(ChangePercent(*previous, value)) > c.Threshold
+	case ConditionCross:
+		if previous == nil {
+			return false
+		}
+		return (*previous < c.Threshold && value >= c.Threshold) ||
+			(*previous > c.Threshold && value <= c.Threshold)
+	default:
+		return false
+	}
+}
+
+// EventType returns the type of the events the condition emits.
+func (c Condition) EventType() EventType {
+	switch c.Kind {
+	case ConditionChange:
+		return EventRateChanged
+	case ConditionCross:
+		return EventRateCrossed
+	default:
+		return EventRateCreated
+	}
+}
+
+// ChangePercent returns the change from previous to value in percent.
+func ChangePercent(previous, value float64) float64 {
+	return (value - previous) / previous * 100
+}
+
+// secretPrefix marks RateFlow webhook signing secrets.
+const secretPrefix = "whsec_"
+
+// Subscription is a webhook registered by an external system.
+// Its secret signs every payload, so that receivers can verify the sender.
+type Subscription struct {
+	ID          string
+	URL         string
+	Secret      string
+	Pair        currency.Pair // rates of the pair, in this direction, are notified
+	Condition   Condition
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewSubscription creates an active subscription with a new signing secret.
+func NewSubscription(rawURL string, pair currency.Pair, condition Condition, description string, now time.Time) (*Subscription, error) {
+	s := &Subscription{
+		ID:          uuid.New().String(),
+		Secret:      secretPrefix + rand.Text(),
+		Pair:        pair,
+		Description: description,
+		Active:      true,
+		CreatedAt:   now,
+	}
+	if err := s.Change(rawURL, condition, true, now); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Change replaces the subscription's URL, condition and status.
+func (s *Subscription) Change(rawURL string, condition Condition, active bool, now time.Time) error {
+	if err := validateURL(rawURL); err != nil {
+		return err
+	}
+	if err := condition.Validate(); err != nil {
+		return err
+	}
+
+	s.URL = rawURL
+	s.Condition = condition
+	s.Active = active
+	s.UpdatedAt = now
+	return nil
+}
+
+// Observe returns the payload notifying the subscription of r, whose pair is
+// the subscription's or its inverse, or false if the condition does not match.
+// previous is the stored rate of r's pair on the preceding day, if any.
+func (s *Subscription) Observe(r *rate.Rate, previous *float64, now time.Time) (Payload, bool) {
+	value := r.Value()
+	if !r.Pair().Equal(s.Pair) {
+		value = r.Pair().ConvertRate(value)
+		if previous != nil {
+			inverse := r.Pair().ConvertRate(*previous)
+			previous = &inverse
+		}
+	}
+
+	if !s.Active || !s.Condition.Matches(value, previous) {
+		return Payload{}, false
+	}
+
+	payload := Payload{
+		ID:             uuid.New().String(),
+		Type:           s.Condition.EventType(),
+		SubscriptionID: s.ID,
+		Rate: RatePayload{
+			ID:            r.ID(),
+			Pair:          s.Pair.String(),
+			Value:         value,
+			EffectiveDate: r.EffectiveDate(),
+			Source:        r.Source(),
+		},
+		PreviousValue: previous,
+		OccurredAt:    now,
+	}
+	if previous != nil && *previous != 0 {
+		change := ChangePercent(*previous, value)
+		payload.ChangePercent = &change
+	}
+	if s.Condition.Kind != ConditionNewRate {
+		threshold := s.Condition.Threshold
+		payload.Threshold = &threshold
+	}
+	return payload, true
+}
+
+func validateURL(rawURL string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" || !slices.Contains([]string{"http", "https"}, u.Scheme) {
+		return ErrInvalidSubscription{reason: "url must be an absolute http or https URL"}
+	}
+	return nil
+}
+
+// EventType is the type of a webhook event.
+type EventType string
+
+const (
+	EventRateCreated EventType = "rate.created"
+	EventRateChanged EventType = "rate.changed"
+	EventRateCrossed EventType = "rate.crossed"
+)
+
+// Payload is the JSON body of a webhook event.
+type Payload struct {
+	ID             string      `json:"id"` // event ID; redeliveries carry the same one
+	Type           EventType   `json:"type"`
+	SubscriptionID string      `json:"subscriptionId"`
+	Rate           RatePayload `json:"rate"`
+	PreviousValue  *float64    `json:"previousValue,omitempty"` // rate of the previous day
+	ChangePercent  *float64    `json:"changePercent,omitempty"` // change from the previous day
+	Threshold      *float64    `json:"threshold,omitempty"`
+	OccurredAt     time.Time   `json:"occurredAt"`
+}
+
+// RatePayload is the rate an event notifies of, in the subscription's direction.
+type RatePayload struct {
+	ID            string      `json:"id"`
+	Pair          string      `json:"pair"`
+	Value         float64     `json:"value"`
+	EffectiveDate time.Time   `json:"effectiveDate"`
+	Source        rate.Source `json:"source"`
+}
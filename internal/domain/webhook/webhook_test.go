@@ -0,0 +1,171 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+)
+
+func TestCondition_Matches(t *testing.T) {
+	prev := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name      string
+		condition webhook.Condition
+		value     float64
+		previous  *float64
+		want      bool
+	}{
+		{"new rate without previous", webhook.Condition{Kind: webhook.ConditionNewRate}, 20, nil, true},
+		{"change above threshold", webhook.Condition{Kind: webhook.ConditionChange, Threshold: 1}, 20.3, prev(20), true},
+		{"drop above threshold", webhook.Condition{Kind: webhook.ConditionChange, Threshold: 1}, 19.7, prev(20), true},
+		{"change below threshold", webhook.Condition{Kind: webhook.ConditionChange, Threshold: 1}, 20.1, prev(20), false},
+		{"change without previous", webhook.Condition{Kind: webhook.ConditionChange, Threshold: 1}, 30, nil, false},
+		{"cross upwards", webhook.Condition{Kind: webhook.ConditionCross, Threshold: 20}, 20.1, prev(19.9), true},
+		{"cross downwards", webhook.Condition{Kind: webhook.ConditionCross, Threshold: 20}, 19.9, prev(20.1), true},
+		{"reach level", webhook.Condition{Kind: webhook.ConditionCross, Threshold: 20}, 20, prev(19.9), true},
+		{"stay above", webhook.Condition{Kind: webhook.ConditionCross, Threshold: 20}, 20.2, prev(20.1), false},
+		{"leave level", webhook.Condition{Kind: webhook.ConditionCross, Threshold: 20}, 20.1, prev(20), false},
+		{"cross without previous", webhook.Condition{Kind: webhook.ConditionCross, Threshold: 20}, 20.1, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.Matches(tt.value, tt.previous); got != tt.want {
+				t.Errorf("Matches(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSubscription(t *testing.T) {
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+
+	tests := []struct {
+		name      string
+		url       string
+		condition webhook.Condition
+		wantErr   bool
+	}{
+		{"new rate", "https://billing.example.com/hooks/rates", webhook.Condition{Kind: webhook.ConditionNewRate}, false},
+		{"cross", "http://localhost:9000/hook", webhook.Condition{Kind: webhook.ConditionCross, Threshold: 21}, false},
+		{"relative url", "/hooks/rates", webhook.Condition{Kind: webhook.ConditionNewRate}, true},
+		{"unsupported scheme", "ftp://example.com/hook", webhook.Condition{Kind: webhook.ConditionNewRate}, true},
+		{"unknown condition", "https://example.com/hook", webhook.Condition{Kind: "above"}, true},
+		{"missing threshold", "https://example.com/hook", webhook.Condition{Kind: webhook.ConditionChange}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := webhook.NewSubscription(tt.url, pair, tt.condition, "", time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSubscription() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (!s.Active || s.Secret == "") {
+				t.Errorf("NewSubscription() = %+v, want an active subscription with a secret", s)
+			}
+		})
+	}
+}
+
+func TestSubscription_Observe(t *testing.T) {
+	now := time.Now()
+	stored, err := rate.NewRate(currency.MustNewPair(currency.CNY, currency.JPY), 20, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	previous := 19.5
+
+	// 1 JPY/CNY crossed 0.05 downwards: 1/19.5 = 0.0513 -> 1/20 = 0.05
+	inverse, err := webhook.NewSubscription("https://example.com/hook", currency.MustNewPair(currency.JPY, currency.CNY),
+		webhook.Condition{Kind: webhook.ConditionCross, Threshold: 0.05}, "", now)
+	if err != nil {
+		t.Fatalf("NewSubscription() error = %v", err)
+	}
+
+	payload, ok := inverse.Observe(stored, &previous, now)
+	if !ok {
+		t.Fatal("Observe() did not match a crossing of the inverse rate")
+	}
+	if payload.Type != webhook.EventRateCrossed || payload.Rate.Pair != "JPY/CNY" || payload.Rate.Value != 0.05 {
+		t.Errorf("payload = %+v, want a rate.crossed event of JPY/CNY at 0.05", payload)
+	}
+	if payload.ChangePercent == nil || *payload.ChangePercent >= 0 {
+		t.Errorf("ChangePercent = %v, want a negative change", payload.ChangePercent)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(body, &decoded); err != nil || decoded["threshold"] != 0.05 {
+		t.Errorf("payload JSON = %s, want the threshold", body)
+	}
+
+	inverse.Active = false
+	if _, ok := inverse.Observe(stored, &previous, now); ok {
+		t.Error("Observe() matched on an inactive subscription")
+	}
+}
+
+func TestDelivery_Fail(t *testing.T) {
+	policy := webhook.RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Minute, MaxBackoff: 3 * time.Minute}
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	d := webhook.NewDelivery("sub", "event", webhook.EventRateCreated, []byte(`{}`), start)
+
+	// Backoff doubles up to the maximum, and the last attempt fails the delivery
+	wantDelays := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute}
+	at := start
+	for i, want := range wantDelays {
+		d.Fail(503, "unavailable", at, policy)
+		if d.Status != webhook.DeliveryPending || d.NextAttemptAt.Sub(at) != want {
+			t.Fatalf("after attempt %d: status %s, next attempt in %v; want pending in %v", i+1, d.Status, d.NextAttemptAt.Sub(at), want)
+		}
+		at = d.NextAttemptAt
+	}
+
+	d.Fail(503, "unavailable", at, policy)
+	if d.Status != webhook.DeliveryFailed || d.Attempts != 4 {
+		t.Errorf("after last attempt: status %s after %d attempts, want failed after 4", d.Status, d.Attempts)
+	}
+
+	redelivery := d.Redeliver(at)
+	if redelivery.ID == d.ID || redelivery.EventID != d.EventID || redelivery.Status != webhook.DeliveryPending || redelivery.Attempts != 0 {
+		t.Errorf("Redeliver() = %+v, want a new pending delivery of the same event", redelivery)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"rate.created"}`)
+	sentAt := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	header := webhook.Sign("whsec_test", sentAt, body)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", "whsec_test", header, body, sentAt.Add(time.Minute), false},
+		{"wrong secret", "whsec_other", header, body, sentAt, true},
+		{"tampered body", "whsec_test", header, []byte(`{"type":"rate.crossed"}`), sentAt, true},
+		{"expired", "whsec_test", header, body, sentAt.Add(10 * time.Minute), true},
+		{"malformed", "whsec_test", "sha256=abc", body, sentAt, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhook.Verify(tt.secret, tt.header, tt.body, tt.now, 5*time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -7,15 +7,25 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds the application configuration.
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Database DatabaseConfig `json:"database"`
-	Redis    RedisConfig    `json:"redis"`
-	Logger   LoggerConfig   `json:"logger"`
+	Server    ServerConfig    `json:"server"`
+	Database  DatabaseConfig  `json:"database"`
+	Redis     RedisConfig     `json:"redis"`
+	Cache     CacheConfig     `json:"cache"`
+	Health    HealthConfig    `json:"health"`
+	Metrics   MetricsConfig   `json:"metrics"`
+	Tracing   TracingConfig   `json:"tracing"`
+	Auth      AuthConfig      `json:"auth"`
+	RateLimit RateLimitConfig `json:"rateLimit"`
+	Stream    StreamConfig    `json:"stream"`
+	Webhooks  WebhookConfig   `json:"webhooks"`
+	Anomaly   AnomalyConfig   `json:"anomaly"`
+	Logger    LoggerConfig    `json:"logger"`
 }
 
 // ServerConfig holds HTTP server configuration.
@@ -25,10 +35,25 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `json:"readTimeout"`
 	WriteTimeout time.Duration `json:"writeTimeout"`
 	Environment  string        `json:"environment"` // dev, staging, prod
+
+	// ProblemDetails writes error responses as RFC 7807 problem details
+	// (application/problem+json) instead of the JSON envelope.
+	ProblemDetails bool `json:"problemDetails"`
 }
 
-// DatabaseConfig holds PostgreSQL configuration.
+// Supported database drivers.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory" // rates kept in the API process, for demos
+)
+
+// DatabaseConfig holds database configuration.
+// Driver selects PostgreSQL (the default), SQLite or memory; SQLite and
+// memory only use Path.
 type DatabaseConfig struct {
+	Driver   string `json:"driver"` // postgres, sqlite, memory
+	Path     string `json:"path"`   // SQLite database file, or JSON file of rates the memory driver starts with
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	User     string `json:"user"`
@@ -40,6 +65,7 @@ type DatabaseConfig struct {
 }
 
 // RedisConfig holds Redis configuration.
+// Redis is optional: without a host an in-process cache is used instead.
 type RedisConfig struct {
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
@@ -47,6 +73,115 @@ type RedisConfig struct {
 	DB       int    `json:"db"`
 }
 
+// Supported cache modes.
+const (
+	CacheModeMemory = "memory" // in-process LRU only
+	CacheModeRedis  = "redis"  // Redis only
+	CacheModeTiered = "tiered" // in-process LRU in front of Redis
+)
+
+// CacheConfig holds response cache configuration.
+// An empty Mode selects tiered when Redis is configured and memory otherwise.
+type CacheConfig struct {
+	Mode       string        `json:"mode"`       // memory, redis, tiered
+	MaxEntries int           `json:"maxEntries"` // capacity of the in-process LRU
+	LocalTTL   time.Duration `json:"localTTL"`   // lifetime of entries in the in-process tier of a tiered cache
+
+	// Lifetimes of cached query results
+	LatestTTL time.Duration `json:"latestTTL"` // latest rate of a pair
+	ByDateTTL time.Duration `json:"byDateTTL"` // rate of a pair on a given date
+	StaleTTL  time.Duration `json:"staleTTL"`  // how long expired results are served while being refreshed
+	TTLJitter float64       `json:"ttlJitter"` // random variation of TTLs as a fraction, e.g. 0.1 for ±10%
+
+	// Cache-Control of rate responses, for browser and CDN caches
+	FetchInterval    time.Duration `json:"fetchInterval"`    // interval of the worker's fetch runs, until whose next run current rates are cacheable
+	HistoricalMaxAge time.Duration `json:"historicalMaxAge"` // how long rates of past dates are cacheable
+}
+
+// HealthConfig holds readiness check configuration.
+type HealthConfig struct {
+	Pairs     []string      `json:"pairs"`     // pairs whose data freshness is checked, e.g. CNY/JPY
+	Providers []string      `json:"providers"` // providers whose last fetch outcome is checked
+	Timeout   time.Duration `json:"timeout"`   // time limit of each check
+}
+
+// MetricsConfig holds Prometheus metrics configuration.
+type MetricsConfig struct {
+	PushgatewayURL string `json:"pushgatewayURL"` // where the worker pushes metrics of batch runs; empty disables pushing
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration.
+type TracingConfig struct {
+	Endpoint    string  `json:"endpoint"`    // OTLP/HTTP collector URL, e.g. http://localhost:4318; empty disables exporting
+	SampleRatio float64 `json:"sampleRatio"` // fraction of new traces that are sampled
+}
+
+// AuthConfig holds API authentication configuration.
+type AuthConfig struct {
+	Enabled bool `json:"enabled"` // require an API key on the rate endpoints
+}
+
+// RateLimitConfig holds request rate limiting configuration.
+// Limits are written as "<requests>/<window>", e.g. "120/1m", and counted per
+// API key, or per client IP for requests without one.
+type RateLimitConfig struct {
+	Enabled bool              `json:"enabled"`
+	Default string            `json:"default"` // limit of routes without their own; empty for none
+	Routes  map[string]string `json:"routes"`  // limits by route template, e.g. "/api/v1/rates/list": "30/1m"
+}
+
+// ParseRateLimit parses a limit written as "<requests>/<window>".
+func ParseRateLimit(s string) (int, time.Duration, error) {
+	count, window, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid rate limit %q: want <requests>/<window>, e.g. 120/1m", s)
+	}
+	requests, err := strconv.Atoi(count)
+	if err != nil || requests < 1 {
+		return 0, 0, fmt.Errorf("invalid rate limit %q: requests must be a positive number", s)
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d < time.Second {
+		return 0, 0, fmt.Errorf("invalid rate limit %q: window must be a duration of at least 1s", s)
+	}
+	return requests, d, nil
+}
+
+// StreamConfig holds configuration of the rate update stream.
+type StreamConfig struct {
+	Retention int           `json:"retention"` // number of recent events kept for clients resuming a stream
+	Heartbeat time.Duration `json:"heartbeat"` // interval of keep-alives on idle streams
+}
+
+// WebhookConfig holds configuration of outbound webhooks.
+// The worker queues deliveries of the rates it stores and the API sends them.
+type WebhookConfig struct {
+	Enabled        bool          `json:"enabled"`        // serve the webhook endpoints, queue and send deliveries; requires auth
+	PollInterval   time.Duration `json:"pollInterval"`   // how often the API looks for due deliveries
+	BatchSize      int           `json:"batchSize"`      // deliveries attempted per poll
+	Timeout        time.Duration `json:"timeout"`        // time limit of each delivery request
+	MaxAttempts    int           `json:"maxAttempts"`    // attempts per delivery before it fails
+	InitialBackoff time.Duration `json:"initialBackoff"` // delay after the first failed attempt, doubling with each further one
+	MaxBackoff     time.Duration `json:"maxBackoff"`     // upper bound of the delay between attempts
+
+	// AllowPrivateTargets lets deliveries reach loopback, private and
+	// link-local addresses, for receivers on the server's own network.
+	AllowPrivateTargets bool `json:"allowPrivateTargets"`
+}
+
+// AnomalyConfig holds configuration of the screening of fetched rates.
+// Rates that do not fit the recent history of their pair are quarantined for
+// review instead of being stored. A zero limit disables its check.
+type AnomalyConfig struct {
+	Enabled             bool               `json:"enabled"`             // screen rates before storing them; requires auth
+	Lookback            int                `json:"lookback"`            // recent rates a new one is compared with
+	MinHistory          int                `json:"minHistory"`          // recent rates the z-score check needs
+	ZScore              float64            `json:"zScore"`              // largest distance from the recent mean, in standard deviations
+	MaxJumpPercent      float64            `json:"maxJumpPercent"`      // largest change from the previous rate, in percent
+	PairJumpPercent     map[string]float64 `json:"pairJumpPercent"`     // MaxJumpPercent by pair, e.g. "USD/JPY": 3
+	ReciprocalTolerance float64            `json:"reciprocalTolerance"` // relative distance from the inverse of the previous rate
+}
+
 // LoggerConfig holds logging configuration.
 type LoggerConfig struct {
 	Level  string `json:"level"`  // debug, info, warn, error
@@ -87,6 +222,8 @@ func defaultConfig() *Config {
 			Environment:  "dev",
 		},
 		Database: DatabaseConfig{
+			Driver:   DriverPostgres,
+			Path:     "rateflow.db",
 			Host:     "localhost",
 			Port:     5432,
 			SSLMode:  "disable",
@@ -94,10 +231,54 @@ func defaultConfig() *Config {
 			MaxConns: 25,
 		},
 		Redis: RedisConfig{
-			Host: "localhost",
 			Port: 6379,
 			DB:   0,
 		},
+		Cache: CacheConfig{
+			MaxEntries: 10000,
+			LocalTTL:   30 * time.Second,
+			LatestTTL:  5 * time.Minute,
+			ByDateTTL:  time.Hour,
+			StaleTTL:   time.Minute,
+			TTLJitter:  0.1,
+
+			FetchInterval:    time.Hour,
+			HistoricalMaxAge: 24 * time.Hour,
+		},
+		Health: HealthConfig{
+			Pairs:     []string{"CNY/JPY"},
+			Providers: []string{"unionpay"},
+			Timeout:   2 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Default: "600/1m",
+			Routes: map[string]string{
+				"/api/v1/rates/list": "60/1m",
+				"/api/rates/list":    "60/1m",
+			},
+		},
+		Stream: StreamConfig{
+			Retention: 1000,
+			Heartbeat: 15 * time.Second,
+		},
+		Webhooks: WebhookConfig{
+			PollInterval:   5 * time.Second,
+			BatchSize:      50,
+			Timeout:        10 * time.Second,
+			MaxAttempts:    10,
+			InitialBackoff: 30 * time.Second,
+			MaxBackoff:     6 * time.Hour,
+		},
+		Anomaly: AnomalyConfig{
+			Lookback:            30,
+			MinHistory:          10,
+			ZScore:              8,
+			MaxJumpPercent:      10,
+			ReciprocalTolerance: 0.02,
+		},
+		Tracing: TracingConfig{
+			SampleRatio: 1,
+		},
 		Logger: LoggerConfig{
 			Level:  "info",
 			Format: "json",
@@ -128,8 +309,19 @@ func overrideFromEnv(cfg *Config) {
 	if v := os.Getenv("ENVIRONMENT"); v != "" {
 		cfg.Server.Environment = v
 	}
+	if v := os.Getenv("SERVER_PROBLEM_DETAILS"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			cfg.Server.ProblemDetails = enabled
+		}
+	}
 
 	// Database
+	if v := os.Getenv("DB_DRIVER"); v != "" {
+		cfg.Database.Driver = v
+	}
+	if v := os.Getenv("DB_PATH"); v != "" {
+		cfg.Database.Path = v
+	}
 	if v := os.Getenv("DB_HOST"); v != "" {
 		cfg.Database.Host = v
 	}
@@ -177,6 +369,204 @@ func overrideFromEnv(cfg *Config) {
 		}
 	}
 
+	// Cache
+	if v := os.Getenv("CACHE_MODE"); v != "" {
+		cfg.Cache.Mode = v
+	}
+	if v := os.Getenv("CACHE_MAX_ENTRIES"); v != "" {
+		if maxEntries, err := strconv.Atoi(v); err == nil {
+			cfg.Cache.MaxEntries = maxEntries
+		}
+	}
+	if v := os.Getenv("CACHE_LOCAL_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil {
+			cfg.Cache.LocalTTL = ttl
+		}
+	}
+	if v := os.Getenv("CACHE_LATEST_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil {
+			cfg.Cache.LatestTTL = ttl
+		}
+	}
+	if v := os.Getenv("CACHE_BY_DATE_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil {
+			cfg.Cache.ByDateTTL = ttl
+		}
+	}
+	if v := os.Getenv("CACHE_STALE_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil {
+			cfg.Cache.StaleTTL = ttl
+		}
+	}
+	if v := os.Getenv("CACHE_TTL_JITTER"); v != "" {
+		if jitter, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Cache.TTLJitter = jitter
+		}
+	}
+	if v := os.Getenv("CACHE_FETCH_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			cfg.Cache.FetchInterval = interval
+		}
+	}
+	if v := os.Getenv("CACHE_HISTORICAL_MAX_AGE"); v != "" {
+		if maxAge, err := time.ParseDuration(v); err == nil {
+			cfg.Cache.HistoricalMaxAge = maxAge
+		}
+	}
+
+	// Health
+	if v := os.Getenv("HEALTH_PAIRS"); v != "" {
+		cfg.Health.Pairs = splitList(v)
+	}
+	if v := os.Getenv("HEALTH_PROVIDERS"); v != "" {
+		cfg.Health.Providers = splitList(v)
+	}
+	if v := os.Getenv("HEALTH_TIMEOUT"); v != "" {
+		if timeout, err := time.ParseDuration(v); err == nil {
+			cfg.Health.Timeout = timeout
+		}
+	}
+
+	// Metrics
+	if v := os.Getenv("METRICS_PUSHGATEWAY_URL"); v != "" {
+		cfg.Metrics.PushgatewayURL = v
+	}
+
+	// Auth
+	if v := os.Getenv("AUTH_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			cfg.Auth.Enabled = enabled
+		}
+	}
+
+	// Rate limiting
+	if v := os.Getenv("RATE_LIMIT_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			cfg.RateLimit.Enabled = enabled
+		}
+	}
+	if v, ok := os.LookupEnv("RATE_LIMIT_DEFAULT"); ok {
+		cfg.RateLimit.Default = v
+	}
+	if v := os.Getenv("RATE_LIMIT_ROUTES"); v != "" {
+		// route=limit pairs, e.g. /api/v1/rates/list=30/1m,/api/rates/list=30/1m
+		routes := make(map[string]string)
+		for _, item := range splitList(v) {
+			if route, limit, ok := strings.Cut(item, "="); ok {
+				routes[strings.TrimSpace(route)] = strings.TrimSpace(limit)
+			}
+		}
+		cfg.RateLimit.Routes = routes
+	}
+
+	// Stream
+	if v := os.Getenv("STREAM_RETENTION"); v != "" {
+		if retention, err := strconv.Atoi(v); err == nil {
+			cfg.Stream.Retention = retention
+		}
+	}
+	if v := os.Getenv("STREAM_HEARTBEAT"); v != "" {
+		if heartbeat, err := time.ParseDuration(v); err == nil {
+			cfg.Stream.Heartbeat = heartbeat
+		}
+	}
+
+	// Webhooks
+	if v := os.Getenv("WEBHOOKS_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			cfg.Webhooks.Enabled = enabled
+		}
+	}
+	if v := os.Getenv("WEBHOOKS_POLL_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			cfg.Webhooks.PollInterval = interval
+		}
+	}
+	if v := os.Getenv("WEBHOOKS_BATCH_SIZE"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil {
+			cfg.Webhooks.BatchSize = size
+		}
+	}
+	if v := os.Getenv("WEBHOOKS_TIMEOUT"); v != "" {
+		if timeout, err := time.ParseDuration(v); err == nil {
+			cfg.Webhooks.Timeout = timeout
+		}
+	}
+	if v := os.Getenv("WEBHOOKS_MAX_ATTEMPTS"); v != "" {
+		if attempts, err := strconv.Atoi(v); err == nil {
+			cfg.Webhooks.MaxAttempts = attempts
+		}
+	}
+	if v := os.Getenv("WEBHOOKS_INITIAL_BACKOFF"); v != "" {
+		if backoff, err := time.ParseDuration(v); err == nil {
+			cfg.Webhooks.InitialBackoff = backoff
+		}
+	}
+	if v := os.Getenv("WEBHOOKS_MAX_BACKOFF"); v != "" {
+		if backoff, err := time.ParseDuration(v); err == nil {
+			cfg.Webhooks.MaxBackoff = backoff
+		}
+	}
+	if v := os.Getenv("WEBHOOKS_ALLOW_PRIVATE_TARGETS"); v != "" {
+		if allow, err := strconv.ParseBool(v); err == nil {
+			cfg.Webhooks.AllowPrivateTargets = allow
+		}
+	}
+
+	// Anomaly screening
+	if v := os.Getenv("ANOMALY_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			cfg.Anomaly.Enabled = enabled
+		}
+	}
+	if v := os.Getenv("ANOMALY_LOOKBACK"); v != "" {
+		if lookback, err := strconv.Atoi(v); err == nil {
+			cfg.Anomaly.Lookback = lookback
+		}
+	}
+	if v := os.Getenv("ANOMALY_MIN_HISTORY"); v != "" {
+		if history, err := strconv.Atoi(v); err == nil {
+			cfg.Anomaly.MinHistory = history
+		}
+	}
+	if v := os.Getenv("ANOMALY_Z_SCORE"); v != "" {
+		if z, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Anomaly.ZScore = z
+		}
+	}
+	if v := os.Getenv("ANOMALY_MAX_JUMP_PERCENT"); v != "" {
+		if jump, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Anomaly.MaxJumpPercent = jump
+		}
+	}
+	if v := os.Getenv("ANOMALY_PAIR_JUMP_PERCENT"); v != "" {
+		// pair=limit pairs, e.g. USD/JPY=3,CNY/JPY=5
+		limits := make(map[string]float64)
+		for _, item := range splitList(v) {
+			if pair, limit, ok := strings.Cut(item, "="); ok {
+				if jump, err := strconv.ParseFloat(strings.TrimSpace(limit), 64); err == nil {
+					limits[strings.TrimSpace(pair)] = jump
+				}
+			}
+		}
+		cfg.Anomaly.PairJumpPercent = limits
+	}
+	if v := os.Getenv("ANOMALY_RECIPROCAL_TOLERANCE"); v != "" {
+		if tolerance, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Anomaly.ReciprocalTolerance = tolerance
+		}
+	}
+
+	// Tracing
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); v != "" {
+		cfg.Tracing.Endpoint = v
+	}
+	if v := os.Getenv("TRACING_SAMPLE_RATIO"); v != "" {
+		if ratio, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Tracing.SampleRatio = ratio
+		}
+	}
+
 	// Logger
 	if v := os.Getenv("LOG_LEVEL"); v != "" {
 		cfg.Logger.Level = v
@@ -186,16 +576,112 @@ func overrideFromEnv(cfg *Config) {
 	}
 }
 
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // Validate validates the configuration.
 func (c *Config) Validate() error {
-	if c.Database.Host == "" {
-		return fmt.Errorf("database host is required")
+	switch c.Database.Driver {
+	case DriverPostgres:
+		if c.Database.Host == "" {
+			return fmt.Errorf("database host is required")
+		}
+		if c.Database.User == "" {
+			return fmt.Errorf("database user is required")
+		}
+		if c.Database.Database == "" {
+			return fmt.Errorf("database name is required")
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			return fmt.Errorf("database path is required")
+		}
+	case DriverMemory:
+		// Only rates are kept in memory; keys, webhooks and quarantined rates
+		// need a database
+		if c.Auth.Enabled || c.Webhooks.Enabled || c.Anomaly.Enabled {
+			return fmt.Errorf("the memory database driver does not support auth, webhooks or anomaly screening")
+		}
+	default:
+		return fmt.Errorf("unsupported database driver: %s", c.Database.Driver)
+	}
+	switch c.Cache.Mode {
+	case "", CacheModeMemory:
+	case CacheModeRedis, CacheModeTiered:
+		if !c.Redis.Enabled() {
+			return fmt.Errorf("cache mode %s requires a redis host", c.Cache.Mode)
+		}
+	default:
+		return fmt.Errorf("unsupported cache mode: %s", c.Cache.Mode)
 	}
-	if c.Database.User == "" {
-		return fmt.Errorf("database user is required")
+	if c.Cache.TTLJitter < 0 || c.Cache.TTLJitter >= 1 {
+		return fmt.Errorf("cache ttl jitter must be in [0, 1): %v", c.Cache.TTLJitter)
+	}
+	if c.Cache.FetchInterval < 0 || c.Cache.HistoricalMaxAge < 0 {
+		return fmt.Errorf("cache fetch interval and historical max age must not be negative")
+	}
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Default != "" {
+			if _, _, err := ParseRateLimit(c.RateLimit.Default); err != nil {
+				return err
+			}
+		}
+		for route, limit := range c.RateLimit.Routes {
+			if _, _, err := ParseRateLimit(limit); err != nil {
+				return fmt.Errorf("route %s: %w", route, err)
+			}
+		}
 	}
-	if c.Database.Database == "" {
-		return fmt.Errorf("database name is required")
+	if c.Stream.Retention < 1 {
+		return fmt.Errorf("stream retention must be positive: %d", c.Stream.Retention)
+	}
+	if c.Stream.Heartbeat <= 0 {
+		return fmt.Errorf("stream heartbeat must be positive: %v", c.Stream.Heartbeat)
+	}
+	if c.Webhooks.Enabled {
+		// Webhooks make the server send requests to the URLs they name, so
+		// they are only managed by authenticated keys
+		if !c.Auth.Enabled {
+			return fmt.Errorf("webhooks require auth to be enabled for their management endpoints")
+		}
+		if c.Webhooks.PollInterval <= 0 || c.Webhooks.Timeout <= 0 {
+			return fmt.Errorf("webhook poll interval and timeout must be positive")
+		}
+		if c.Webhooks.BatchSize < 1 || c.Webhooks.MaxAttempts < 1 {
+			return fmt.Errorf("webhook batch size and max attempts must be positive")
+		}
+		if c.Webhooks.InitialBackoff <= 0 || c.Webhooks.MaxBackoff < c.Webhooks.InitialBackoff {
+			return fmt.Errorf("webhook backoff must be positive, with max backoff at least the initial backoff")
+		}
+	}
+	if c.Anomaly.Enabled {
+		// Quarantined rates are released through the admin endpoints, which
+		// the API only serves to authenticated keys
+		if !c.Auth.Enabled {
+			return fmt.Errorf("anomaly screening requires auth to be enabled for the review of quarantined rates")
+		}
+		if c.Anomaly.Lookback < 1 || c.Anomaly.MinHistory < 0 {
+			return fmt.Errorf("anomaly lookback must be positive and min history not negative")
+		}
+		if c.Anomaly.ZScore < 0 || c.Anomaly.MaxJumpPercent < 0 || c.Anomaly.ReciprocalTolerance < 0 {
+			return fmt.Errorf("anomaly limits must not be negative")
+		}
+		for pair, limit := range c.Anomaly.PairJumpPercent {
+			if limit < 0 {
+				return fmt.Errorf("anomaly jump limit of %s must not be negative: %v", pair, limit)
+			}
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be in [0, 1]: %v", c.Tracing.SampleRatio)
 	}
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
@@ -216,6 +702,23 @@ func (c *RedisConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
+// Enabled reports whether a Redis server is configured.
+func (c *RedisConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+// EffectiveMode returns the cache mode to use, resolving an empty Mode
+// according to whether Redis is configured.
+func (c *CacheConfig) EffectiveMode(redis RedisConfig) string {
+	if c.Mode != "" {
+		return c.Mode
+	}
+	if redis.Enabled() {
+		return CacheModeTiered
+	}
+	return CacheModeMemory
+}
+
 // IsDevelopment returns true if the environment is development.
 func (c *ServerConfig) IsDevelopment() bool {
 	return c.Environment == "dev" || c.Environment == "development"
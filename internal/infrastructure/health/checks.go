@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/provider"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// DatabaseCheck pings the database and reports its connection pool statistics.
+// An exhausted pool with waiting callers degrades the service.
+func DatabaseCheck(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) Result {
+		if err := db.PingContext(ctx); err != nil {
+			return Down(fmt.Sprintf("ping failed: %v", err))
+		}
+
+		stats := db.Stats()
+		details := map[string]any{
+			"maxOpen":      stats.MaxOpenConnections,
+			"open":         stats.OpenConnections,
+			"inUse":        stats.InUse,
+			"idle":         stats.Idle,
+			"waitCount":    stats.WaitCount,
+			"waitDuration": stats.WaitDuration.String(),
+		}
+
+		if stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections && stats.WaitCount > 0 {
+			return Degraded("connection pool exhausted").WithDetails(details)
+		}
+		return Up("").WithDetails(details)
+	}
+}
+
+// CacheCheck pings the cache.
+func CacheCheck(cache redis.CacheInterface) CheckFunc {
+	return func(ctx context.Context) Result {
+		if err := cache.Ping(ctx); err != nil {
+			return Down(fmt.Sprintf("ping failed: %v", err))
+		}
+		return Up("")
+	}
+}
+
+// FreshnessCheck compares the latest effective date of each pair, in either
+// direction, with the previous business day. Rates are published per business
+// day, so data older than the previous business day means fetching has stalled.
+// now returns the current time in the timezone rates are published in.
+func FreshnessCheck(repo rate.Repository, pairs []currency.Pair, now func() time.Time) CheckFunc {
+	return func(ctx context.Context) Result {
+		expected := timeutil.FormatDate(timeutil.PreviousBusinessDay(now()))
+
+		latest := make(map[string]string, len(pairs))
+		var stale []string
+		for _, pair := range pairs {
+			date, err := latestDate(ctx, repo, pair)
+			switch {
+			case errors.As(err, new(rate.ErrRateNotFound)):
+				latest[pair.String()] = ""
+				stale = append(stale, pair.String())
+			case err != nil:
+				return Down(fmt.Sprintf("query %s: %v", pair, err))
+			default:
+				latest[pair.String()] = date
+				if date < expected {
+					stale = append(stale, pair.String())
+				}
+			}
+		}
+
+		details := map[string]any{
+			"expected": expected,
+			"latest":   latest,
+		}
+		if len(stale) > 0 {
+			return Degraded("stale rates: " + strings.Join(stale, ", ")).WithDetails(details)
+		}
+		return Up("").WithDetails(details)
+	}
+}
+
+// latestDate returns the latest effective date stored for the pair or its inverse.
+func latestDate(ctx context.Context, repo rate.Repository, pair currency.Pair) (string, error) {
+	r, err := repo.FindLatest(ctx, pair)
+	if errors.As(err, new(rate.ErrRateNotFound)) {
+		r, err = repo.FindLatest(ctx, pair.Inverse())
+	}
+	if err != nil {
+		return "", err
+	}
+	return timeutil.FormatDate(r.EffectiveDate()), nil
+}
+
+// ProviderCheck reports whether the last fetch from the provider succeeded.
+// The worker records the outcomes, so the API learns about an unreachable
+// provider without calling it itself.
+func ProviderCheck(statuses provider.StatusRepository, providerName string) CheckFunc {
+	return func(ctx context.Context) Result {
+		status, err := statuses.FindStatus(ctx, providerName)
+		if errors.As(err, new(provider.ErrStatusNotFound)) {
+			return Degraded("no fetch recorded yet")
+		}
+		if err != nil {
+			return Down(fmt.Sprintf("read provider status: %v", err))
+		}
+
+		details := map[string]any{
+			"lastAttemptAt":       status.LastAttemptAt,
+			"consecutiveFailures": status.ConsecutiveFailures,
+		}
+		if status.LastSuccessAt != nil {
+			details["lastSuccessAt"] = *status.LastSuccessAt
+		}
+
+		if !status.Healthy() {
+			return Down("last fetch failed: " + status.LastError).WithDetails(details)
+		}
+		return Up("").WithDetails(details)
+	}
+}
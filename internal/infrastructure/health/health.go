@@ -0,0 +1,196 @@
+// Package health runs the dependency checks behind the readiness endpoint.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each check of a registry created without a timeout.
+const DefaultTimeout = 2 * time.Second
+
+// Status is the outcome of a check or of a whole report.
+type Status string
+
+// Check statuses, from best to worst.
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded" // working, but with reduced quality of service
+	StatusDown     Status = "down"
+)
+
+// Result is what a check returns.
+type Result struct {
+	Status  Status
+	Message string
+	Details map[string]any
+}
+
+// Up returns a passing result.
+func Up(message string) Result {
+	return Result{Status: StatusUp, Message: message}
+}
+
+// Degraded returns a result for a dependency that works with reduced quality.
+func Degraded(message string) Result {
+	return Result{Status: StatusDegraded, Message: message}
+}
+
+// Down returns a failing result.
+func Down(message string) Result {
+	return Result{Status: StatusDown, Message: message}
+}
+
+// WithDetails returns the result with additional details attached.
+func (r Result) WithDetails(details map[string]any) Result {
+	r.Details = details
+	return r
+}
+
+// CheckFunc checks one dependency.
+// It should return promptly when ctx is done.
+type CheckFunc func(ctx context.Context) Result
+
+// CheckReport is the result of one check in a report.
+type CheckReport struct {
+	Name      string         `json:"name"`
+	Status    Status         `json:"status"`
+	Critical  bool           `json:"critical"`
+	LatencyMs float64        `json:"latencyMs"`
+	Message   string         `json:"message,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// Report is the result of running all checks of a registry.
+type Report struct {
+	Status    Status        `json:"status"`
+	CheckedAt time.Time     `json:"checkedAt"`
+	Checks    []CheckReport `json:"checks"`
+}
+
+// Ready reports whether the service can take traffic, that is whether all
+// critical checks passed. Degraded dependencies do not make a service unready.
+func (r Report) Ready() bool {
+	return r.Status != StatusDown
+}
+
+type registration struct {
+	name     string
+	check    CheckFunc
+	critical bool
+}
+
+// Registry holds the checks of the service.
+//
+// A failing critical check makes the whole report down, so the service is
+// taken out of rotation. A failing non-critical check only degrades it: the
+// service keeps serving, e.g. from the database while the cache is down.
+type Registry struct {
+	mu      sync.RWMutex
+	checks  []registration
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewRegistry creates an empty registry.
+// Each check gets at most timeout to complete; a non-positive timeout selects DefaultTimeout.
+func NewRegistry(timeout time.Duration, logger *slog.Logger) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Registry{
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Register adds a check. Checks are reported in registration order.
+func (r *Registry) Register(name string, check CheckFunc, critical bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, registration{
+		name:     name,
+		check:    check,
+		critical: critical,
+	})
+}
+
+// Run runs all checks concurrently and aggregates their results.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := r.checks
+	r.mu.RUnlock()
+
+	reports := make([]CheckReport, len(checks))
+	var wg sync.WaitGroup
+	for i, reg := range checks {
+		wg.Go(func() {
+			reports[i] = r.run(ctx, reg)
+		})
+	}
+	wg.Wait()
+
+	status := StatusUp
+	for _, report := range reports {
+		switch {
+		case report.Status == StatusUp:
+		case report.Critical && report.Status == StatusDown:
+			status = StatusDown
+		case status == StatusUp:
+			status = StatusDegraded
+		}
+	}
+
+	return Report{
+		Status:    status,
+		CheckedAt: time.Now().UTC(),
+		Checks:    reports,
+	}
+}
+
+// run runs one check with the registry timeout.
+func (r *Registry) run(ctx context.Context, reg registration) CheckReport {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := r.call(ctx, reg)
+	latency := time.Since(start)
+
+	// A check that ignores ctx has still run out of time
+	if result.Status == StatusUp && ctx.Err() != nil {
+		result = Down("check timed out")
+	}
+
+	if result.Status != StatusUp {
+		r.logger.Warn("health check not passing",
+			"check", reg.name,
+			"status", result.Status,
+			"message", result.Message,
+		)
+	}
+
+	return CheckReport{
+		Name:      reg.name,
+		Status:    result.Status,
+		Critical:  reg.critical,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+		Message:   result.Message,
+		Details:   result.Details,
+	}
+}
+
+// call runs a check, turning a panic into a failing result.
+func (r *Registry) call(ctx context.Context, reg registration) (result Result) {
+	defer func() {
+		if p := recover(); p != nil {
+			r.logger.Error("health check panicked", "check", reg.name, "panic", p)
+			result = Down("check panicked")
+		}
+	}()
+	return reg.check(ctx)
+}
@@ -0,0 +1,159 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/provider"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/health"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+func check(result health.Result) health.CheckFunc {
+	return func(ctx context.Context) health.Result { return result }
+}
+
+func TestRegistry_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		critical health.Result
+		optional health.Result
+		want     health.Status
+		ready    bool
+	}{
+		{"all up", health.Up(""), health.Up(""), health.StatusUp, true},
+		{"optional down", health.Up(""), health.Down("redis down"), health.StatusDegraded, true},
+		{"optional degraded", health.Up(""), health.Degraded("stale"), health.StatusDegraded, true},
+		{"critical degraded", health.Degraded("pool exhausted"), health.Up(""), health.StatusDegraded, true},
+		{"critical down", health.Down("db down"), health.Up(""), health.StatusDown, false},
+		{"both down", health.Down("db down"), health.Down("redis down"), health.StatusDown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := health.NewRegistry(time.Second, logger.NewNoop())
+			registry.Register("database", check(tt.critical), true)
+			registry.Register("cache", check(tt.optional), false)
+
+			report := registry.Run(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("Status = %s, want %s", report.Status, tt.want)
+			}
+			if report.Ready() != tt.ready {
+				t.Errorf("Ready() = %v, want %v", report.Ready(), tt.ready)
+			}
+			if len(report.Checks) != 2 || report.Checks[0].Name != "database" || report.Checks[1].Name != "cache" {
+				t.Errorf("checks not reported in registration order: %+v", report.Checks)
+			}
+		})
+	}
+}
+
+func TestRegistry_TimesOutSlowChecks(t *testing.T) {
+	registry := health.NewRegistry(20*time.Millisecond, logger.NewNoop())
+	registry.Register("database", func(ctx context.Context) health.Result {
+		<-ctx.Done()
+		return health.Down(ctx.Err().Error())
+	}, true)
+	registry.Register("panics", func(ctx context.Context) health.Result {
+		panic("boom")
+	}, false)
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Run() took %v, want about the check timeout", elapsed)
+	}
+	if report.Status != health.StatusDown {
+		t.Errorf("Status = %s, want down", report.Status)
+	}
+	if got := report.Checks[1].Status; got != health.StatusDown {
+		t.Errorf("panicking check status = %s, want down", got)
+	}
+}
+
+func TestFreshnessCheck(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRateRepository(logger.NewNoop())
+	cnyJPY := currency.MustNewPair(currency.CNY, currency.JPY)
+	usdJPY := currency.MustNewPair(currency.USD, currency.JPY)
+
+	// Monday 2024-01-15; the previous business day is Friday 2024-01-12
+	monday := func() time.Time { return time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC) }
+
+	create := func(pair currency.Pair, date time.Time) {
+		t.Helper()
+		r, err := rate.NewRate(pair, 1.5, date, rate.SourceUnionPay)
+		if err != nil {
+			t.Fatalf("NewRate() error = %v", err)
+		}
+		if err := repo.Create(ctx, r); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// Stored as JPY/CNY, so the check has to look at the inverse pair
+	create(cnyJPY.Inverse(), time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC))
+	create(usdJPY, time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC))
+
+	result := health.FreshnessCheck(repo, []currency.Pair{cnyJPY}, monday)(ctx)
+	if result.Status != health.StatusUp {
+		t.Errorf("fresh pair: Status = %s (%s), want up", result.Status, result.Message)
+	}
+
+	result = health.FreshnessCheck(repo, []currency.Pair{cnyJPY, usdJPY}, monday)(ctx)
+	if result.Status != health.StatusDegraded || result.Message != "stale rates: USD/JPY" {
+		t.Errorf("stale pair: got %s %q, want degraded for USD/JPY", result.Status, result.Message)
+	}
+
+	missing := currency.MustNewPair(currency.EUR, currency.GBP)
+	result = health.FreshnessCheck(repo, []currency.Pair{missing}, monday)(ctx)
+	if result.Status != health.StatusDegraded {
+		t.Errorf("missing pair: Status = %s, want degraded", result.Status)
+	}
+}
+
+// statusStore is an in-memory provider.StatusRepository.
+type statusStore map[string]*provider.Status
+
+func (s statusStore) Record(ctx context.Context, name string, at time.Time, fetchErr error) error {
+	return errors.New("not implemented")
+}
+
+func (s statusStore) FindStatus(ctx context.Context, name string) (*provider.Status, error) {
+	if status, ok := s[name]; ok {
+		return status, nil
+	}
+	return nil, provider.ErrStatusNotFound{Provider: name}
+}
+
+func TestProviderCheck(t *testing.T) {
+	now := time.Now()
+	store := statusStore{
+		"ok":      {Provider: "ok", LastAttemptAt: now, LastSuccessAt: &now},
+		"failing": {Provider: "failing", LastAttemptAt: now, LastError: "connection refused", ConsecutiveFailures: 3},
+	}
+
+	tests := []struct {
+		provider string
+		want     health.Status
+	}{
+		{"ok", health.StatusUp},
+		{"failing", health.StatusDown},
+		{"unknown", health.StatusDegraded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			result := health.ProviderCheck(store, tt.provider)(context.Background())
+			if result.Status != tt.want {
+				t.Errorf("Status = %s (%s), want %s", result.Status, result.Message, tt.want)
+			}
+		})
+	}
+}
@@ -34,7 +34,7 @@ func New(cfg config.LoggerConfig) *slog.Logger {
 		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
 
-	return slog.New(handler)
+	return slog.New(contextHandler{handler})
 }
 
 // WithContext adds common context fields to a logger.
@@ -0,0 +1,96 @@
+// Package metrics defines the Prometheus metrics of RateFlow.
+//
+// The metrics are registered with the default Prometheus registry, which also
+// carries the Go runtime and process metrics. The API serves them at /metrics;
+// batch runs of the worker push them to a Pushgateway.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "rateflow"
+
+// Cache lookup results.
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheStale = "stale" // expired entry served while it is refreshed
+)
+
+var (
+	// HTTPRequestDuration observes served HTTP requests by route template and status.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// CacheRequests counts cache lookups of query handlers by result.
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Cache lookups of query handlers by query and result (hit, miss, stale).",
+	}, []string{"query", "result"})
+
+	// RepositoryQueryDuration observes database statements by operation and table.
+	RepositoryQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database statements by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// ProviderFetchDuration observes fetches from rate providers.
+	ProviderFetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "fetch_duration_seconds",
+		Help:      "Duration of fetches from rate providers by provider, operation and outcome.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"provider", "operation", "outcome"})
+
+	// ProviderErrors counts failed provider fetches by provider error kind.
+	ProviderErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "errors_total",
+		Help:      "Failed fetches from rate providers by provider and error kind.",
+	}, []string{"provider", "kind"})
+
+	// HTTPClientRetries counts retried outgoing HTTP requests by host.
+	HTTPClientRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http_client",
+		Name:      "retries_total",
+		Help:      "Retries of outgoing HTTP requests by host.",
+	}, []string{"host"})
+
+	// WebhookDeliveries counts webhook delivery attempts by outcome.
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "deliveries_total",
+		Help:      "Webhook delivery attempts by outcome (succeeded, retrying, failed).",
+	}, []string{"outcome"})
+
+	// SuspectRates counts rates from providers quarantined for review.
+	SuspectRates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "anomaly",
+		Name:      "suspect_rates_total",
+		Help:      "Rates from providers quarantined for review by pair and anomaly kind.",
+	}, []string{"pair", "kind"})
+)
+
+// CountRetry counts a retried outgoing request. It is an httputil.RetryHook.
+func CountRetry(req *http.Request, attempt int, err error) {
+	HTTPClientRetries.WithLabelValues(req.URL.Host).Inc()
+}
@@ -0,0 +1,106 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/provider"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+// stubProvider fails every fetch with err, or succeeds when err is nil.
+type stubProvider struct {
+	provider.Provider
+	err error
+}
+
+func (p *stubProvider) Name() string {
+	return "stub"
+}
+
+func (p *stubProvider) FetchRate(ctx context.Context, pair currency.Pair, date time.Time) (float64, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	return 20.5, nil
+}
+
+func TestInstrumentProvider(t *testing.T) {
+	ctx := context.Background()
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+
+	noData := provider.NewProviderErrorKind("stub", provider.ErrorKindNoData, "holiday", nil)
+	tests := []struct {
+		name string
+		err  error
+		kind provider.ErrorKind
+	}{
+		{"provider error", noData, provider.ErrorKindNoData},
+		{"wrapped provider error", errors.Join(errors.New("fetch"), noData), provider.ErrorKindNoData},
+		{"other error", context.DeadlineExceeded, provider.ErrorKindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := metrics.ProviderErrors.WithLabelValues("stub", string(tt.kind))
+			before := testutil.ToFloat64(errs)
+
+			p := metrics.InstrumentProvider(&stubProvider{err: tt.err})
+			if _, err := p.FetchRate(ctx, pair, time.Now()); !errors.Is(err, tt.err) {
+				t.Fatalf("FetchRate() error = %v, want %v", err, tt.err)
+			}
+
+			if got := testutil.ToFloat64(errs) - before; got != 1 {
+				t.Errorf("errors of kind %s increased by %v, want 1", tt.kind, got)
+			}
+		})
+	}
+
+	p := metrics.InstrumentProvider(&stubProvider{})
+	if _, err := p.FetchRate(ctx, pair, time.Now()); err != nil {
+		t.Fatalf("FetchRate() error = %v", err)
+	}
+	if n := testutil.CollectAndCount(metrics.ProviderFetchDuration, "rateflow_provider_fetch_duration_seconds"); n != 2 {
+		t.Errorf("fetch duration series = %d, want 2 (success and error)", n)
+	}
+}
+
+func TestRateAgeCollector(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRateRepository(logger.NewNoop())
+
+	cnyJPY := currency.MustNewPair(currency.CNY, currency.JPY)
+	r, err := rate.NewRate(cnyJPY.Inverse(), 0.05, time.Now().AddDate(0, 0, -2), rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	if err := repo.Create(ctx, r); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	missing := currency.MustNewPair(currency.EUR, currency.GBP)
+	collector := metrics.NewRateAgeCollector(repo, []currency.Pair{cnyJPY, missing}, logger.NewNoop())
+
+	// Only the stored pair is reported, found through its inverse
+	if n := testutil.CollectAndCount(collector); n != 1 {
+		t.Fatalf("collected %d series, want 1", n)
+	}
+
+	problems, err := testutil.CollectAndLint(collector)
+	if err != nil || len(problems) > 0 {
+		t.Errorf("lint: %v %v", problems, err)
+	}
+
+	age := testutil.ToFloat64(collector)
+	if min, max := (2 * 24 * time.Hour).Seconds(), (3 * 24 * time.Hour).Seconds(); age < min || age > max {
+		t.Errorf("age = %vs, want between 2 and 3 days", age)
+	}
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/provider"
+)
+
+// instrumentedProvider records the duration and errors of provider fetches.
+type instrumentedProvider struct {
+	provider.Provider
+}
+
+// InstrumentProvider wraps p so that its fetches are observed in
+// ProviderFetchDuration and their errors counted in ProviderErrors.
+func InstrumentProvider(p provider.Provider) provider.Provider {
+	return &instrumentedProvider{Provider: p}
+}
+
+// FetchRate implements provider.Provider.
+func (p *instrumentedProvider) FetchRate(ctx context.Context, pair currency.Pair, date time.Time) (float64, error) {
+	start := time.Now()
+	rate, err := p.Provider.FetchRate(ctx, pair, date)
+	p.observe("fetch_rate", start, err)
+	return rate, err
+}
+
+// FetchLatest implements provider.Provider.
+func (p *instrumentedProvider) FetchLatest(ctx context.Context, pair currency.Pair) (float64, error) {
+	start := time.Now()
+	rate, err := p.Provider.FetchLatest(ctx, pair)
+	p.observe("fetch_latest", start, err)
+	return rate, err
+}
+
+// FetchMulti implements provider.Provider.
+func (p *instrumentedProvider) FetchMulti(ctx context.Context, pairs []currency.Pair, date time.Time) (map[string]float64, error) {
+	start := time.Now()
+	rates, err := p.Provider.FetchMulti(ctx, pairs, date)
+	p.observe("fetch_multi", start, err)
+	return rates, err
+}
+
+func (p *instrumentedProvider) observe(operation string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		ProviderErrors.WithLabelValues(p.Name(), string(provider.KindOf(err))).Inc()
+	}
+	ProviderFetchDuration.WithLabelValues(p.Name(), operation, outcome).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// Push sends all metrics of the default registry to a Pushgateway under the
+// given job name. Batch runs that end before they could be scraped use it.
+func Push(ctx context.Context, url, job string) error {
+	return push.New(url, job).
+		Gatherer(prometheus.DefaultGatherer).
+		PushContext(ctx)
+}
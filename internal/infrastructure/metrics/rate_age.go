@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// rateAgeTimeout bounds the queries of one scrape of RateAgeCollector.
+const rateAgeTimeout = 5 * time.Second
+
+var rateAgeDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "latest_rate_age_seconds"),
+	"Time since the effective date of the latest stored rate of a pair, in either direction.",
+	[]string{"pair"}, nil,
+)
+
+// RateAgeCollector reports the age of the latest rate of each pair when scraped,
+// so alerts on stale data do not depend on a process that last saw a fetch.
+type RateAgeCollector struct {
+	repo   rate.Repository
+	pairs  []currency.Pair
+	logger *slog.Logger
+}
+
+// Ensure RateAgeCollector implements prometheus.Collector
+var _ prometheus.Collector = (*RateAgeCollector)(nil)
+
+// NewRateAgeCollector creates a collector for the given pairs.
+func NewRateAgeCollector(repo rate.Repository, pairs []currency.Pair, logger *slog.Logger) *RateAgeCollector {
+	return &RateAgeCollector{
+		repo:   repo,
+		pairs:  pairs,
+		logger: logger,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *RateAgeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rateAgeDesc
+}
+
+// Collect implements prometheus.Collector.
+// Pairs without any stored rate are left out.
+func (c *RateAgeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), rateAgeTimeout)
+	defer cancel()
+
+	now := time.Now()
+	for _, pair := range c.pairs {
+		r, err := c.repo.FindLatest(ctx, pair)
+		if errors.As(err, new(rate.ErrRateNotFound)) {
+			r, err = c.repo.FindLatest(ctx, pair.Inverse())
+		}
+		if err != nil {
+			if !errors.As(err, new(rate.ErrRateNotFound)) {
+				c.logger.Warn("failed to collect latest rate age", "pair", pair.String(), "error", err)
+			}
+			continue
+		}
+
+		age := now.Sub(r.EffectiveDate()).Seconds()
+		ch <- prometheus.MustNewConstMetric(rateAgeDesc, prometheus.GaugeValue, age, pair.String())
+	}
+}
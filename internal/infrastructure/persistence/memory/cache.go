@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+)
+
+// DefaultMaxEntries is the capacity of a cache created with a non-positive size.
+const DefaultMaxEntries = 10000
+
+// cacheEntry is a JSON-encoded value with an optional expiry time.
+type cacheEntry struct {
+	key       string
+	data      []byte
+	expiresAt time.Time // zero means no expiry
+}
+
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Cache is an in-process LRU cache with per-key TTLs.
+// Values are stored as JSON, like in Redis, so callers see the same copy
+// semantics. When the cache is full, the least recently used key is evicted;
+// expired keys are removed when they are next accessed.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+	logger     *slog.Logger
+}
+
+// Ensure Cache implements redis.CacheInterface
+var _ redis.CacheInterface = (*Cache)(nil)
+
+// NewCache creates a new in-process cache holding at most maxEntries keys.
+func NewCache(maxEntries int, logger *slog.Logger) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	logger.Info("in-process cache initialized", "max_entries", maxEntries)
+
+	return &Cache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		logger:     logger,
+	}
+}
+
+// Get retrieves a value from the cache.
+func (c *Cache) Get(ctx context.Context, key string, dest any) error {
+	c.mu.Lock()
+	entry, ok := c.lookup(key, time.Now())
+	var data []byte
+	if ok {
+		c.order.MoveToFront(c.entries[key])
+		data = entry.data
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("key not found: %s", key)
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		c.logger.Error("cache unmarshal error", "key", key, "error", err)
+		return err
+	}
+
+	c.logger.Debug("cache hit", "key", key)
+	return nil
+}
+
+// Set stores a value in the cache with TTL. A zero TTL means no expiry.
+func (c *Cache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Error("cache marshal error", "key", key, "error", err)
+		return err
+	}
+
+	entry := &cacheEntry{key: key, data: data}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.order.PushFront(entry)
+		c.evict()
+	}
+	c.mu.Unlock()
+
+	c.logger.Debug("cache set", "key", key, "ttl", ttl)
+	return nil
+}
+
+// Delete removes one or more keys from the cache.
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	for _, key := range keys {
+		c.remove(key)
+	}
+	c.mu.Unlock()
+
+	c.logger.Debug("cache delete", "keys", keys)
+	return nil
+}
+
+// Exists returns how many of the given keys exist in the cache.
+func (c *Cache) Exists(ctx context.Context, keys ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var count int64
+	for _, key := range keys {
+		if _, ok := c.lookup(key, now); ok {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Expire sets a timeout on a key. Missing keys are ignored, as in Redis.
+func (c *Cache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := c.lookup(key, now); ok {
+		entry.expiresAt = now.Add(ttl)
+	}
+	return nil
+}
+
+// Ping always succeeds for the in-process cache.
+func (c *Cache) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close drops all cached values.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	c.order.Init()
+	clear(c.entries)
+	c.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of keys currently held, including expired ones
+// that have not been accessed since they expired.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// lookup returns a live entry and removes it if it has expired.
+// Callers must hold c.mu.
+func (c *Cache) lookup(key string, now time.Time) (*cacheEntry, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if entry.expired(now) {
+		c.remove(key)
+		return nil, false
+	}
+	return entry, true
+}
+
+// remove deletes a key. Callers must hold c.mu.
+func (c *Cache) remove(key string) {
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// evict removes least recently used keys until the cache fits its capacity.
+// Callers must hold c.mu.
+func (c *Cache) evict() {
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.remove(oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,92 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+func TestCache_GetSet(t *testing.T) {
+	ctx := context.Background()
+	cache := memory.NewCache(10, logger.NewNoop())
+
+	type value struct {
+		Rate float64 `json:"rate"`
+	}
+
+	if err := cache.Set(ctx, "latest:CNY/JPY", value{Rate: 20.5}, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var got value
+	if err := cache.Get(ctx, "latest:CNY/JPY", &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Rate != 20.5 {
+		t.Errorf("Get() = %v, want 20.5", got.Rate)
+	}
+
+	if err := cache.Get(ctx, "latest:USD/JPY", &got); err == nil {
+		t.Error("Get() for missing key error = nil, want error")
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	cache := memory.NewCache(10, logger.NewNoop())
+
+	if err := cache.Set(ctx, "short", 1, 10*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := cache.Set(ctx, "forever", 2, 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if n, _ := cache.Exists(ctx, "short", "forever"); n != 1 {
+		t.Errorf("Exists() = %d, want 1", n)
+	}
+
+	var got int
+	if err := cache.Get(ctx, "short", &got); err == nil {
+		t.Error("Get() for expired key error = nil, want error")
+	}
+
+	if err := cache.Expire(ctx, "forever", 10*time.Millisecond); err != nil {
+		t.Fatalf("Expire() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n, _ := cache.Exists(ctx, "forever"); n != 0 {
+		t.Errorf("Exists() after Expire = %d, want 0", n)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := memory.NewCache(2, logger.NewNoop())
+
+	_ = cache.Set(ctx, "a", 1, 0)
+	_ = cache.Set(ctx, "b", 2, 0)
+
+	// Touch "a" so that "b" becomes the least recently used key
+	var got int
+	if err := cache.Get(ctx, "a", &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	_ = cache.Set(ctx, "c", 3, 0)
+
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+	if n, _ := cache.Exists(ctx, "a", "c"); n != 2 {
+		t.Errorf("Exists(a, c) = %d, want 2", n)
+	}
+	if n, _ := cache.Exists(ctx, "b"); n != 0 {
+		t.Errorf("Exists(b) = %d, want 0 (evicted)", n)
+	}
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+)
+
+// RateEvents is an in-process rate.EventLog. Events only reach subscribers of
+// the same process, so it suits single-process deployments and tests.
+type RateEvents struct {
+	mu          sync.Mutex
+	events      []rate.Event // the most recent events, oldest first
+	retention   int
+	lastID      int64
+	subscribers map[chan rate.Event]struct{}
+	logger      *slog.Logger
+}
+
+// Ensure RateEvents implements rate.EventLog
+var _ rate.EventLog = (*RateEvents)(nil)
+
+// NewRateEvents creates an event log retaining retention events.
+func NewRateEvents(retention int, logger *slog.Logger) *RateEvents {
+	return &RateEvents{
+		retention:   max(retention, 1),
+		subscribers: make(map[chan rate.Event]struct{}),
+		logger:      logger,
+	}
+}
+
+// Publish implements rate.EventLog. Subscribers that do not keep up miss
+// events rather than blocking the publisher.
+func (e *RateEvents) Publish(ctx context.Context, event rate.Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastID++
+	event.ID = strconv.FormatInt(e.lastID, 10)
+	e.events = append(e.events, event)
+	if len(e.events) > e.retention {
+		e.events = e.events[len(e.events)-e.retention:]
+	}
+
+	for ch := range e.subscribers {
+		select {
+		case ch <- event:
+		default:
+			e.logger.Warn("rate event subscriber too slow, event dropped", "id", event.ID)
+		}
+	}
+	return nil
+}
+
+// After implements rate.EventLog.
+func (e *RateEvents) After(ctx context.Context, id string) ([]rate.Event, error) {
+	after, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || after < 0 {
+		return nil, rate.ErrInvalidEventID{ID: id}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var events []rate.Event
+	for _, event := range e.events {
+		if n, _ := strconv.ParseInt(event.ID, 10, 64); n > after {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// Subscribe implements rate.EventLog.
+func (e *RateEvents) Subscribe(ctx context.Context) (<-chan rate.Event, error) {
+	ch := make(chan rate.Event, 16)
+
+	e.mu.Lock()
+	e.subscribers[ch] = struct{}{}
+	e.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		e.mu.Lock()
+		delete(e.subscribers, ch)
+		close(ch)
+		e.mu.Unlock()
+	}()
+	return ch, nil
+}
+
+// Close implements rate.EventLog.
+func (e *RateEvents) Close() error {
+	return nil
+}
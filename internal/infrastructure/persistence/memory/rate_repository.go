@@ -0,0 +1,701 @@
+// Package memory provides in-memory implementations of the domain repositories
+// for unit tests, demos and embedded deployments without a database.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/pkg/genericrepo"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+const (
+	// valuePrecision matches the precision of the Postgres value columns,
+	// so that both implementations agree on when a value has changed.
+	valuePrecision = 1e-10
+
+	// defaultStreamBatchSize is the number of rates Stream reads per lock acquisition.
+	defaultStreamBatchSize = 100
+)
+
+// rateFields is the allow-list of filterable rate fields.
+// Columns are not used in memory but keep the same names as in Postgres.
+var rateFields = genericrepo.Fields{
+	rate.FieldID:            "id",
+	rate.FieldBaseCurrency:  "base_currency",
+	rate.FieldQuoteCurrency: "quote_currency",
+	rate.FieldValue:         "value",
+	rate.FieldEffectiveDate: "effective_date",
+	rate.FieldSource:        "source",
+	rate.FieldCreatedAt:     "created_at",
+	rate.FieldUpdatedAt:     "updated_at",
+}
+
+// record is the stored form of a rate.
+type record struct {
+	id            string
+	pair          currency.Pair
+	value         float64
+	effectiveDate time.Time
+	source        rate.Source
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+// revision is a stored revision with its transaction-time validity.
+type revision struct {
+	rate.Revision
+	validFrom time.Time
+	validTo   *time.Time
+}
+
+// RateRepository implements rate.Repository in memory.
+// It is safe for concurrent use and mirrors the behaviour of the
+// Postgres implementation, including upserts, revisions and as-of reads.
+type RateRepository struct {
+	mu        sync.RWMutex
+	rates     map[string]*record
+	revisions map[string][]*revision // by rate ID, oldest first
+	logger    *slog.Logger
+}
+
+// NewRateRepository creates a new, empty in-memory rate repository.
+func NewRateRepository(logger *slog.Logger) rate.Repository {
+	return &RateRepository{
+		rates:     make(map[string]*record),
+		revisions: make(map[string][]*revision),
+		logger:    logger,
+	}
+}
+
+// Create stores a new rate.
+// If a rate with the same (base, quote, date, source) exists, it updates the existing rate.
+// Every value change is recorded as a revision with the change information carried by ctx.
+func (r *RateRepository) Create(ctx context.Context, entity *rate.Rate) error {
+	rec := toRecord(entity)
+	info := rate.ChangeInfoFromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.rates {
+		if existing.pair == rec.pair &&
+			existing.effectiveDate.Equal(rec.effectiveDate) &&
+			existing.source == rec.source {
+			if sameValue(existing.value, rec.value) {
+				return nil
+			}
+
+			oldValue := existing.value
+			existing.value = rec.value
+			existing.updatedAt = time.Now()
+			r.appendRevision(existing.id, &oldValue, rec.value, rec.source, info)
+			return nil
+		}
+	}
+
+	if rec.id == "" {
+		rec.id = uuid.New().String()
+	}
+	r.rates[rec.id] = rec
+	r.appendRevision(rec.id, nil, rec.value, rec.source, info)
+
+	return nil
+}
+
+// FindByID retrieves a rate by its ID.
+func (r *RateRepository) FindByID(ctx context.Context, id string) (*rate.Rate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rec, ok := r.rates[id]
+	if !ok {
+		return nil, rate.ErrRateNotFound{ID: id}
+	}
+
+	return rec.toDomain(), nil
+}
+
+// Update modifies an existing rate.
+// A revision is recorded when the stored value changes.
+func (r *RateRepository) Update(ctx context.Context, entity *rate.Rate) error {
+	rec := toRecord(entity)
+	info := rate.ChangeInfoFromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.rates[rec.id]
+	if !ok {
+		return rate.ErrRateNotFound{ID: rec.id}
+	}
+
+	oldValue := existing.value
+	r.rates[rec.id] = rec
+
+	if !sameValue(oldValue, rec.value) {
+		r.appendRevision(rec.id, &oldValue, rec.value, rec.source, info)
+	}
+
+	return nil
+}
+
+// Delete removes a rate by its ID, together with its revisions.
+// Deleting a missing rate is not an error.
+func (r *RateRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.rates, id)
+	delete(r.revisions, id)
+	return nil
+}
+
+// FindAll retrieves rates with optional filtering, ordering and pagination.
+// With a cursor the results continue after that position in
+// (effective_date, id) order instead of using the offset.
+func (r *RateRepository) FindAll(ctx context.Context, opts ...genericrepo.QueryOption) ([]*rate.Rate, error) {
+	cfg := genericrepo.BuildQueryConfig(opts...)
+
+	r.mu.RLock()
+	records, err := r.query(cfg)
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply ordering
+	if cfg.Cursor != nil {
+		records = keyset(records, cfg.Cursor, isDescending(cfg.OrderBy))
+	} else if cfg.OrderBy != "" {
+		terms, err := rateFields.Order(cfg.OrderBy)
+		if err != nil {
+			return nil, err
+		}
+		slices.SortStableFunc(records, func(a, b *record) int {
+			return compareBy(terms, a, b)
+		})
+	}
+
+	// Apply pagination
+	if cfg.Offset > 0 && cfg.Cursor == nil {
+		records = records[min(cfg.Offset, len(records)):]
+	}
+	if cfg.Limit > 0 && len(records) > cfg.Limit {
+		records = records[:cfg.Limit]
+	}
+
+	return toDomainSlice(records), nil
+}
+
+// Count returns the total number of rates matching the criteria.
+func (r *RateRepository) Count(ctx context.Context, opts ...genericrepo.QueryOption) (int64, error) {
+	cfg := genericrepo.BuildQueryConfig(opts...)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records, err := r.query(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(records)), nil
+}
+
+// Stream returns an iterator for memory-efficient traversal.
+// Errors are logged and skipped.
+func (r *RateRepository) Stream(ctx context.Context, opts ...genericrepo.QueryOption) iter.Seq[*rate.Rate] {
+	return func(yield func(*rate.Rate) bool) {
+		for domainRate, err := range r.StreamWithError(ctx, opts...) {
+			if err != nil {
+				r.logger.Error("stream error", "error", err)
+				continue
+			}
+
+			if !yield(domainRate) {
+				return // Early termination
+			}
+		}
+	}
+}
+
+// StreamWithError returns an iterator that also yields errors.
+//
+// The matching rates are copied and sorted by (effective_date, id) once, when
+// iteration starts, and then yielded in batches of BatchSize, checking the
+// context between batches; rates written while iterating are not seen. The
+// order is ascending unless OrderBy is "effective_date DESC".
+func (r *RateRepository) StreamWithError(ctx context.Context, opts ...genericrepo.QueryOption) iter.Seq2[*rate.Rate, error] {
+	return func(yield func(*rate.Rate, error) bool) {
+		cfg := genericrepo.BuildQueryConfig(opts...)
+
+		batchSize := cfg.BatchSize
+		if batchSize <= 0 {
+			batchSize = defaultStreamBatchSize
+		}
+
+		if err := ctx.Err(); err != nil {
+			var zero *rate.Rate
+			yield(zero, err)
+			return
+		}
+
+		r.mu.RLock()
+		records, err := r.query(cfg)
+		r.mu.RUnlock()
+		if err != nil {
+			var zero *rate.Rate
+			yield(zero, err)
+			return
+		}
+		records = keyset(records, cfg.Cursor, isDescending(cfg.OrderBy))
+
+		for batch := range slices.Chunk(records, batchSize) {
+			if err := ctx.Err(); err != nil {
+				var zero *rate.Rate
+				yield(zero, err)
+				return
+			}
+
+			for _, rec := range batch {
+				if !yield(rec.toDomain(), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Exists checks if a rate with the given ID exists.
+func (r *RateRepository) Exists(ctx context.Context, id string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.rates[id]
+	return ok, nil
+}
+
+// FindByPairAndDate finds a rate for a specific currency pair and date.
+func (r *RateRepository) FindByPairAndDate(ctx context.Context, pair currency.Pair, date time.Time) (*rate.Rate, error) {
+	return r.findByPairAndDate(pair, date, nil)
+}
+
+// FindByPairAndDateAsOf finds a rate for a pair and date as it was known at asOf.
+func (r *RateRepository) FindByPairAndDateAsOf(ctx context.Context, pair currency.Pair, date, asOf time.Time) (*rate.Rate, error) {
+	return r.findByPairAndDate(pair, date, &asOf)
+}
+
+func (r *RateRepository) findByPairAndDate(pair currency.Pair, date time.Time, asOf *time.Time) (*rate.Rate, error) {
+	day := dateOf(date)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := r.snapshot(asOf, func(rec *record) bool {
+		return rec.pair == pair && rec.effectiveDate.Equal(day)
+	})
+	if len(records) == 0 {
+		return nil, rate.ErrRateNotFound{}
+	}
+
+	// Several sources may report the same day; pick one deterministically
+	slices.SortFunc(records, func(a, b *record) int { return cmp.Compare(a.id, b.id) })
+	return records[0].toDomain(), nil
+}
+
+// FindLatest finds the most recent rate for a currency pair.
+func (r *RateRepository) FindLatest(ctx context.Context, pair currency.Pair) (*rate.Rate, error) {
+	return r.findLatest(pair, nil)
+}
+
+// FindLatestAsOf finds the most recent rate for a pair as it was known at asOf.
+func (r *RateRepository) FindLatestAsOf(ctx context.Context, pair currency.Pair, asOf time.Time) (*rate.Rate, error) {
+	return r.findLatest(pair, &asOf)
+}
+
+func (r *RateRepository) findLatest(pair currency.Pair, asOf *time.Time) (*rate.Rate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := r.snapshot(asOf, func(rec *record) bool { return rec.pair == pair })
+	if len(records) == 0 {
+		return nil, rate.ErrRateNotFound{}
+	}
+
+	sortByDate(records, true)
+	return records[0].toDomain(), nil
+}
+
+// FindByDateRange finds rates for a currency pair within a date range, newest first.
+func (r *RateRepository) FindByDateRange(ctx context.Context, pair currency.Pair, start, end time.Time) ([]*rate.Rate, error) {
+	return r.findByDateRange(pair, start, end, nil)
+}
+
+// FindByDateRangeAsOf finds rates for a pair within a date range as they were known at asOf.
+func (r *RateRepository) FindByDateRangeAsOf(ctx context.Context, pair currency.Pair, start, end, asOf time.Time) ([]*rate.Rate, error) {
+	return r.findByDateRange(pair, start, end, &asOf)
+}
+
+func (r *RateRepository) findByDateRange(pair currency.Pair, start, end time.Time, asOf *time.Time) ([]*rate.Rate, error) {
+	startDay, endDay := dateOf(start), dateOf(end)
+
+	r.mu.RLock()
+	records := r.snapshot(asOf, func(rec *record) bool {
+		return rec.pair == pair &&
+			!rec.effectiveDate.Before(startDay) &&
+			!rec.effectiveDate.After(endDay)
+	})
+	r.mu.RUnlock()
+
+	sortByDate(records, true)
+	return toDomainSlice(records), nil
+}
+
+// FindByPairs finds the latest rates for multiple currency pairs.
+// Pairs without rates are skipped.
+func (r *RateRepository) FindByPairs(ctx context.Context, pairs []currency.Pair) ([]*rate.Rate, error) {
+	rates := make([]*rate.Rate, 0, len(pairs))
+
+	for _, pair := range pairs {
+		latestRate, err := r.FindLatest(ctx, pair)
+		if err != nil {
+			r.logger.Warn("failed to find rate for pair",
+				"pair", pair.String(),
+				"error", err,
+			)
+			continue
+		}
+		rates = append(rates, latestRate)
+	}
+
+	return rates, nil
+}
+
+// ExistsByPairAndDate checks if a rate exists for a specific pair and date.
+func (r *RateRepository) ExistsByPairAndDate(ctx context.Context, pair currency.Pair, date time.Time) (bool, error) {
+	day := dateOf(date)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rec := range r.rates {
+		if rec.pair == pair && rec.effectiveDate.Equal(day) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// DeleteOlderThan deletes rates older than the specified date, together with
+// their revisions.
+func (r *RateRepository) DeleteOlderThan(ctx context.Context, date time.Time) (int64, error) {
+	day := dateOf(date)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, rec := range r.rates {
+		if rec.effectiveDate.Before(day) {
+			delete(r.rates, id)
+			delete(r.revisions, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// FindRevisions returns the value history of a rate, oldest first.
+func (r *RateRepository) FindRevisions(ctx context.Context, rateID string) ([]*rate.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.revisions[rateID]
+	revisions := make([]*rate.Revision, 0, len(stored))
+	for _, rev := range stored {
+		copied := rev.Revision
+		revisions = append(revisions, &copied)
+	}
+
+	return revisions, nil
+}
+
+// Aggregate returns the aggregates of the matching rates per period of
+// interval, oldest first.
+func (r *RateRepository) Aggregate(ctx context.Context, interval rate.Interval, opts ...genericrepo.QueryOption) ([]*rate.Period, error) {
+	if _, err := rate.ParseInterval(string(interval)); err != nil {
+		return nil, err
+	}
+	cfg := genericrepo.BuildQueryConfig(opts...)
+
+	r.mu.RLock()
+	records, err := r.query(cfg)
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	sortByDate(records, false)
+
+	var periods []*rate.Period
+	var sum, inverseSum float64
+	for _, rec := range records {
+		start := interval.Truncate(dateOf(rec.effectiveDate))
+
+		p := lastPeriod(periods)
+		if p == nil || !p.Start.Equal(start) {
+			sum, inverseSum = 0, 0
+			p = &rate.Period{
+				Start: start,
+				End:   interval.Last(start),
+				Open:  rec.value,
+				High:  rec.value,
+				Low:   rec.value,
+			}
+			periods = append(periods, p)
+		}
+
+		p.High = max(p.High, rec.value)
+		p.Low = min(p.Low, rec.value)
+		p.Close = rec.value
+		p.CloseDate = dateOf(rec.effectiveDate)
+		p.Count++
+		sum += rec.value
+		inverseSum += 1 / rec.value
+		p.Average = sum / float64(p.Count)
+		p.InverseAverage = inverseSum / float64(p.Count)
+	}
+
+	return periods, nil
+}
+
+// lastPeriod returns the last of periods, or nil if there is none.
+func lastPeriod(periods []*rate.Period) *rate.Period {
+	if len(periods) == 0 {
+		return nil
+	}
+	return periods[len(periods)-1]
+}
+
+// query returns copies of the rates visible at cfg.AsOf that match the
+// query's filter conditions, in unspecified order. Callers must hold r.mu.
+func (r *RateRepository) query(cfg *genericrepo.QueryConfig) ([]*record, error) {
+	condition := cfg.Condition()
+
+	var matchErr error
+	records := r.snapshot(cfg.AsOf, func(rec *record) bool {
+		if matchErr != nil {
+			return false
+		}
+		ok, err := rateFields.Match(condition, rec.field)
+		if err != nil {
+			matchErr = err
+		}
+		return ok
+	})
+	if matchErr != nil {
+		return nil, matchErr
+	}
+
+	return records, nil
+}
+
+// snapshot returns copies of the rates accepted by keep, as they were known
+// at asOf. With a nil asOf the current values are returned; otherwise each
+// rate carries the value of the revision valid at asOf, and rates first
+// recorded after asOf are absent. The filter sees the as-of values.
+// Callers must hold r.mu.
+func (r *RateRepository) snapshot(asOf *time.Time, keep func(*record) bool) []*record {
+	records := make([]*record, 0, len(r.rates))
+
+	for _, rec := range r.rates {
+		copied := *rec
+
+		if asOf != nil {
+			rev := r.revisionAt(rec.id, *asOf)
+			if rev == nil {
+				continue
+			}
+			copied.value = rev.NewValue
+			copied.updatedAt = rev.validFrom
+		}
+
+		if keep(&copied) {
+			records = append(records, &copied)
+		}
+	}
+
+	return records
+}
+
+// revisionAt returns the revision of a rate that was valid at asOf, if any.
+// Callers must hold r.mu.
+func (r *RateRepository) revisionAt(rateID string, asOf time.Time) *revision {
+	for _, rev := range r.revisions[rateID] {
+		if !rev.validFrom.After(asOf) && (rev.validTo == nil || rev.validTo.After(asOf)) {
+			return rev
+		}
+	}
+	return nil
+}
+
+// appendRevision closes the current revision of a rate and records a new one.
+// Callers must hold r.mu for writing.
+func (r *RateRepository) appendRevision(rateID string, oldValue *float64, newValue float64, source rate.Source, info rate.ChangeInfo) {
+	now := time.Now()
+
+	for _, rev := range r.revisions[rateID] {
+		if rev.validTo == nil {
+			rev.validTo = &now
+		}
+	}
+
+	r.revisions[rateID] = append(r.revisions[rateID], &revision{
+		Revision: rate.Revision{
+			ID:        uuid.New().String(),
+			RateID:    rateID,
+			OldValue:  oldValue,
+			NewValue:  newValue,
+			Source:    source,
+			Actor:     info.Actor,
+			ActorID:   info.ActorID,
+			Reason:    info.Reason,
+			CreatedAt: now,
+		},
+		validFrom: now,
+	})
+}
+
+// field returns the value of a filterable field for genericrepo.Fields.Match.
+func (rec *record) field(name string) any {
+	switch name {
+	case rate.FieldID:
+		return rec.id
+	case rate.FieldBaseCurrency:
+		return rec.pair.Base().String()
+	case rate.FieldQuoteCurrency:
+		return rec.pair.Quote().String()
+	case rate.FieldValue:
+		return rec.value
+	case rate.FieldEffectiveDate:
+		return rec.effectiveDate
+	case rate.FieldSource:
+		return string(rec.source)
+	case rate.FieldCreatedAt:
+		return rec.createdAt
+	case rate.FieldUpdatedAt:
+		return rec.updatedAt
+	default:
+		return nil
+	}
+}
+
+func (rec *record) toDomain() *rate.Rate {
+	return rate.Reconstitute(
+		rec.id,
+		rec.pair,
+		rec.value,
+		rec.effectiveDate,
+		rec.source,
+		rec.createdAt,
+		rec.updatedAt,
+	)
+}
+
+// toRecord converts a domain rate to its stored form.
+// The effective date is truncated to a calendar day like the Postgres date column.
+func toRecord(entity *rate.Rate) *record {
+	return &record{
+		id:            entity.ID(),
+		pair:          entity.Pair(),
+		value:         entity.Value(),
+		effectiveDate: dateOf(entity.EffectiveDate()),
+		source:        entity.Source(),
+		createdAt:     entity.CreatedAt(),
+		updatedAt:     entity.UpdatedAt(),
+	}
+}
+
+func toDomainSlice(records []*record) []*rate.Rate {
+	rates := make([]*rate.Rate, 0, len(records))
+	for _, rec := range records {
+		rates = append(rates, rec.toDomain())
+	}
+	return rates
+}
+
+// keyset sorts records by (effective_date, id) and, when a cursor is given,
+// drops the records up to and including the cursor position.
+func keyset(records []*record, cursor *genericrepo.Cursor, desc bool) []*record {
+	sortByDate(records, desc)
+	if cursor == nil {
+		return records
+	}
+
+	return slices.DeleteFunc(records, func(rec *record) bool {
+		n := cmp.Or(
+			strings.Compare(timeutil.FormatDate(rec.effectiveDate), cursor.SortKey),
+			strings.Compare(rec.id, cursor.ID),
+		)
+		if desc {
+			return n >= 0
+		}
+		return n <= 0
+	})
+}
+
+// sortByDate sorts records by (effective_date, id).
+func sortByDate(records []*record, desc bool) {
+	slices.SortFunc(records, func(a, b *record) int {
+		n := cmp.Or(a.effectiveDate.Compare(b.effectiveDate), cmp.Compare(a.id, b.id))
+		if desc {
+			return -n
+		}
+		return n
+	})
+}
+
+// compareBy compares two records by the given order terms.
+func compareBy(terms []genericrepo.OrderTerm, a, b *record) int {
+	for _, term := range terms {
+		n, err := genericrepo.CompareValues(a.field(term.Field), b.field(term.Field))
+		if err != nil || n == 0 {
+			continue
+		}
+		if term.Desc {
+			return -n
+		}
+		return n
+	}
+	return 0
+}
+
+// isDescending reports whether an OrderBy clause asks for newest rates first.
+func isDescending(orderBy string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(orderBy)), "EFFECTIVE_DATE DESC")
+}
+
+// dateOf returns the calendar day of t as midnight UTC.
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// sameValue reports whether two rate values are equal at the stored precision.
+func sameValue(a, b float64) bool {
+	return math.Abs(a-b) < valuePrecision
+}
@@ -0,0 +1,16 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/rate/ratetest"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+)
+
+func TestRateRepository_Conformance(t *testing.T) {
+	ratetest.RunRepositoryTests(t, func(t *testing.T) rate.Repository {
+		return memory.NewRateRepository(logger.NewNoop())
+	})
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+)
+
+// sweepInterval is how many requests pass between removals of idle counters.
+const sweepInterval = 1000
+
+// windowCounter holds the request counts of a key in its current and previous window.
+type windowCounter struct {
+	window time.Duration
+	index  int64 // index of the current window
+	curr   int64
+	prev   int64
+}
+
+// roll moves the counter to the window with the given index.
+func (w *windowCounter) roll(index int64) {
+	switch {
+	case index == w.index:
+	case index == w.index+1:
+		w.prev, w.curr = w.curr, 0
+	default:
+		w.prev, w.curr = 0, 0
+	}
+	w.index = index
+}
+
+// RateLimiter keeps sliding-window counters in process memory.
+// It limits requests per replica and serves as the fallback when Redis is
+// unavailable.
+type RateLimiter struct {
+	mu       sync.Mutex
+	counters map[string]*windowCounter
+	requests int
+	logger   *slog.Logger
+}
+
+// Ensure RateLimiter implements redis.Limiter
+var _ redis.Limiter = (*RateLimiter)(nil)
+
+// NewRateLimiter creates an in-process rate limiter.
+func NewRateLimiter(logger *slog.Logger) *RateLimiter {
+	return &RateLimiter{
+		counters: make(map[string]*windowCounter),
+		logger:   logger,
+	}
+}
+
+// Allow implements redis.Limiter.
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit redis.Limit, now time.Time) (redis.LimitResult, error) {
+	index, elapsed := limit.Windows(now)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.requests++
+	if l.requests%sweepInterval == 0 {
+		l.sweep(now)
+	}
+
+	counter, ok := l.counters[key]
+	if !ok || counter.window != limit.Window {
+		counter = &windowCounter{window: limit.Window, index: index}
+		l.counters[key] = counter
+	}
+	counter.roll(index)
+
+	result := limit.Decide(counter.prev, counter.curr, elapsed)
+	if result.Allowed {
+		counter.curr++
+	}
+	return result, nil
+}
+
+// sweep removes counters whose windows have both passed.
+func (l *RateLimiter) sweep(now time.Time) {
+	for key, counter := range l.counters {
+		if index, _ := (redis.Limit{Window: counter.window}).Windows(now); index > counter.index+1 {
+			delete(l.counters, key)
+		}
+	}
+}
+
+// Close implements redis.Limiter.
+func (l *RateLimiter) Close() error {
+	return nil
+}
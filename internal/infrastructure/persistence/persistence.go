@@ -0,0 +1,185 @@
+// Package persistence selects the storage backends configured for the application.
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+	"github.com/tyokyo320/rateflow/internal/domain/apikey"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/provider"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/postgres"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/sqlite"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/tracing"
+)
+
+// NewConnection opens and migrates the database selected by cfg.Driver.
+// Statement durations are observed in the repository metrics and every
+// statement is traced.
+func NewConnection(cfg config.DatabaseConfig, log *slog.Logger) (*gorm.DB, error) {
+	var db *gorm.DB
+	var err error
+	switch cfg.Driver {
+	case config.DriverPostgres, "":
+		db, err = postgres.NewConnection(cfg, log)
+	case config.DriverSQLite:
+		db, err = sqlite.NewConnection(cfg, log)
+	case config.DriverMemory:
+		return nil, fmt.Errorf("the memory database driver keeps rates in the API process and has no connection; use postgres or sqlite")
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("register metrics plugin: %w", err)
+	}
+	if err := db.Use(tracing.GormPlugin{System: db.Dialector.Name()}); err != nil {
+		return nil, fmt.Errorf("register tracing plugin: %w", err)
+	}
+	return db, nil
+}
+
+// NewRateRepository creates the rate repository for a connection opened by NewConnection.
+// The GORM implementation works on every supported driver.
+func NewRateRepository(db *gorm.DB, log *slog.Logger) rate.Repository {
+	return postgres.NewRateRepository(db, log)
+}
+
+// NewMemoryRateRepository creates the in-process rate repository of the memory
+// driver. No database is opened or migrated and the rates are lost on exit.
+// When path is set, the repository starts with the rates in that JSON file:
+// an array of rates as served by /api/v1/rates/list, of which pair, rate,
+// effectiveDate and source are read.
+func NewMemoryRateRepository(ctx context.Context, path string, log *slog.Logger) (rate.Repository, error) {
+	repo := memory.NewRateRepository(log)
+	if path == "" {
+		return repo, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rates: %w", err)
+	}
+	var seeds []struct {
+		Pair          string    `json:"pair"`
+		Rate          float64   `json:"rate"`
+		EffectiveDate time.Time `json:"effectiveDate"`
+		Source        string    `json:"source"`
+	}
+	if err := json.Unmarshal(data, &seeds); err != nil {
+		return nil, fmt.Errorf("decode rates in %s: %w", path, err)
+	}
+
+	ctx = rate.WithChangeInfo(ctx, rate.ChangeInfo{Actor: rate.ActorImport, ActorID: path, Reason: "memory database seed"})
+	for i, seed := range seeds {
+		pair, err := currency.ParsePair(seed.Pair)
+		if err != nil {
+			return nil, fmt.Errorf("rate %d in %s: %w", i, path, err)
+		}
+		r, err := rate.NewRate(pair, seed.Rate, seed.EffectiveDate, rate.Source(seed.Source))
+		if err != nil {
+			return nil, fmt.Errorf("rate %d in %s: %w", i, path, err)
+		}
+		if err := repo.Create(ctx, r); err != nil {
+			return nil, fmt.Errorf("store rate %d in %s: %w", i, path, err)
+		}
+	}
+	log.Info("loaded rates into the memory database", "path", path, "count", len(seeds))
+	return repo, nil
+}
+
+// NewProviderStatusRepository creates the provider status repository for a
+// connection opened by NewConnection.
+func NewProviderStatusRepository(db *gorm.DB, log *slog.Logger) provider.StatusRepository {
+	return postgres.NewProviderStatusRepository(db, log)
+}
+
+// NewAPIKeyRepository creates the API key repository for a connection opened
+// by NewConnection.
+func NewAPIKeyRepository(db *gorm.DB, log *slog.Logger) apikey.Repository {
+	return postgres.NewAPIKeyRepository(db, log)
+}
+
+// NewWebhookRepository creates the webhook repository for a connection opened
+// by NewConnection.
+func NewWebhookRepository(db *gorm.DB, log *slog.Logger) webhook.Repository {
+	return postgres.NewWebhookRepository(db, log)
+}
+
+// NewSuspectRateRepository creates the repository of quarantined rates for a
+// connection opened by NewConnection.
+func NewSuspectRateRepository(db *gorm.DB, log *slog.Logger) anomaly.Repository {
+	return postgres.NewSuspectRateRepository(db, log)
+}
+
+// NewCache creates the cache selected by the cache mode.
+//
+// Redis is checked once at startup. When it is unreachable the application
+// degrades instead of failing: a Redis-only cache is replaced by a tiered
+// cache, whose in-process tier keeps serving while Redis is down and which
+// uses Redis again as soon as it is back. Cache operations are traced.
+func NewCache(ctx context.Context, cacheCfg config.CacheConfig, redisCfg config.RedisConfig, log *slog.Logger) redis.CacheInterface {
+	return tracing.InstrumentCache(newCache(ctx, cacheCfg, redisCfg, log))
+}
+
+func newCache(ctx context.Context, cacheCfg config.CacheConfig, redisCfg config.RedisConfig, log *slog.Logger) redis.CacheInterface {
+	mode := cacheCfg.EffectiveMode(redisCfg)
+	if mode == config.CacheModeMemory {
+		return memory.NewCache(cacheCfg.MaxEntries, log)
+	}
+
+	remote := redis.NewCache(redisCfg, log)
+	if err := remote.Ping(ctx); err != nil {
+		log.Warn("redis unreachable, serving from in-process cache until it recovers",
+			"addr", redisCfg.Addr(),
+			"error", err,
+		)
+		mode = config.CacheModeTiered
+	}
+
+	if mode == config.CacheModeRedis {
+		return remote
+	}
+
+	// Replicas tell each other about deletions, so no local tier keeps serving
+	// entries another process has invalidated
+	cache := redis.NewTieredCache(memory.NewCache(cacheCfg.MaxEntries, log), remote, cacheCfg.LocalTTL, log)
+	cache.Broadcast(remote)
+	return cache
+}
+
+// NewRateEvents creates the rate event log: shared through Redis when it is
+// configured, so the worker's events reach the API, and in-process otherwise.
+func NewRateEvents(redisCfg config.RedisConfig, retention int, log *slog.Logger) rate.EventLog {
+	if !redisCfg.Enabled() {
+		return memory.NewRateEvents(retention, log)
+	}
+	return redis.NewRateEvents(redisCfg, int64(retention), log)
+}
+
+// NewRateLimiter creates the rate limiter for the configured backend.
+// With Redis, limits are shared by all replicas; while Redis is unreachable,
+// and without Redis, they are enforced per replica.
+func NewRateLimiter(redisCfg config.RedisConfig, log *slog.Logger) redis.Limiter {
+	local := memory.NewRateLimiter(log)
+	if !redisCfg.Enabled() {
+		return local
+	}
+	return redis.NewFallbackLimiter(redis.NewRateLimiter(redisCfg, log), local, log)
+}
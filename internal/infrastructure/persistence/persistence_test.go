@@ -0,0 +1,55 @@
+package persistence_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+)
+
+func TestNewMemoryRateRepository(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	seed := `[
+		{"id": "ignored", "pair": "CNY/JPY", "rate": 20.5, "effectiveDate": "2024-01-15T00:00:00Z", "source": "unionpay"},
+		{"pair": "CNY/JPY", "rate": 20.6, "effectiveDate": "2024-01-16T00:00:00Z", "source": "unionpay"}
+	]`
+	if err := os.WriteFile(path, []byte(seed), 0o600); err != nil {
+		t.Fatalf("write seed: %v", err)
+	}
+
+	repo, err := persistence.NewMemoryRateRepository(ctx, path, log)
+	if err != nil {
+		t.Fatalf("NewMemoryRateRepository() error = %v", err)
+	}
+	latest, err := repo.FindLatest(ctx, currency.MustNewPair(currency.CNY, currency.JPY))
+	if err != nil {
+		t.Fatalf("FindLatest() error = %v", err)
+	}
+	if latest.Value() != 20.6 || !latest.EffectiveDate().Equal(time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("latest = %v on %v, want 20.6 on Jan 16", latest.Value(), latest.EffectiveDate())
+	}
+	revisions, err := repo.FindRevisions(ctx, latest.ID())
+	if err != nil || len(revisions) != 1 || revisions[0].Actor != rate.ActorImport {
+		t.Errorf("revisions = %+v, %v; want one import", revisions, err)
+	}
+
+	// Without a file the repository starts empty; a broken file fails the start
+	if _, err := persistence.NewMemoryRateRepository(ctx, "", log); err != nil {
+		t.Errorf("NewMemoryRateRepository() without a file error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`[{"pair": "CNY", "rate": 1}]`), 0o600); err != nil {
+		t.Fatalf("write seed: %v", err)
+	}
+	if _, err := persistence.NewMemoryRateRepository(ctx, path, log); err == nil {
+		t.Error("NewMemoryRateRepository() with an invalid pair succeeded, want an error")
+	}
+}
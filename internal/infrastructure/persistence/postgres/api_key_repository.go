@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tyokyo320/rateflow/internal/domain/apikey"
+)
+
+// APIKeyRepository implements apikey.Repository interface.
+// Like RateRepository, it works on PostgreSQL and SQLite.
+type APIKeyRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewAPIKeyRepository creates a new API key repository.
+func NewAPIKeyRepository(db *gorm.DB, logger *slog.Logger) apikey.Repository {
+	return &APIKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new key.
+func (r *APIKeyRepository) Create(ctx context.Context, key *apikey.Key) error {
+	model := r.toModel(key)
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// FindByHash returns the key whose secret has the given hash.
+func (r *APIKeyRepository) FindByHash(ctx context.Context, hash string) (*apikey.Key, error) {
+	var model APIKeyModel
+	err := r.db.WithContext(ctx).Where("hash = ?", hash).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apikey.ErrKeyNotFound{}
+		}
+		return nil, err
+	}
+	return r.toDomain(model), nil
+}
+
+// List returns all keys, oldest first.
+func (r *APIKeyRepository) List(ctx context.Context) ([]*apikey.Key, error) {
+	var models []APIKeyModel
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make([]*apikey.Key, len(models))
+	for i, model := range models {
+		keys[i] = r.toDomain(model)
+	}
+	return keys, nil
+}
+
+// Revoke marks the key as revoked. Revoking a revoked key keeps the original time.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&APIKeyModel{}).
+		Where("id = ?", id).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, ?)", at.UTC()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apikey.ErrKeyNotFound{ID: id}
+	}
+	return nil
+}
+
+// RecordUsage counts a request and returns the key's requests on that UTC day.
+// Requests beyond quota are not counted.
+func (r *APIKeyRepository) RecordUsage(ctx context.Context, id, route string, at time.Time, quota int64) (int64, error) {
+	at = at.UTC()
+	day := Date(at.Truncate(24 * time.Hour))
+
+	var used int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Updating the key first locks it, so that concurrent requests of the
+		// key read the usage one after another
+		if err := tx.Model(&APIKeyModel{}).Where("id = ?", id).Update("last_used_at", at).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&APIKeyUsageModel{}).
+			Where("key_id = ? AND day = ?", id, day).
+			Select("COALESCE(SUM(requests), 0)").
+			Scan(&used).Error
+		if err != nil {
+			return err
+		}
+		if quota > 0 && used >= quota {
+			return apikey.ErrQuotaExceeded{Quota: quota}
+		}
+
+		usage := APIKeyUsageModel{KeyID: id, Day: day, Route: route, Requests: 1}
+		used++
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key_id"}, {Name: "day"}, {Name: "route"}},
+			DoUpdates: clause.Assignments(map[string]any{"requests": gorm.Expr("api_key_usage.requests + 1")}),
+		}).Create(&usage).Error
+	})
+	if err != nil {
+		return used, err
+	}
+	return used, nil
+}
+
+// UsageOn returns the usage of the key per route on a UTC day.
+func (r *APIKeyRepository) UsageOn(ctx context.Context, id string, day time.Time) ([]apikey.Usage, error) {
+	var models []APIKeyUsageModel
+	err := r.db.WithContext(ctx).
+		Where("key_id = ? AND day = ?", id, Date(day.UTC())).
+		Order("route ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]apikey.Usage, len(models))
+	for i, model := range models {
+		usage[i] = apikey.Usage{
+			KeyID: model.KeyID,
+			Day:   time.Time(model.Day),
+			Route: model.Route,
+			Count: model.Requests,
+		}
+	}
+	return usage, nil
+}
+
+func (r *APIKeyRepository) toModel(key *apikey.Key) APIKeyModel {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	return APIKeyModel{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Hash:       key.Hash,
+		Scopes:     strings.Join(scopes, ","),
+		DailyQuota: key.DailyQuota,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+func (r *APIKeyRepository) toDomain(model APIKeyModel) *apikey.Key {
+	var scopes []apikey.Scope
+	for s := range strings.SplitSeq(model.Scopes, ",") {
+		if s != "" {
+			scopes = append(scopes, apikey.Scope(s))
+		}
+	}
+
+	return &apikey.Key{
+		ID:         model.ID,
+		Name:       model.Name,
+		Prefix:     model.Prefix,
+		Hash:       model.Hash,
+		Scopes:     scopes,
+		DailyQuota: model.DailyQuota,
+		CreatedAt:  model.CreatedAt,
+		LastUsedAt: model.LastUsedAt,
+		RevokedAt:  model.RevokedAt,
+	}
+}
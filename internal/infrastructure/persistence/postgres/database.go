@@ -35,9 +35,8 @@ func NewConnection(cfg config.DatabaseConfig, log *slog.Logger) (*gorm.DB, error
 	sqlDB.SetMaxIdleConns(cfg.MaxConns / 2)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	// Auto-migrate tables
-	if err := db.AutoMigrate(&RateModel{}); err != nil {
-		return nil, fmt.Errorf("failed to auto-migrate: %w", err)
+	if err := Migrate(db); err != nil {
+		return nil, err
 	}
 
 	log.Info("database connected",
@@ -48,3 +47,18 @@ func NewConnection(cfg config.DatabaseConfig, log *slog.Logger) (*gorm.DB, error
 
 	return db, nil
 }
+
+// Migrate creates or updates the database schema.
+func Migrate(db *gorm.DB) error {
+	// Auto-migrate tables
+	if err := db.AutoMigrate(
+		&RateModel{}, &RateRevisionModel{}, &ProviderStatusModel{}, &APIKeyModel{}, &APIKeyUsageModel{},
+		&WebhookSubscriptionModel{}, &WebhookDeliveryModel{}, &SuspectRateModel{}, &SchemaMigrationModel{},
+	); err != nil {
+		return fmt.Errorf("failed to auto-migrate: %w", err)
+	}
+	if err := migrateRevisions(db); err != nil {
+		return fmt.Errorf("failed to migrate revisions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revisionsBackfill is the version under which migrateRevisions is recorded.
+const revisionsBackfill = "0001_backfill_rate_revisions"
+
+// migrateRevisions brings the revision history of a database created before
+// revisions were tracked in line with the rate table:
+//  1. rates stored before revisions existed get an initial revision,
+//  2. revisions written before transaction time was tracked get valid_from,
+//  3. superseded revisions get valid_to set to the start of their successor.
+//
+// Later writes keep the history consistent themselves, so the steps scan the
+// tables only once: the first start records the version in schema_migrations
+// and later starts skip it after a single-row lookup. The steps are
+// idempotent, so instances starting together may both run them.
+func migrateRevisions(db *gorm.DB) error {
+	err := db.Where("version = ?", revisionsBackfill).First(&SchemaMigrationModel{}).Error
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("check schema migrations: %w", err)
+	}
+
+	steps := []struct {
+		name string
+		sql  string
+	}{
+		{
+			name: "backfill initial revisions",
+			sql: `INSERT INTO rate_revisions
+					(id, rate_id, old_value, new_value, source, actor, reason, created_at, valid_from)
+				SELECT gen_random_uuid(), r.id, NULL, r.value, r.source, 'import',
+					'backfill of pre-existing rate', r.created_at, r.created_at
+				FROM exchange_rates r
+				WHERE NOT EXISTS (SELECT 1 FROM rate_revisions v WHERE v.rate_id = r.id)`,
+		},
+		{
+			name: "backfill valid_from",
+			sql:  `UPDATE rate_revisions SET valid_from = created_at WHERE valid_from IS NULL`,
+		},
+		{
+			name: "close superseded revisions",
+			sql: `UPDATE rate_revisions AS v SET valid_to = (
+					SELECT MIN(n.valid_from) FROM rate_revisions n
+					WHERE n.rate_id = v.rate_id AND n.valid_from > v.valid_from
+				)
+				WHERE v.valid_to IS NULL`,
+		},
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, step := range steps {
+			if err := tx.Exec(step.sql).Error; err != nil {
+				return fmt.Errorf("%s: %w", step.name, err)
+			}
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&SchemaMigrationModel{
+			Version:   revisionsBackfill,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+}
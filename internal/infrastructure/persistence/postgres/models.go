@@ -1,17 +1,21 @@
 package postgres
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"time"
+
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
 )
 
 // RateModel represents the database table for exchange rates.
 type RateModel struct {
-	ID            string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	BaseCurrency  string    `gorm:"type:varchar(3);not null;uniqueIndex:idx_unique_rate"`
-	QuoteCurrency string    `gorm:"type:varchar(3);not null;uniqueIndex:idx_unique_rate"`
-	Value         float64   `gorm:"type:decimal(20,10);not null"`
-	EffectiveDate time.Time `gorm:"type:date;not null;uniqueIndex:idx_unique_rate"`
-	Source        string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_unique_rate"`
+	ID            string  `gorm:"primaryKey;type:uuid;default:(gen_random_uuid())"`
+	BaseCurrency  string  `gorm:"type:varchar(3);not null;uniqueIndex:idx_unique_rate"`
+	QuoteCurrency string  `gorm:"type:varchar(3);not null;uniqueIndex:idx_unique_rate"`
+	Value         float64 `gorm:"type:decimal(20,10);not null"`
+	EffectiveDate Date    `gorm:"type:date;not null;uniqueIndex:idx_unique_rate"`
+	Source        string  `gorm:"type:varchar(50);not null;uniqueIndex:idx_unique_rate"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
@@ -20,3 +24,181 @@ type RateModel struct {
 func (RateModel) TableName() string {
 	return "exchange_rates"
 }
+
+// RateRevisionModel represents the append-only audit table of rate value changes.
+// ValidFrom and ValidTo carry the transaction-time validity of each revision:
+// a revision describes what the system knew between ValidFrom (inclusive) and
+// ValidTo (exclusive). The current revision of a rate has a nil ValidTo.
+type RateRevisionModel struct {
+	ID        string     `gorm:"primaryKey;type:uuid;default:(gen_random_uuid())"`
+	RateID    string     `gorm:"type:uuid;not null;index:idx_revision_rate"`
+	OldValue  *float64   `gorm:"type:decimal(20,10)"`
+	NewValue  float64    `gorm:"type:decimal(20,10);not null"`
+	Source    string     `gorm:"type:varchar(50);not null"`
+	Actor     string     `gorm:"type:varchar(20);not null"`
+	ActorID   string     `gorm:"type:varchar(100)"`
+	Reason    string     `gorm:"type:text"`
+	CreatedAt time.Time  `gorm:"not null;index:idx_revision_rate"`
+	ValidFrom *time.Time `gorm:"index:idx_revision_validity"`
+	ValidTo   *time.Time `gorm:"index:idx_revision_validity"`
+}
+
+// TableName specifies the table name for RateRevisionModel.
+func (RateRevisionModel) TableName() string {
+	return "rate_revisions"
+}
+
+// ProviderStatusModel represents the outcome of the latest fetches from a provider.
+type ProviderStatusModel struct {
+	Provider            string    `gorm:"primaryKey;type:varchar(50)"`
+	LastAttemptAt       time.Time `gorm:"not null"`
+	LastSuccessAt       *time.Time
+	LastError           string `gorm:"type:text"`
+	ConsecutiveFailures int    `gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for ProviderStatusModel.
+func (ProviderStatusModel) TableName() string {
+	return "provider_statuses"
+}
+
+// APIKeyModel represents an API key. Only the hash of the secret is stored.
+type APIKeyModel struct {
+	ID         string `gorm:"primaryKey;type:uuid"`
+	Name       string `gorm:"type:varchar(100);not null"`
+	Prefix     string `gorm:"type:varchar(20);not null"`
+	Hash       string `gorm:"type:char(64);not null;uniqueIndex"`
+	Scopes     string `gorm:"type:varchar(200);not null"` // comma-separated
+	DailyQuota int64  `gorm:"not null;default:0"`
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// TableName specifies the table name for APIKeyModel.
+func (APIKeyModel) TableName() string {
+	return "api_keys"
+}
+
+// APIKeyUsageModel counts the requests made with an API key to a route on a UTC day.
+type APIKeyUsageModel struct {
+	KeyID    string `gorm:"primaryKey;type:uuid"`
+	Day      Date   `gorm:"primaryKey;type:date"`
+	Route    string `gorm:"primaryKey;type:varchar(200)"`
+	Requests int64  `gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for APIKeyUsageModel.
+func (APIKeyUsageModel) TableName() string {
+	return "api_key_usage"
+}
+
+// WebhookSubscriptionModel represents a webhook subscription.
+// The signing secret is stored in clear, as every payload is signed with it.
+type WebhookSubscriptionModel struct {
+	ID            string  `gorm:"primaryKey;type:uuid"`
+	URL           string  `gorm:"type:varchar(2048);not null"`
+	Secret        string  `gorm:"type:varchar(100);not null"`
+	BaseCurrency  string  `gorm:"type:varchar(3);not null;index:idx_webhook_pair"`
+	QuoteCurrency string  `gorm:"type:varchar(3);not null;index:idx_webhook_pair"`
+	Kind          string  `gorm:"type:varchar(20);not null"` // condition kind
+	Threshold     float64 `gorm:"type:decimal(20,10);not null;default:0"`
+	Description   string  `gorm:"type:text"`
+	Active        bool    `gorm:"not null;default:true"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName specifies the table name for WebhookSubscriptionModel.
+func (WebhookSubscriptionModel) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// WebhookDeliveryModel represents a delivery of a webhook event, pending or done.
+type WebhookDeliveryModel struct {
+	ID             string    `gorm:"primaryKey;type:uuid"`
+	SubscriptionID string    `gorm:"type:uuid;not null;index:idx_delivery_subscription"`
+	EventID        string    `gorm:"type:uuid;not null"`
+	EventType      string    `gorm:"type:varchar(30);not null"`
+	Payload        string    `gorm:"type:text;not null"`
+	Status         string    `gorm:"type:varchar(20);not null;index:idx_delivery_due"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"not null;index:idx_delivery_due"`
+	LastAttemptAt  *time.Time
+	ResponseStatus int       `gorm:"not null;default:0"`
+	LastError      string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"not null;index:idx_delivery_subscription"`
+}
+
+// TableName specifies the table name for WebhookDeliveryModel.
+func (WebhookDeliveryModel) TableName() string {
+	return "webhook_deliveries"
+}
+
+// SuspectRateModel represents a rate from a provider quarantined for review.
+type SuspectRateModel struct {
+	ID            string    `gorm:"primaryKey;type:uuid"`
+	BaseCurrency  string    `gorm:"type:varchar(3);not null;index:idx_suspect_rate"`
+	QuoteCurrency string    `gorm:"type:varchar(3);not null;index:idx_suspect_rate"`
+	Value         float64   `gorm:"type:decimal(20,10);not null"`
+	EffectiveDate Date      `gorm:"type:date;not null;index:idx_suspect_rate"`
+	Source        string    `gorm:"type:varchar(50);not null"`
+	Previous      *float64  `gorm:"type:decimal(20,10)"`
+	Findings      string    `gorm:"type:text;not null"` // JSON array of findings
+	Status        string    `gorm:"type:varchar(20);not null;index:idx_suspect_status"`
+	CreatedAt     time.Time `gorm:"not null;index:idx_suspect_status"`
+	ReviewedAt    *time.Time
+	ReviewedBy    string `gorm:"type:varchar(100)"`
+	Note          string `gorm:"type:text"`
+	RateID        string `gorm:"type:varchar(36)"`
+}
+
+// TableName specifies the table name for SuspectRateModel.
+func (SuspectRateModel) TableName() string {
+	return "suspect_rates"
+}
+
+// SchemaMigrationModel records a data migration that has been applied.
+type SchemaMigrationModel struct {
+	Version   string    `gorm:"primaryKey;type:varchar(100)"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for SchemaMigrationModel.
+func (SchemaMigrationModel) TableName() string {
+	return "schema_migrations"
+}
+
+// Date is a calendar day stored in a DATE column.
+// It is written as a "YYYY-MM-DD" string so that it compares correctly with
+// the date strings used in queries, also on SQLite which has no date type.
+type Date time.Time
+
+// Value implements driver.Valuer.
+func (d Date) Value() (driver.Value, error) {
+	return timeutil.FormatDate(time.Time(d)), nil
+}
+
+// Scan implements sql.Scanner.
+func (d *Date) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = Date(v)
+		return nil
+	case string:
+		return d.parse(v)
+	case []byte:
+		return d.parse(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+}
+
+func (d *Date) parse(s string) error {
+	t, err := timeutil.ParseFlexible(s)
+	if err != nil {
+		return err
+	}
+	*d = Date(t)
+	return nil
+}
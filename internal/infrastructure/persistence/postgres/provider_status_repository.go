@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tyokyo320/rateflow/internal/domain/provider"
+)
+
+// ProviderStatusRepository implements provider.StatusRepository interface.
+// Like RateRepository, it works on PostgreSQL and SQLite.
+type ProviderStatusRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewProviderStatusRepository creates a new provider status repository.
+func NewProviderStatusRepository(db *gorm.DB, logger *slog.Logger) provider.StatusRepository {
+	return &ProviderStatusRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Record stores the outcome of a fetch attempt.
+func (r *ProviderStatusRepository) Record(ctx context.Context, providerName string, at time.Time, fetchErr error) error {
+	at = at.UTC()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model ProviderStatusModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("provider = ?", providerName).
+			First(&model).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		model.Provider = providerName
+		model.LastAttemptAt = at
+		if fetchErr == nil {
+			model.LastSuccessAt = &at
+			model.LastError = ""
+			model.ConsecutiveFailures = 0
+		} else {
+			model.LastError = fetchErr.Error()
+			model.ConsecutiveFailures++
+		}
+
+		return tx.Save(&model).Error
+	})
+}
+
+// FindStatus returns the status of a provider.
+func (r *ProviderStatusRepository) FindStatus(ctx context.Context, providerName string) (*provider.Status, error) {
+	var model ProviderStatusModel
+	err := r.db.WithContext(ctx).
+		Where("provider = ?", providerName).
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, provider.ErrStatusNotFound{Provider: providerName}
+		}
+		return nil, err
+	}
+
+	return &provider.Status{
+		Provider:            model.Provider,
+		LastAttemptAt:       model.LastAttemptAt,
+		LastSuccessAt:       model.LastSuccessAt,
+		LastError:           model.LastError,
+		ConsecutiveFailures: model.ConsecutiveFailures,
+	}, nil
+}
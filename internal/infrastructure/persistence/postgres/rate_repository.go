@@ -5,9 +5,13 @@ import (
 	"errors"
 	"iter"
 	"log/slog"
+	"math"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/tyokyo320/rateflow/internal/domain/currency"
 	"github.com/tyokyo320/rateflow/internal/domain/rate"
@@ -15,7 +19,30 @@ import (
 	"github.com/tyokyo320/rateflow/pkg/timeutil"
 )
 
+const (
+	// valuePrecision is the smallest difference representable by the value columns.
+	valuePrecision = 1e-10
+
+	// defaultStreamBatchSize is the number of rows Stream fetches per round trip.
+	defaultStreamBatchSize = 100
+)
+
+// rateFields maps the filterable rate fields to their columns.
+var rateFields = genericrepo.Fields{
+	rate.FieldID:            "id",
+	rate.FieldBaseCurrency:  "base_currency",
+	rate.FieldQuoteCurrency: "quote_currency",
+	rate.FieldValue:         "value",
+	rate.FieldEffectiveDate: "effective_date",
+	rate.FieldSource:        "source",
+	rate.FieldCreatedAt:     "created_at",
+	rate.FieldUpdatedAt:     "updated_at",
+}
+
 // RateRepository implements rate.Repository interface.
+// It only uses SQL that PostgreSQL and SQLite both understand, so it also
+// serves as the repository of the sqlite package. Timestamps are written in
+// UTC so that they compare correctly on SQLite, which stores them as text.
 type RateRepository struct {
 	db     *gorm.DB
 	logger *slog.Logger
@@ -31,25 +58,50 @@ func NewRateRepository(db *gorm.DB, logger *slog.Logger) rate.Repository {
 
 // Create inserts a new rate into the database.
 // If a rate with the same (base, quote, date, source) exists, it updates the existing rate.
+// Every value change is recorded in the rate_revisions table together with the
+// change information carried by ctx (see rate.WithChangeInfo).
 func (r *RateRepository) Create(ctx context.Context, entity *rate.Rate) error {
 	model := r.domainToModel(entity)
+	info := rate.ChangeInfoFromContext(ctx)
+
+	// Look up the existing row inside a transaction so that the value change
+	// and its revision are written atomically. This keeps re-running fetch
+	// commands idempotent while preserving the previous value.
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing RateModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(&RateModel{
+				BaseCurrency:  model.BaseCurrency,
+				QuoteCurrency: model.QuoteCurrency,
+				EffectiveDate: model.EffectiveDate,
+				Source:        model.Source,
+			}).
+			First(&existing).Error
 
-	// Use ON CONFLICT DO UPDATE to handle duplicates gracefully
-	// This ensures idempotent behavior when re-running fetch commands
-	result := r.db.WithContext(ctx).
-		Where(&RateModel{
-			BaseCurrency:  model.BaseCurrency,
-			QuoteCurrency: model.QuoteCurrency,
-			EffectiveDate: model.EffectiveDate,
-			Source:        model.Source,
-		}).
-		Assign(&RateModel{
-			Value:     model.Value,
-			UpdatedAt: time.Now(),
-		}).
-		FirstOrCreate(model)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Create(model).Error; err != nil {
+				return err
+			}
+			return appendRevision(tx, model.ID, nil, model.Value, model.Source, info)
+		}
+		if err != nil {
+			return err
+		}
 
-	return result.Error
+		if sameValue(existing.Value, model.Value) {
+			return nil
+		}
+
+		oldValue := existing.Value
+		if err := tx.Model(&existing).Updates(map[string]any{
+			"value":      model.Value,
+			"updated_at": time.Now().UTC(),
+		}).Error; err != nil {
+			return err
+		}
+
+		return appendRevision(tx, existing.ID, &oldValue, model.Value, model.Source, info)
+	})
 }
 
 // FindByID retrieves a rate by its ID.
@@ -68,37 +120,71 @@ func (r *RateRepository) FindByID(ctx context.Context, id string) (*rate.Rate, e
 }
 
 // Update modifies an existing rate.
+// A revision is recorded when the stored value changes.
 func (r *RateRepository) Update(ctx context.Context, entity *rate.Rate) error {
 	model := r.domainToModel(entity)
-	return r.db.WithContext(ctx).Save(model).Error
+	info := rate.ChangeInfoFromContext(ctx)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing RateModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", model.ID).
+			First(&existing).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return rate.ErrRateNotFound{ID: model.ID}
+			}
+			return err
+		}
+
+		if err := tx.Save(model).Error; err != nil {
+			return err
+		}
+
+		if sameValue(existing.Value, model.Value) {
+			return nil
+		}
+
+		oldValue := existing.Value
+		return appendRevision(tx, model.ID, &oldValue, model.Value, model.Source, info)
+	})
 }
 
-// Delete removes a rate by its ID.
+// Delete removes a rate by its ID, together with its revisions.
 func (r *RateRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&RateModel{}, "id = ?", id).Error
+	_, err := DeleteRates(r.db.WithContext(ctx), func(q *gorm.DB) *gorm.DB {
+		return q.Where("id = ?", id)
+	})
+	return err
 }
 
 // FindAll retrieves rates with optional filtering.
+// With a cursor the results continue after that position in
+// (effective_date, id) order instead of using the offset.
 func (r *RateRepository) FindAll(ctx context.Context, opts ...genericrepo.QueryOption) ([]*rate.Rate, error) {
 	cfg := genericrepo.BuildQueryConfig(opts...)
 
-	query := r.db.WithContext(ctx).Model(&RateModel{})
-
-	// Apply filters
-	for key, value := range cfg.Filters {
-		query = query.Where(key+" = ?", value)
+	query, err := applyConditions(r.snapshot(ctx, cfg.AsOf), cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	// Apply ordering
-	if cfg.OrderBy != "" {
-		query = query.Order(cfg.OrderBy)
+	if cfg.Cursor != nil {
+		query = applyKeyset(query, cfg.Cursor, isDescending(cfg.OrderBy))
+	} else if cfg.OrderBy != "" {
+		orderBy, err := rateFields.OrderSQL(cfg.OrderBy)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Order(orderBy)
 	}
 
 	// Apply pagination
 	if cfg.Limit > 0 {
 		query = query.Limit(cfg.Limit)
 	}
-	if cfg.Offset > 0 {
+	if cfg.Offset > 0 && cfg.Cursor == nil {
 		query = query.Offset(cfg.Offset)
 	}
 
@@ -124,86 +210,60 @@ func (r *RateRepository) FindAll(ctx context.Context, opts ...genericrepo.QueryO
 func (r *RateRepository) Count(ctx context.Context, opts ...genericrepo.QueryOption) (int64, error) {
 	cfg := genericrepo.BuildQueryConfig(opts...)
 
-	query := r.db.WithContext(ctx).Model(&RateModel{})
-
-	for key, value := range cfg.Filters {
-		query = query.Where(key+" = ?", value)
+	query, err := applyConditions(r.snapshot(ctx, cfg.AsOf), cfg)
+	if err != nil {
+		return 0, err
 	}
 
 	var count int64
-	err := query.Count(&count).Error
+	err = query.Count(&count).Error
 	return count, err
 }
 
 // Stream returns an iterator for memory-efficient traversal.
 // Uses Go 1.23+ range over function feature.
+// Rows that fail to convert are logged and skipped; a query error ends the stream.
 func (r *RateRepository) Stream(ctx context.Context, opts ...genericrepo.QueryOption) iter.Seq[*rate.Rate] {
 	return func(yield func(*rate.Rate) bool) {
-		const batchSize = 100
-		offset := 0
-		cfg := genericrepo.BuildQueryConfig(opts...)
-
-		for {
-			query := r.db.WithContext(ctx).Model(&RateModel{}).
-				Limit(batchSize).
-				Offset(offset)
-
-			// Apply filters
-			for key, value := range cfg.Filters {
-				query = query.Where(key+" = ?", value)
-			}
-
-			// Apply ordering
-			if cfg.OrderBy != "" {
-				query = query.Order(cfg.OrderBy)
-			}
-
-			var models []RateModel
-			if err := query.Find(&models).Error; err != nil {
+		for domainRate, err := range r.StreamWithError(ctx, opts...) {
+			if err != nil {
 				r.logger.Error("stream error", "error", err)
-				return
+				continue
 			}
 
-			if len(models) == 0 {
-				return
+			if !yield(domainRate) {
+				return // Early termination
 			}
-
-			for i := range models {
-				domainRate, err := r.modelToDomain(&models[i])
-				if err != nil {
-					r.logger.Error("failed to convert model", "error", err)
-					continue
-				}
-
-				if !yield(domainRate) {
-					return // Early termination
-				}
-			}
-
-			offset += batchSize
 		}
 	}
 }
 
 // StreamWithError returns an iterator that also yields errors.
+//
+// Rows are read in batches using keyset pagination on (effective_date, id),
+// so each batch is an index range scan and concurrent writes cannot cause rows
+// to be skipped or repeated. The order is ascending unless OrderBy is
+// "effective_date DESC"; other orderings are not supported for streaming.
 func (r *RateRepository) StreamWithError(ctx context.Context, opts ...genericrepo.QueryOption) iter.Seq2[*rate.Rate, error] {
 	return func(yield func(*rate.Rate, error) bool) {
-		const batchSize = 100
-		offset := 0
 		cfg := genericrepo.BuildQueryConfig(opts...)
+		desc := isDescending(cfg.OrderBy)
+		cursor := cfg.Cursor
 
-		for {
-			query := r.db.WithContext(ctx).Model(&RateModel{}).
-				Limit(batchSize).
-				Offset(offset)
+		batchSize := cfg.BatchSize
+		if batchSize <= 0 {
+			batchSize = defaultStreamBatchSize
+		}
 
-			for key, value := range cfg.Filters {
-				query = query.Where(key+" = ?", value)
-			}
+		base, err := applyConditions(r.snapshot(ctx, cfg.AsOf), cfg)
+		if err != nil {
+			var zero *rate.Rate
+			yield(zero, err)
+			return
+		}
 
-			if cfg.OrderBy != "" {
-				query = query.Order(cfg.OrderBy)
-			}
+		for {
+			query := applyKeyset(base.Session(&gorm.Session{}), cursor, desc).Limit(batchSize)
 
 			var models []RateModel
 			if err := query.Find(&models).Error; err != nil {
@@ -212,10 +272,6 @@ func (r *RateRepository) StreamWithError(ctx context.Context, opts ...genericrep
 				return
 			}
 
-			if len(models) == 0 {
-				return
-			}
-
 			for i := range models {
 				domainRate, err := r.modelToDomain(&models[i])
 				if !yield(domainRate, err) {
@@ -223,7 +279,15 @@ func (r *RateRepository) StreamWithError(ctx context.Context, opts ...genericrep
 				}
 			}
 
-			offset += batchSize
+			if len(models) < batchSize {
+				return
+			}
+
+			last := models[len(models)-1]
+			cursor = &genericrepo.Cursor{
+				SortKey: timeutil.FormatDate(time.Time(last.EffectiveDate)),
+				ID:      last.ID,
+			}
 		}
 	}
 }
@@ -237,11 +301,20 @@ func (r *RateRepository) Exists(ctx context.Context, id string) (bool, error) {
 
 // FindByPairAndDate finds a rate for a specific currency pair and date.
 func (r *RateRepository) FindByPairAndDate(ctx context.Context, pair currency.Pair, date time.Time) (*rate.Rate, error) {
+	return r.findByPairAndDate(ctx, pair, date, nil)
+}
+
+// FindByPairAndDateAsOf finds a rate for a pair and date as it was known at asOf.
+func (r *RateRepository) FindByPairAndDateAsOf(ctx context.Context, pair currency.Pair, date, asOf time.Time) (*rate.Rate, error) {
+	return r.findByPairAndDate(ctx, pair, date, &asOf)
+}
+
+func (r *RateRepository) findByPairAndDate(ctx context.Context, pair currency.Pair, date time.Time, asOf *time.Time) (*rate.Rate, error) {
 	var model RateModel
 
 	dateStr := timeutil.FormatDate(date)
 
-	err := r.db.WithContext(ctx).
+	err := r.snapshot(ctx, asOf).
 		Where("base_currency = ? AND quote_currency = ? AND effective_date = ?",
 			pair.Base().String(),
 			pair.Quote().String(),
@@ -261,9 +334,18 @@ func (r *RateRepository) FindByPairAndDate(ctx context.Context, pair currency.Pa
 
 // FindLatest finds the most recent rate for a currency pair.
 func (r *RateRepository) FindLatest(ctx context.Context, pair currency.Pair) (*rate.Rate, error) {
+	return r.findLatest(ctx, pair, nil)
+}
+
+// FindLatestAsOf finds the most recent rate for a pair as it was known at asOf.
+func (r *RateRepository) FindLatestAsOf(ctx context.Context, pair currency.Pair, asOf time.Time) (*rate.Rate, error) {
+	return r.findLatest(ctx, pair, &asOf)
+}
+
+func (r *RateRepository) findLatest(ctx context.Context, pair currency.Pair, asOf *time.Time) (*rate.Rate, error) {
 	var model RateModel
 
-	err := r.db.WithContext(ctx).
+	err := r.snapshot(ctx, asOf).
 		Where("base_currency = ? AND quote_currency = ?",
 			pair.Base().String(),
 			pair.Quote().String(),
@@ -283,12 +365,21 @@ func (r *RateRepository) FindLatest(ctx context.Context, pair currency.Pair) (*r
 
 // FindByDateRange finds rates for a currency pair within a date range.
 func (r *RateRepository) FindByDateRange(ctx context.Context, pair currency.Pair, start, end time.Time) ([]*rate.Rate, error) {
+	return r.findByDateRange(ctx, pair, start, end, nil)
+}
+
+// FindByDateRangeAsOf finds rates for a pair within a date range as they were known at asOf.
+func (r *RateRepository) FindByDateRangeAsOf(ctx context.Context, pair currency.Pair, start, end, asOf time.Time) ([]*rate.Rate, error) {
+	return r.findByDateRange(ctx, pair, start, end, &asOf)
+}
+
+func (r *RateRepository) findByDateRange(ctx context.Context, pair currency.Pair, start, end time.Time, asOf *time.Time) ([]*rate.Rate, error) {
 	var models []RateModel
 
 	startStr := timeutil.FormatDate(start)
 	endStr := timeutil.FormatDate(end)
 
-	err := r.db.WithContext(ctx).
+	err := r.snapshot(ctx, asOf).
 		Where("base_currency = ? AND quote_currency = ? AND effective_date BETWEEN ? AND ?",
 			pair.Base().String(),
 			pair.Quote().String(),
@@ -356,15 +447,201 @@ func (r *RateRepository) ExistsByPairAndDate(ctx context.Context, pair currency.
 	return count > 0, err
 }
 
-// DeleteOlderThan deletes rates older than the specified date.
+// DeleteOlderThan deletes rates older than the specified date, together with
+// their revisions.
 func (r *RateRepository) DeleteOlderThan(ctx context.Context, date time.Time) (int64, error) {
 	dateStr := timeutil.FormatDate(date)
 
-	result := r.db.WithContext(ctx).
-		Where("effective_date < ?", dateStr).
-		Delete(&RateModel{})
+	return DeleteRates(r.db.WithContext(ctx), func(q *gorm.DB) *gorm.DB {
+		return q.Where("effective_date < ?", dateStr)
+	})
+}
+
+// DeleteRates deletes the rates selected by scope, a condition on
+// exchange_rates, and returns how many were deleted. Their revisions are
+// deleted in the same transaction: as-of reads are answered from the
+// revisions of stored rates, so a purged rate leaves no history behind and is
+// absent at every point in time, rather than leaving orphaned revisions.
+func DeleteRates(db *gorm.DB, scope func(*gorm.DB) *gorm.DB) (int64, error) {
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		ids := tx.Model(&RateModel{}).Scopes(scope).Select("id")
+		if err := tx.Where("rate_id IN (?)", ids).Delete(&RateRevisionModel{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Scopes(scope).Delete(&RateModel{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// applyConditions adds the query's filter conditions as a WHERE clause.
+// Field names are checked against the rate allow-list before use.
+// Time values are converted to UTC like all stored timestamps, because
+// SQLite compares timestamps as text.
+func applyConditions(query *gorm.DB, cfg *genericrepo.QueryConfig) (*gorm.DB, error) {
+	sql, args, err := rateFields.SQL(cfg.Condition())
+	if err != nil {
+		return nil, err
+	}
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			args[i] = t.UTC()
+		}
+	}
+	return query.Where(sql, args...), nil
+}
+
+// applyKeyset orders the query by (effective_date, id) and, when a cursor is
+// given, restricts it to rows strictly after the cursor in that order.
+func applyKeyset(query *gorm.DB, cursor *genericrepo.Cursor, desc bool) *gorm.DB {
+	if desc {
+		if cursor != nil {
+			query = query.Where("(effective_date, id) < (?, ?)", cursor.SortKey, cursor.ID)
+		}
+		return query.Order("effective_date DESC, id DESC")
+	}
+
+	if cursor != nil {
+		query = query.Where("(effective_date, id) > (?, ?)", cursor.SortKey, cursor.ID)
+	}
+	return query.Order("effective_date ASC, id ASC")
+}
+
+// isDescending reports whether an OrderBy clause asks for newest rates first.
+func isDescending(orderBy string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(orderBy)), "EFFECTIVE_DATE DESC")
+}
+
+// snapshot returns the base query for reading rates.
+// With a nil asOf it reads the current table. Otherwise it reads a derived
+// table with the same columns, where each rate carries the value of the
+// revision that was valid at asOf; rates first recorded after asOf are absent.
+func (r *RateRepository) snapshot(ctx context.Context, asOf *time.Time) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	if asOf == nil {
+		return db.Model(&RateModel{})
+	}
+
+	at := asOf.UTC()
+	sub := db.Table("exchange_rates AS r").
+		Select("r.id, r.base_currency, r.quote_currency, v.new_value AS value, "+
+			"r.effective_date, r.source, r.created_at, v.valid_from AS updated_at").
+		Joins("JOIN rate_revisions v ON v.rate_id = r.id "+
+			"AND v.valid_from <= ? AND (v.valid_to IS NULL OR v.valid_to > ?)", at, at)
+
+	return db.Table("(?) AS exchange_rates", sub)
+}
+
+// FindRevisions returns the value history of a rate, oldest first.
+func (r *RateRepository) FindRevisions(ctx context.Context, rateID string) ([]*rate.Revision, error) {
+	var models []RateRevisionModel
+
+	err := r.db.WithContext(ctx).
+		Where("rate_id = ?", rateID).
+		Order("created_at ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]*rate.Revision, 0, len(models))
+	for i := range models {
+		revisions = append(revisions, revisionModelToDomain(&models[i]))
+	}
+
+	return revisions, nil
+}
 
-	return result.RowsAffected, result.Error
+// periodRow is a row of the aggregation query.
+type periodRow struct {
+	PeriodStart    Date
+	OpenRate       float64
+	HighRate       float64
+	LowRate        float64
+	CloseRate      float64
+	CloseDate      Date
+	AverageRate    float64
+	InverseAverage float64
+	RateCount      int
+}
+
+// Aggregate returns the aggregates of the matching rates per period of
+// interval, oldest first. The rates are grouped and aggregated in the
+// database; open and close are read with window functions over each period.
+// Unknown intervals are rejected, as the interval is part of the SQL.
+func (r *RateRepository) Aggregate(ctx context.Context, interval rate.Interval, opts ...genericrepo.QueryOption) ([]*rate.Period, error) {
+	if _, err := rate.ParseInterval(string(interval)); err != nil {
+		return nil, err
+	}
+	cfg := genericrepo.BuildQueryConfig(opts...)
+
+	base, err := applyConditions(r.snapshot(ctx, cfg.AsOf), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	period := r.periodStart(interval)
+	rates := base.Select(period + " AS period_start, value, effective_date, " +
+		"FIRST_VALUE(value) OVER (PARTITION BY " + period + " ORDER BY effective_date ASC, id ASC) AS open_rate, " +
+		"FIRST_VALUE(value) OVER (PARTITION BY " + period + " ORDER BY effective_date DESC, id DESC) AS close_rate")
+
+	var rows []periodRow
+	err = r.db.WithContext(ctx).Table("(?) AS rates", rates).
+		Select("period_start, MAX(open_rate) AS open_rate, MAX(value) AS high_rate, MIN(value) AS low_rate, " +
+			"MAX(close_rate) AS close_rate, MAX(effective_date) AS close_date, AVG(value) AS average_rate, " +
+			"AVG(1.0 / value) AS inverse_average, COUNT(*) AS rate_count").
+		Group("period_start").
+		Order("period_start ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	periods := make([]*rate.Period, 0, len(rows))
+	for _, row := range rows {
+		start := time.Time(row.PeriodStart)
+		periods = append(periods, &rate.Period{
+			Start:          start,
+			End:            interval.Last(start),
+			Open:           row.OpenRate,
+			High:           row.HighRate,
+			Low:            row.LowRate,
+			Close:          row.CloseRate,
+			CloseDate:      time.Time(row.CloseDate),
+			Average:        row.AverageRate,
+			InverseAverage: row.InverseAverage,
+			Count:          row.RateCount,
+		})
+	}
+
+	return periods, nil
+}
+
+// periodStart returns the SQL expression of the first day of the period of
+// interval containing a rate's effective date. PostgreSQL truncates with
+// date_trunc; SQLite, which has no date_trunc, with date modifiers.
+func (r *RateRepository) periodStart(interval rate.Interval) string {
+	if r.db.Dialector.Name() == "postgres" {
+		return "CAST(date_trunc('" + string(interval) + "', CAST(effective_date AS timestamp)) AS date)"
+	}
+
+	switch interval {
+	case rate.IntervalWeek:
+		return "date(effective_date, 'weekday 0', '-6 days')"
+	case rate.IntervalQuarter:
+		return "date(effective_date, 'start of month', " +
+			"'-' || ((CAST(strftime('%m', effective_date) AS INTEGER) - 1) % 3) || ' months')"
+	case rate.IntervalYear:
+		return "date(effective_date, 'start of year')"
+	default:
+		return "date(effective_date, 'start of month')"
+	}
 }
 
 // domainToModel converts a domain Rate entity to a database model.
@@ -374,10 +651,10 @@ func (r *RateRepository) domainToModel(entity *rate.Rate) *RateModel {
 		BaseCurrency:  entity.Pair().Base().String(),
 		QuoteCurrency: entity.Pair().Quote().String(),
 		Value:         entity.Value(),
-		EffectiveDate: entity.EffectiveDate(),
+		EffectiveDate: Date(entity.EffectiveDate()),
 		Source:        string(entity.Source()),
-		CreatedAt:     entity.CreatedAt(),
-		UpdatedAt:     entity.UpdatedAt(),
+		CreatedAt:     entity.CreatedAt().UTC(),
+		UpdatedAt:     entity.UpdatedAt().UTC(),
 	}
 }
 
@@ -402,9 +679,55 @@ func (r *RateRepository) modelToDomain(model *RateModel) (*rate.Rate, error) {
 		model.ID,
 		pair,
 		model.Value,
-		model.EffectiveDate,
+		time.Time(model.EffectiveDate),
 		rate.Source(model.Source),
 		model.CreatedAt,
 		model.UpdatedAt,
 	), nil
 }
+
+// appendRevision closes the current revision of a rate and records a new one.
+// Both share the same timestamp so that transaction-time intervals are contiguous.
+func appendRevision(tx *gorm.DB, rateID string, oldValue *float64, newValue float64, source string, info rate.ChangeInfo) error {
+	now := time.Now().UTC()
+
+	if err := tx.Model(&RateRevisionModel{}).
+		Where("rate_id = ? AND valid_to IS NULL", rateID).
+		Update("valid_to", now).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&RateRevisionModel{
+		ID:        uuid.New().String(),
+		RateID:    rateID,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		Source:    source,
+		Actor:     string(info.Actor),
+		ActorID:   info.ActorID,
+		Reason:    info.Reason,
+		CreatedAt: now,
+		ValidFrom: &now,
+	}).Error
+}
+
+// revisionModelToDomain converts a revision model to a domain Revision.
+func revisionModelToDomain(model *RateRevisionModel) *rate.Revision {
+	return &rate.Revision{
+		ID:        model.ID,
+		RateID:    model.RateID,
+		OldValue:  model.OldValue,
+		NewValue:  model.NewValue,
+		Source:    rate.Source(model.Source),
+		Actor:     rate.Actor(model.Actor),
+		ActorID:   model.ActorID,
+		Reason:    model.Reason,
+		CreatedAt: model.CreatedAt,
+	}
+}
+
+// sameValue reports whether two rate values are equal at the precision
+// of the decimal(20,10) column they are stored in.
+func sameValue(a, b float64) bool {
+	return math.Abs(a-b) < valuePrecision
+}
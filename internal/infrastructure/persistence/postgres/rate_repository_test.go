@@ -0,0 +1,47 @@
+package postgres_test
+
+import (
+	"os"
+	"testing"
+
+	pgdriver "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/rate/ratetest"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/postgres"
+)
+
+// TestRateRepository_Conformance runs against the database in RATEFLOW_TEST_DSN.
+// The tables of that database are truncated before every test. Without a
+// database the test is skipped, unless RATEFLOW_REQUIRE_DB is set: the
+// workflows with a Postgres service set it so that the Postgres-only queries
+// cannot go untested there.
+func TestRateRepository_Conformance(t *testing.T) {
+	dsn := os.Getenv("RATEFLOW_TEST_DSN")
+	if dsn == "" {
+		if os.Getenv("RATEFLOW_REQUIRE_DB") != "" {
+			t.Fatal("RATEFLOW_TEST_DSN must be set when RATEFLOW_REQUIRE_DB is")
+		}
+		t.Skip("RATEFLOW_TEST_DSN not set")
+	}
+
+	db, err := gorm.Open(pgdriver.Open(dsn), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if err := postgres.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	ratetest.RunRepositoryTests(t, func(t *testing.T) rate.Repository {
+		if err := db.Exec("TRUNCATE exchange_rates, rate_revisions").Error; err != nil {
+			t.Fatalf("failed to truncate tables: %v", err)
+		}
+		return postgres.NewRateRepository(db, logger.NewNoop())
+	})
+}
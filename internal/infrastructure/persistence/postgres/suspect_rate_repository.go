@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// SuspectRateRepository implements anomaly.Repository interface.
+// Like RateRepository, it works on PostgreSQL and SQLite.
+type SuspectRateRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewSuspectRateRepository creates a new suspect rate repository.
+func NewSuspectRateRepository(db *gorm.DB, logger *slog.Logger) anomaly.Repository {
+	return &SuspectRateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new suspect.
+func (r *SuspectRateRepository) Create(ctx context.Context, s *anomaly.Suspect) error {
+	model, err := r.toModel(s)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// Find returns a suspect.
+func (r *SuspectRateRepository) Find(ctx context.Context, id string) (*anomaly.Suspect, error) {
+	var model SuspectRateModel
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, anomaly.ErrSuspectNotFound{ID: id}
+		}
+		return nil, err
+	}
+	return r.toSuspect(model)
+}
+
+// FindByRate returns the suspects of the rate of pair on date from source, newest first.
+func (r *SuspectRateRepository) FindByRate(ctx context.Context, pair currency.Pair, date time.Time, source rate.Source) ([]*anomaly.Suspect, error) {
+	var models []SuspectRateModel
+	err := r.db.WithContext(ctx).
+		Where("base_currency = ? AND quote_currency = ? AND effective_date = ? AND source = ?",
+			pair.Base().String(),
+			pair.Quote().String(),
+			timeutil.FormatDate(date),
+			string(source),
+		).
+		Order("created_at DESC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toSuspects(models)
+}
+
+// List returns up to limit suspects with status, or with any status if it is
+// empty, newest first.
+func (r *SuspectRateRepository) List(ctx context.Context, status anomaly.Status, limit int) ([]*anomaly.Suspect, error) {
+	query := r.db.WithContext(ctx)
+	if status != "" {
+		query = query.Where("status = ?", string(status))
+	}
+
+	var models []SuspectRateModel
+	err := query.
+		Order("created_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toSuspects(models)
+}
+
+// Update stores the review of a suspect.
+func (r *SuspectRateRepository) Update(ctx context.Context, s *anomaly.Suspect) error {
+	model, err := r.toModel(s)
+	if err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&SuspectRateModel{}).
+		Where("id = ?", s.ID).
+		Select("status", "reviewed_at", "reviewed_by", "note", "rate_id").
+		Updates(&model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return anomaly.ErrSuspectNotFound{ID: s.ID}
+	}
+	return nil
+}
+
+func (r *SuspectRateRepository) toModel(s *anomaly.Suspect) (SuspectRateModel, error) {
+	findings, err := json.Marshal(s.Findings)
+	if err != nil {
+		return SuspectRateModel{}, fmt.Errorf("encode findings: %w", err)
+	}
+
+	var reviewedAt *time.Time
+	if s.ReviewedAt != nil {
+		at := s.ReviewedAt.UTC()
+		reviewedAt = &at
+	}
+
+	return SuspectRateModel{
+		ID:            s.ID,
+		BaseCurrency:  s.Pair.Base().String(),
+		QuoteCurrency: s.Pair.Quote().String(),
+		Value:         s.Value,
+		EffectiveDate: Date(s.EffectiveDate),
+		Source:        string(s.Source),
+		Previous:      s.Previous,
+		Findings:      string(findings),
+		Status:        string(s.Status),
+		CreatedAt:     s.CreatedAt.UTC(),
+		ReviewedAt:    reviewedAt,
+		ReviewedBy:    s.ReviewedBy,
+		Note:          s.Note,
+		RateID:        s.RateID,
+	}, nil
+}
+
+func (r *SuspectRateRepository) toSuspect(model SuspectRateModel) (*anomaly.Suspect, error) {
+	pair, err := currency.ParsePair(model.BaseCurrency + "/" + model.QuoteCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []anomaly.Finding
+	if err := json.Unmarshal([]byte(model.Findings), &findings); err != nil {
+		return nil, fmt.Errorf("decode findings of suspect rate %s: %w", model.ID, err)
+	}
+
+	return &anomaly.Suspect{
+		ID:            model.ID,
+		Pair:          pair,
+		Value:         model.Value,
+		EffectiveDate: time.Time(model.EffectiveDate),
+		Source:        rate.Source(model.Source),
+		Previous:      model.Previous,
+		Findings:      findings,
+		Status:        anomaly.Status(model.Status),
+		CreatedAt:     model.CreatedAt,
+		ReviewedAt:    model.ReviewedAt,
+		ReviewedBy:    model.ReviewedBy,
+		Note:          model.Note,
+		RateID:        model.RateID,
+	}, nil
+}
+
+func (r *SuspectRateRepository) toSuspects(models []SuspectRateModel) ([]*anomaly.Suspect, error) {
+	suspects := make([]*anomaly.Suspect, len(models))
+	for i, model := range models {
+		s, err := r.toSuspect(model)
+		if err != nil {
+			return nil, err
+		}
+		suspects[i] = s
+	}
+	return suspects, nil
+}
@@ -0,0 +1,290 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+)
+
+// WebhookRepository implements webhook.Repository interface.
+// Like RateRepository, it works on PostgreSQL and SQLite.
+type WebhookRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+// NewWebhookRepository creates a new webhook repository.
+func NewWebhookRepository(db *gorm.DB, logger *slog.Logger) webhook.Repository {
+	return &WebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateSubscription stores a new subscription.
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, s *webhook.Subscription) error {
+	model := r.toSubscriptionModel(s)
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// FindSubscription returns a subscription.
+func (r *WebhookRepository) FindSubscription(ctx context.Context, id string) (*webhook.Subscription, error) {
+	var model WebhookSubscriptionModel
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, webhook.ErrSubscriptionNotFound{ID: id}
+		}
+		return nil, err
+	}
+	return r.toSubscription(model)
+}
+
+// ListSubscriptions returns all subscriptions, oldest first.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	var models []WebhookSubscriptionModel
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return r.toSubscriptions(models)
+}
+
+// FindActiveSubscriptions returns the active subscriptions to pair or its inverse.
+func (r *WebhookRepository) FindActiveSubscriptions(ctx context.Context, pair currency.Pair) ([]*webhook.Subscription, error) {
+	var models []WebhookSubscriptionModel
+	err := r.db.WithContext(ctx).
+		Where("active = ?", true).
+		Where("(base_currency = ? AND quote_currency = ?) OR (base_currency = ? AND quote_currency = ?)",
+			pair.Base().String(), pair.Quote().String(),
+			pair.Quote().String(), pair.Base().String(),
+		).
+		Order("created_at ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toSubscriptions(models)
+}
+
+// UpdateSubscription stores the changes to a subscription.
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, s *webhook.Subscription) error {
+	model := r.toSubscriptionModel(s)
+	result := r.db.WithContext(ctx).Model(&WebhookSubscriptionModel{}).
+		Where("id = ?", s.ID).
+		Select("url", "kind", "threshold", "description", "active", "updated_at").
+		Updates(&model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return webhook.ErrSubscriptionNotFound{ID: s.ID}
+	}
+	return nil
+}
+
+// DeleteSubscription deletes a subscription and its deliveries.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&WebhookSubscriptionModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return webhook.ErrSubscriptionNotFound{ID: id}
+		}
+		return tx.Where("subscription_id = ?", id).Delete(&WebhookDeliveryModel{}).Error
+	})
+}
+
+// CreateDeliveries stores new deliveries.
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*webhook.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	models := make([]WebhookDeliveryModel, len(deliveries))
+	for i, d := range deliveries {
+		models[i] = r.toDeliveryModel(d)
+	}
+	return r.db.WithContext(ctx).Create(&models).Error
+}
+
+// FindDelivery returns a delivery.
+func (r *WebhookRepository) FindDelivery(ctx context.Context, id string) (*webhook.Delivery, error) {
+	var model WebhookDeliveryModel
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, webhook.ErrDeliveryNotFound{ID: id}
+		}
+		return nil, err
+	}
+	return r.toDelivery(model), nil
+}
+
+// ListDeliveries returns up to limit deliveries of a subscription, newest first.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*webhook.Delivery, error) {
+	var models []WebhookDeliveryModel
+	err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("created_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.toDeliveries(models), nil
+}
+
+// ClaimDueDeliveries returns the pending deliveries due at now and postpones
+// them by lease. A delivery is only claimed if its due time is still the one
+// that was read, so concurrent claims of the same delivery fail on all but one
+// process without needing row locks, which SQLite lacks.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	now = now.UTC()
+
+	var models []WebhookDeliveryModel
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", string(webhook.DeliveryPending), now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]WebhookDeliveryModel, 0, len(models))
+	for _, model := range models {
+		result := r.db.WithContext(ctx).Model(&WebhookDeliveryModel{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", model.ID, string(webhook.DeliveryPending), model.NextAttemptAt).
+			Update("next_attempt_at", now.Add(lease))
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			claimed = append(claimed, model)
+		}
+	}
+	return r.toDeliveries(claimed), nil
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt.
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	model := r.toDeliveryModel(d)
+	result := r.db.WithContext(ctx).Model(&WebhookDeliveryModel{}).
+		Where("id = ?", d.ID).
+		Select("status", "attempts", "next_attempt_at", "last_attempt_at", "response_status", "last_error").
+		Updates(&model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return webhook.ErrDeliveryNotFound{ID: d.ID}
+	}
+	return nil
+}
+
+func (r *WebhookRepository) toSubscriptionModel(s *webhook.Subscription) WebhookSubscriptionModel {
+	return WebhookSubscriptionModel{
+		ID:            s.ID,
+		URL:           s.URL,
+		Secret:        s.Secret,
+		BaseCurrency:  s.Pair.Base().String(),
+		QuoteCurrency: s.Pair.Quote().String(),
+		Kind:          string(s.Condition.Kind),
+		Threshold:     s.Condition.Threshold,
+		Description:   s.Description,
+		Active:        s.Active,
+		CreatedAt:     s.CreatedAt.UTC(),
+		UpdatedAt:     s.UpdatedAt.UTC(),
+	}
+}
+
+func (r *WebhookRepository) toSubscription(model WebhookSubscriptionModel) (*webhook.Subscription, error) {
+	pair, err := currency.ParsePair(model.BaseCurrency + "/" + model.QuoteCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook.Subscription{
+		ID:     model.ID,
+		URL:    model.URL,
+		Secret: model.Secret,
+		Pair:   pair,
+		Condition: webhook.Condition{
+			Kind:      webhook.ConditionKind(model.Kind),
+			Threshold: model.Threshold,
+		},
+		Description: model.Description,
+		Active:      model.Active,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}, nil
+}
+
+func (r *WebhookRepository) toSubscriptions(models []WebhookSubscriptionModel) ([]*webhook.Subscription, error) {
+	subscriptions := make([]*webhook.Subscription, len(models))
+	for i, model := range models {
+		s, err := r.toSubscription(model)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions[i] = s
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) toDeliveryModel(d *webhook.Delivery) WebhookDeliveryModel {
+	var lastAttemptAt *time.Time
+	if d.LastAttemptAt != nil {
+		at := d.LastAttemptAt.UTC()
+		lastAttemptAt = &at
+	}
+
+	return WebhookDeliveryModel{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Payload:        string(d.Payload),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt.UTC(),
+		LastAttemptAt:  lastAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt.UTC(),
+	}
+}
+
+func (r *WebhookRepository) toDelivery(model WebhookDeliveryModel) *webhook.Delivery {
+	return &webhook.Delivery{
+		ID:             model.ID,
+		SubscriptionID: model.SubscriptionID,
+		EventID:        model.EventID,
+		EventType:      webhook.EventType(model.EventType),
+		Payload:        []byte(model.Payload),
+		Status:         webhook.DeliveryStatus(model.Status),
+		Attempts:       model.Attempts,
+		NextAttemptAt:  model.NextAttemptAt,
+		LastAttemptAt:  model.LastAttemptAt,
+		ResponseStatus: model.ResponseStatus,
+		LastError:      model.LastError,
+		CreatedAt:      model.CreatedAt,
+	}
+}
+
+func (r *WebhookRepository) toDeliveries(models []WebhookDeliveryModel) []*webhook.Delivery {
+	deliveries := make([]*webhook.Delivery, len(models))
+	for i, model := range models {
+		deliveries[i] = r.toDelivery(model)
+	}
+	return deliveries
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+)
+
+// Keys builds cache keys in versioned namespaces, one per currency pair.
+//
+// Both directions of a pair share a namespace, so CNY/JPY and JPY/CNY entries
+// live under the same version:
+//
+//	ns:rates:CNY:JPY                                  -> version, e.g. "lq3c0f2k8"
+//	rates:CNY:JPY:lq3c0f2k8:JPY/CNY:latest            -> cached value
+//	rates:CNY:JPY:lq3c0f2k8:CNY/JPY:bydate:2024-01-15 -> cached value
+//
+// Invalidating a pair deletes its version key. Readers then start a new
+// version, so every entry derived from the pair - latest, by date, lists or
+// conversions, in either direction - becomes unreachable at once and simply
+// expires with its TTL. Cached values never need to be enumerated.
+type Keys struct {
+	cache  CacheInterface
+	logger *slog.Logger
+}
+
+// NewKeys creates a key builder that stores namespace versions in cache.
+func NewKeys(cache CacheInterface, logger *slog.Logger) *Keys {
+	return &Keys{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// Key returns the cache key for an entry derived from pair.
+// The requested direction of the pair is part of the key.
+func (k *Keys) Key(ctx context.Context, pair currency.Pair, parts ...string) string {
+	var b strings.Builder
+	b.WriteString(namespace(pair))
+	b.WriteByte(':')
+	b.WriteString(k.version(ctx, pair))
+	b.WriteByte(':')
+	b.WriteString(pair.String())
+	for _, part := range parts {
+		b.WriteByte(':')
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// Invalidate drops every cached entry of the given pairs, in both directions.
+func (k *Keys) Invalidate(ctx context.Context, pairs ...currency.Pair) error {
+	keys := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		keys = append(keys, versionKey(pair))
+	}
+
+	if err := k.cache.Delete(ctx, keys...); err != nil {
+		return err
+	}
+
+	k.logger.Debug("cache namespaces invalidated", "keys", keys)
+	return nil
+}
+
+// version returns the current version of the pair's namespace, starting a new
+// one if there is none.
+func (k *Keys) version(ctx context.Context, pair currency.Pair) string {
+	key := versionKey(pair)
+
+	var version string
+	if err := k.cache.Get(ctx, key, &version); err == nil && version != "" {
+		return version
+	}
+
+	// Versions only need to differ from earlier ones, so the clock is enough.
+	// Two readers racing here just leave one of their versions unused.
+	version = strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := k.cache.Set(ctx, key, version, 0); err != nil {
+		k.logger.Warn("failed to store cache namespace version", "key", key, "error", err)
+	}
+	return version
+}
+
+// namespace returns the key prefix shared by both directions of a pair.
+func namespace(pair currency.Pair) string {
+	base, quote := pair.Base().String(), pair.Quote().String()
+	if quote < base {
+		base, quote = quote, base
+	}
+	return "rates:" + base + ":" + quote
+}
+
+// versionKey returns the key holding the current version of a pair's namespace.
+func versionKey(pair currency.Pair) string {
+	return "ns:" + namespace(pair)
+}
@@ -0,0 +1,113 @@
+package redis_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+)
+
+func TestKeys_InvalidateCoversBothDirections(t *testing.T) {
+	ctx := context.Background()
+	keys := redis.NewKeys(memory.NewCache(100, logger.NewNoop()), logger.NewNoop())
+
+	cnyJPY := currency.MustNewPair(currency.CNY, currency.JPY)
+	jpyCNY := cnyJPY.Inverse()
+	usdJPY := currency.MustNewPair(currency.USD, currency.JPY)
+
+	before := map[string]string{
+		"latest":         keys.Key(ctx, cnyJPY, "latest"),
+		"inverse latest": keys.Key(ctx, jpyCNY, "latest"),
+		"by date":        keys.Key(ctx, cnyJPY, "bydate", "2024-01-15"),
+		"other pair":     keys.Key(ctx, usdJPY, "latest"),
+	}
+
+	if before["latest"] == before["inverse latest"] {
+		t.Fatalf("directions share key %s", before["latest"])
+	}
+	if got := keys.Key(ctx, cnyJPY, "latest"); got != before["latest"] {
+		t.Fatalf("Key() = %s, want stable %s", got, before["latest"])
+	}
+
+	if err := keys.Invalidate(ctx, jpyCNY); err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+
+	after := map[string]string{
+		"latest":         keys.Key(ctx, cnyJPY, "latest"),
+		"inverse latest": keys.Key(ctx, jpyCNY, "latest"),
+		"by date":        keys.Key(ctx, cnyJPY, "bydate", "2024-01-15"),
+		"other pair":     keys.Key(ctx, usdJPY, "latest"),
+	}
+
+	for name, key := range before {
+		changed := after[name] != key
+		if want := name != "other pair"; changed != want {
+			t.Errorf("%s: key changed = %v, want %v (%s -> %s)", name, changed, want, key, after[name])
+		}
+	}
+}
+
+func TestTieredCache_BroadcastsInvalidations(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	redisCfg := config.RedisConfig{Host: server.Host(), Port: mustPort(t, server)}
+
+	// Two replicas sharing one Redis, each with its own in-process tier
+	newReplica := func() *redis.TieredCache {
+		remote := redis.NewCache(redisCfg, logger.NewNoop())
+		cache := redis.NewTieredCache(memory.NewCache(100, logger.NewNoop()), remote, time.Minute, logger.NewNoop())
+		cache.Broadcast(remote)
+		t.Cleanup(func() { _ = cache.Close() })
+		return cache
+	}
+	writer, reader := newReplica(), newReplica()
+	writerKeys := redis.NewKeys(writer, logger.NewNoop())
+	readerKeys := redis.NewKeys(reader, logger.NewNoop())
+
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	before := readerKeys.Key(ctx, pair, "latest")
+	if got := writerKeys.Key(ctx, pair, "latest"); got != before {
+		t.Fatalf("replicas disagree on key: %s != %s", got, before)
+	}
+
+	// Wait until both subscriptions are active
+	deadline := time.Now().Add(time.Second)
+	for server.PubSubNumSub(redis.InvalidationChannel)[redis.InvalidationChannel] < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscriptions not established")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := writerKeys.Invalidate(ctx, pair); err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+
+	// Without the broadcast the reader would keep the old version in its
+	// in-process tier for the whole local TTL
+	for readerKeys.Key(ctx, pair, "latest") == before {
+		if time.Now().After(deadline) {
+			t.Fatal("reader still uses the invalidated namespace")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func mustPort(t *testing.T, server *miniredis.Miniredis) int {
+	t.Helper()
+
+	port, err := strconv.Atoi(server.Port())
+	if err != nil {
+		t.Fatalf("invalid miniredis port %q: %v", server.Port(), err)
+	}
+	return port
+}
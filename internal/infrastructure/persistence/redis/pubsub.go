@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// InvalidationChannel is the pub/sub channel on which deleted cache keys are broadcast.
+const InvalidationChannel = "rateflow:cache:invalidate"
+
+// Invalidations broadcasts deleted cache keys between replicas.
+type Invalidations interface {
+	// Publish announces that keys were deleted.
+	Publish(ctx context.Context, keys ...string) error
+	// Subscribe calls handle with the keys of every announcement until ctx is done.
+	Subscribe(ctx context.Context, handle func(keys []string))
+}
+
+// Ensure Cache implements Invalidations
+var _ Invalidations = (*Cache)(nil)
+
+// Publish announces deleted keys on InvalidationChannel.
+func (c *Cache) Publish(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	if err := c.client.Publish(ctx, InvalidationChannel, data).Err(); err != nil {
+		c.logger.Error("cache invalidation publish error", "keys", keys, "error", err)
+		return err
+	}
+
+	c.logger.Debug("cache invalidation published", "keys", keys)
+	return nil
+}
+
+// Subscribe listens on InvalidationChannel in the background until ctx is done.
+// The subscription reconnects by itself when the connection to Redis is lost.
+func (c *Cache) Subscribe(ctx context.Context, handle func(keys []string)) {
+	sub := c.client.Subscribe(ctx, InvalidationChannel)
+
+	go func() {
+		defer sub.Close()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var keys []string
+				if err := json.Unmarshal([]byte(msg.Payload), &keys); err != nil {
+					c.logger.Warn("invalid cache invalidation message", "payload", msg.Payload, "error", err)
+					continue
+				}
+				handle(keys)
+			}
+		}
+	}()
+}
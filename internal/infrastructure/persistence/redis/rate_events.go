@@ -0,0 +1,169 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+)
+
+const (
+	// RateEventsStream is the stream retaining the most recent rate events.
+	RateEventsStream = "rateflow:rates:events"
+	// RateEventsChannel is the pub/sub channel on which rate events are delivered.
+	RateEventsChannel = "rateflow:rates:stored"
+)
+
+// streamID matches the IDs of stream entries, e.g. 1705280400000-0.
+var streamID = regexp.MustCompile(`^\d+(-\d+)?$`)
+
+// RateEvents is a rate.EventLog shared by all processes through Redis.
+// Events are appended to a capped stream, whose entry IDs become the event IDs,
+// and delivered over pub/sub.
+type RateEvents struct {
+	client    *redis.Client
+	retention int64
+	logger    *slog.Logger
+}
+
+// Ensure RateEvents implements rate.EventLog
+var _ rate.EventLog = (*RateEvents)(nil)
+
+// NewRateEvents creates an event log retaining about retention events.
+func NewRateEvents(cfg config.RedisConfig, retention int64, logger *slog.Logger) *RateEvents {
+	return &RateEvents{
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr(),
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}),
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Publish implements rate.EventLog.
+func (e *RateEvents) Publish(ctx context.Context, event rate.Event) error {
+	event.ID = ""
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	id, err := e.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: RateEventsStream,
+		MaxLen: e.retention,
+		Approx: true,
+		Values: map[string]any{"event": data},
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("append rate event: %w", err)
+	}
+
+	event.ID = id
+	if data, err = json.Marshal(event); err != nil {
+		return err
+	}
+	if err := e.client.Publish(ctx, RateEventsChannel, data).Err(); err != nil {
+		return fmt.Errorf("publish rate event: %w", err)
+	}
+
+	e.logger.Debug("rate event published", "id", id, "pair", event.Pair)
+	return nil
+}
+
+// After implements rate.EventLog.
+func (e *RateEvents) After(ctx context.Context, id string) ([]rate.Event, error) {
+	if !streamID.MatchString(id) {
+		return nil, rate.ErrInvalidEventID{ID: id}
+	}
+
+	// The range is inclusive, and id itself may have been trimmed already
+	messages, err := e.client.XRange(ctx, RateEventsStream, id, "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("read rate events: %w", err)
+	}
+
+	events := make([]rate.Event, 0, len(messages))
+	for _, msg := range messages {
+		if msg.ID == id {
+			continue
+		}
+		event, err := decodeStreamEvent(msg)
+		if err != nil {
+			e.logger.Warn("invalid rate event", "id", msg.ID, "error", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// decodeStreamEvent decodes the event stored in a stream entry.
+func decodeStreamEvent(msg redis.XMessage) (rate.Event, error) {
+	data, ok := msg.Values["event"].(string)
+	if !ok {
+		return rate.Event{}, errors.New("missing event field")
+	}
+
+	var event rate.Event
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return rate.Event{}, err
+	}
+	event.ID = msg.ID
+	return event, nil
+}
+
+// Subscribe implements rate.EventLog. It returns once the subscription is
+// active, so events published afterwards are not missed; the subscription
+// reconnects by itself when the connection to Redis is lost.
+func (e *RateEvents) Subscribe(ctx context.Context) (<-chan rate.Event, error) {
+	sub := e.client.Subscribe(ctx, RateEventsChannel)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("subscribe to rate events: %w", err)
+	}
+
+	events := make(chan rate.Event, 16)
+	go func() {
+		defer close(events)
+		defer sub.Close()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event rate.Event
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					e.logger.Warn("invalid rate event message", "payload", msg.Payload, "error", err)
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// Close implements rate.EventLog.
+func (e *RateEvents) Close() error {
+	return e.client.Close()
+}
@@ -0,0 +1,87 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+)
+
+func TestRateEvents(t *testing.T) {
+	server := miniredis.RunT(t)
+	redisCfg := config.RedisConfig{Host: server.Host(), Port: mustPort(t, server)}
+
+	logs := map[string]rate.EventLog{
+		"redis":  redis.NewRateEvents(redisCfg, 100, logger.NewNoop()),
+		"memory": memory.NewRateEvents(100, logger.NewNoop()),
+	}
+
+	for name, log := range logs {
+		t.Run(name, func(t *testing.T) {
+			defer log.Close()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			live, err := log.Subscribe(ctx)
+			if err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+
+			pairs := []string{"CNY/JPY", "USD/JPY", "EUR/JPY"}
+			for _, pair := range pairs {
+				if err := log.Publish(ctx, rate.Event{RateID: "rate-" + pair, Pair: pair, Value: 1.5}); err != nil {
+					t.Fatalf("Publish() error = %v", err)
+				}
+			}
+
+			var received []rate.Event
+			for range pairs {
+				select {
+				case event := <-live:
+					received = append(received, event)
+				case <-time.After(time.Second):
+					t.Fatalf("received %d events, want %d", len(received), len(pairs))
+				}
+			}
+			for i, event := range received {
+				if event.Pair != pairs[i] || event.ID == "" {
+					t.Errorf("event %d = %+v, want %s with an ID", i, event, pairs[i])
+				}
+			}
+
+			// Resuming after the first event replays the others with the same IDs
+			missed, err := log.After(ctx, received[0].ID)
+			if err != nil {
+				t.Fatalf("After() error = %v", err)
+			}
+			if len(missed) != 2 || missed[0].ID != received[1].ID || missed[1].ID != received[2].ID {
+				t.Errorf("After() = %+v, want the last two events", missed)
+			}
+			if missed[0].RateID != "rate-USD/JPY" {
+				t.Errorf("replayed event = %+v, want the USD/JPY rate", missed[0])
+			}
+
+			if _, err := log.After(ctx, "not-an-id"); !errors.As(err, new(rate.ErrInvalidEventID)) {
+				t.Errorf("After() error = %v, want ErrInvalidEventID", err)
+			}
+
+			cancel()
+			select {
+			case _, ok := <-live:
+				if ok {
+					t.Error("subscription delivered an event after its context was done")
+				}
+			case <-time.After(time.Second):
+				t.Error("subscription not closed after its context was done")
+			}
+		})
+	}
+}
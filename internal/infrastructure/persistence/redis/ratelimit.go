@@ -0,0 +1,203 @@
+package redis
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+)
+
+// Limit allows Requests requests per Window.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// LimitResult is the outcome of counting a request against a Limit.
+type LimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // requests left in the window
+	Reset      time.Duration // until the current window ends
+	RetryAfter time.Duration // until a request would be allowed again; zero when allowed
+}
+
+// Limiter counts requests in sliding windows.
+type Limiter interface {
+	// Allow counts a request for key at now and reports whether it is within
+	// limit. Rejected requests are not counted.
+	Allow(ctx context.Context, key string, limit Limit, now time.Time) (LimitResult, error)
+
+	// Close releases the limiter's resources.
+	Close() error
+}
+
+// Windows returns the fixed window that now falls in, identified by its index
+// since the epoch, and how far into it now is.
+func (l Limit) Windows(now time.Time) (index int64, elapsed time.Duration) {
+	window := l.Window.Milliseconds()
+	ms := now.UnixMilli()
+	return ms / window, time.Duration(ms%window) * time.Millisecond
+}
+
+// weight returns the share of the previous window that still overlaps the
+// sliding window ending at elapsed into the current one.
+func (l Limit) weight(elapsed time.Duration) float64 {
+	return 1 - float64(elapsed)/float64(l.Window)
+}
+
+// Decide applies the sliding window to the request counts of the previous and
+// current fixed windows, elapsed into the current one.
+//
+// The sliding window is approximated by weighting the previous window with
+// its overlap, as a sliding log would need to store every request.
+func (l Limit) Decide(prev, curr int64, elapsed time.Duration) LimitResult {
+	allowed := l.estimate(prev, curr, elapsed)+1 <= float64(l.Requests)
+	return l.result(prev, curr, elapsed, allowed)
+}
+
+// estimate returns the number of requests in the sliding window.
+func (l Limit) estimate(prev, curr int64, elapsed time.Duration) float64 {
+	return float64(prev)*l.weight(elapsed) + float64(curr)
+}
+
+// result describes the decision on a request made with the given counts.
+func (l Limit) result(prev, curr int64, elapsed time.Duration, allowed bool) LimitResult {
+	estimate := l.estimate(prev, curr, elapsed)
+	result := LimitResult{
+		Allowed: allowed,
+		Limit:   l.Requests,
+		Reset:   l.Window - elapsed,
+	}
+	if allowed {
+		estimate++
+	} else {
+		result.RetryAfter = l.retryAfter(prev, curr, elapsed)
+	}
+	result.Remaining = max(int(math.Floor(float64(l.Requests)-estimate)), 0)
+
+	return result
+}
+
+// retryAfter returns how long until a request would be allowed.
+func (l Limit) retryAfter(prev, curr int64, elapsed time.Duration) time.Duration {
+	window := float64(l.Window)
+	room := float64(l.Requests - 1)
+
+	// The current window alone is full: wait for it to become the previous
+	// window and to overlap little enough
+	if float64(curr) > room {
+		overlap := 1 - room/float64(curr)
+		return l.Window - elapsed + time.Duration(math.Round(window*overlap))
+	}
+
+	// The previous window has to slide out far enough
+	share := (room - float64(curr)) / float64(prev)
+	return max(time.Duration(math.Round(window*(1-share)))-elapsed, 0)
+}
+
+// slidingWindowScript counts a request in the current window unless the
+// weighted estimate of the sliding window is already at the limit.
+// It returns whether the request was counted and the counts before it.
+//
+//go:embed ratelimit.lua
+var slidingWindowScript string
+
+var slidingWindow = redis.NewScript(slidingWindowScript)
+
+// RateLimiter keeps sliding-window counters in Redis, so that all replicas
+// share the limits.
+type RateLimiter struct {
+	client *redis.Client
+	logger *slog.Logger
+}
+
+// Ensure RateLimiter implements Limiter
+var _ Limiter = (*RateLimiter)(nil)
+
+// NewRateLimiter creates a Redis rate limiter.
+func NewRateLimiter(cfg config.RedisConfig, logger *slog.Logger) *RateLimiter {
+	return &RateLimiter{
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr(),
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}),
+		logger: logger,
+	}
+}
+
+// Allow implements Limiter.
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit Limit, now time.Time) (LimitResult, error) {
+	index, elapsed := limit.Windows(now)
+	keys := []string{
+		"ratelimit:" + key + ":" + strconv.FormatInt(index, 10),
+		"ratelimit:" + key + ":" + strconv.FormatInt(index-1, 10),
+	}
+
+	// The counter of a window is needed while it is the current or previous one
+	ttl := 2 * limit.Window.Milliseconds()
+	counts, err := slidingWindow.Run(ctx, l.client, keys, limit.weight(elapsed), limit.Requests, ttl).Int64Slice()
+	if err != nil {
+		return LimitResult{}, fmt.Errorf("run sliding window script: %w", err)
+	}
+
+	// The script decided atomically; the result only describes its decision
+	return limit.result(counts[1], counts[2], elapsed, counts[0] == 1), nil
+}
+
+// Close closes the Redis connection.
+func (l *RateLimiter) Close() error {
+	return l.client.Close()
+}
+
+// FallbackLimiter uses a primary limiter, normally Redis, and falls back to a
+// local one while the primary fails. Limits are then enforced per replica.
+type FallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	degraded atomic.Bool
+	logger   *slog.Logger
+}
+
+// Ensure FallbackLimiter implements Limiter
+var _ Limiter = (*FallbackLimiter)(nil)
+
+// NewFallbackLimiter creates a limiter that falls back to fallback when primary fails.
+func NewFallbackLimiter(primary, fallback Limiter, logger *slog.Logger) *FallbackLimiter {
+	return &FallbackLimiter{
+		primary:  primary,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+// Allow implements Limiter.
+func (l *FallbackLimiter) Allow(ctx context.Context, key string, limit Limit, now time.Time) (LimitResult, error) {
+	result, err := l.primary.Allow(ctx, key, limit, now)
+	if err == nil {
+		if l.degraded.CompareAndSwap(true, false) {
+			l.logger.Info("rate limiter recovered, sharing limits through redis again")
+		}
+		return result, nil
+	}
+
+	if l.degraded.CompareAndSwap(false, true) {
+		l.logger.Warn("rate limiter unavailable, enforcing limits per replica", "error", err)
+	}
+	return l.fallback.Allow(ctx, key, limit, now)
+}
+
+// Close closes both limiters.
+func (l *FallbackLimiter) Close() error {
+	return errors.Join(l.primary.Close(), l.fallback.Close())
+}
@@ -0,0 +1,12 @@
+-- KEYS[1]: counter of the current window, KEYS[2]: counter of the previous window
+-- ARGV[1]: weight of the previous window, ARGV[2]: limit, ARGV[3]: counter TTL in ms
+local curr = tonumber(redis.call('GET', KEYS[1]) or '0')
+local prev = tonumber(redis.call('GET', KEYS[2]) or '0')
+
+if prev * tonumber(ARGV[1]) + curr + 1 > tonumber(ARGV[2]) then
+  return {0, prev, curr}
+end
+
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {1, prev, curr}
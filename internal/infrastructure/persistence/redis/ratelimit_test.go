@@ -0,0 +1,119 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+)
+
+func TestLimit_Decide(t *testing.T) {
+	limit := redis.Limit{Requests: 10, Window: time.Minute}
+
+	tests := []struct {
+		name       string
+		prev, curr int64
+		elapsed    time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}{
+		{"empty", 0, 0, 0, true, 9, 0},
+		{"last request of the window", 0, 9, 10 * time.Second, true, 0, 0},
+		{"current window full", 0, 10, 15 * time.Second, false, 0, 51 * time.Second},
+		{"previous window half slid out", 10, 0, 30 * time.Second, true, 4, 0},
+		{"previous window still weighs", 10, 5, 30 * time.Second, false, 0, 6 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := limit.Decide(tt.prev, tt.curr, tt.elapsed)
+			if got.Allowed != tt.allowed || got.Remaining != tt.remaining || got.RetryAfter != tt.retryAfter {
+				t.Errorf("Decide() = %+v, want allowed %v, remaining %d, retry after %v",
+					got, tt.allowed, tt.remaining, tt.retryAfter)
+			}
+			if got.Reset != limit.Window-tt.elapsed {
+				t.Errorf("Reset = %v, want %v", got.Reset, limit.Window-tt.elapsed)
+			}
+		})
+	}
+}
+
+func TestRateLimiters(t *testing.T) {
+	server := miniredis.RunT(t)
+	redisCfg := config.RedisConfig{Host: server.Host(), Port: mustPort(t, server)}
+
+	limiters := map[string]redis.Limiter{
+		"redis":  redis.NewRateLimiter(redisCfg, logger.NewNoop()),
+		"memory": memory.NewRateLimiter(logger.NewNoop()),
+	}
+
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			defer limiter.Close()
+			ctx := context.Background()
+			limit := redis.Limit{Requests: 3, Window: time.Minute}
+			start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+			allow := func(key string, at time.Time) redis.LimitResult {
+				t.Helper()
+				result, err := limiter.Allow(ctx, name+":"+key, limit, at)
+				if err != nil {
+					t.Fatalf("Allow() error = %v", err)
+				}
+				return result
+			}
+
+			for i := range 3 {
+				if result := allow("a", start.Add(time.Duration(i)*time.Second)); !result.Allowed || result.Remaining != 2-i {
+					t.Fatalf("request %d: %+v, want allowed with %d remaining", i+1, result, 2-i)
+				}
+			}
+			if result := allow("a", start.Add(3*time.Second)); result.Allowed || result.RetryAfter <= 0 {
+				t.Fatalf("request over the limit: %+v, want rejected with a retry time", result)
+			}
+			if result := allow("b", start.Add(3*time.Second)); !result.Allowed {
+				t.Errorf("other key: %+v, want allowed", result)
+			}
+
+			// Early in the next window the full previous window still counts
+			if result := allow("a", start.Add(61*time.Second)); result.Allowed {
+				t.Errorf("start of next window: %+v, want rejected", result)
+			}
+			// Two thirds into it, a third of the previous window still counts
+			if result := allow("a", start.Add(100*time.Second)); !result.Allowed || result.Remaining != 1 {
+				t.Errorf("two thirds into next window: %+v, want allowed with 1 remaining", result)
+			}
+		})
+	}
+}
+
+func TestFallbackLimiter(t *testing.T) {
+	server := miniredis.RunT(t)
+	redisCfg := config.RedisConfig{Host: server.Host(), Port: mustPort(t, server)}
+	limiter := redis.NewFallbackLimiter(
+		redis.NewRateLimiter(redisCfg, logger.NewNoop()),
+		memory.NewRateLimiter(logger.NewNoop()),
+		logger.NewNoop(),
+	)
+	defer limiter.Close()
+
+	ctx := context.Background()
+	limit := redis.Limit{Requests: 1, Window: time.Minute}
+	now := time.Now()
+
+	server.SetError("LOADING Redis is loading the dataset in memory")
+	result, err := limiter.Allow(ctx, "client", limit, now)
+	if err != nil || !result.Allowed {
+		t.Fatalf("Allow() with redis down = %+v, %v; want allowed by the fallback", result, err)
+	}
+	if result, _ := limiter.Allow(ctx, "client", limit, now); result.Allowed {
+		t.Errorf("fallback does not enforce the limit: %+v", result)
+	}
+}
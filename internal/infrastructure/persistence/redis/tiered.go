@@ -0,0 +1,162 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// DefaultLocalTTL is the local TTL of a tiered cache created without one.
+const DefaultLocalTTL = 30 * time.Second
+
+// TieredCache combines a fast local cache with a shared remote cache.
+// Reads try the local tier first and fill it from the remote tier on a hit,
+// so hot keys are served without a network round trip. Writes go to both tiers.
+//
+// The remote tier is best effort: when it fails, the local tier keeps serving
+// and writes still succeed locally. Entries live in the local tier for at most
+// localTTL, which bounds how long a replica can serve a value that another
+// process has already invalidated in the remote tier. With Broadcast enabled,
+// deletions reach the local tiers of other replicas right away.
+type TieredCache struct {
+	local    CacheInterface
+	remote   CacheInterface
+	localTTL time.Duration
+	bus      Invalidations
+	stop     context.CancelFunc
+	logger   *slog.Logger
+}
+
+// Ensure TieredCache implements CacheInterface
+var _ CacheInterface = (*TieredCache)(nil)
+
+// NewTieredCache creates a two-tier cache.
+// A non-positive localTTL selects DefaultLocalTTL.
+func NewTieredCache(local, remote CacheInterface, localTTL time.Duration, logger *slog.Logger) *TieredCache {
+	if localTTL <= 0 {
+		localTTL = DefaultLocalTTL
+	}
+
+	return &TieredCache{
+		local:    local,
+		remote:   remote,
+		localTTL: localTTL,
+		logger:   logger,
+	}
+}
+
+// Broadcast shares deletions with other replicas through bus: keys deleted
+// through this cache are published, and keys published by other replicas are
+// dropped from the local tier. It must be called before the cache is used;
+// the subscription ends when the cache is closed.
+func (c *TieredCache) Broadcast(bus Invalidations) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.bus = bus
+	c.stop = cancel
+
+	bus.Subscribe(ctx, func(keys []string) {
+		if err := c.local.Delete(ctx, keys...); err != nil {
+			c.logger.Warn("failed to drop invalidated keys from local cache", "keys", keys, "error", err)
+		}
+	})
+}
+
+// Get retrieves a value from the local tier, falling back to the remote tier.
+func (c *TieredCache) Get(ctx context.Context, key string, dest any) error {
+	if err := c.local.Get(ctx, key, dest); err == nil {
+		return nil
+	}
+
+	if err := c.remote.Get(ctx, key, dest); err != nil {
+		return err
+	}
+
+	// The remaining remote TTL is unknown, so the local copy gets the local TTL
+	if err := c.local.Set(ctx, key, dest, c.localTTL); err != nil {
+		c.logger.Warn("failed to fill local cache", "key", key, "error", err)
+	}
+
+	return nil
+}
+
+// Set stores a value in both tiers. A remote failure is logged, not returned.
+func (c *TieredCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if err := c.local.Set(ctx, key, value, c.capTTL(ttl)); err != nil {
+		return err
+	}
+
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		c.logger.Warn("remote cache set failed, value cached locally only", "key", key, "error", err)
+	}
+
+	return nil
+}
+
+// Delete removes keys from both tiers and, with Broadcast enabled, from the
+// local tiers of other replicas.
+// A remote failure is returned because other replicas may still see the keys.
+func (c *TieredCache) Delete(ctx context.Context, keys ...string) error {
+	if err := c.local.Delete(ctx, keys...); err != nil {
+		return err
+	}
+
+	err := c.remote.Delete(ctx, keys...)
+	if c.bus != nil {
+		err = errors.Join(err, c.bus.Publish(ctx, keys...))
+	}
+	return err
+}
+
+// Exists returns how many of the given keys exist in either tier.
+func (c *TieredCache) Exists(ctx context.Context, keys ...string) (int64, error) {
+	var count int64
+	for _, key := range keys {
+		n, err := c.local.Exists(ctx, key)
+		if err == nil && n > 0 {
+			count++
+			continue
+		}
+
+		n, err = c.remote.Exists(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// Expire sets a timeout on a key in both tiers.
+func (c *TieredCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.local.Expire(ctx, key, c.capTTL(ttl)); err != nil {
+		return err
+	}
+	return c.remote.Expire(ctx, key, ttl)
+}
+
+// Ping checks the remote tier; the local tier is always available.
+func (c *TieredCache) Ping(ctx context.Context) error {
+	return c.remote.Ping(ctx)
+}
+
+// Close ends the broadcast subscription and closes both tiers.
+func (c *TieredCache) Close() error {
+	if c.stop != nil {
+		c.stop()
+	}
+
+	localErr := c.local.Close()
+	if err := c.remote.Close(); err != nil {
+		return err
+	}
+	return localErr
+}
+
+// capTTL limits a TTL to the local TTL; entries without expiry get the local TTL.
+func (c *TieredCache) capTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 || ttl > c.localTTL {
+		return c.localTTL
+	}
+	return ttl
+}
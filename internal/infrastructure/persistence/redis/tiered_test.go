@@ -0,0 +1,110 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+)
+
+// countingCache wraps a cache and counts Get calls, optionally failing all operations.
+type countingCache struct {
+	redis.CacheInterface
+	gets int
+	down bool
+}
+
+var errDown = errors.New("connection refused")
+
+func (c *countingCache) Get(ctx context.Context, key string, dest any) error {
+	c.gets++
+	if c.down {
+		return errDown
+	}
+	return c.CacheInterface.Get(ctx, key, dest)
+}
+
+func (c *countingCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if c.down {
+		return errDown
+	}
+	return c.CacheInterface.Set(ctx, key, value, ttl)
+}
+
+func (c *countingCache) Ping(ctx context.Context) error {
+	if c.down {
+		return errDown
+	}
+	return nil
+}
+
+func newRemote() *countingCache {
+	return &countingCache{CacheInterface: memory.NewCache(100, logger.NewNoop())}
+}
+
+func TestTieredCache_ServesHotKeysLocally(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemote()
+	cache := redis.NewTieredCache(memory.NewCache(100, logger.NewNoop()), remote, time.Minute, logger.NewNoop())
+
+	if err := cache.Set(ctx, "latest:CNY/JPY", 20.5, 5*time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	for range 3 {
+		var got float64
+		if err := cache.Get(ctx, "latest:CNY/JPY", &got); err != nil || got != 20.5 {
+			t.Fatalf("Get() = %v, %v, want 20.5", got, err)
+		}
+	}
+
+	if remote.gets != 0 {
+		t.Errorf("remote Get calls = %d, want 0", remote.gets)
+	}
+}
+
+func TestTieredCache_FillsLocalFromRemote(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemote()
+	cache := redis.NewTieredCache(memory.NewCache(100, logger.NewNoop()), remote, time.Minute, logger.NewNoop())
+
+	// Written by another replica
+	if err := remote.Set(ctx, "latest:CNY/JPY", 20.5, 5*time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var got float64
+	for range 2 {
+		if err := cache.Get(ctx, "latest:CNY/JPY", &got); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+
+	if remote.gets != 1 {
+		t.Errorf("remote Get calls = %d, want 1", remote.gets)
+	}
+}
+
+func TestTieredCache_DegradesWhenRemoteIsDown(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemote()
+	remote.down = true
+	cache := redis.NewTieredCache(memory.NewCache(100, logger.NewNoop()), remote, time.Minute, logger.NewNoop())
+
+	if err := cache.Ping(ctx); err == nil {
+		t.Error("Ping() error = nil, want remote error")
+	}
+
+	if err := cache.Set(ctx, "latest:CNY/JPY", 20.5, 5*time.Minute); err != nil {
+		t.Fatalf("Set() error = %v, want nil while remote is down", err)
+	}
+
+	var got float64
+	if err := cache.Get(ctx, "latest:CNY/JPY", &got); err != nil || got != 20.5 {
+		t.Errorf("Get() = %v, %v, want 20.5", got, err)
+	}
+}
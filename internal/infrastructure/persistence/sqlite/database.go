@@ -0,0 +1,62 @@
+// Package sqlite provides a SQLite database connection for single-node deployments.
+// The schema and the rate repository are shared with the postgres package.
+package sqlite
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+
+	sqlitelib "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/postgres"
+)
+
+func init() {
+	// The shared schema and migrations generate IDs with the PostgreSQL function
+	sqlitelib.MustRegisterScalarFunction("gen_random_uuid", 0,
+		func(ctx *sqlitelib.FunctionContext, args []driver.Value) (driver.Value, error) {
+			return uuid.New().String(), nil
+		},
+	)
+}
+
+// NewConnection opens the SQLite database file at cfg.Path and migrates it.
+// The file is created if it does not exist.
+func NewConnection(cfg config.DatabaseConfig, log *slog.Logger) (*gorm.DB, error) {
+	// Use silent logger to avoid GORM's verbose output
+	gormLogger := logger.Default.LogMode(logger.Silent)
+
+	dsn := cfg.Path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: gormLogger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+
+	// SQLite allows a single writer; one connection avoids "database is locked"
+	// errors and keeps ":memory:" databases shared across queries
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := postgres.Migrate(db); err != nil {
+		return nil, err
+	}
+
+	log.Info("database connected",
+		"driver", config.DriverSQLite,
+		"path", cfg.Path,
+	)
+
+	return db, nil
+}
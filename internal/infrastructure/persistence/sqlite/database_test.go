@@ -0,0 +1,68 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/sqlite"
+)
+
+func TestNewConnection_BackfillsRevisionsOnce(t *testing.T) {
+	cfg := config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "rateflow.db"),
+	}
+	open := func() *gorm.DB {
+		t.Helper()
+		db, err := sqlite.NewConnection(cfg, logger.NewNoop())
+		if err != nil {
+			t.Fatalf("NewConnection() error = %v", err)
+		}
+		t.Cleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+		return db
+	}
+	insertRate := func(db *gorm.DB, date string) {
+		t.Helper()
+		err := db.Exec(`INSERT INTO exchange_rates
+				(id, base_currency, quote_currency, value, effective_date, source, created_at, updated_at)
+			VALUES (gen_random_uuid(), 'CNY', 'JPY', 20.5, ?, 'unionpay', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, date).Error
+		if err != nil {
+			t.Fatalf("insert rate: %v", err)
+		}
+	}
+	revisions := func(db *gorm.DB) int64 {
+		t.Helper()
+		var n int64
+		if err := db.Table("rate_revisions").Count(&n).Error; err != nil {
+			t.Fatalf("count revisions: %v", err)
+		}
+		return n
+	}
+
+	// A database from before revisions were tracked is backfilled on its
+	// first start
+	db := open()
+	if err := db.Exec("DELETE FROM schema_migrations").Error; err != nil {
+		t.Fatalf("forget migrations: %v", err)
+	}
+	insertRate(db, "2024-01-15")
+	db = open()
+	if n := revisions(db); n != 1 {
+		t.Fatalf("revisions after first start = %d, want the backfilled one", n)
+	}
+
+	// Later starts skip the backfill
+	insertRate(db, "2024-01-16")
+	db = open()
+	if n := revisions(db); n != 1 {
+		t.Errorf("revisions after restart = %d, want the backfill not to run again", n)
+	}
+}
@@ -0,0 +1,72 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/provider"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/postgres"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/sqlite"
+)
+
+func TestProviderStatusRepository_Record(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "rateflow.db"),
+	}
+	db, err := sqlite.NewConnection(cfg, logger.NewNoop())
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	repo := postgres.NewProviderStatusRepository(db, logger.NewNoop())
+
+	if _, err := repo.FindStatus(ctx, "unionpay"); !errors.As(err, new(provider.ErrStatusNotFound)) {
+		t.Fatalf("FindStatus() error = %v, want ErrStatusNotFound", err)
+	}
+
+	success := time.Date(2024, 1, 15, 1, 0, 0, 0, time.UTC)
+	if err := repo.Record(ctx, "unionpay", success, nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	for i := range 2 {
+		if err := repo.Record(ctx, "unionpay", success.Add(time.Duration(i+1)*time.Hour), errors.New("timeout")); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	status, err := repo.FindStatus(ctx, "unionpay")
+	if err != nil {
+		t.Fatalf("FindStatus() error = %v", err)
+	}
+	if status.Healthy() || status.ConsecutiveFailures != 2 || status.LastError != "timeout" {
+		t.Errorf("after failures: %+v, want 2 consecutive failures with the last error", status)
+	}
+	if status.LastSuccessAt == nil || !status.LastSuccessAt.Equal(success) {
+		t.Errorf("LastSuccessAt = %v, want %v", status.LastSuccessAt, success)
+	}
+	if !status.LastAttemptAt.Equal(success.Add(2 * time.Hour)) {
+		t.Errorf("LastAttemptAt = %v, want %v", status.LastAttemptAt, success.Add(2*time.Hour))
+	}
+
+	if err := repo.Record(ctx, "unionpay", success.Add(3*time.Hour), nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	status, err = repo.FindStatus(ctx, "unionpay")
+	if err != nil {
+		t.Fatalf("FindStatus() error = %v", err)
+	}
+	if !status.Healthy() || status.LastError != "" {
+		t.Errorf("after success: %+v, want healthy", status)
+	}
+}
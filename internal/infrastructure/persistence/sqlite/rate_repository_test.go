@@ -0,0 +1,34 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/rate/ratetest"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/postgres"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/sqlite"
+)
+
+func TestRateRepository_Conformance(t *testing.T) {
+	ratetest.RunRepositoryTests(t, func(t *testing.T) rate.Repository {
+		cfg := config.DatabaseConfig{
+			Driver: config.DriverSQLite,
+			Path:   filepath.Join(t.TempDir(), "rateflow.db"),
+		}
+
+		db, err := sqlite.NewConnection(cfg, logger.NewNoop())
+		if err != nil {
+			t.Fatalf("NewConnection() error = %v", err)
+		}
+		t.Cleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+
+		return postgres.NewRateRepository(db, logger.NewNoop())
+	})
+}
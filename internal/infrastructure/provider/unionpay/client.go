@@ -10,6 +10,8 @@ import (
 
 	"github.com/tyokyo320/rateflow/internal/domain/currency"
 	"github.com/tyokyo320/rateflow/internal/domain/provider"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/metrics"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/tracing"
 	"github.com/tyokyo320/rateflow/pkg/httputil"
 	"github.com/tyokyo320/rateflow/pkg/timeutil"
 )
@@ -36,8 +38,12 @@ type Client struct {
 
 // NewClient creates a new UnionPay provider client.
 func NewClient(logger *slog.Logger) provider.Provider {
+	cfg := httputil.DefaultConfig()
+	cfg.OnRetry = metrics.CountRetry
+	cfg.Transport = tracing.NewTransport(nil)
+
 	return &Client{
-		http:   httputil.NewClient(httputil.DefaultConfig()),
+		http:   httputil.NewClient(cfg),
 		logger: logger,
 	}
 }
@@ -71,14 +77,16 @@ func (c *Client) FetchRate(ctx context.Context, pair currency.Pair, date time.Ti
 				"date", dateStr,
 				"url", url,
 			)
-			return 0, provider.NewProviderError(
+			return 0, provider.NewProviderErrorKind(
 				c.Name(),
+				provider.ErrorKindNoData,
 				fmt.Sprintf("data not available for %s (404 - possibly too old or API unavailable)", dateStr),
 				err,
 			)
 		}
-		return 0, provider.NewProviderError(
+		return 0, provider.NewProviderErrorKind(
 			c.Name(),
+			provider.ErrorKindUnavailable,
 			"failed to fetch data",
 			err,
 		)
@@ -87,8 +95,9 @@ func (c *Client) FetchRate(ctx context.Context, pair currency.Pair, date time.Ti
 	// Parse response
 	var resp Response
 	if err := json.Unmarshal(data, &resp); err != nil {
-		return 0, provider.NewProviderError(
+		return 0, provider.NewProviderErrorKind(
 			c.Name(),
+			provider.ErrorKindInvalid,
 			"failed to parse response",
 			err,
 		)
@@ -143,8 +152,9 @@ func (c *Client) FetchRate(ctx context.Context, pair currency.Pair, date time.Ti
 		"date", dateStr,
 	)
 
-	return 0, provider.NewProviderError(
+	return 0, provider.NewProviderErrorKind(
 		c.Name(),
+		provider.ErrorKindNoData,
 		fmt.Sprintf("rate not found for %s (possibly weekend/holiday or unsupported pair)", pair.String()),
 		nil,
 	)
@@ -193,8 +203,9 @@ func (c *Client) SupportsMulti() bool {
 
 // FetchMulti is not supported by UnionPay.
 func (c *Client) FetchMulti(ctx context.Context, pairs []currency.Pair, date time.Time) (map[string]float64, error) {
-	return nil, provider.NewProviderError(
+	return nil, provider.NewProviderErrorKind(
 		c.Name(),
+		provider.ErrorKindUnsupported,
 		"batch fetch not supported",
 		nil,
 	)
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+)
+
+// tracedCache creates a span for every cache operation.
+type tracedCache struct {
+	redis.CacheInterface
+}
+
+// InstrumentCache wraps cache so that its operations are traced.
+// A Get that misses is not an error; the span records the outcome in cache.hit.
+func InstrumentCache(cache redis.CacheInterface) redis.CacheInterface {
+	return &tracedCache{CacheInterface: cache}
+}
+
+// Get implements redis.CacheInterface.
+func (c *tracedCache) Get(ctx context.Context, key string, dest any) error {
+	ctx, span := c.start(ctx, "cache.get", key)
+	defer span.End()
+
+	err := c.CacheInterface.Get(ctx, key, dest)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+	return err
+}
+
+// Set implements redis.CacheInterface.
+func (c *tracedCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	ctx, span := c.start(ctx, "cache.set", key)
+	defer span.End()
+
+	span.SetAttributes(attribute.String("cache.ttl", ttl.String()))
+	return recordError(span, c.CacheInterface.Set(ctx, key, value, ttl))
+}
+
+// Delete implements redis.CacheInterface.
+func (c *tracedCache) Delete(ctx context.Context, keys ...string) error {
+	ctx, span := Tracer().Start(ctx, "cache.delete", trace.WithAttributes(attribute.StringSlice("cache.keys", keys)))
+	defer span.End()
+
+	return recordError(span, c.CacheInterface.Delete(ctx, keys...))
+}
+
+func (c *tracedCache) start(ctx context.Context, name, key string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attribute.String("cache.key", key)))
+}
+
+// recordError marks span as failed when err is not nil and returns err.
+func recordError(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+// Package tracing sets up OpenTelemetry tracing and instruments the
+// infrastructure (database, cache, outbound HTTP) with spans.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+)
+
+// instrumentationName identifies the spans created by RateFlow itself.
+const instrumentationName = "github.com/tyokyo320/rateflow"
+
+// Tracer returns the tracer for RateFlow's own spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup installs the global tracer provider and the W3C trace context propagator.
+//
+// Spans are exported over OTLP/HTTP to cfg.Endpoint. Without an endpoint no
+// spans are exported, but trace context is still propagated so that callers'
+// traces continue through the service. The returned function flushes and
+// stops the exporter.
+func Setup(ctx context.Context, cfg config.TracingConfig, serviceName, serviceVersion string, log *slog.Logger) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if cfg.Endpoint == "" {
+		log.Info("tracing disabled, no OTLP endpoint configured")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP exporter: %w", err)
+	}
+
+	provider := NewProvider(sdktrace.NewBatchSpanProcessor(exporter), cfg.SampleRatio, serviceName, serviceVersion)
+	otel.SetTracerProvider(provider)
+
+	log.Info("tracing enabled",
+		"endpoint", cfg.Endpoint,
+		"sample_ratio", cfg.SampleRatio,
+	)
+
+	return provider.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider that sends spans to processor.
+// Traces are sampled with sampleRatio unless the caller's trace is sampled.
+// Tests pass a processor around an in-memory exporter.
+func NewProvider(processor sdktrace.SpanProcessor, sampleRatio float64, serviceName, serviceVersion string) *sdktrace.TracerProvider {
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(serviceVersion),
+	)
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(res),
+	)
+}
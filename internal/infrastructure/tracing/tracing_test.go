@@ -0,0 +1,182 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/health"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/tracing"
+	httpHandler "github.com/tyokyo320/rateflow/internal/presentation/http"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/handler"
+)
+
+// recordSpans installs a tracer provider that keeps finished spans in memory.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(sdktrace.NewSimpleSpanProcessor(exporter), 1, "rateflow-test", "test")
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	return exporter
+}
+
+func TestRequestIsTracedThroughQueryCacheAndDatabase(t *testing.T) {
+	exporter := recordSpans(t)
+	ctx := context.Background()
+	log := logger.NewNoop()
+
+	db, err := persistence.NewConnection(config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "rateflow.db"),
+	}, log)
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	// Stored as JPY/CNY so that the query falls back to the inverse pair
+	repo := persistence.NewRateRepository(db, log)
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	r, err := rate.NewRate(pair.Inverse(), 0.05, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	if err := repo.Create(ctx, r); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	exporter.Reset()
+
+	cache := persistence.NewCache(ctx, config.CacheConfig{Mode: config.CacheModeMemory, MaxEntries: 100}, config.RedisConfig{}, log)
+	rateHandler := handler.NewRateHandler(
+		query.NewGetLatestRateHandler(repo, cache, query.DefaultLatestCachePolicy, log),
+		query.NewGetRateByDateHandler(repo, cache, query.DefaultByDateCachePolicy, log),
+		query.NewListRatesHandler(repo, log),
+		query.NewGetRateHistoryHandler(repo, log),
+		handler.DefaultHTTPCachePolicy,
+		log,
+	)
+	router := httpHandler.SetupRouter(httpHandler.RouterConfig{
+		RateHandler:   rateHandler,
+		HealthHandler: handler.NewHealthHandler(health.NewRegistry(time.Second, log), log),
+		Logger:        log,
+		ServiceName:   "rateflow-test",
+		Environment:   "test",
+	})
+
+	// The caller's trace is continued
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rates/latest?pair=CNY/JPY", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-ID", "req-123")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		if got := span.SpanContext.TraceID().String(); got != traceID {
+			t.Errorf("span %q has trace ID %s, want %s", span.Name, got, traceID)
+		}
+		if _, seen := spans[span.Name]; !seen {
+			spans[span.Name] = span
+		}
+	}
+
+	server, ok := spans["GET /api/v1/rates/latest"]
+	if !ok {
+		t.Fatalf("no server span, got %v", names(spans))
+	}
+	if v, ok := attribute(server, "request.id"); !ok || v != "req-123" {
+		t.Errorf("server span request.id = %q, want req-123", v)
+	}
+
+	q, ok := spans["query.GetLatestRate"]
+	if !ok {
+		t.Fatalf("no query span, got %v", names(spans))
+	}
+	if q.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Error("query span is not a child of the server span")
+	}
+	if v, _ := attribute(q, "cache.result"); v != "miss" {
+		t.Errorf("query span cache.result = %q, want miss", v)
+	}
+	if len(q.Events) != 1 || q.Events[0].Name != "inverse pair fallback" {
+		t.Errorf("query span events = %v, want the inverse pair fallback", q.Events)
+	}
+
+	for _, name := range []string{"cache.get", "cache.set", "gorm.query exchange_rates"} {
+		if _, ok := spans[name]; !ok {
+			t.Errorf("no %q span, got %v", name, names(spans))
+		}
+	}
+}
+
+func TestTransportPropagatesTraceContext(t *testing.T) {
+	exporter := recordSpans(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, span := tracing.Tracer().Start(context.Background(), "fetch")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	client := &http.Client{Transport: tracing.NewTransport(nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	span.End()
+
+	traceID := span.SpanContext().TraceID().String()
+	if !strings.Contains(traceparent, traceID) {
+		t.Errorf("traceparent = %q, want trace ID %s", traceparent, traceID)
+	}
+	if n := len(exporter.GetSpans()); n != 2 {
+		t.Errorf("recorded %d spans, want the client span and its parent", n)
+	}
+}
+
+func attribute(span tracetest.SpanStub, key string) (string, bool) {
+	for _, kv := range span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value.Emit(), true
+		}
+	}
+	return "", false
+}
+
+func names(spans map[string]tracetest.SpanStub) []string {
+	out := make([]string, 0, len(spans))
+	for name := range spans {
+		out = append(out, name)
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+// Package webhook sends webhook deliveries over HTTP.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/tracing"
+)
+
+// userAgent identifies webhook requests to receivers.
+const userAgent = "RateFlow-Webhooks/1.0"
+
+// maxErrorBody is how much of an error response is kept in the delivery log.
+const maxErrorBody = 512
+
+// Sender implements webhook.Sender with signed HTTP POST requests.
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+	logger *slog.Logger
+}
+
+// NewSender creates a sender whose requests time out after timeout.
+// Requests carry the W3C trace context.
+//
+// Unless allowPrivate is set, the sender refuses to connect to loopback,
+// private and link-local addresses, so that webhooks cannot reach services
+// on the server's own network. The address is checked when connecting, after
+// the host is resolved, which also covers redirects and hosts resolving to a
+// different address than when the webhook was created.
+func NewSender(timeout time.Duration, allowPrivate bool, logger *slog.Logger) *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   checkPublicAddress,
+		}
+		transport.DialContext = dialer.DialContext
+		// A proxy would make the connection to its own address instead
+		transport.Proxy = nil
+	}
+
+	return &Sender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: tracing.NewTransport(transport),
+		},
+		now:    time.Now,
+		logger: logger,
+	}
+}
+
+// checkPublicAddress rejects connections to addresses that are not public.
+func checkPublicAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("webhook target %s: %w", address, err)
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("webhook target %s: %w", address, err)
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Errorf("webhook target %s is not a public address", ip)
+	}
+	return nil
+}
+
+// Send posts the payload of d to the subscription's URL, signed with its secret.
+func (s *Sender) Send(ctx context.Context, sub *webhook.Subscription, d *webhook.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(sub.Secret, s.now(), d.Payload))
+	req.Header.Set(webhook.EventHeader, string(d.EventType))
+	req.Header.Set(webhook.DeliveryHeader, d.ID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	s.logger.DebugContext(ctx, "webhook delivered",
+		"delivery_id", d.ID,
+		"subscription_id", sub.ID,
+		"status", resp.StatusCode,
+	)
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,57 @@
+package webhook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	webhooksender "github.com/tyokyo320/rateflow/internal/infrastructure/webhook"
+)
+
+func TestSender_PrivateTargets(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	byName := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	tests := []struct {
+		name         string
+		url          string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{"loopback address", srv.URL, false, true},
+		{"host resolving to loopback", byName, false, true},
+		{"allowed private target", srv.URL, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := requests.Load()
+			sender := webhooksender.NewSender(time.Second, tt.allowPrivate, logger.NewNoop())
+			status, err := sender.Send(context.Background(),
+				&webhook.Subscription{ID: "sub", URL: tt.url, Secret: "secret"},
+				&webhook.Delivery{ID: "delivery", EventType: webhook.EventRateCreated, Payload: []byte(`{}`)},
+			)
+
+			if tt.wantErr {
+				if err == nil || requests.Load() != before {
+					t.Errorf("Send() = %d, %v; want an error without reaching the receiver", status, err)
+				}
+				return
+			}
+			if err != nil || status != http.StatusNoContent {
+				t.Errorf("Send() = %d, %v; want 204", status, err)
+			}
+		})
+	}
+}
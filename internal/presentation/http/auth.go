@@ -0,0 +1,115 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/domain/apikey"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+)
+
+// Gin context keys of the API key a request was authenticated with.
+// The logger and rate limit middleware read them as well.
+const (
+	APIKeyIDKey   = "api_key_id"
+	APIKeyNameKey = "api_key_name"
+)
+
+// apiKeyKey is the gin context key of the authenticated *apikey.Key.
+const apiKeyKey = "api_key"
+
+// RequireAPIKey returns a middleware that rejects requests without an API key
+// granting scope. The key is read from the Authorization header as a bearer
+// token or from the X-API-Key header.
+//
+// It only looks the key up; RecordAPIKeyUsage counts accepted requests.
+func RequireAPIKey(auth *command.AuthenticateHandler, scope apikey.Scope, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := apiKeyFromRequest(c.Request)
+		if secret == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="rateflow"`)
+			response.UnauthorizedError(c, "API key required")
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key, err := auth.Handle(ctx, command.AuthenticateCommand{Secret: secret, Scope: scope})
+		if key != nil {
+			c.Set(apiKeyKey, key)
+			c.Set(APIKeyIDKey, key.ID)
+			c.Set(APIKeyNameKey, key.Name)
+			trace.SpanFromContext(ctx).SetAttributes(
+				attribute.String("api_key.id", key.ID),
+				attribute.String("api_key.name", key.Name),
+			)
+		}
+
+		switch {
+		case err == nil:
+			c.Next()
+			return
+		case errors.As(err, new(apikey.ErrKeyNotFound)), errors.As(err, new(apikey.ErrKeyRevoked)):
+			c.Header("WWW-Authenticate", `Bearer realm="rateflow", error="invalid_token"`)
+			response.UnauthorizedError(c, "invalid API key")
+		case errors.As(err, new(apikey.ErrScopeDenied)):
+			response.ForbiddenError(c, err.Error())
+		default:
+			logger.ErrorContext(ctx, "failed to authenticate api key", "error", err)
+			response.InternalServerError(c, "failed to authenticate API key")
+		}
+		c.Abort()
+	}
+}
+
+// RecordAPIKeyUsage returns a middleware that counts requests authenticated
+// by RequireAPIKey per key and route. Once a key's daily quota is used up its
+// requests are rejected with 429 until the next UTC day; rejected requests are
+// not counted.
+//
+// It runs after the rate limit, so that requests rejected there cost no write.
+func RecordAPIKeyUsage(auth *command.AuthenticateHandler, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get(apiKeyKey)
+		if !ok {
+			c.Next()
+			return
+		}
+		key := value.(*apikey.Key)
+
+		ctx := c.Request.Context()
+		used, err := auth.RecordUsage(ctx, key, c.FullPath())
+		if key.DailyQuota > 0 && (err == nil || errors.As(err, new(apikey.ErrQuotaExceeded))) {
+			c.Header("X-Quota-Limit", strconv.FormatInt(key.DailyQuota, 10))
+			c.Header("X-Quota-Remaining", strconv.FormatInt(max(key.DailyQuota-used, 0), 10))
+		}
+
+		switch {
+		case err == nil:
+			c.Next()
+			return
+		case errors.As(err, new(apikey.ErrQuotaExceeded)):
+			response.ErrorResponse(c, http.StatusTooManyRequests, response.CodeQuotaExceeded, err.Error())
+		default:
+			logger.ErrorContext(ctx, "failed to record api key usage", "error", err)
+			response.InternalServerError(c, "failed to record API key usage")
+		}
+		c.Abort()
+	}
+}
+
+// apiKeyFromRequest returns the API key sent with the request, if any.
+func apiKeyFromRequest(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
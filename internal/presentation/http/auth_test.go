@@ -0,0 +1,129 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/domain/apikey"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	httpHandler "github.com/tyokyo320/rateflow/internal/presentation/http"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/middleware"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewNoop()
+
+	db, err := persistence.NewConnection(config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "rateflow.db"),
+	}, log)
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	keys := persistence.NewAPIKeyRepository(db, log)
+
+	create := command.NewCreateAPIKeyHandler(keys, log)
+	reader, readerSecret, err := create.Handle(ctx, command.CreateAPIKeyCommand{
+		Name:       "partner",
+		Scopes:     []apikey.Scope{apikey.ScopeRatesRead},
+		DailyQuota: 2,
+	})
+	if err != nil {
+		t.Fatalf("create reader key: %v", err)
+	}
+	_, writerSecret, err := create.Handle(ctx, command.CreateAPIKeyCommand{
+		Name:   "ingest",
+		Scopes: []apikey.Scope{apikey.ScopeRatesWrite},
+	})
+	if err != nil {
+		t.Fatalf("create writer key: %v", err)
+	}
+	_, adminSecret, err := create.Handle(ctx, command.CreateAPIKeyCommand{
+		Name:   "ops",
+		Scopes: []apikey.Scope{apikey.ScopeAdmin},
+	})
+	if err != nil {
+		t.Fatalf("create admin key: %v", err)
+	}
+
+	// Keys are checked, then limited to 4 requests a minute, then counted
+	gin.SetMode(gin.TestMode)
+	auth := command.NewAuthenticateHandler(keys, log)
+	limits := middleware.RateLimits{Default: redis.Limit{Requests: 4, Window: time.Minute}}
+	router := gin.New()
+	router.GET("/rates",
+		httpHandler.RequireAPIKey(auth, apikey.ScopeRatesRead, log),
+		middleware.RateLimit(memory.NewRateLimiter(log), limits, log),
+		httpHandler.RecordAPIKeyUsage(auth, log),
+		func(c *gin.Context) { c.String(http.StatusOK, c.GetString(httpHandler.APIKeyNameKey)) },
+	)
+
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/rates", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	steps := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"no key", "", "", http.StatusUnauthorized},
+		{"unknown key", "X-API-Key", "rfk_UNKNOWN", http.StatusUnauthorized},
+		{"missing scope", "X-API-Key", writerSecret, http.StatusForbidden},
+		{"admin key", "Authorization", "Bearer " + adminSecret, http.StatusOK},
+		{"first request", "X-API-Key", readerSecret, http.StatusOK},
+		{"bearer token", "Authorization", "Bearer " + readerSecret, http.StatusOK},
+		{"quota used up", "X-API-Key", readerSecret, http.StatusTooManyRequests},
+		{"quota still used up", "X-API-Key", readerSecret, http.StatusTooManyRequests},
+		{"rate limited", "X-API-Key", readerSecret, http.StatusTooManyRequests},
+	}
+	for _, step := range steps {
+		rec := get(step.header, step.value)
+		if rec.Code != step.want {
+			t.Fatalf("%s: status = %d, want %d (body %s)", step.name, rec.Code, step.want, rec.Body)
+		}
+		if step.name == "rate limited" && rec.Header().Get("Retry-After") == "" {
+			t.Errorf("%s: rejected without Retry-After, want the rate limit to reject it before the quota", step.name)
+		}
+	}
+
+	usage, err := keys.UsageOn(ctx, reader.ID, time.Now())
+	if err != nil {
+		t.Fatalf("UsageOn() error = %v", err)
+	}
+	// Requests rejected by the quota or the rate limit are not counted
+	if len(usage) != 1 || usage[0].Route != "/rates" || usage[0].Count != 2 {
+		t.Errorf("usage = %+v, want 2 requests to /rates", usage)
+	}
+
+	if err := command.NewRevokeAPIKeyHandler(keys, log).Handle(ctx, command.RevokeAPIKeyCommand{ID: reader.ID}); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if rec := get("X-API-Key", readerSecret); rec.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,555 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// Bounds of time series requests, so that a response stays a few hundred
+// kilobytes: ten years of daily values for ten pairs.
+const (
+	maxTimeSeriesPairs = 10
+	maxTimeSeriesSteps = 3660
+)
+
+// Bounds of indicator and comparison requests: ten years of daily rates,
+// windows of up to about two years of them and as many quotes as pairs of a
+// time series.
+const (
+	maxRangeDays        = 3660
+	maxIndicatorWindow  = 500
+	maxComparisonQuotes = maxTimeSeriesPairs
+)
+
+// Bounds of forecast requests: a year of daily forecasts from up to the
+// longest range of rates, by default the last year of them.
+const (
+	defaultForecastHistory = 365
+	maxForecastHorizon     = 366
+)
+
+// AnalyticsHandler handles HTTP requests for figures computed from the rates of
+// a pair over a window.
+type AnalyticsHandler struct {
+	statsHandler      *query.GetRateStatsHandler
+	aggregateHandler  *query.AggregateRatesHandler
+	timeSeriesHandler *query.GetTimeSeriesHandler
+	indicatorsHandler *query.GetIndicatorsHandler
+	compareHandler    *query.CompareRatesHandler
+	forecastHandler   *query.GetForecastHandler
+	cachePolicy       HTTPCachePolicy
+	logger            *slog.Logger
+}
+
+// NewAnalyticsHandler creates a new analytics handler for the rates stored in repo.
+func NewAnalyticsHandler(repo rate.Repository, cachePolicy HTTPCachePolicy, logger *slog.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		statsHandler:      query.NewGetRateStatsHandler(repo, logger),
+		aggregateHandler:  query.NewAggregateRatesHandler(repo, logger),
+		timeSeriesHandler: query.NewGetTimeSeriesHandler(repo, logger),
+		indicatorsHandler: query.NewGetIndicatorsHandler(repo, logger),
+		compareHandler:    query.NewCompareRatesHandler(repo, logger),
+		forecastHandler:   query.NewGetForecastHandler(repo, logger),
+		cachePolicy:       cachePolicy,
+		logger:            logger,
+	}
+}
+
+// Stats handles GET /api/v1/rates/stats requests.
+// @Summary Get statistics of a pair over a window
+// @Description Computes count, min and max with their dates, mean, median, sample standard deviation, first and last rates, change and annualised volatility of log returns over every rate in the window
+// @Tags rates
+// @Security ApiKeyAuth
+// @Produce json
+// @Param pair query string true "Currency pair (e.g., CNY/JPY, CNYJPY, or CNY-JPY)"
+// @Param start query string false "Earliest effective date (YYYY-MM-DD)"
+// @Param end query string false "Latest effective date (YYYY-MM-DD)"
+// @Param asOf query string false "Transaction time (RFC3339) to compute the statistics as the rates were known then"
+// @Success 200 {object} map[string]interface{} "Success response with statistics"
+// @Failure 400 {object} map[string]interface{} "Bad request error"
+// @Failure 404 {object} map[string]interface{} "No rates in the window"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/rates/stats [get]
+func (h *AnalyticsHandler) Stats(c *gin.Context) {
+	pair, ok := parsePair(c)
+	if !ok {
+		return
+	}
+	start, end, ok := parseWindow(c)
+	if !ok {
+		return
+	}
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.statsHandler.Handle(c.Request.Context(), query.GetRateStatsQuery{
+		Pair:  pair,
+		Start: start,
+		End:   end,
+		AsOf:  asOf,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to get rate stats", err, "pair", pair.String())
+		return
+	}
+
+	c.Header("Cache-Control", h.cacheControl(end, asOf))
+	response.SuccessResponse(c, result)
+}
+
+// Aggregate handles GET /api/v1/rates/aggregate requests.
+// @Summary Aggregate rates of a pair per period
+// @Description Computes open, high, low, close (the period-end value), average and count of the rates of each week, month, quarter or year in the window, oldest first
+// @Tags rates
+// @Security ApiKeyAuth
+// @Produce json
+// @Param pair query string true "Currency pair (e.g., CNY/JPY, CNYJPY, or CNY-JPY)"
+// @Param interval query string false "Period length: week, month, quarter or year (default: month)" default(month)
+// @Param start query string false "Earliest effective date (YYYY-MM-DD)"
+// @Param end query string false "Latest effective date (YYYY-MM-DD)"
+// @Param asOf query string false "Transaction time (RFC3339) to aggregate the rates as they were known then"
+// @Success 200 {object} map[string]interface{} "Success response with periods"
+// @Failure 400 {object} map[string]interface{} "Bad request error"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/rates/aggregate [get]
+func (h *AnalyticsHandler) Aggregate(c *gin.Context) {
+	pair, ok := parsePair(c)
+	if !ok {
+		return
+	}
+	interval, err := rate.ParseInterval(c.DefaultQuery("interval", string(rate.IntervalMonth)))
+	if err != nil {
+		fail(c, h.logger, "invalid interval", err)
+		return
+	}
+	start, end, ok := parseWindow(c)
+	if !ok {
+		return
+	}
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.aggregateHandler.Handle(c.Request.Context(), query.AggregateRatesQuery{
+		Pair:     pair,
+		Interval: interval,
+		Start:    start,
+		End:      end,
+		AsOf:     asOf,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to aggregate rates", err, "pair", pair.String(), "interval", interval)
+		return
+	}
+
+	c.Header("Cache-Control", h.cacheControl(end, asOf))
+	response.SuccessResponse(c, result)
+}
+
+// TimeSeries handles GET /api/v1/rates/timeseries requests.
+// @Summary Get aligned time series of several pairs
+// @Description Returns one value per step for each pair, all on the same dates, in columns: the i-th value of every series belongs to the i-th date. The value of a step is the last rate within it; steps without rates are filled as requested.
+// @Tags rates
+// @Security ApiKeyAuth
+// @Produce json
+// @Param pairs query string true "Comma-separated currency pairs, at most 10 (e.g., CNY/JPY,USD/JPY)"
+// @Param start query string true "First date (YYYY-MM-DD)"
+// @Param end query string true "Last date (YYYY-MM-DD)"
+// @Param step query string false "Step length in days or weeks, e.g. 1d, 7d or 1w (default: 1d)" default(1d)
+// @Param fill query string false "Filling of steps without rates: previous, linear or none (default: previous)" default(previous)
+// @Param asOf query string false "Transaction time (RFC3339) to read the rates as they were known then"
+// @Success 200 {object} map[string]interface{} "Success response with dates and series"
+// @Failure 400 {object} map[string]interface{} "Bad request error, including ranges over 3660 steps"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/rates/timeseries [get]
+func (h *AnalyticsHandler) TimeSeries(c *gin.Context) {
+	var pairs []currency.Pair
+	for _, s := range strings.Split(c.Query("pairs"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		pair, err := currency.ParsePair(s)
+		if err != nil {
+			response.BadRequestError(c, fmt.Sprintf("invalid currency pair format: %s", s))
+			return
+		}
+		pairs = append(pairs, pair)
+	}
+	if len(pairs) == 0 || len(pairs) > maxTimeSeriesPairs {
+		response.BadRequestError(c, fmt.Sprintf("pairs parameter must list 1 to %d pairs", maxTimeSeriesPairs))
+		return
+	}
+
+	start, end, ok := parseWindow(c)
+	if !ok {
+		return
+	}
+	if start == nil || end == nil {
+		response.BadRequestError(c, "start and end parameters are required")
+		return
+	}
+
+	step, ok := parseStep(c.DefaultQuery("step", "1d"))
+	if !ok {
+		response.BadRequestError(c, "invalid step, use a number of days or weeks such as 1d, 7d or 1w")
+		return
+	}
+
+	fill := query.FillMode(c.DefaultQuery("fill", string(query.FillPrevious)))
+	switch fill {
+	case query.FillNone, query.FillPrevious, query.FillLinear:
+	default:
+		response.BadRequestError(c, "invalid fill, use previous, linear or none")
+		return
+	}
+
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	q := query.GetTimeSeriesQuery{
+		Pairs:    pairs,
+		Start:    *start,
+		End:      *end,
+		StepDays: step,
+		Fill:     fill,
+		AsOf:     asOf,
+	}
+	if steps := q.Steps(); steps > maxTimeSeriesSteps {
+		response.BadRequestError(c, fmt.Sprintf("range too large: %d steps, at most %d; use a longer step", steps, maxTimeSeriesSteps))
+		return
+	}
+
+	result, err := h.timeSeriesHandler.Handle(c.Request.Context(), q)
+	if err != nil {
+		fail(c, h.logger, "failed to get time series", err)
+		return
+	}
+
+	c.Header("Cache-Control", h.cacheControl(end, asOf))
+	response.SuccessResponse(c, result)
+}
+
+// Indicators handles GET /api/v1/rates/indicators requests.
+// @Summary Get a technical indicator of a pair
+// @Description Computes a simple or exponential moving average, Bollinger bands (two standard deviations around the simple moving average), Wilder's RSI, the rate of change in percent or the annualised rolling volatility of log returns over a window of rates. Returns one point per rate in the range, oldest first; the rates before the range warm the indicator up.
+// @Tags rates
+// @Security ApiKeyAuth
+// @Produce json
+// @Param pair query string true "Currency pair (e.g., CNY/JPY, CNYJPY, or CNY-JPY)"
+// @Param indicator query string true "Indicator: sma, ema, bollinger, rsi, roc or volatility"
+// @Param window query int false "Number of rates (sma, ema, bollinger) or changes (rsi, roc, volatility) the indicator spans, at most 500 (default: 20)" default(20)
+// @Param start query string false "First date (YYYY-MM-DD, default: one year before end)"
+// @Param end query string false "Last date (YYYY-MM-DD, default: today)"
+// @Param asOf query string false "Transaction time (RFC3339) to compute the indicator from the rates as they were known then"
+// @Success 200 {object} map[string]interface{} "Success response with points"
+// @Failure 400 {object} map[string]interface{} "Bad request error, including ranges over 3660 days"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/rates/indicators [get]
+func (h *AnalyticsHandler) Indicators(c *gin.Context) {
+	pair, ok := parsePair(c)
+	if !ok {
+		return
+	}
+
+	indicator := query.Indicator(strings.ToLower(c.Query("indicator")))
+	if !slices.Contains(query.Indicators, indicator) {
+		response.BadRequestError(c, "invalid indicator, use sma, ema, bollinger, rsi, roc or volatility")
+		return
+	}
+
+	window, err := strconv.Atoi(c.DefaultQuery("window", "20"))
+	if err != nil || window < indicator.MinWindow() || window > maxIndicatorWindow {
+		response.BadRequestError(c, fmt.Sprintf("window must be between %d and %d for %s", indicator.MinWindow(), maxIndicatorWindow, indicator))
+		return
+	}
+
+	start, end, ok := parseRange(c)
+	if !ok {
+		return
+	}
+
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.indicatorsHandler.Handle(c.Request.Context(), query.GetIndicatorsQuery{
+		Pair:      pair,
+		Indicator: indicator,
+		Window:    window,
+		Start:     start,
+		End:       end,
+		AsOf:      asOf,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to compute indicator", err, "pair", pair.String(), "indicator", indicator)
+		return
+	}
+
+	c.Header("Cache-Control", h.cacheControl(&end, asOf))
+	response.SuccessResponse(c, result)
+}
+
+// Compare handles GET /api/v1/rates/compare requests.
+// @Summary Compare pairs of one base currency
+// @Description Compares the pairs base/quote of several quote currencies over a window: every series normalised to 100 at its first rate, its percent performance from the first to the last rate and the Pearson correlation matrix of daily log returns. The dates are those with a rate of any pair; a pair without a rate on a date carries its previous rate forward.
+// @Tags rates
+// @Security ApiKeyAuth
+// @Produce json
+// @Param base query string true "Base currency (e.g., JPY)"
+// @Param quotes query string true "Comma-separated quote currencies, at most 10 (e.g., CNY,USD,EUR)"
+// @Param start query string false "First date (YYYY-MM-DD, default: one year before end)"
+// @Param end query string false "Last date (YYYY-MM-DD, default: today)"
+// @Param asOf query string false "Transaction time (RFC3339) to compare the rates as they were known then"
+// @Success 200 {object} map[string]interface{} "Success response with dates, series and correlations"
+// @Failure 400 {object} map[string]interface{} "Bad request error, including ranges over 3660 days"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/rates/compare [get]
+func (h *AnalyticsHandler) Compare(c *gin.Context) {
+	base, err := currency.NewCode(c.Query("base"))
+	if err != nil {
+		response.BadRequestError(c, "base parameter must be a supported currency code")
+		return
+	}
+
+	var quotes []currency.Code
+	for _, s := range strings.Split(c.Query("quotes"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		quote, err := currency.NewCode(s)
+		if err != nil {
+			response.BadRequestError(c, fmt.Sprintf("invalid currency code: %s", s))
+			return
+		}
+		if quote == base || slices.Contains(quotes, quote) {
+			response.BadRequestError(c, fmt.Sprintf("quotes must differ from the base and each other: %s", s))
+			return
+		}
+		quotes = append(quotes, quote)
+	}
+	if len(quotes) == 0 || len(quotes) > maxComparisonQuotes {
+		response.BadRequestError(c, fmt.Sprintf("quotes parameter must list 1 to %d currencies", maxComparisonQuotes))
+		return
+	}
+
+	start, end, ok := parseRange(c)
+	if !ok {
+		return
+	}
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.compareHandler.Handle(c.Request.Context(), query.CompareRatesQuery{
+		Base:   base,
+		Quotes: quotes,
+		Start:  start,
+		End:    end,
+		AsOf:   asOf,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to compare rates", err, "base", base.String())
+		return
+	}
+
+	c.Header("Cache-Control", h.cacheControl(&end, asOf))
+	response.SuccessResponse(c, result)
+}
+
+// Forecast handles GET /api/v1/rates/forecast requests.
+// @Summary Forecast the rates of a pair
+// @Description Fits Holt's linear exponential smoothing or a random walk with drift to the last rates up to end and forecasts the following rate dates, skipping weekends unless the pair has weekend rates. Each point carries a prediction interval of the requested coverage.
+// @Tags rates
+// @Security ApiKeyAuth
+// @Produce json
+// @Param pair query string true "Currency pair (e.g., CNY/JPY, CNYJPY, or CNY-JPY)"
+// @Param model query string false "Model: holt or drift (default: holt)" default(holt)
+// @Param horizon query int false "Number of rates to forecast, at most 366 (default: 30)" default(30)
+// @Param level query number false "Coverage of the prediction intervals in percent, from 50 to below 100 (default: 95)" default(95)
+// @Param history query int false "Number of rates the model is fitted to, at most 3660 (default: 365)" default(365)
+// @Param end query string false "Last date of the history (YYYY-MM-DD, default: today)"
+// @Param asOf query string false "Transaction time (RFC3339) to forecast from the rates as they were known then"
+// @Success 200 {object} map[string]interface{} "Success response with forecasts"
+// @Failure 400 {object} map[string]interface{} "Bad request error"
+// @Failure 422 {object} map[string]interface{} "Too few rates to fit the model"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/rates/forecast [get]
+func (h *AnalyticsHandler) Forecast(c *gin.Context) {
+	pair, ok := parsePair(c)
+	if !ok {
+		return
+	}
+
+	model := query.ForecastModel(strings.ToLower(c.DefaultQuery("model", string(query.ForecastHolt))))
+	if !slices.Contains(query.ForecastModels, model) {
+		response.BadRequestError(c, "invalid model, use holt or drift")
+		return
+	}
+
+	horizon, err := strconv.Atoi(c.DefaultQuery("horizon", "30"))
+	if err != nil || horizon < 1 || horizon > maxForecastHorizon {
+		response.BadRequestError(c, fmt.Sprintf("horizon must be between 1 and %d", maxForecastHorizon))
+		return
+	}
+
+	level, err := strconv.ParseFloat(c.DefaultQuery("level", "95"), 64)
+	if err != nil || level < 50 || level >= 100 {
+		response.BadRequestError(c, "level must be a percentage from 50 to below 100")
+		return
+	}
+
+	history, err := strconv.Atoi(c.DefaultQuery("history", strconv.Itoa(defaultForecastHistory)))
+	if err != nil || history < model.MinHistory() || history > maxRangeDays {
+		response.BadRequestError(c, fmt.Sprintf("history must be between %d and %d for %s", model.MinHistory(), maxRangeDays, model))
+		return
+	}
+
+	end := timeutil.Today()
+	if str := c.Query("end"); str != "" {
+		if end, err = timeutil.ParseDate(str); err != nil {
+			response.BadRequestError(c, "invalid end format, use YYYY-MM-DD")
+			return
+		}
+	}
+
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.forecastHandler.Handle(c.Request.Context(), query.GetForecastQuery{
+		Pair:    pair,
+		Model:   model,
+		Horizon: horizon,
+		Level:   level / 100,
+		History: history,
+		End:     end,
+		AsOf:    asOf,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to forecast rates", err, "pair", pair.String(), "model", model)
+		return
+	}
+
+	c.Header("Cache-Control", h.cacheControl(&end, asOf))
+	response.SuccessResponse(c, result)
+}
+
+// cacheControl returns the Cache-Control of figures about rates up to end, as
+// known at asOf.
+func (h *AnalyticsHandler) cacheControl(end, asOf *time.Time) string {
+	if asOf != nil {
+		return h.cachePolicy.forDate(asOf)
+	}
+	return h.cachePolicy.forDate(end)
+}
+
+// parsePair parses the required pair query parameter.
+// It writes a 400 response and returns false when it is missing or invalid.
+func parsePair(c *gin.Context) (currency.Pair, bool) {
+	pairStr := c.Query("pair")
+	if pairStr == "" {
+		response.BadRequestError(c, "pair parameter is required")
+		return currency.Pair{}, false
+	}
+
+	pair, err := currency.ParsePair(pairStr)
+	if err != nil {
+		response.BadRequestError(c, "invalid currency pair format")
+		return currency.Pair{}, false
+	}
+
+	return pair, true
+}
+
+// parseWindow parses the optional start and end query parameters.
+// It writes a 400 response and returns false when they are invalid.
+func parseWindow(c *gin.Context) (*time.Time, *time.Time, bool) {
+	var dates [2]*time.Time
+	for i, name := range []string{"start", "end"} {
+		str := c.Query(name)
+		if str == "" {
+			continue
+		}
+		date, err := timeutil.ParseDate(str)
+		if err != nil {
+			response.BadRequestError(c, fmt.Sprintf("invalid %s format, use YYYY-MM-DD", name))
+			return nil, nil, false
+		}
+		dates[i] = &date
+	}
+
+	if dates[0] != nil && dates[1] != nil && dates[1].Before(*dates[0]) {
+		response.BadRequestError(c, "end must not be before start")
+		return nil, nil, false
+	}
+
+	return dates[0], dates[1], true
+}
+
+// parseRange parses the optional start and end query parameters of a
+// bounded range, which defaults to the year up to today.
+// It writes a 400 response and returns false when they are invalid.
+func parseRange(c *gin.Context) (time.Time, time.Time, bool) {
+	start, end, ok := parseWindow(c)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	if end == nil {
+		today := timeutil.Today()
+		end = &today
+	}
+	if start == nil {
+		yearBefore := end.AddDate(-1, 0, 0)
+		start = &yearBefore
+	}
+
+	if end.Before(*start) {
+		response.BadRequestError(c, "end must not be before start")
+		return time.Time{}, time.Time{}, false
+	}
+	if days := timeutil.DaysBetween(*start, *end); days > maxRangeDays {
+		response.BadRequestError(c, fmt.Sprintf("range too large: %d days, at most %d", days, maxRangeDays))
+		return time.Time{}, time.Time{}, false
+	}
+
+	return *start, *end, true
+}
+
+// parseStep parses a step length such as 1d or 2w into days.
+func parseStep(s string) (int, bool) {
+	unit := 1
+	switch {
+	case strings.HasSuffix(s, "d"):
+		s = strings.TrimSuffix(s, "d")
+	case strings.HasSuffix(s, "w"):
+		s, unit = strings.TrimSuffix(s, "w"), 7
+	default:
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 366 {
+		return 0, false
+	}
+	return n * unit, true
+}
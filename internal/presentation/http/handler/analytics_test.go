@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/handler"
+)
+
+func TestAnalyticsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	log := logger.NewNoop()
+
+	repo := memory.NewRateRepository(log)
+	r, err := rate.NewRate(currency.MustNewPair(currency.CNY, currency.JPY), 20.5, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	if err := repo.Create(ctx, r); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	analyticsHandler := handler.NewAnalyticsHandler(repo, handler.DefaultHTTPCachePolicy, log)
+	router := gin.New()
+	router.GET("/rates/stats", analyticsHandler.Stats)
+	router.GET("/rates/aggregate", analyticsHandler.Aggregate)
+	router.GET("/rates/timeseries", analyticsHandler.TimeSeries)
+	router.GET("/rates/indicators", analyticsHandler.Indicators)
+	router.GET("/rates/compare", analyticsHandler.Compare)
+	router.GET("/rates/forecast", analyticsHandler.Forecast)
+
+	tests := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{"stats", "/rates/stats?pair=CNY/JPY&start=2024-01-01&end=2024-01-31", http.StatusOK},
+		{"stats without rates", "/rates/stats?pair=CNY/JPY&start=2024-02-01", http.StatusNotFound},
+		{"stats without pair", "/rates/stats", http.StatusBadRequest},
+		{"stats with reversed window", "/rates/stats?pair=CNY/JPY&start=2024-02-01&end=2024-01-01", http.StatusBadRequest},
+		{"aggregate", "/rates/aggregate?pair=JPY/CNY&interval=quarter", http.StatusOK},
+		{"aggregate by day", "/rates/aggregate?pair=CNY/JPY&interval=day", http.StatusBadRequest},
+		{"timeseries", "/rates/timeseries?pairs=CNY/JPY,USD/JPY&start=2024-01-01&end=2024-01-31&fill=linear&step=1w", http.StatusOK},
+		{"timeseries without end", "/rates/timeseries?pairs=CNY/JPY&start=2024-01-01", http.StatusBadRequest},
+		{"timeseries with invalid step", "/rates/timeseries?pairs=CNY/JPY&start=2024-01-01&end=2024-01-31&step=1h", http.StatusBadRequest},
+		{"timeseries with invalid fill", "/rates/timeseries?pairs=CNY/JPY&start=2024-01-01&end=2024-01-31&fill=next", http.StatusBadRequest},
+		{"timeseries over ten years of days", "/rates/timeseries?pairs=CNY/JPY&start=2000-01-01&end=2024-01-01", http.StatusBadRequest},
+		{"timeseries over ten years of weeks", "/rates/timeseries?pairs=CNY/JPY&start=2000-01-01&end=2024-01-01&step=1w", http.StatusOK},
+		{"indicators", "/rates/indicators?pair=CNY/JPY&indicator=bollinger&window=5&end=2024-01-31", http.StatusOK},
+		{"indicators with default window", "/rates/indicators?pair=CNY/JPY&indicator=RSI", http.StatusOK},
+		{"indicators without indicator", "/rates/indicators?pair=CNY/JPY", http.StatusBadRequest},
+		{"indicators with unknown indicator", "/rates/indicators?pair=CNY/JPY&indicator=macd", http.StatusBadRequest},
+		{"indicators with too large window", "/rates/indicators?pair=CNY/JPY&indicator=sma&window=501", http.StatusBadRequest},
+		{"volatility over one return", "/rates/indicators?pair=CNY/JPY&indicator=volatility&window=1", http.StatusBadRequest},
+		{"indicators over ten years", "/rates/indicators?pair=CNY/JPY&indicator=sma&start=2000-01-01&end=2024-01-01", http.StatusBadRequest},
+		{"compare", "/rates/compare?base=JPY&quotes=CNY,USD,EUR&start=2024-01-01&end=2024-01-31", http.StatusOK},
+		{"compare with default range", "/rates/compare?base=jpy&quotes=cny", http.StatusOK},
+		{"compare without base", "/rates/compare?quotes=CNY", http.StatusBadRequest},
+		{"compare without quotes", "/rates/compare?base=JPY", http.StatusBadRequest},
+		{"compare with unknown quote", "/rates/compare?base=JPY&quotes=CNY,XXX", http.StatusBadRequest},
+		{"compare with the base as quote", "/rates/compare?base=JPY&quotes=CNY,JPY", http.StatusBadRequest},
+		{"compare with reversed range", "/rates/compare?base=JPY&quotes=CNY&start=2024-02-01&end=2024-01-01", http.StatusBadRequest},
+		{"forecast from one rate", "/rates/forecast?pair=CNY/JPY&end=2024-01-31", http.StatusUnprocessableEntity},
+		{"forecast by drift from one rate", "/rates/forecast?pair=CNY/JPY&model=drift&horizon=5&level=80", http.StatusUnprocessableEntity},
+		{"forecast with unknown model", "/rates/forecast?pair=CNY/JPY&model=arima", http.StatusBadRequest},
+		{"forecast without horizon", "/rates/forecast?pair=CNY/JPY&horizon=0", http.StatusBadRequest},
+		{"forecast with too large horizon", "/rates/forecast?pair=CNY/JPY&horizon=367", http.StatusBadRequest},
+		{"forecast with certain intervals", "/rates/forecast?pair=CNY/JPY&level=100", http.StatusBadRequest},
+		{"forecast from too short a history", "/rates/forecast?pair=CNY/JPY&history=3", http.StatusBadRequest},
+		{"forecast with invalid end", "/rates/forecast?pair=CNY/JPY&end=31-01-2024", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rec.Code != tt.want {
+				t.Errorf("GET %s: status %d, want %d: %s", tt.url, rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
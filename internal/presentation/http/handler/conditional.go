@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+)
+
+// HTTPCachePolicy determines the Cache-Control of rate responses.
+//
+// Rates are fetched by the worker every FetchInterval, so a current rate cannot
+// change before the next run and is cacheable until then. Rates of past dates
+// are only revised by corrections and are cacheable for HistoricalMaxAge.
+type HTTPCachePolicy struct {
+	FetchInterval        time.Duration // interval of the worker's fetch runs; 0 to always revalidate
+	HistoricalMaxAge     time.Duration // max-age of rates of past dates
+	StaleWhileRevalidate time.Duration // how long caches may serve expired responses while revalidating
+
+	// Private keeps responses out of shared caches such as CDNs, which would
+	// otherwise serve them without checking API keys.
+	Private bool
+
+	// Now returns the current time in the timezone rates are published in.
+	Now func() time.Time
+}
+
+// DefaultHTTPCachePolicy matches hourly fetch runs.
+var DefaultHTTPCachePolicy = HTTPCachePolicy{
+	FetchInterval:        time.Hour,
+	HistoricalMaxAge:     24 * time.Hour,
+	StaleWhileRevalidate: time.Minute,
+	Now:                  time.Now,
+}
+
+// current returns the Cache-Control of responses that may change with the
+// next fetch run.
+func (p HTTPCachePolicy) current() string {
+	if p.FetchInterval <= 0 {
+		return p.directives(0)
+	}
+	now := p.now()
+	return p.directives(now.Truncate(p.FetchInterval).Add(p.FetchInterval).Sub(now))
+}
+
+// historical returns the Cache-Control of responses about past dates only.
+func (p HTTPCachePolicy) historical() string {
+	return p.directives(p.HistoricalMaxAge)
+}
+
+// forDate returns the Cache-Control of responses about rates up to date, where
+// nil means no upper bound.
+func (p HTTPCachePolicy) forDate(date *time.Time) string {
+	if date != nil && dateKey(*date) < dateKey(p.now()) {
+		return p.historical()
+	}
+	return p.current()
+}
+
+func (p HTTPCachePolicy) directives(maxAge time.Duration) string {
+	visibility := "public"
+	if p.Private {
+		visibility = "private"
+	}
+	if maxAge <= 0 {
+		return visibility + ", no-cache"
+	}
+
+	directives := fmt.Sprintf("%s, max-age=%d", visibility, int(maxAge.Seconds()))
+	if p.StaleWhileRevalidate > 0 {
+		directives += fmt.Sprintf(", stale-while-revalidate=%d", int(p.StaleWhileRevalidate.Seconds()))
+	}
+	return directives
+}
+
+func (p HTTPCachePolicy) now() time.Time {
+	if p.Now == nil {
+		return time.Now()
+	}
+	return p.Now()
+}
+
+// dateKey returns the calendar date of t in its own timezone, for comparing
+// dates regardless of the timezones they were parsed in.
+func dateKey(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// validators derives the ETag and Last-Modified of a response from the IDs and
+// update times of the rates it was built from. extra distinguishes responses
+// built from the same rates, e.g. pages of an empty list.
+func validators(rates []*dto.RateResponse, extra ...string) (string, time.Time) {
+	hash := sha256.New()
+	var lastModified time.Time
+	for _, r := range rates {
+		fmt.Fprintf(hash, "%s@%d;", r.ID, r.UpdatedAt.UnixNano())
+		if r.UpdatedAt.After(lastModified) {
+			lastModified = r.UpdatedAt
+		}
+	}
+	for _, s := range extra {
+		fmt.Fprintf(hash, "%s;", s)
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`, lastModified
+}
+
+// notModified sets the validators and Cache-Control of a response and reports
+// whether the request's preconditions show that the client's copy is current,
+// in which case a 304 has been written and the handler must not write a body.
+// If-None-Match takes precedence over If-Modified-Since, as in RFC 9110.
+func notModified(c *gin.Context, etag string, lastModified time.Time, cacheControl string) bool {
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	c.Header("Cache-Control", cacheControl)
+
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		if !etagMatches(inm, etag) {
+			return false
+		}
+	} else if ims := c.GetHeader("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil || lastModified.Truncate(time.Second).After(since) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using the
+// weak comparison RFC 9110 prescribes for it.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// revisionsTag identifies the revisions of a rate history in its ETag.
+func revisionsTag(history *dto.RateHistoryResponse) string {
+	tag := strconv.Itoa(len(history.Revisions))
+	if n := len(history.Revisions); n > 0 {
+		tag += "@" + history.Revisions[n-1].ID
+	}
+	return tag
+}
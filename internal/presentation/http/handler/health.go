@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/health"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+)
+
+// HealthHandler handles liveness and readiness probes.
+type HealthHandler struct {
+	registry *health.Registry
+	logger   *slog.Logger
+}
+
+// NewHealthHandler creates a new health handler.
+func NewHealthHandler(registry *health.Registry, logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// Livez handles GET /livez requests.
+// @Summary Liveness probe
+// @Description Reports that the process is running. Dependencies are not checked, so a database outage does not restart the service.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Service is alive"
+// @Router /livez [get]
+func (h *HealthHandler) Livez(c *gin.Context) {
+	response.SuccessResponse(c, gin.H{
+		"status": health.StatusUp,
+	})
+}
+
+// Readyz handles GET /readyz and GET /health requests.
+// @Summary Readiness probe
+// @Description Runs the dependency checks (database, cache, data freshness, provider) and reports the status, latency and message of each.
+// @Description Responds with 503 when a critical dependency is down; degraded dependencies still respond with 200.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Service is ready"
+// @Failure 503 {object} map[string]interface{} "Service is not ready"
+// @Router /readyz [get]
+// @Router /health [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.registry.Run(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, gin.H{
+		"success": report.Ready(),
+		"data":    report,
+	})
+}
@@ -1,35 +1,50 @@
 package handler
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/tyokyo320/rateflow/internal/application/dto"
 	"github.com/tyokyo320/rateflow/internal/application/query"
 	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+	"github.com/tyokyo320/rateflow/pkg/genericrepo"
 	"github.com/tyokyo320/rateflow/pkg/timeutil"
 )
 
 // RateHandler handles rate-related HTTP requests.
 type RateHandler struct {
-	getLatestHandler *query.GetLatestRateHandler
-	listRatesHandler *query.ListRatesHandler
-	logger           *slog.Logger
+	getLatestHandler  *query.GetLatestRateHandler
+	getByDateHandler  *query.GetRateByDateHandler
+	listRatesHandler  *query.ListRatesHandler
+	getHistoryHandler *query.GetRateHistoryHandler
+	cachePolicy       HTTPCachePolicy
+	logger            *slog.Logger
 }
 
 // NewRateHandler creates a new rate handler.
 func NewRateHandler(
 	getLatestHandler *query.GetLatestRateHandler,
+	getByDateHandler *query.GetRateByDateHandler,
 	listRatesHandler *query.ListRatesHandler,
+	getHistoryHandler *query.GetRateHistoryHandler,
+	cachePolicy HTTPCachePolicy,
 	logger *slog.Logger,
 ) *RateHandler {
 	return &RateHandler{
-		getLatestHandler: getLatestHandler,
-		listRatesHandler: listRatesHandler,
-		logger:           logger,
+		getLatestHandler:  getLatestHandler,
+		getByDateHandler:  getByDateHandler,
+		listRatesHandler:  listRatesHandler,
+		getHistoryHandler: getHistoryHandler,
+		cachePolicy:       cachePolicy,
+		logger:            logger,
 	}
 }
 
@@ -37,10 +52,15 @@ func NewRateHandler(
 // @Summary Get latest exchange rate
 // @Description Retrieves the most recent exchange rate for a given currency pair
 // @Tags rates
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param pair query string true "Currency pair (e.g., CNY/JPY, CNYJPY, or CNY-JPY)"
+// @Param asOf query string false "Transaction time (RFC3339) to reconstruct the rate as it was known then"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Param If-Modified-Since header string false "Last-Modified of a cached response"
 // @Success 200 {object} map[string]interface{} "Success response with rate data"
+// @Success 304 {string} string "Not modified"
 // @Failure 400 {object} map[string]interface{} "Bad request error"
 // @Failure 404 {object} map[string]interface{} "Rate not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -49,58 +69,56 @@ func NewRateHandler(
 func (h *RateHandler) GetLatest(c *gin.Context) {
 	pairStr := c.Query("pair")
 	if pairStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "BAD_REQUEST",
-				"message": "pair parameter is required",
-			},
-		})
+		response.BadRequestError(c, "pair parameter is required")
 		return
 	}
 
 	pair, err := currency.ParsePair(pairStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "BAD_REQUEST",
-				"message": "invalid currency pair format",
-			},
-		})
+		response.BadRequestError(c, "invalid currency pair format")
+		return
+	}
+
+	asOf, ok := parseAsOf(c)
+	if !ok {
 		return
 	}
 
 	result, err := h.getLatestHandler.Handle(c.Request.Context(), query.GetLatestRateQuery{
 		Pair: pair,
+		AsOf: asOf,
 	})
 	if err != nil {
-		h.logger.Error("failed to get latest rate", "error", err)
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "NOT_FOUND",
-				"message": "rate not found",
-			},
-		})
+		fail(c, h.logger, "failed to get latest rate", err, "pair", pair.String())
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    result,
-	})
+	cacheControl := h.cachePolicy.current()
+	if asOf != nil {
+		cacheControl = h.cachePolicy.forDate(asOf)
+	}
+	etag, lastModified := validators([]*dto.RateResponse{result})
+	if notModified(c, etag, lastModified, cacheControl) {
+		return
+	}
+
+	response.SuccessResponse(c, result)
 }
 
 // GetByDate handles GET /api/rates requests for a specific date.
 // @Summary Get exchange rate for a specific date
 // @Description Retrieves the exchange rate for a given currency pair on a specific date
 // @Tags rates
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param pair query string true "Currency pair (e.g., CNY/JPY, CNYJPY, or CNY-JPY)"
 // @Param date query string true "Date in YYYY-MM-DD format (e.g., 2025-01-15)"
+// @Param asOf query string false "Transaction time (RFC3339) to reconstruct the rate as it was known then"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Param If-Modified-Since header string false "Last-Modified of a cached response"
 // @Success 200 {object} map[string]interface{} "Success response with rate data"
+// @Success 304 {string} string "Not modified"
 // @Failure 400 {object} map[string]interface{} "Bad request error"
 // @Failure 404 {object} map[string]interface{} "Rate not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -111,82 +129,75 @@ func (h *RateHandler) GetByDate(c *gin.Context) {
 	dateStr := c.Query("date")
 
 	if pairStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "BAD_REQUEST",
-				"message": "pair parameter is required",
-			},
-		})
+		response.BadRequestError(c, "pair parameter is required")
 		return
 	}
 
 	if dateStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "BAD_REQUEST",
-				"message": "date parameter is required",
-			},
-		})
+		response.BadRequestError(c, "date parameter is required")
 		return
 	}
 
 	pair, err := currency.ParsePair(pairStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "BAD_REQUEST",
-				"message": "invalid currency pair format",
-			},
-		})
+		response.BadRequestError(c, "invalid currency pair format")
 		return
 	}
 
 	date, err := timeutil.ParseDate(dateStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "BAD_REQUEST",
-				"message": "invalid date format, use YYYY-MM-DD",
-			},
-		})
+		response.BadRequestError(c, "invalid date format, use YYYY-MM-DD")
 		return
 	}
 
-	result, err := h.getLatestHandler.Handle(c.Request.Context(), query.GetLatestRateQuery{
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.getByDateHandler.Handle(c.Request.Context(), query.GetRateByDateQuery{
 		Pair: pair,
+		Date: date,
+		AsOf: asOf,
 	})
-	if err != nil || !result.EffectiveDate.Truncate(24*3600000000000).Equal(date.Truncate(24*3600000000000)) {
-		h.logger.Error("failed to get rate by date", "error", err)
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "NOT_FOUND",
-				"message": "rate not found for the specified date",
-			},
-		})
+	if err != nil {
+		fail(c, h.logger, "failed to get rate by date", err, "pair", pair.String(), "date", dateStr)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    result,
-	})
+	cacheControl := h.cachePolicy.forDate(&date)
+	if asOf != nil {
+		cacheControl = h.cachePolicy.forDate(asOf)
+	}
+	etag, lastModified := validators([]*dto.RateResponse{result})
+	if notModified(c, etag, lastModified, cacheControl) {
+		return
+	}
+
+	response.SuccessResponse(c, result)
 }
 
 // List handles GET /api/rates/list requests.
 // @Summary List exchange rates with pagination
 // @Description Retrieves a paginated list of exchange rates, optionally filtered by currency pair
 // @Tags rates
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param pair query string false "Currency pair filter (e.g., CNY/JPY, CNYJPY, or CNY-JPY)"
 // @Param page query int false "Page number (default: 1)" default(1)
 // @Param pageSize query int false "Items per page (default: 20, max: 100)" default(20)
+// @Param asOf query string false "Transaction time (RFC3339) to reconstruct the list as it was known then"
+// @Param cursor query string false "Opaque cursor from a previous response's meta.nextCursor; replaces page"
+// @Param startDate query string false "Earliest effective date (YYYY-MM-DD)"
+// @Param endDate query string false "Latest effective date (YYYY-MM-DD)"
+// @Param source query string false "Comma-separated sources to include (e.g., unionpay,ecb)"
+// @Param minRate query number false "Minimum rate in the requested direction (inclusive)"
+// @Param maxRate query number false "Maximum rate in the requested direction (inclusive)"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Param If-Modified-Since header string false "Last-Modified of a cached response"
 // @Success 200 {object} map[string]interface{} "Success response with paginated rate list and metadata"
+// @Success 304 {string} string "Not modified"
 // @Failure 400 {object} map[string]interface{} "Bad request error"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/rates/list [get]
@@ -217,13 +228,7 @@ func (h *RateHandler) List(c *gin.Context) {
 	if pairStr != "" {
 		pair, err = currency.ParsePair(pairStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "BAD_REQUEST",
-					"message": "invalid currency pair format",
-				},
-			})
+			response.BadRequestError(c, "invalid currency pair format")
 			return
 		}
 	}
@@ -233,13 +238,7 @@ func (h *RateHandler) List(c *gin.Context) {
 	if startDateStr != "" {
 		parsed, err := time.Parse("2006-01-02", startDateStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "BAD_REQUEST",
-					"message": "invalid startDate format, expected YYYY-MM-DD",
-				},
-			})
+			response.BadRequestError(c, "invalid startDate format, expected YYYY-MM-DD")
 			return
 		}
 		startDate = &parsed
@@ -247,18 +246,47 @@ func (h *RateHandler) List(c *gin.Context) {
 	if endDateStr != "" {
 		parsed, err := time.Parse("2006-01-02", endDateStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"success": false,
-				"error": gin.H{
-					"code":    "BAD_REQUEST",
-					"message": "invalid endDate format, expected YYYY-MM-DD",
-				},
-			})
+			response.BadRequestError(c, "invalid endDate format, expected YYYY-MM-DD")
 			return
 		}
 		endDate = &parsed
 	}
 
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	// Parse cursor if provided
+	var cursor *genericrepo.Cursor
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		decoded, err := genericrepo.DecodeCursor(cursorStr)
+		if err != nil {
+			response.BadRequestError(c, "invalid cursor")
+			return
+		}
+		cursor = &decoded
+	}
+
+	// Parse source and rate range filters if provided
+	var sources []rate.Source
+	if sourceStr := c.Query("source"); sourceStr != "" {
+		for _, source := range strings.Split(sourceStr, ",") {
+			if source = strings.TrimSpace(source); source != "" {
+				sources = append(sources, rate.Source(strings.ToLower(source)))
+			}
+		}
+	}
+
+	minRate, ok := parsePositiveFloat(c, "minRate")
+	if !ok {
+		return
+	}
+	maxRate, ok := parsePositiveFloat(c, "maxRate")
+	if !ok {
+		return
+	}
+
 	// Execute query
 	result, err := h.listRatesHandler.Handle(c.Request.Context(), query.ListRatesQuery{
 		Pair:      pair,
@@ -266,44 +294,111 @@ func (h *RateHandler) List(c *gin.Context) {
 		PageSize:  pageSize,
 		StartDate: startDate,
 		EndDate:   endDate,
+		AsOf:      asOf,
+		Cursor:    cursor,
+		Sources:   sources,
+		MinRate:   minRate,
+		MaxRate:   maxRate,
 	})
 	if err != nil {
-		h.logger.Error("failed to list rates", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error": gin.H{
-				"code":    "INTERNAL_ERROR",
-				"message": "failed to retrieve rates",
-			},
-		})
+		fail(c, h.logger, "failed to list rates", err)
+		return
+	}
+
+	// A later page or total changes the list even when its items do not
+	etag, lastModified := validators(result.Items,
+		strconv.FormatInt(result.Pagination.Total, 10),
+		strconv.Itoa(result.Pagination.Page),
+		result.NextCursor,
+	)
+	if notModified(c, etag, lastModified, h.cachePolicy.forDate(endDate)) {
 		return
 	}
 
 	// Return response with metadata
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    result.Items,
-		"meta": gin.H{
-			"page":       result.Pagination.Page,
-			"pageSize":   result.Pagination.PageSize,
-			"total":      result.Pagination.Total,
-			"totalPages": result.Pagination.TotalPages,
-		},
+	response.SuccessResponseWithMeta(c, result.Items, &response.Meta{
+		Page:       result.Pagination.Page,
+		PageSize:   result.Pagination.PageSize,
+		Total:      result.Pagination.Total,
+		TotalPages: result.Pagination.TotalPages,
+		NextCursor: result.NextCursor,
 	})
 }
 
-// Health handles GET /health requests.
-// @Summary Health check endpoint
-// @Description Returns the health status of the API service
-// @Tags health
+// GetHistory handles GET /api/v1/rates/{id}/history requests.
+// @Summary Get revision history of a rate
+// @Description Retrieves every recorded value change of a rate, oldest first
+// @Tags rates
+// @Security ApiKeyAuth
+// @Accept json
 // @Produce json
-// @Success 200 {object} map[string]interface{} "Health status response"
-// @Router /health [get]
-func (h *RateHandler) Health(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data": gin.H{
-			"status": "healthy",
-		},
+// @Param id path string true "Rate ID"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Param If-Modified-Since header string false "Last-Modified of a cached response"
+// @Success 200 {object} map[string]interface{} "Success response with rate and revisions"
+// @Success 304 {string} string "Not modified"
+// @Failure 404 {object} map[string]interface{} "Rate not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/rates/{id}/history [get]
+func (h *RateHandler) GetHistory(c *gin.Context) {
+	rateID := c.Param("id")
+
+	result, err := h.getHistoryHandler.Handle(c.Request.Context(), query.GetRateHistoryQuery{
+		RateID: rateID,
 	})
+	if err != nil {
+		fail(c, h.logger, "failed to get rate history", err, "rate_id", rateID)
+		return
+	}
+
+	etag, lastModified := validators([]*dto.RateResponse{result.Rate}, revisionsTag(result))
+	if notModified(c, etag, lastModified, h.cachePolicy.current()) {
+		return
+	}
+
+	response.SuccessResponse(c, result)
+}
+
+// fail writes the error response err maps to. Only failures of the service are
+// logged; missing data and invalid requests are the client's concern.
+func fail(c *gin.Context, logger *slog.Logger, msg string, err error, args ...any) {
+	m := response.MapError(err)
+	if m.Status >= http.StatusInternalServerError {
+		logger.ErrorContext(c.Request.Context(), msg, append(args, "error", err)...)
+	}
+	response.ErrorResponse(c, m.Status, m.Code, m.Message)
+}
+
+// parseAsOf parses the optional asOf query parameter.
+// It writes a 400 response and returns false when the value is invalid.
+func parseAsOf(c *gin.Context) (*time.Time, bool) {
+	asOfStr := c.Query("asOf")
+	if asOfStr == "" {
+		return nil, true
+	}
+
+	asOf, err := timeutil.ParseFlexible(asOfStr)
+	if err != nil {
+		response.BadRequestError(c, "invalid asOf format, use RFC3339 (e.g., 2025-01-31T23:59:59Z)")
+		return nil, false
+	}
+
+	return &asOf, true
+}
+
+// parsePositiveFloat parses an optional positive number query parameter.
+// It writes a 400 response and returns false when the value is invalid.
+func parsePositiveFloat(c *gin.Context, name string) (*float64, bool) {
+	str := c.Query(name)
+	if str == "" {
+		return nil, true
+	}
+
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil || value <= 0 {
+		response.BadRequestError(c, fmt.Sprintf("invalid %s, expected a positive number", name))
+		return nil, false
+	}
+
+	return &value, true
 }
@@ -0,0 +1,121 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/handler"
+)
+
+func TestRateHandler_ConditionalGet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	log := logger.NewNoop()
+
+	repo := memory.NewRateRepository(log)
+	r, err := rate.NewRate(currency.MustNewPair(currency.CNY, currency.JPY), 20.5, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	if err := repo.Create(ctx, r); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// 20 minutes before the next hourly fetch run
+	now := time.Date(2024, 1, 16, 9, 40, 0, 0, time.UTC)
+	cache := persistence.NewCache(ctx, config.CacheConfig{Mode: config.CacheModeMemory, MaxEntries: 100}, config.RedisConfig{}, log)
+	rateHandler := handler.NewRateHandler(
+		query.NewGetLatestRateHandler(repo, cache, query.DefaultLatestCachePolicy, log),
+		query.NewGetRateByDateHandler(repo, cache, query.DefaultByDateCachePolicy, log),
+		query.NewListRatesHandler(repo, log),
+		query.NewGetRateHistoryHandler(repo, log),
+		handler.HTTPCachePolicy{
+			FetchInterval:        time.Hour,
+			HistoricalMaxAge:     24 * time.Hour,
+			StaleWhileRevalidate: time.Minute,
+			Now:                  func() time.Time { return now },
+		},
+		log,
+	)
+	router := gin.New()
+	router.GET("/rates/latest", rateHandler.GetLatest)
+	router.GET("/rates", rateHandler.GetByDate)
+	router.GET("/rates/list", rateHandler.List)
+
+	get := func(url string, header ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("/rates/latest?pair=CNY/JPY")
+	etag, lastModified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	if first.Code != http.StatusOK || etag == "" || lastModified == "" {
+		t.Fatalf("status = %d, ETag %q, Last-Modified %q; want 200 with validators", first.Code, etag, lastModified)
+	}
+	if got, want := first.Header().Get("Cache-Control"), "public, max-age=1200, stale-while-revalidate=60"; got != want {
+		t.Errorf("Cache-Control = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		header []string
+		want   int
+	}{
+		{"matching etag", []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"weak etag in list", []string{"If-None-Match", `"other", W/` + etag}, http.StatusNotModified},
+		{"other etag", []string{"If-None-Match", `"other"`}, http.StatusOK},
+		{"not modified since", []string{"If-Modified-Since", lastModified}, http.StatusNotModified},
+		{"modified since", []string{"If-Modified-Since", "Mon, 01 Jan 2024 00:00:00 GMT"}, http.StatusOK},
+		{"etag takes precedence", []string{"If-None-Match", `"other"`, "If-Modified-Since", lastModified}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get("/rates/latest?pair=CNY/JPY", tt.header...)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 with body %s", rec.Body)
+			}
+		})
+	}
+
+	// Rates of past dates are cacheable for longer
+	byDate := get("/rates?pair=CNY/JPY&date=2024-01-15")
+	if got, want := byDate.Header().Get("Cache-Control"), "public, max-age=86400, stale-while-revalidate=60"; got != want {
+		t.Errorf("past date Cache-Control = %q, want %q", got, want)
+	}
+
+	// A corrected rate changes the validators of the responses built from it
+	list := get("/rates/list?pair=CNY/JPY")
+	if err := r.UpdateValue(20.6); err != nil {
+		t.Fatalf("UpdateValue() error = %v", err)
+	}
+	if err := repo.Update(ctx, r); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if rec := get("/rates/list?pair=CNY/JPY", "If-None-Match", list.Header().Get("ETag")); rec.Code != http.StatusOK {
+		t.Errorf("after update: status = %d, want 200", rec.Code)
+	}
+	if missing := get("/rates/latest?pair=EUR/GBP"); missing.Header().Get("ETag") != "" {
+		t.Errorf("error response carries ETag %q", missing.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+)
+
+// wsWriteTimeout bounds each write to a WebSocket client, so that a client
+// that stopped reading does not hold its stream open.
+const wsWriteTimeout = 10 * time.Second
+
+// StreamHandler streams newly stored rates to clients over Server-Sent Events
+// or, for clients requesting an upgrade, WebSocket.
+type StreamHandler struct {
+	streamHandler *query.StreamRatesHandler
+	heartbeat     time.Duration
+	upgrader      websocket.Upgrader
+	logger        *slog.Logger
+
+	done      chan struct{} // closed on shutdown
+	closeOnce sync.Once
+}
+
+// NewStreamHandler creates a new stream handler sending keep-alives on
+// streams idle for heartbeat.
+func NewStreamHandler(streamHandler *query.StreamRatesHandler, heartbeat time.Duration, logger *slog.Logger) *StreamHandler {
+	return &StreamHandler{
+		streamHandler: streamHandler,
+		heartbeat:     heartbeat,
+		upgrader: websocket.Upgrader{
+			// Origins are not restricted, as with CORS; streams carry public data
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+}
+
+// Shutdown ends all streams. Streams never complete on their own, so a graceful
+// server shutdown would otherwise wait for its timeout.
+func (h *StreamHandler) Shutdown() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// Stream handles GET /api/v1/rates/stream requests.
+// @Summary Stream newly stored rates
+// @Description Pushes an event whenever a rate of the requested pairs is stored, as Server-Sent Events or, when the request asks for an upgrade, WebSocket messages.
+// @Description Clients resume after a disconnect with the Last-Event-ID header (or lastEventId parameter); idle streams receive heartbeats.
+// @Tags rates
+// @Security ApiKeyAuth
+// @Produce text/event-stream
+// @Param pairs query string false "Comma-separated currency pairs (e.g., CNY/JPY,USD/JPY); all pairs when omitted"
+// @Param lastEventId query string false "ID of the last event received, to resume after"
+// @Param Last-Event-ID header string false "ID of the last event received, to resume after"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} map[string]interface{} "Bad request error"
+// @Router /api/v1/rates/stream [get]
+func (h *StreamHandler) Stream(c *gin.Context) {
+	var pairs []currency.Pair
+	for _, s := range strings.Split(c.Query("pairs"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		pair, err := currency.ParsePair(s)
+		if err != nil {
+			response.BadRequestError(c, fmt.Sprintf("invalid currency pair format: %s", s))
+			return
+		}
+		pairs = append(pairs, pair)
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	// Streams end on shutdown, and a hijacked WebSocket connection is not tied
+	// to the request context, so the handler ends the stream itself
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-h.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	events, err := h.streamHandler.Handle(ctx, query.StreamRatesQuery{
+		Pairs:       pairs,
+		LastEventID: lastEventID,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to stream rates", err)
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		h.streamWebSocket(ctx, cancel, c, events)
+		return
+	}
+	h.streamSSE(ctx, c, events)
+}
+
+// streamSSE writes events as Server-Sent Events until the client disconnects.
+func (h *StreamHandler) streamSSE(ctx context.Context, c *gin.Context, events <-chan dto.RateEventResponse) {
+	// Streams outlive the server's write timeout
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.WarnContext(ctx, "failed to clear write deadline of rate stream", "error", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // keep proxies such as nginx from buffering events
+	c.Status(http.StatusOK)
+
+	write := func(format string, args ...any) bool {
+		if _, err := fmt.Fprintf(c.Writer, format, args...); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	// Clients reconnect after the heartbeat interval when the stream breaks
+	if !write("retry: %d\n\n", h.heartbeat.Milliseconds()) {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event.Rate)
+			if err != nil {
+				h.logger.ErrorContext(ctx, "failed to encode rate event", "error", err)
+				continue
+			}
+			if !write("id: %s\nevent: rate\ndata: %s\n\n", event.ID, data) {
+				return
+			}
+			heartbeat.Reset(h.heartbeat)
+		case <-heartbeat.C:
+			if !write(": heartbeat\n\n") {
+				return
+			}
+		}
+	}
+}
+
+// wsMessage is a message sent to WebSocket clients.
+type wsMessage struct {
+	Type string                 `json:"type"` // rate
+	Data *dto.RateEventResponse `json:"data"`
+}
+
+// streamWebSocket upgrades the connection and sends events as JSON messages
+// until the client disconnects. Heartbeats are ping frames; a client that
+// answers none within two heartbeat intervals is disconnected.
+func (h *StreamHandler) streamWebSocket(ctx context.Context, cancel context.CancelFunc, c *gin.Context, events <-chan dto.RateEventResponse) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has written the error response
+		h.logger.DebugContext(ctx, "websocket upgrade failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	// Clients send nothing but control frames; reading processes them and
+	// notices when the client goes away
+	conn.SetReadLimit(512)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(time.Second))
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(wsMessage{Type: "rate", Data: &event}); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
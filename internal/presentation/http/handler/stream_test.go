@@ -0,0 +1,170 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/handler"
+)
+
+// sseEvent is an event read from a Server-Sent Events stream.
+type sseEvent struct {
+	id, event, data string
+	comment         bool
+}
+
+// readSSE reads the events of a stream until it ends.
+func readSSE(body *bufio.Scanner, events chan<- sseEvent) {
+	defer close(events)
+	var e sseEvent
+	for body.Scan() {
+		line := body.Text()
+		switch {
+		case line == "":
+			events <- e
+			e = sseEvent{}
+		case strings.HasPrefix(line, ":"):
+			e.comment = true
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStreamHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.NewNoop()
+	events := memory.NewRateEvents(100, log)
+
+	stream := handler.NewStreamHandler(query.NewStreamRatesHandler(events, log), 50*time.Millisecond, log)
+	router := gin.New()
+	router.GET("/rates/stream", stream.Stream)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	defer stream.Shutdown()
+
+	ctx := context.Background()
+	publish := func(pair string, value float64) {
+		t.Helper()
+		if err := events.Publish(ctx, rate.Event{RateID: "rate-" + pair, Pair: pair, Value: value}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	publish("JPY/CNY", 0.05) // before the stream starts; only replayed on resume
+
+	// nextRate skips the retry field and heartbeats and returns the next rate event
+	nextRate := func(received <-chan sseEvent) sseEvent {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case e, ok := <-received:
+				if !ok {
+					t.Fatal("stream ended")
+				}
+				if e.event == "rate" {
+					return e
+				}
+			case <-timeout:
+				t.Fatal("no rate event received")
+			}
+		}
+	}
+
+	open := func(lastEventID string) (<-chan sseEvent, func()) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/rates/stream?pairs=CNY/JPY", nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET stream: %v", err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+		}
+		received := make(chan sseEvent, 16)
+		go readSSE(bufio.NewScanner(resp.Body), received)
+		return received, func() { resp.Body.Close() }
+	}
+
+	// Streams subscribe before responding, so events published from now on arrive
+	received, closeStream := open("")
+	publish("USD/JPY", 150) // not requested
+	publish("CNY/JPY", 20.5)
+
+	first := nextRate(received)
+	var r dto.RateResponse
+	if err := json.Unmarshal([]byte(first.data), &r); err != nil || r.Pair != "CNY/JPY" || r.Rate != 20.5 {
+		t.Fatalf("first event = %+v, want CNY/JPY 20.5", first)
+	}
+
+	// An idle stream receives heartbeats
+	select {
+	case e := <-received:
+		if !e.comment {
+			t.Errorf("idle stream sent %+v, want a heartbeat", e)
+		}
+	case <-time.After(time.Second):
+		t.Error("no heartbeat on the idle stream")
+	}
+	closeStream()
+
+	// The inverse pair published while disconnected is delivered on resume, inverted
+	publish("JPY/CNY", 0.04)
+	received, closeStream = open(first.id)
+	defer closeStream()
+	resumed := nextRate(received)
+	if err := json.Unmarshal([]byte(resumed.data), &r); err != nil || r.Pair != "CNY/JPY" || r.Rate != 25 {
+		t.Errorf("resumed event = %+v, want CNY/JPY 25", resumed)
+	}
+
+	// Invalid resume positions are rejected before streaming
+	resp, err := http.Get(server.URL + "/rates/stream?lastEventId=abc")
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid Last-Event-ID: status = %d, want 400", resp.StatusCode)
+	}
+
+	// WebSocket clients get the same events as JSON messages
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/rates/stream?pairs=JPY/CNY", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	publish("CNY/JPY", 20)
+
+	var msg struct {
+		Type string                `json:"type"`
+		Data dto.RateEventResponse `json:"data"`
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if msg.Type != "rate" || msg.Data.ID == "" || msg.Data.Rate.Pair != "JPY/CNY" || msg.Data.Rate.Rate != 0.05 {
+		t.Errorf("message = %+v, want JPY/CNY 0.05 with an event ID", msg)
+	}
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+)
+
+// Number of suspect rates listed by default and at most.
+const (
+	defaultSuspectLimit = 50
+	maxSuspectLimit     = 500
+)
+
+// anonymousReviewer names the reviewer of requests without an API key.
+const anonymousReviewer = "anonymous"
+
+// SuspectRateHandler handles the review of quarantined rates.
+type SuspectRateHandler struct {
+	listHandler    *query.ListSuspectRatesHandler
+	approveHandler *command.ApproveSuspectRateHandler
+	rejectHandler  *command.RejectSuspectRateHandler
+	logger         *slog.Logger
+}
+
+// NewSuspectRateHandler creates a new suspect rate handler for the rates
+// quarantined in suspects. Approved rates are stored in rateRepo and published
+// like fetched ones; webhooks may be nil, in which case no webhooks are emitted.
+func NewSuspectRateHandler(
+	suspects anomaly.Repository,
+	rateRepo rate.Repository,
+	cache redis.CacheInterface,
+	events rate.EventLog,
+	webhooks *command.EmitWebhooksHandler,
+	logger *slog.Logger,
+) *SuspectRateHandler {
+	return &SuspectRateHandler{
+		listHandler:    query.NewListSuspectRatesHandler(suspects, logger),
+		approveHandler: command.NewApproveSuspectRateHandler(rateRepo, suspects, cache, events, webhooks, logger),
+		rejectHandler:  command.NewRejectSuspectRateHandler(suspects, logger),
+		logger:         logger,
+	}
+}
+
+// List handles GET /api/v1/admin/suspect-rates requests.
+// @Summary List suspect rates
+// @Description Retrieves rates from providers quarantined because they do not fit the recent history of their pair, newest first
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param status query string false "Review status (pending, approved, rejected); all when omitted"
+// @Param limit query int false "Number of suspect rates (default: 50, max: 500)" default(50)
+// @Success 200 {object} map[string]interface{} "Success response with suspect rates"
+// @Failure 400 {object} map[string]interface{} "Bad request error"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/admin/suspect-rates [get]
+func (h *SuspectRateHandler) List(c *gin.Context) {
+	status := anomaly.Status(c.Query("status"))
+	if status != "" && !slices.Contains(anomaly.Statuses, status) {
+		response.BadRequestError(c, "status must be pending, approved or rejected")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSuspectLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultSuspectLimit
+	}
+	limit = min(limit, maxSuspectLimit)
+
+	result, err := h.listHandler.Handle(c.Request.Context(), query.ListSuspectRatesQuery{
+		Status: status,
+		Limit:  limit,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to list suspect rates", err)
+		return
+	}
+
+	response.SuccessResponse(c, result)
+}
+
+// Get handles GET /api/v1/admin/suspect-rates/{id} requests.
+// @Summary Get a suspect rate
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Suspect rate ID"
+// @Success 200 {object} map[string]interface{} "Success response with the suspect rate"
+// @Failure 404 {object} map[string]interface{} "Suspect rate not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/admin/suspect-rates/{id} [get]
+func (h *SuspectRateHandler) Get(c *gin.Context) {
+	result, err := h.listHandler.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		fail(c, h.logger, "failed to get suspect rate", err, "suspect_id", c.Param("id"))
+		return
+	}
+
+	response.SuccessResponse(c, result)
+}
+
+// Approve handles POST /api/v1/admin/suspect-rates/{id}/approve requests.
+// @Summary Approve a suspect rate
+// @Description Stores the quarantined rate, attributed to the reviewer, and publishes it to the stream and webhooks like a fetched rate
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Suspect rate ID"
+// @Param review body dto.ReviewRequest false "Reason for the approval"
+// @Success 200 {object} map[string]interface{} "Success response with the approved suspect rate"
+// @Failure 400 {object} map[string]interface{} "Bad request error"
+// @Failure 404 {object} map[string]interface{} "Suspect rate not found"
+// @Failure 409 {object} map[string]interface{} "Suspect rate already reviewed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/admin/suspect-rates/{id}/approve [post]
+func (h *SuspectRateHandler) Approve(c *gin.Context) {
+	req, ok := bindReview(c)
+	if !ok {
+		return
+	}
+
+	s, err := h.approveHandler.Handle(c.Request.Context(), command.ApproveSuspectRateCommand{
+		ID:       c.Param("id"),
+		Reviewer: reviewer(c),
+		Note:     req.Note,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to approve suspect rate", err, "suspect_id", c.Param("id"))
+		return
+	}
+
+	response.SuccessResponse(c, query.SuspectRateResponse(s))
+}
+
+// Reject handles POST /api/v1/admin/suspect-rates/{id}/reject requests.
+// @Summary Reject a suspect rate
+// @Description Discards the quarantined rate. Later fetches repeating the rejected value are discarded too.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Suspect rate ID"
+// @Param review body dto.ReviewRequest false "Reason for the rejection"
+// @Success 200 {object} map[string]interface{} "Success response with the rejected suspect rate"
+// @Failure 400 {object} map[string]interface{} "Bad request error"
+// @Failure 404 {object} map[string]interface{} "Suspect rate not found"
+// @Failure 409 {object} map[string]interface{} "Suspect rate already reviewed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/admin/suspect-rates/{id}/reject [post]
+func (h *SuspectRateHandler) Reject(c *gin.Context) {
+	req, ok := bindReview(c)
+	if !ok {
+		return
+	}
+
+	s, err := h.rejectHandler.Handle(c.Request.Context(), command.RejectSuspectRateCommand{
+		ID:       c.Param("id"),
+		Reviewer: reviewer(c),
+		Note:     req.Note,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to reject suspect rate", err, "suspect_id", c.Param("id"))
+		return
+	}
+
+	response.SuccessResponse(c, query.SuspectRateResponse(s))
+}
+
+// bindReview reads the optional body of a review request, responding with an
+// error if it is malformed.
+func bindReview(c *gin.Context) (dto.ReviewRequest, bool) {
+	var req dto.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequestError(c, "invalid request body")
+		return req, false
+	}
+	return req, true
+}
+
+// reviewer returns the name of the API key reviewing a suspect rate.
+func reviewer(c *gin.Context) string {
+	if name := c.GetString("api_key_name"); name != "" {
+		return name
+	}
+	return anonymousReviewer
+}
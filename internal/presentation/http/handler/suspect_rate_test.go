@@ -0,0 +1,235 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/handler"
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// stubProvider answers with fixed rates by date.
+type stubProvider struct {
+	rates map[string]float64
+}
+
+func (p stubProvider) Name() string { return "unionpay" }
+
+func (p stubProvider) FetchRate(_ context.Context, _ currency.Pair, date time.Time) (float64, error) {
+	return p.rates[timeutil.FormatDate(date)], nil
+}
+
+func (p stubProvider) FetchLatest(context.Context, currency.Pair) (float64, error) { return 0, nil }
+
+func (p stubProvider) SupportedPairs() []currency.Pair { return nil }
+
+func (p stubProvider) SupportsMulti() bool { return false }
+
+func (p stubProvider) FetchMulti(context.Context, []currency.Pair, time.Time) (map[string]float64, error) {
+	return nil, nil
+}
+
+func TestSuspectRateHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	log := logger.NewNoop()
+
+	db, err := persistence.NewConnection(config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "rateflow.db"),
+	}, log)
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	rateRepo := persistence.NewRateRepository(db, log)
+	suspectRepo := persistence.NewSuspectRateRepository(db, log)
+	cache := memory.NewCache(100, log)
+	events := memory.NewRateEvents(100, log)
+
+	// Twelve days of CNY/JPY wobbling around 20.5
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	for d, v := range []float64{20.40, 20.55, 20.48, 20.62, 20.51, 20.45, 20.58, 20.60, 20.49, 20.53, 20.57, 20.50} {
+		r, err := rate.NewRate(pair, v, day(d+1), rate.SourceUnionPay)
+		if err != nil {
+			t.Fatalf("NewRate() error = %v", err)
+		}
+		if err := rateRepo.Create(ctx, r); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// The provider inverts the rate on the 13th and drops its decimal point on the 14th
+	prov := stubProvider{rates: map[string]float64{
+		"2024-01-13": 1 / 20.52,
+		"2024-01-14": 2054,
+		"2024-01-15": 20.56,
+	}}
+	screen := command.NewRateScreen(rateRepo, suspectRepo, anomaly.DefaultPolicy, log)
+	fetcher := command.NewFetchRateHandler(rateRepo, prov, persistence.NewProviderStatusRepository(db, log), cache, events, nil, screen, log)
+	fetch := func(d int) {
+		t.Helper()
+		if err := fetcher.Handle(ctx, command.FetchRateCommand{Pair: pair, Date: day(d)}); err != nil {
+			t.Fatalf("fetch %s: %v", timeutil.FormatDate(day(d)), err)
+		}
+	}
+	stored := func(d int) *rate.Rate {
+		t.Helper()
+		r, err := rateRepo.FindByPairAndDate(ctx, pair, day(d))
+		if err != nil {
+			return nil
+		}
+		return r
+	}
+
+	for _, d := range []int{13, 13, 14, 15} {
+		fetch(d)
+	}
+	if stored(13) != nil || stored(14) != nil {
+		t.Fatal("suspect rates were stored")
+	}
+	if stored(15) == nil {
+		t.Fatal("plausible rate was not stored")
+	}
+
+	suspectHandler := handler.NewSuspectRateHandler(suspectRepo, rateRepo, cache, events, nil, log)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("api_key_name", "ops") })
+	router.GET("/suspect-rates", suspectHandler.List)
+	router.GET("/suspect-rates/:id", suspectHandler.Get)
+	router.POST("/suspect-rates/:id/approve", suspectHandler.Approve)
+	router.POST("/suspect-rates/:id/reject", suspectHandler.Reject)
+
+	do := func(method, url, body string, data any) int {
+		t.Helper()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if data != nil && rec.Code < 300 {
+			envelope := struct{ Data any }{Data: data}
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("%s %s: decode %s: %v", method, url, rec.Body, err)
+			}
+		}
+		return rec.Code
+	}
+
+	// Refetching the inverted rate does not quarantine it twice
+	var pending []dto.SuspectRateResponse
+	if code := do(http.MethodGet, "/suspect-rates?status=pending", "", &pending); code != http.StatusOK {
+		t.Fatalf("list pending: status %d", code)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("listed %d pending suspect rates, want 2", len(pending))
+	}
+	var inverted, missingDecimal dto.SuspectRateResponse
+	for _, s := range pending {
+		switch s.Rate {
+		case 1 / 20.52:
+			inverted = s
+		case 2054:
+			missingDecimal = s
+		}
+	}
+	if inverted.ID == "" || missingDecimal.ID == "" {
+		t.Fatalf("pending = %+v, want the inverted rate and the missing decimal", pending)
+	}
+	if inverted.Previous == nil || *inverted.Previous != 20.50 {
+		t.Errorf("previous of inverted rate = %v, want 20.50", inverted.Previous)
+	}
+	kinds := make([]string, len(inverted.Findings))
+	for i, f := range inverted.Findings {
+		kinds[i] = f.Kind
+	}
+	if strings.Join(kinds, ",") != "zscore,jump,reciprocal" {
+		t.Errorf("findings of inverted rate = %v, want zscore, jump and reciprocal", kinds)
+	}
+	if code := do(http.MethodGet, "/suspect-rates?status=unknown", "", nil); code != http.StatusBadRequest {
+		t.Errorf("list with unknown status: status %d, want 400", code)
+	}
+
+	// An approved rate is stored and published like a fetched one
+	stream, err := events.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	var approved dto.SuspectRateResponse
+	if code := do(http.MethodPost, "/suspect-rates/"+inverted.ID+"/approve", `{"note":"confirmed with the provider"}`, &approved); code != http.StatusOK {
+		t.Fatalf("approve: status %d", code)
+	}
+	r := stored(13)
+	if r == nil || r.Value() != 1/20.52 {
+		t.Fatalf("approved rate = %v, want it stored", r)
+	}
+	if approved.Status != string(anomaly.StatusApproved) || approved.RateID != r.ID() || approved.ReviewedBy != "ops" {
+		t.Errorf("approved = %+v, want approved by ops as rate %s", approved, r.ID())
+	}
+	select {
+	case e := <-stream:
+		if e.RateID != r.ID() {
+			t.Errorf("published event of rate %s, want %s", e.RateID, r.ID())
+		}
+	case <-time.After(time.Second):
+		t.Error("approved rate was not published")
+	}
+	revisions, err := rateRepo.FindRevisions(ctx, r.ID())
+	if err != nil || len(revisions) != 1 || revisions[0].Actor != rate.ActorManual || revisions[0].ActorID != "ops" {
+		t.Errorf("revisions = %+v, %v, want one by ops", revisions, err)
+	}
+	if code := do(http.MethodPost, "/suspect-rates/"+inverted.ID+"/reject", "", nil); code != http.StatusConflict {
+		t.Errorf("reject approved: status %d, want 409", code)
+	}
+
+	// A rejected rate stays discarded when the provider repeats it
+	var rejected dto.SuspectRateResponse
+	if code := do(http.MethodPost, "/suspect-rates/"+missingDecimal.ID+"/reject", "", &rejected); code != http.StatusOK {
+		t.Fatalf("reject: status %d", code)
+	}
+	if rejected.Status != string(anomaly.StatusRejected) || rejected.ReviewedAt == nil {
+		t.Errorf("rejected = %+v", rejected)
+	}
+	fetch(14)
+	if stored(14) != nil {
+		t.Error("rejected rate was stored on refetch")
+	}
+	if code := do(http.MethodGet, "/suspect-rates?status=pending", "", &pending); code != http.StatusOK || len(pending) != 0 {
+		t.Errorf("list pending after review: status %d, %d suspects, want none", code, len(pending))
+	}
+
+	var all []dto.SuspectRateResponse
+	if code := do(http.MethodGet, "/suspect-rates", "", &all); code != http.StatusOK || len(all) != 2 {
+		t.Errorf("list all: status %d, %d suspects, want 2", code, len(all))
+	}
+	if code := do(http.MethodGet, "/suspect-rates/"+missingDecimal.ID, "", nil); code != http.StatusOK {
+		t.Errorf("get: status %d", code)
+	}
+	if code := do(http.MethodGet, "/suspect-rates/not-a-uuid", "", nil); code != http.StatusNotFound {
+		t.Errorf("get malformed id: status %d, want 404", code)
+	}
+	if code := do(http.MethodPost, "/suspect-rates/00000000-0000-0000-0000-000000000000/approve", "", nil); code != http.StatusNotFound {
+		t.Errorf("approve unknown: status %d, want 404", code)
+	}
+}
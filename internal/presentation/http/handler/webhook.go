@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/application/query"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+)
+
+// Size of the delivery log returned by default and at most.
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+// WebhookHandler handles webhook subscription HTTP requests.
+type WebhookHandler struct {
+	createHandler     *command.CreateWebhookHandler
+	updateHandler     *command.UpdateWebhookHandler
+	deleteHandler     *command.DeleteWebhookHandler
+	redeliverHandler  *command.RedeliverWebhookHandler
+	listHandler       *query.ListWebhooksHandler
+	deliveriesHandler *query.ListWebhookDeliveriesHandler
+	logger            *slog.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler for the webhooks stored in repo.
+func NewWebhookHandler(repo webhook.Repository, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		createHandler:     command.NewCreateWebhookHandler(repo, logger),
+		updateHandler:     command.NewUpdateWebhookHandler(repo, logger),
+		deleteHandler:     command.NewDeleteWebhookHandler(repo, logger),
+		redeliverHandler:  command.NewRedeliverWebhookHandler(repo, logger),
+		listHandler:       query.NewListWebhooksHandler(repo, logger),
+		deliveriesHandler: query.NewListWebhookDeliveriesHandler(repo, logger),
+		logger:            logger,
+	}
+}
+
+// Create handles POST /api/v1/webhooks requests.
+// @Summary Create a webhook
+// @Description Subscribes a URL to new rates of a pair. The response contains the secret that signs the payloads; it is not shown again.
+// @Tags webhooks
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param webhook body dto.WebhookRequest true "URL, pair, condition (new_rate, change or cross) and threshold"
+// @Success 201 {object} map[string]interface{} "Created webhook with its secret"
+// @Failure 400 {object} map[string]interface{} "Bad request error"
+// @Failure 422 {object} map[string]interface{} "Invalid webhook"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req dto.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequestError(c, "invalid request body")
+		return
+	}
+	if req.URL == nil || req.Pair == nil {
+		response.BadRequestError(c, "url and pair are required")
+		return
+	}
+
+	pair, err := currency.ParsePair(*req.Pair)
+	if err != nil {
+		response.BadRequestError(c, "invalid currency pair format")
+		return
+	}
+
+	cmd := command.CreateWebhookCommand{
+		URL:       *req.URL,
+		Pair:      pair,
+		Condition: webhook.Condition{Kind: webhook.ConditionNewRate},
+	}
+	if req.Condition != nil || req.Threshold != nil {
+		cmd.Condition = condition(req, cmd.Condition)
+	}
+	if req.Description != nil {
+		cmd.Description = *req.Description
+	}
+
+	s, err := h.createHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		fail(c, h.logger, "failed to create webhook", err)
+		return
+	}
+
+	response.CreatedResponse(c, query.WebhookResponse(s, true))
+}
+
+// List handles GET /api/v1/webhooks requests.
+// @Summary List webhooks
+// @Description Retrieves all webhook subscriptions, oldest first
+// @Tags webhooks
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Success response with webhooks"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	result, err := h.listHandler.Handle(c.Request.Context())
+	if err != nil {
+		fail(c, h.logger, "failed to list webhooks", err)
+		return
+	}
+
+	response.SuccessResponse(c, result)
+}
+
+// Get handles GET /api/v1/webhooks/{id} requests.
+// @Summary Get a webhook
+// @Tags webhooks
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} map[string]interface{} "Success response with the webhook"
+// @Failure 404 {object} map[string]interface{} "Webhook not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) Get(c *gin.Context) {
+	result, err := h.listHandler.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		fail(c, h.logger, "failed to get webhook", err, "webhook_id", c.Param("id"))
+		return
+	}
+
+	response.SuccessResponse(c, result)
+}
+
+// Update handles PATCH /api/v1/webhooks/{id} requests.
+// @Summary Update a webhook
+// @Description Changes the URL, condition, description or status of a webhook. Omitted fields are left unchanged; the pair cannot be changed.
+// @Tags webhooks
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param webhook body dto.WebhookRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{} "Success response with the updated webhook"
+// @Failure 400 {object} map[string]interface{} "Bad request error"
+// @Failure 404 {object} map[string]interface{} "Webhook not found"
+// @Failure 422 {object} map[string]interface{} "Invalid webhook"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/webhooks/{id} [patch]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var req dto.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequestError(c, "invalid request body")
+		return
+	}
+	if req.Pair != nil {
+		response.BadRequestError(c, "the pair of a webhook cannot be changed")
+		return
+	}
+
+	ctx := c.Request.Context()
+	cmd := command.UpdateWebhookCommand{
+		ID:          c.Param("id"),
+		URL:         req.URL,
+		Description: req.Description,
+		Active:      req.Active,
+	}
+	if req.Condition != nil || req.Threshold != nil {
+		current, err := h.listHandler.Get(ctx, cmd.ID)
+		if err != nil {
+			fail(c, h.logger, "failed to update webhook", err, "webhook_id", cmd.ID)
+			return
+		}
+		var threshold float64
+		if current.Threshold != nil {
+			threshold = *current.Threshold
+		}
+		changed := condition(req, webhook.Condition{Kind: webhook.ConditionKind(current.Condition), Threshold: threshold})
+		cmd.Condition = &changed
+	}
+
+	s, err := h.updateHandler.Handle(ctx, cmd)
+	if err != nil {
+		fail(c, h.logger, "failed to update webhook", err, "webhook_id", cmd.ID)
+		return
+	}
+
+	response.SuccessResponse(c, query.WebhookResponse(s, false))
+}
+
+// Delete handles DELETE /api/v1/webhooks/{id} requests.
+// @Summary Delete a webhook
+// @Description Deletes a webhook together with its delivery log
+// @Tags webhooks
+// @Security ApiKeyAuth
+// @Param id path string true "Webhook ID"
+// @Success 204 {string} string "Deleted"
+// @Failure 404 {object} map[string]interface{} "Webhook not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if err := h.deleteHandler.Handle(c.Request.Context(), command.DeleteWebhookCommand{ID: c.Param("id")}); err != nil {
+		fail(c, h.logger, "failed to delete webhook", err, "webhook_id", c.Param("id"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries handles GET /api/v1/webhooks/{id}/deliveries requests.
+// @Summary Get the delivery log of a webhook
+// @Description Retrieves the most recent deliveries of a webhook with their status, attempts and last response, newest first
+// @Tags webhooks
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Number of deliveries (default: 50, max: 500)" default(50)
+// @Success 200 {object} map[string]interface{} "Success response with deliveries"
+// @Failure 404 {object} map[string]interface{} "Webhook not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultDeliveryLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultDeliveryLimit
+	}
+	limit = min(limit, maxDeliveryLimit)
+
+	result, err := h.deliveriesHandler.Handle(c.Request.Context(), query.ListWebhookDeliveriesQuery{
+		SubscriptionID: c.Param("id"),
+		Limit:          limit,
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to list webhook deliveries", err, "webhook_id", c.Param("id"))
+		return
+	}
+
+	response.SuccessResponse(c, result)
+}
+
+// Redeliver handles POST /api/v1/webhooks/{id}/deliveries/{deliveryId}/redeliver requests.
+// @Summary Redeliver a webhook event
+// @Description Queues a new delivery of the event of a past delivery, which is sent immediately and retried like any other
+// @Tags webhooks
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param deliveryId path string true "Delivery ID"
+// @Success 202 {object} map[string]interface{} "Queued delivery"
+// @Failure 404 {object} map[string]interface{} "Webhook or delivery not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.redeliverHandler.Handle(c.Request.Context(), command.RedeliverWebhookCommand{
+		SubscriptionID: c.Param("id"),
+		DeliveryID:     c.Param("deliveryId"),
+	})
+	if err != nil {
+		fail(c, h.logger, "failed to redeliver webhook", err, "webhook_id", c.Param("id"), "delivery_id", c.Param("deliveryId"))
+		return
+	}
+
+	response.AcceptedResponse(c, query.WebhookDeliveryResponse(delivery))
+}
+
+// condition applies the condition fields of req to current.
+func condition(req dto.WebhookRequest, current webhook.Condition) webhook.Condition {
+	if req.Condition != nil {
+		current.Kind = webhook.ConditionKind(*req.Condition)
+	}
+	if req.Threshold != nil {
+		current.Threshold = *req.Threshold
+	}
+	return current
+}
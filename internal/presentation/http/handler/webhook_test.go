@@ -0,0 +1,249 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/application/dto"
+	"github.com/tyokyo320/rateflow/internal/domain/currency"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/config"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence"
+	webhooksender "github.com/tyokyo320/rateflow/internal/infrastructure/webhook"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/handler"
+)
+
+// receiver is a webhook endpoint that fails the first requests.
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (rcv *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.bodies = append(rcv.bodies, body)
+	rcv.headers = append(rcv.headers, r.Header.Clone())
+	if rcv.failures > 0 {
+		rcv.failures--
+		http.Error(w, "try again later", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rcv *receiver) requests() int {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return len(rcv.bodies)
+}
+
+func TestWebhookHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	log := logger.NewNoop()
+
+	db, err := persistence.NewConnection(config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "rateflow.db"),
+	}, log)
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	rateRepo := persistence.NewRateRepository(db, log)
+	webhookRepo := persistence.NewWebhookRepository(db, log)
+
+	rcv := &receiver{failures: 1}
+	server := httptest.NewServer(rcv)
+	t.Cleanup(server.Close)
+
+	webhookHandler := handler.NewWebhookHandler(webhookRepo, log)
+	router := gin.New()
+	router.POST("/webhooks", webhookHandler.Create)
+	router.GET("/webhooks/:id", webhookHandler.Get)
+	router.PATCH("/webhooks/:id", webhookHandler.Update)
+	router.DELETE("/webhooks/:id", webhookHandler.Delete)
+	router.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries)
+	router.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+
+	do := func(method, url, body string, data any) int {
+		t.Helper()
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if data != nil && rec.Code < 300 {
+			envelope := struct{ Data any }{Data: data}
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("%s %s: decode %s: %v", method, url, rec.Body, err)
+			}
+		}
+		return rec.Code
+	}
+
+	// Subscribe to CNY/JPY crossing 20 and to every new rate of the inverse pair
+	var crossing, every dto.WebhookResponse
+	if code := do(http.MethodPost, "/webhooks", `{"url":"`+server.URL+`","pair":"CNY/JPY","condition":"cross","threshold":20}`, &crossing); code != http.StatusCreated {
+		t.Fatalf("create crossing webhook: status %d", code)
+	}
+	if crossing.Secret == "" || crossing.Threshold == nil || *crossing.Threshold != 20 {
+		t.Fatalf("created webhook = %+v, want its secret and threshold", crossing)
+	}
+	if code := do(http.MethodPost, "/webhooks", `{"url":"`+server.URL+`","pair":"JPY/CNY"}`, &every); code != http.StatusCreated {
+		t.Fatalf("create new rate webhook: status %d", code)
+	}
+	if code := do(http.MethodPost, "/webhooks", `{"url":"not a url","pair":"CNY/JPY"}`, nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("create with invalid url: status %d, want 422", code)
+	}
+
+	var fetched dto.WebhookResponse
+	if code := do(http.MethodGet, "/webhooks/"+crossing.ID, "", &fetched); code != http.StatusOK || fetched.Secret != "" {
+		t.Errorf("get webhook: status %d, %+v; want 200 without the secret", code, fetched)
+	}
+
+	// The worker stores two days of rates; only the second crosses 20
+	emitter := command.NewEmitWebhooksHandler(webhookRepo, rateRepo, log)
+	pair := currency.MustNewPair(currency.CNY, currency.JPY)
+	for i, value := range []float64{19.9, 20.1} {
+		r, err := rate.NewRate(pair, value, time.Date(2024, 1, 12+i, 0, 0, 0, 0, time.UTC), rate.SourceUnionPay)
+		if err != nil {
+			t.Fatalf("NewRate() error = %v", err)
+		}
+		if err := rateRepo.Create(ctx, r); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := emitter.Handle(ctx, command.EmitWebhooksCommand{Rate: r}); err != nil {
+			t.Fatalf("emit webhooks: %v", err)
+		}
+	}
+
+	// The receiver listens on loopback
+	deliverer := command.NewDeliverWebhooksHandler(webhookRepo, webhooksender.NewSender(5*time.Second, true, log),
+		webhook.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 10 * time.Millisecond},
+		10, time.Minute, log)
+	deliver := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for rcv.requests() < want && time.Now().Before(deadline) {
+			if _, err := deliverer.Handle(ctx); err != nil {
+				t.Fatalf("deliver webhooks: %v", err)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		if got := rcv.requests(); got != want {
+			t.Fatalf("receiver got %d requests, want %d", got, want)
+		}
+	}
+
+	// Two new rates and one crossing; the first attempt fails and is retried
+	deliver(4)
+
+	var crossings []dto.WebhookDeliveryResponse
+	if code := do(http.MethodGet, "/webhooks/"+crossing.ID+"/deliveries", "", &crossings); code != http.StatusOK {
+		t.Fatalf("list deliveries: status %d", code)
+	}
+	if len(crossings) != 1 || crossings[0].Status != string(webhook.DeliverySucceeded) || crossings[0].EventType != string(webhook.EventRateCrossed) {
+		t.Fatalf("crossing deliveries = %+v, want one succeeded rate.crossed delivery", crossings)
+	}
+
+	var payload webhook.Payload
+	if err := json.Unmarshal(crossings[0].Payload, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Rate.Value != 20.1 || payload.PreviousValue == nil || *payload.PreviousValue != 19.9 {
+		t.Errorf("payload = %+v, want the crossing from 19.9 to 20.1", payload)
+	}
+
+	// Every request is signed with the secret of its webhook
+	secrets := map[string]string{crossing.ID: crossing.Secret}
+	rcv.mu.Lock()
+	for i, body := range rcv.bodies {
+		var p webhook.Payload
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Fatalf("decode request %d: %v", i, err)
+		}
+		secret, ok := secrets[p.SubscriptionID]
+		if !ok {
+			continue
+		}
+		if err := webhook.Verify(secret, rcv.headers[i].Get(webhook.SignatureHeader), body, time.Now(), time.Minute); err != nil {
+			t.Errorf("request %d: %v", i, err)
+		}
+		if got := rcv.headers[i].Get(webhook.EventHeader); got != string(webhook.EventRateCrossed) {
+			t.Errorf("request %d: %s = %q, want %s", i, webhook.EventHeader, got, webhook.EventRateCrossed)
+		}
+	}
+	rcv.mu.Unlock()
+
+	var everyLog []dto.WebhookDeliveryResponse
+	do(http.MethodGet, "/webhooks/"+every.ID+"/deliveries", "", &everyLog)
+	attempts := 0
+	for _, d := range everyLog {
+		attempts += d.Attempts
+	}
+	if len(everyLog) != 2 || attempts != 3 {
+		t.Errorf("new rate deliveries = %+v, want 2 deliveries with one retry", everyLog)
+	}
+
+	// Redelivery sends the same event again
+	var redelivery dto.WebhookDeliveryResponse
+	if code := do(http.MethodPost, "/webhooks/"+crossing.ID+"/deliveries/"+crossings[0].ID+"/redeliver", "", &redelivery); code != http.StatusAccepted {
+		t.Fatalf("redeliver: status %d", code)
+	}
+	if redelivery.EventID != crossings[0].EventID || redelivery.Status != string(webhook.DeliveryPending) {
+		t.Errorf("redelivery = %+v, want a pending delivery of event %s", redelivery, crossings[0].EventID)
+	}
+	deliver(5)
+	if code := do(http.MethodPost, "/webhooks/"+every.ID+"/deliveries/"+crossings[0].ID+"/redeliver", "", nil); code != http.StatusNotFound {
+		t.Errorf("redeliver delivery of another webhook: status %d, want 404", code)
+	}
+
+	// Disabled webhooks are not notified
+	var updated dto.WebhookResponse
+	if code := do(http.MethodPatch, "/webhooks/"+every.ID, `{"active":false,"description":"paused"}`, &updated); code != http.StatusOK {
+		t.Fatalf("update webhook: status %d", code)
+	}
+	if updated.Active || updated.Description != "paused" || updated.Condition != string(webhook.ConditionNewRate) {
+		t.Errorf("updated webhook = %+v, want it paused with its condition unchanged", updated)
+	}
+	if code := do(http.MethodPatch, "/webhooks/"+every.ID, `{"condition":"change"}`, nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("update to change without threshold: status %d, want 422", code)
+	}
+
+	if code := do(http.MethodDelete, "/webhooks/"+crossing.ID, "", nil); code != http.StatusNoContent {
+		t.Errorf("delete webhook: status %d, want 204", code)
+	}
+	if code := do(http.MethodGet, "/webhooks/"+crossing.ID, "", nil); code != http.StatusNotFound {
+		t.Errorf("get deleted webhook: status %d, want 404", code)
+	}
+
+	r, err := rate.NewRate(pair, 19.8, time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), rate.SourceUnionPay)
+	if err != nil {
+		t.Fatalf("NewRate() error = %v", err)
+	}
+	if n, err := emitter.Handle(ctx, command.EmitWebhooksCommand{Rate: r}); err != nil || n != 0 {
+		t.Errorf("emit after disabling and deleting: %d deliveries, error %v; want none", n, err)
+	}
+}
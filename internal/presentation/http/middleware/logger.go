@@ -6,15 +6,17 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
 )
 
 // Logger returns a middleware that logs HTTP requests.
-func Logger(logger *slog.Logger) gin.HandlerFunc {
+// The request ID, trace ID and span ID are added from the request context,
+// so RequestID and Tracing must run first.
+func Logger(log *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Generate request ID
-		requestID := uuid.New().String()
-		c.Set("request_id", requestID)
-
 		// Start timer
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -38,7 +40,6 @@ func Logger(logger *slog.Logger) gin.HandlerFunc {
 
 		// Log with structured fields
 		fields := []any{
-			slog.String("request_id", requestID),
 			slog.String("client_ip", clientIP),
 			slog.String("method", method),
 			slog.String("path", path),
@@ -47,18 +48,27 @@ func Logger(logger *slog.Logger) gin.HandlerFunc {
 			slog.String("user_agent", userAgent),
 		}
 
+		// Add the caller if the request was authenticated
+		if name := c.GetString("api_key_name"); name != "" {
+			fields = append(fields,
+				slog.String("api_key_id", c.GetString("api_key_id")),
+				slog.String("api_key_name", name),
+			)
+		}
+
 		// Add error if present
 		if len(c.Errors) > 0 {
 			fields = append(fields, slog.String("error", c.Errors.String()))
 		}
 
 		// Log based on status code
+		ctx := c.Request.Context()
 		if status >= 500 {
-			logger.Error("server error", fields...)
+			log.ErrorContext(ctx, "server error", fields...)
 		} else if status >= 400 {
-			logger.Warn("client error", fields...)
+			log.WarnContext(ctx, "client error", fields...)
 		} else {
-			logger.Info("request completed", fields...)
+			log.InfoContext(ctx, "request completed", fields...)
 		}
 	}
 }
@@ -76,6 +86,11 @@ func RequestID() gin.HandlerFunc {
 		c.Set("request_id", requestID)
 		c.Writer.Header().Set("X-Request-ID", requestID)
 
+		// Carry the request ID to logs written further down and to the span
+		ctx := logger.ContextWithRequestID(c.Request.Context(), requestID)
+		c.Request = c.Request.WithContext(ctx)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", requestID))
+
 		c.Next()
 	}
 }
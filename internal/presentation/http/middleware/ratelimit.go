@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+)
+
+// RateLimits holds the request limits of the routes.
+type RateLimits struct {
+	Default redis.Limit            // limit of routes without their own; zero for none
+	Routes  map[string]redis.Limit // limits by route template
+}
+
+// limit returns the limit of route and whether it is limited at all.
+func (l RateLimits) limit(route string) (redis.Limit, bool) {
+	if limit, ok := l.Routes[route]; ok {
+		return limit, true
+	}
+	return l.Default, l.Default.Requests > 0
+}
+
+// RateLimit returns a middleware that limits requests per route with sliding
+// windows. Requests are counted per API key, so it must run after the API key
+// check; requests without a key are counted per client IP.
+//
+// Responses carry the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset
+// and RateLimit-Policy headers. Rejected requests get 429 with Retry-After.
+// When the limiter fails, requests are let through.
+func RateLimit(limiter redis.Limiter, limits RateLimits, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		limit, ok := limits.limit(route)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		client := "ip:" + c.ClientIP()
+		if id := c.GetString("api_key_id"); id != "" {
+			client = "key:" + id
+		}
+
+		ctx := c.Request.Context()
+		result, err := limiter.Allow(ctx, route+":"+client, limit, time.Now())
+		if err != nil {
+			logger.WarnContext(ctx, "rate limiter failed, letting request through", "route", route, "error", err)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", seconds(result.Reset))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", limit.Requests, seconds(limit.Window)))
+
+		if !result.Allowed {
+			header.Set("Retry-After", seconds(result.RetryAfter))
+			response.TooManyRequestsError(c, "rate limit exceeded, retry in "+seconds(result.RetryAfter)+"s")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// seconds formats d as whole seconds, rounded up so that clients do not retry early.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
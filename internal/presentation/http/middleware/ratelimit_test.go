@@ -0,0 +1,85 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/memory"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/middleware"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		// Stands in for the API key check
+		if key := c.GetHeader("X-Test-Key"); key != "" {
+			c.Set("api_key_id", key)
+		}
+	})
+	router.Use(middleware.RateLimit(memory.NewRateLimiter(logger.NewNoop()), middleware.RateLimits{
+		Routes: map[string]redis.Limit{"/list": {Requests: 2, Window: time.Minute}},
+	}, logger.NewNoop()))
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/list", ok)
+	router.GET("/latest", ok)
+
+	get := func(path, ip, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		if key != "" {
+			req.Header.Set("X-Test-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := range 2 {
+		rec := get("/list", "10.0.0.1", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != []string{"1", "0"}[i] {
+			t.Errorf("request %d: RateLimit-Remaining = %q", i+1, got)
+		}
+		if got := rec.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+			t.Errorf("RateLimit-Policy = %q, want 2;w=60", got)
+		}
+	}
+
+	rec := get("/list", "10.0.0.1", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("over the limit: status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" || rec.Header().Get("RateLimit-Limit") != "2" {
+		t.Errorf("over the limit: headers %v, want Retry-After and RateLimit-Limit", rec.Header())
+	}
+	var body response.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Success || body.Error == nil || body.Error.Code != "RATE_LIMITED" {
+		t.Errorf("over the limit: body %s, want the error envelope with RATE_LIMITED", rec.Body)
+	}
+
+	// Other clients and API keys have their own windows, even from the same IP
+	if rec := get("/list", "10.0.0.2", ""); rec.Code != http.StatusOK {
+		t.Errorf("other IP: status = %d, want 200", rec.Code)
+	}
+	if rec := get("/list", "10.0.0.1", "partner"); rec.Code != http.StatusOK {
+		t.Errorf("API key from a limited IP: status = %d, want 200", rec.Code)
+	}
+
+	// Routes without a limit are not limited when there is no default
+	rec = get("/latest", "10.0.0.1", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("unlimited route: status %d, headers %v", rec.Code, rec.Header())
+	}
+}
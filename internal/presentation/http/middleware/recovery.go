@@ -3,10 +3,11 @@ package middleware
 import (
 	"fmt"
 	"log/slog"
-	"net/http"
 	"runtime/debug"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
 )
 
 // Recovery returns a middleware that recovers from panics.
@@ -27,10 +28,7 @@ func Recovery(logger *slog.Logger) gin.HandlerFunc {
 				)
 
 				// Return error response
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal server error",
-					"code":  "INTERNAL_ERROR",
-				})
+				response.InternalServerError(c, "internal server error")
 
 				// Abort the request
 				c.Abort()
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// untracedPaths are polled by probes and scrapers; tracing them only adds noise.
+var untracedPaths = map[string]bool{
+	"/metrics": true,
+	"/livez":   true,
+	"/readyz":  true,
+	"/health":  true,
+	"/ping":    true,
+}
+
+// Tracing returns a middleware that starts a server span for each request,
+// continuing the caller's trace when the request carries a traceparent header.
+func Tracing(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return !untracedPaths[r.URL.Path]
+	}))
+}
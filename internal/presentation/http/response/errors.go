@@ -0,0 +1,138 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+	"github.com/tyokyo320/rateflow/internal/domain/provider"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+)
+
+// Error codes of API responses. They are part of the API: clients may branch
+// on them, so existing codes must not change. Missing rates have answered
+// NOT_FOUND since before the codes were listed here; only errors introduced
+// later have codes of their own.
+const (
+	CodeBadRequest      = "BAD_REQUEST"
+	CodeUnauthorized    = "UNAUTHORIZED"
+	CodeForbidden       = "FORBIDDEN"
+	CodeNotFound        = "NOT_FOUND"
+	CodeValidation      = "VALIDATION_ERROR"
+	CodeRateLimited     = "RATE_LIMITED"
+	CodeQuotaExceeded   = "QUOTA_EXCEEDED"
+	CodeInternal        = "INTERNAL_ERROR"
+	CodeRequestCanceled = "REQUEST_CANCELED"
+	CodeTimeout         = "TIMEOUT"
+
+	CodeInvalidRate     = "INVALID_RATE"
+	CodeDuplicateRate   = "DUPLICATE_RATE"
+	CodeStaleRate       = "STALE_RATE"
+	CodeInvalidEvent    = "INVALID_EVENT_ID"
+	CodeInvalidInterval = "INVALID_INTERVAL"
+	CodeShortHistory    = "INSUFFICIENT_HISTORY"
+
+	CodeWebhookNotFound  = "WEBHOOK_NOT_FOUND"
+	CodeDeliveryNotFound = "WEBHOOK_DELIVERY_NOT_FOUND"
+	CodeInvalidWebhook   = "INVALID_WEBHOOK"
+
+	CodeSuspectRateNotFound = "SUSPECT_RATE_NOT_FOUND"
+	CodeAlreadyReviewed     = "SUSPECT_RATE_ALREADY_REVIEWED"
+
+	CodeProviderUnavailable = "PROVIDER_UNAVAILABLE"
+	CodeProviderNoData      = "PROVIDER_NO_DATA"
+	CodeProviderInvalid     = "PROVIDER_INVALID_RESPONSE"
+	CodeProviderUnsupported = "PROVIDER_UNSUPPORTED"
+	CodeProviderError       = "PROVIDER_ERROR"
+)
+
+// StatusClientClosedRequest is the non-standard status, introduced by nginx,
+// of requests the client gave up on before the response was written.
+const StatusClientClosedRequest = 499
+
+// Mapping is the HTTP representation of an error.
+type Mapping struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+// MapError maps an error to its HTTP status, error code and client message.
+//
+// Missing data maps to 404 and invalid or conflicting data to 4xx, while
+// failures of the service or its dependencies map to 5xx, so clients can tell
+// "no data" apart from "service broken". Unknown errors map to 500 with a
+// generic message; their text may expose internals and is only logged.
+func MapError(err error) Mapping {
+	var (
+		notFound  rate.ErrRateNotFound
+		invalid   rate.ErrInvalidRate
+		duplicate rate.ErrDuplicateRate
+		stale     rate.ErrStaleRate
+		eventID   rate.ErrInvalidEventID
+		interval  rate.ErrInvalidInterval
+		history   rate.ErrInsufficientHistory
+		perr      *provider.ProviderError
+
+		webhookNotFound  webhook.ErrSubscriptionNotFound
+		deliveryNotFound webhook.ErrDeliveryNotFound
+		invalidWebhook   webhook.ErrInvalidSubscription
+
+		suspectNotFound anomaly.ErrSuspectNotFound
+		reviewed        anomaly.ErrAlreadyReviewed
+	)
+
+	switch {
+	case errors.As(err, &notFound):
+		return Mapping{http.StatusNotFound, CodeNotFound, notFound.Error()}
+	case errors.As(err, &invalid):
+		return Mapping{http.StatusUnprocessableEntity, CodeInvalidRate, invalid.Error()}
+	case errors.As(err, &duplicate):
+		return Mapping{http.StatusConflict, CodeDuplicateRate, duplicate.Error()}
+	case errors.As(err, &stale):
+		return Mapping{http.StatusServiceUnavailable, CodeStaleRate, stale.Error()}
+	case errors.As(err, &eventID):
+		return Mapping{http.StatusBadRequest, CodeInvalidEvent, eventID.Error()}
+	case errors.As(err, &interval):
+		return Mapping{http.StatusBadRequest, CodeInvalidInterval, interval.Error()}
+	case errors.As(err, &history):
+		return Mapping{http.StatusUnprocessableEntity, CodeShortHistory, history.Error()}
+	case errors.As(err, &perr):
+		return mapProviderError(perr)
+	case errors.As(err, &webhookNotFound):
+		return Mapping{http.StatusNotFound, CodeWebhookNotFound, webhookNotFound.Error()}
+	case errors.As(err, &deliveryNotFound):
+		return Mapping{http.StatusNotFound, CodeDeliveryNotFound, deliveryNotFound.Error()}
+	case errors.As(err, &invalidWebhook):
+		return Mapping{http.StatusUnprocessableEntity, CodeInvalidWebhook, invalidWebhook.Error()}
+	case errors.As(err, &suspectNotFound):
+		return Mapping{http.StatusNotFound, CodeSuspectRateNotFound, suspectNotFound.Error()}
+	case errors.As(err, &reviewed):
+		return Mapping{http.StatusConflict, CodeAlreadyReviewed, reviewed.Error()}
+	case errors.Is(err, context.Canceled):
+		return Mapping{StatusClientClosedRequest, CodeRequestCanceled, "request canceled"}
+	case errors.Is(err, context.DeadlineExceeded):
+		return Mapping{http.StatusGatewayTimeout, CodeTimeout, "request timed out"}
+	default:
+		return Mapping{http.StatusInternalServerError, CodeInternal, "internal server error"}
+	}
+}
+
+// mapProviderError maps a provider error by its kind. Only "no data" is the
+// client's concern; every other kind is a failure of the upstream provider.
+func mapProviderError(err *provider.ProviderError) Mapping {
+	switch err.Kind {
+	case provider.ErrorKindNoData:
+		return Mapping{http.StatusNotFound, CodeProviderNoData, err.ProviderName + " has no data for the request"}
+	case provider.ErrorKindUnavailable:
+		return Mapping{http.StatusBadGateway, CodeProviderUnavailable, err.ProviderName + " is unavailable"}
+	case provider.ErrorKindInvalid:
+		return Mapping{http.StatusBadGateway, CodeProviderInvalid, err.ProviderName + " responded with unusable data"}
+	case provider.ErrorKindUnsupported:
+		return Mapping{http.StatusNotImplemented, CodeProviderUnsupported, err.ProviderName + " does not support the request"}
+	default:
+		return Mapping{http.StatusBadGateway, CodeProviderError, err.ProviderName + " failed"}
+	}
+}
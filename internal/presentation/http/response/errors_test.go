@@ -0,0 +1,125 @@
+package response_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/domain/anomaly"
+	"github.com/tyokyo320/rateflow/internal/domain/provider"
+	"github.com/tyokyo320/rateflow/internal/domain/rate"
+	"github.com/tyokyo320/rateflow/internal/domain/webhook"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
+)
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"rate not found", rate.ErrRateNotFound{}, http.StatusNotFound, response.CodeNotFound},
+		{"wrapped rate not found", fmt.Errorf("query: %w", rate.ErrRateNotFound{ID: "42"}), http.StatusNotFound, response.CodeNotFound},
+		{"invalid rate", rate.ErrInvalidRate{}, http.StatusUnprocessableEntity, response.CodeInvalidRate},
+		{"duplicate rate", rate.ErrDuplicateRate{Pair: "CNY/JPY", Date: "2024-01-15"}, http.StatusConflict, response.CodeDuplicateRate},
+		{"stale rate", rate.ErrStaleRate{Age: "72h"}, http.StatusServiceUnavailable, response.CodeStaleRate},
+		{"invalid interval", rate.ErrInvalidInterval{Interval: "fortnight"}, http.StatusBadRequest, response.CodeInvalidInterval},
+		{"insufficient history", rate.ErrInsufficientHistory{Pair: "CNY/JPY", Have: 2, Need: 4}, http.StatusUnprocessableEntity, response.CodeShortHistory},
+		{"provider no data", provider.NewProviderErrorKind("unionpay", provider.ErrorKindNoData, "holiday", nil), http.StatusNotFound, response.CodeProviderNoData},
+		{"provider unavailable", provider.NewProviderErrorKind("unionpay", provider.ErrorKindUnavailable, "timeout", nil), http.StatusBadGateway, response.CodeProviderUnavailable},
+		{"provider invalid", provider.NewProviderErrorKind("unionpay", provider.ErrorKindInvalid, "bad json", nil), http.StatusBadGateway, response.CodeProviderInvalid},
+		{"provider unsupported", provider.NewProviderErrorKind("unionpay", provider.ErrorKindUnsupported, "no history", nil), http.StatusNotImplemented, response.CodeProviderUnsupported},
+		{"provider unknown", provider.NewProviderError("unionpay", "failed", nil), http.StatusBadGateway, response.CodeProviderError},
+		{"webhook not found", webhook.ErrSubscriptionNotFound{ID: "42"}, http.StatusNotFound, response.CodeWebhookNotFound},
+		{"webhook delivery not found", webhook.ErrDeliveryNotFound{ID: "42"}, http.StatusNotFound, response.CodeDeliveryNotFound},
+		{"suspect rate not found", anomaly.ErrSuspectNotFound{ID: "42"}, http.StatusNotFound, response.CodeSuspectRateNotFound},
+		{"suspect rate already reviewed", anomaly.ErrAlreadyReviewed{ID: "42", Status: anomaly.StatusRejected}, http.StatusConflict, response.CodeAlreadyReviewed},
+		{"canceled", fmt.Errorf("query: %w", context.Canceled), response.StatusClientClosedRequest, response.CodeRequestCanceled},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, response.CodeTimeout},
+		{"database outage", errors.New("dial tcp 10.0.0.5:5432: connection refused"), http.StatusInternalServerError, response.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := response.MapError(tt.err)
+			if got.Status != tt.status || got.Code != tt.code {
+				t.Errorf("MapError() = %d %s, want %d %s", got.Status, got.Code, tt.status, tt.code)
+			}
+		})
+	}
+
+	// Internals of unexpected errors are not exposed to clients
+	if got := response.MapError(errors.New("password authentication failed")); got.Message != "internal server error" {
+		t.Errorf("unknown error message = %q, want a generic message", got.Message)
+	}
+}
+
+func TestFromError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		problemMode bool
+		accept      string
+		wantProblem bool
+	}{
+		{"envelope", false, "application/json", false},
+		{"accept problem", false, "application/problem+json, application/json", true},
+		{"problem mode", true, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if tt.problemMode {
+				router.Use(response.ProblemDetails())
+			}
+			router.GET("/rates", func(c *gin.Context) {
+				response.FromError(c, fmt.Errorf("find: %w", rate.ErrRateNotFound{}))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/rates", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404", rec.Code)
+			}
+
+			if !tt.wantProblem {
+				var body response.Response
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Success || body.Error == nil || body.Error.Code != response.CodeNotFound {
+					t.Errorf("body = %s, want the envelope with %s", rec.Body, response.CodeNotFound)
+				}
+				return
+			}
+
+			if ct := rec.Header().Get("Content-Type"); ct != response.ProblemContentType {
+				t.Errorf("Content-Type = %q, want %q", ct, response.ProblemContentType)
+			}
+			var problem response.Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("unmarshal problem: %v", err)
+			}
+			want := response.Problem{
+				Type:     "about:blank",
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "rate not found",
+				Instance: "/rates",
+				Code:     response.CodeNotFound,
+			}
+			if problem != want {
+				t.Errorf("problem = %+v, want %+v", problem, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package response provides the unified JSON envelope of API responses.
+package response
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// problemDetailsKey marks requests whose errors are written as problem details.
+const problemDetailsKey = "response.problem_details"
+
+// Response represents a unified API response format.
+type Response struct {
+	Success bool   `json:"success"`
+	Data    any    `json:"data,omitempty"`
+	Error   *Error `json:"error,omitempty"`
+	Meta    *Meta  `json:"meta,omitempty"`
+}
+
+// Error represents an error in API responses.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Meta represents metadata in API responses (for pagination, etc.).
+type Meta struct {
+	Page       int    `json:"page"`
+	PageSize   int    `json:"pageSize"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"totalPages"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Problem represents an error as RFC 7807 problem details. The error code and
+// details of the envelope are carried as extension members.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// ProblemDetails returns a middleware that writes every error response of the
+// request as problem details instead of the envelope. Without it, clients opt in
+// per request with "Accept: application/problem+json".
+func ProblemDetails() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(problemDetailsKey, true)
+		c.Next()
+	}
+}
+
+// wantsProblem reports whether the error response of the request is written as
+// problem details.
+func wantsProblem(c *gin.Context) bool {
+	if c.GetBool(problemDetailsKey) {
+		return true
+	}
+	return strings.Contains(c.GetHeader("Accept"), ProblemContentType)
+}
+
+// SuccessResponse returns a success response.
+func SuccessResponse(c *gin.Context, data any) {
+	c.JSON(http.StatusOK, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// SuccessResponseWithMeta returns a success response with metadata.
+func SuccessResponseWithMeta(c *gin.Context, data any, meta *Meta) {
+	c.JSON(http.StatusOK, Response{
+		Success: true,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
+// CreatedResponse returns a created response.
+func CreatedResponse(c *gin.Context, data any) {
+	c.JSON(http.StatusCreated, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// AcceptedResponse returns an accepted response, for work that is done later.
+func AcceptedResponse(c *gin.Context, data any) {
+	c.JSON(http.StatusAccepted, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// ErrorResponse returns an error response.
+func ErrorResponse(c *gin.Context, statusCode int, code, message string) {
+	ErrorResponseWithDetails(c, statusCode, code, message, nil)
+}
+
+// ErrorResponseWithDetails returns an error response with details.
+func ErrorResponseWithDetails(c *gin.Context, statusCode int, code, message string, details any) {
+	if wantsProblem(c) {
+		// The content type is set first, so the JSON renderer keeps it
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(statusCode, Problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(statusCode),
+			Status:    statusCode,
+			Detail:    message,
+			Instance:  c.Request.URL.Path,
+			Code:      code,
+			Details:   details,
+			RequestID: logger.RequestIDFromContext(c.Request.Context()),
+		})
+		return
+	}
+
+	c.JSON(statusCode, Response{
+		Success: false,
+		Error: &Error{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	})
+}
+
+// FromError returns the error response err maps to, see MapError.
+func FromError(c *gin.Context, err error) {
+	m := MapError(err)
+	ErrorResponse(c, m.Status, m.Code, m.Message)
+}
+
+// BadRequestError returns a 400 bad request error.
+func BadRequestError(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// UnauthorizedError returns a 401 unauthorized error.
+func UnauthorizedError(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// ForbiddenError returns a 403 forbidden error.
+func ForbiddenError(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusForbidden, CodeForbidden, message)
+}
+
+// NotFoundError returns a 404 not found error.
+func NotFoundError(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusNotFound, CodeNotFound, message)
+}
+
+// TooManyRequestsError returns a 429 too many requests error.
+func TooManyRequestsError(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusTooManyRequests, CodeRateLimited, message)
+}
+
+// InternalServerError returns a 500 internal server error.
+func InternalServerError(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusInternalServerError, CodeInternal, message)
+}
+
+// ValidationError returns a 422 validation error.
+func ValidationError(c *gin.Context, details any) {
+	ErrorResponseWithDetails(c, http.StatusUnprocessableEntity, CodeValidation, "Validation failed", details)
+}
@@ -4,18 +4,61 @@ import (
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/domain/apikey"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/persistence/redis"
 	"github.com/tyokyo320/rateflow/internal/presentation/http/handler"
 	"github.com/tyokyo320/rateflow/internal/presentation/http/middleware"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/response"
 )
 
 // RouterConfig holds router configuration.
 type RouterConfig struct {
-	RateHandler *handler.RateHandler
-	Logger      *slog.Logger
-	Environment string // dev, staging, prod
+	RateHandler      *handler.RateHandler
+	HealthHandler    *handler.HealthHandler
+	AnalyticsHandler *handler.AnalyticsHandler   // optional; serves the figures computed from rates
+	StreamHandler    *handler.StreamHandler      // optional; serves the rate stream
+	WebhookHandler   *handler.WebhookHandler     // optional; serves the webhook endpoints, given an Authenticator
+	SuspectHandler   *handler.SuspectRateHandler // optional; serves the review of quarantined rates, given an Authenticator
+	Logger           *slog.Logger
+	ServiceName      string // reported on trace spans
+	Environment      string // dev, staging, prod
+
+	// ProblemDetails writes all error responses as RFC 7807 problem details.
+	// Otherwise only requests accepting application/problem+json get them.
+	ProblemDetails bool
+
+	// Authenticator checks the API keys of requests to the API endpoints.
+	// Without it the rate endpoints are public and the webhook and admin
+	// endpoints are not served at all.
+	Authenticator *command.AuthenticateHandler
+
+	// RateLimiter limits requests to the rate endpoints to RateLimits.
+	// Without it requests are not limited.
+	RateLimiter redis.Limiter
+	RateLimits  middleware.RateLimits
+}
+
+// protect returns the middleware that guards the API routes: the API key check
+// for scope, the rate limit and the count towards the key's daily quota, each
+// when configured. The key check only reads the key, and requests rejected by
+// the rate limit are neither written nor counted towards the quota.
+func (cfg RouterConfig) protect(scope apikey.Scope) []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	if cfg.Authenticator != nil {
+		handlers = append(handlers, RequireAPIKey(cfg.Authenticator, scope, cfg.Logger))
+	}
+	if cfg.RateLimiter != nil {
+		handlers = append(handlers, middleware.RateLimit(cfg.RateLimiter, cfg.RateLimits, cfg.Logger))
+	}
+	if cfg.Authenticator != nil {
+		handlers = append(handlers, RecordAPIKeyUsage(cfg.Authenticator, cfg.Logger))
+	}
+	return handlers
 }
 
 // SetupRouter creates and configures the HTTP router.
@@ -34,15 +77,28 @@ func SetupRouter(cfg RouterConfig) *gin.Engine {
 
 	// Apply global middleware
 	router.Use(middleware.Recovery(cfg.Logger))
+	router.Use(middleware.Tracing(cfg.ServiceName))
 	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger(cfg.Logger))
+	router.Use(middleware.Metrics())
 	router.Use(middleware.CORS())
+	if cfg.ProblemDetails {
+		router.Use(response.ProblemDetails())
+	}
+	router.NoRoute(func(c *gin.Context) {
+		response.NotFoundError(c, "route not found")
+	})
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Health check endpoint
-	router.GET("/health", cfg.RateHandler.Health)
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// Health check endpoints: liveness for restarts, readiness for traffic
+	router.GET("/livez", cfg.HealthHandler.Livez)
+	router.GET("/readyz", cfg.HealthHandler.Readyz)
+	router.GET("/health", cfg.HealthHandler.Readyz)
 	router.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "pong"})
 	})
@@ -51,18 +107,57 @@ func SetupRouter(cfg RouterConfig) *gin.Engine {
 	v1 := router.Group("/api/v1")
 	{
 		// Rate endpoints
-		rates := v1.Group("/rates")
+		rates := v1.Group("/rates", cfg.protect(apikey.ScopeRatesRead)...)
 		{
 			rates.GET("/latest", cfg.RateHandler.GetLatest)
 			rates.GET("", cfg.RateHandler.GetByDate)
 			rates.GET("/list", cfg.RateHandler.List)
+			rates.GET("/:id/history", cfg.RateHandler.GetHistory)
+			if cfg.AnalyticsHandler != nil {
+				rates.GET("/stats", cfg.AnalyticsHandler.Stats)
+				rates.GET("/aggregate", cfg.AnalyticsHandler.Aggregate)
+				rates.GET("/timeseries", cfg.AnalyticsHandler.TimeSeries)
+				rates.GET("/indicators", cfg.AnalyticsHandler.Indicators)
+				rates.GET("/compare", cfg.AnalyticsHandler.Compare)
+				rates.GET("/forecast", cfg.AnalyticsHandler.Forecast)
+			}
+			if cfg.StreamHandler != nil {
+				rates.GET("/stream", cfg.StreamHandler.Stream)
+			}
+		}
+
+		// Webhook endpoints, which make the server send requests, are never
+		// open to anonymous callers
+		if cfg.WebhookHandler != nil && cfg.Authenticator != nil {
+			webhooks := v1.Group("/webhooks", cfg.protect(apikey.ScopeWebhooksManage)...)
+			{
+				webhooks.POST("", cfg.WebhookHandler.Create)
+				webhooks.GET("", cfg.WebhookHandler.List)
+				webhooks.GET("/:id", cfg.WebhookHandler.Get)
+				webhooks.PATCH("/:id", cfg.WebhookHandler.Update)
+				webhooks.DELETE("/:id", cfg.WebhookHandler.Delete)
+				webhooks.GET("/:id/deliveries", cfg.WebhookHandler.Deliveries)
+				webhooks.POST("/:id/deliveries/:deliveryId/redeliver", cfg.WebhookHandler.Redeliver)
+			}
+		}
+
+		// Review of quarantined rates, which publishes or discards them, is
+		// never open to anonymous callers
+		if cfg.SuspectHandler != nil && cfg.Authenticator != nil {
+			suspects := v1.Group("/admin/suspect-rates", cfg.protect(apikey.ScopeAdmin)...)
+			{
+				suspects.GET("", cfg.SuspectHandler.List)
+				suspects.GET("/:id", cfg.SuspectHandler.Get)
+				suspects.POST("/:id/approve", cfg.SuspectHandler.Approve)
+				suspects.POST("/:id/reject", cfg.SuspectHandler.Reject)
+			}
 		}
 	}
 
 	// Legacy API routes (for backward compatibility)
 	api := router.Group("/api")
 	{
-		rates := api.Group("/rates")
+		rates := api.Group("/rates", cfg.protect(apikey.ScopeRatesRead)...)
 		{
 			rates.GET("/latest", cfg.RateHandler.GetLatest)
 			rates.GET("", cfg.RateHandler.GetByDate)
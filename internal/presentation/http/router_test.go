@@ -0,0 +1,48 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tyokyo320/rateflow/internal/application/command"
+	"github.com/tyokyo320/rateflow/internal/infrastructure/logger"
+	httpHandler "github.com/tyokyo320/rateflow/internal/presentation/http"
+	"github.com/tyokyo320/rateflow/internal/presentation/http/handler"
+)
+
+func TestSetupRouter_ManagementRoutesRequireAuthenticator(t *testing.T) {
+	log := logger.NewNoop()
+
+	tests := []struct {
+		name          string
+		authenticator *command.AuthenticateHandler
+		want          int
+	}{
+		{"without authenticator", nil, http.StatusNotFound},
+		{"with authenticator", command.NewAuthenticateHandler(nil, log), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := httpHandler.SetupRouter(httpHandler.RouterConfig{
+				RateHandler:    &handler.RateHandler{},
+				HealthHandler:  &handler.HealthHandler{},
+				WebhookHandler: handler.NewWebhookHandler(nil, log),
+				SuspectHandler: handler.NewSuspectRateHandler(nil, nil, nil, nil, nil, log),
+				Logger:         log,
+				Environment:    "test",
+				Authenticator:  tt.authenticator,
+			})
+
+			for _, path := range []string{"/api/v1/webhooks", "/api/v1/admin/suspect-rates/1/approve"} {
+				req := httptest.NewRequest(http.MethodPost, path, nil)
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				if rec.Code != tt.want {
+					t.Errorf("POST %s status = %d, want %d", path, rec.Code, tt.want)
+				}
+			}
+		})
+	}
+}
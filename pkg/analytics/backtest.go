@@ -0,0 +1,51 @@
+package analytics
+
+import "math"
+
+// Accuracy summarises the errors of a model's forecasts against the values
+// that followed.
+type Accuracy struct {
+	Folds     int     // forecast origins evaluated
+	Forecasts int     // forecasts compared with actual values
+	MAE       float64 // mean absolute error
+	MAPE      float64 // mean absolute percentage error, in percent
+}
+
+// Backtest evaluates fit by rolling-origin evaluation over the last folds
+// windows of horizon values: each window is forecast from the values before
+// it and compared with its actual values. Windows whose history is too short
+// for the model are skipped; if all are, the error of the longest is returned.
+func Backtest(values []float64, fit Fitter, horizon, folds int) (Accuracy, error) {
+	var (
+		acc                Accuracy
+		absErrors, percent float64
+		lastErr            error
+	)
+	for fold := min(folds, len(values)/horizon); fold >= 1; fold-- {
+		origin := len(values) - fold*horizon
+		model, err := fit(values[:origin])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		for _, f := range model.Forecast(horizon, 0) {
+			actual := values[origin+f.Step-1]
+			absErrors += math.Abs(actual - f.Value)
+			percent += math.Abs((actual - f.Value) / actual)
+			acc.Forecasts++
+		}
+		acc.Folds++
+	}
+
+	if acc.Folds == 0 {
+		if lastErr == nil {
+			lastErr = ErrInsufficientData{Have: len(values), Need: horizon + 1}
+		}
+		return Accuracy{}, lastErr
+	}
+
+	acc.MAE = absErrors / float64(acc.Forecasts)
+	acc.MAPE = percent / float64(acc.Forecasts) * 100
+	return acc, nil
+}
@@ -0,0 +1,35 @@
+package analytics
+
+import "math"
+
+// Correlation returns the Pearson correlation coefficient of the paired
+// samples x and y, which must have the same length. It reports false when
+// there are fewer than two pairs or either sample is constant.
+func Correlation(x, y []float64) (float64, bool) {
+	n := len(x)
+	if n < 2 || n != len(y) {
+		return 0, false
+	}
+
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var covariance, varianceX, varianceY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+
+	// Rounding can push perfectly correlated samples just past ±1
+	return max(-1, min(1, covariance/math.Sqrt(varianceX*varianceY))), true
+}
@@ -0,0 +1,37 @@
+package analytics_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tyokyo320/rateflow/pkg/analytics"
+)
+
+func TestCorrelation(t *testing.T) {
+	tests := []struct {
+		name   string
+		x, y   []float64
+		want   float64
+		wantOk bool
+	}{
+		{"identical", []float64{1, 2, 3}, []float64{1, 2, 3}, 1, true},
+		{"scaled and shifted", []float64{1, 2, 3, 4}, []float64{12, 14, 16, 18}, 1, true},
+		{"opposite", []float64{1, 2, 3}, []float64{3, 2, 1}, -1, true},
+		// Anscombe's first quartet has a correlation of 0.81642
+		{"anscombe", []float64{10, 8, 13, 9, 11, 14, 6, 4, 12, 7, 5},
+			[]float64{8.04, 6.95, 7.58, 8.81, 8.33, 9.96, 7.24, 4.26, 10.84, 4.82, 5.68}, 0.81642, true},
+		{"uncorrelated", []float64{1, 2, 3, 4}, []float64{1, -1, -1, 1}, 0, true},
+		{"constant", []float64{1, 2, 3}, []float64{5, 5, 5}, 0, false},
+		{"single pair", []float64{1}, []float64{2}, 0, false},
+		{"different lengths", []float64{1, 2, 3}, []float64{1, 2}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := analytics.Correlation(tt.x, tt.y)
+			if ok != tt.wantOk || math.Abs(got-tt.want) > 1e-5 {
+				t.Errorf("Correlation() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
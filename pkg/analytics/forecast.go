@@ -0,0 +1,173 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+)
+
+// Forecast is a point forecast with its prediction interval.
+type Forecast struct {
+	Step  int // steps after the last observation, from 1
+	Value float64
+	Lower float64
+	Upper float64
+}
+
+// Model is a forecasting model fitted to a series.
+type Model interface {
+	// Forecast returns forecasts of the next horizon steps with prediction
+	// intervals of the given coverage, e.g. 0.95.
+	Forecast(horizon int, level float64) []Forecast
+}
+
+// Fitter fits a model to a series, oldest value first.
+type Fitter func(values []float64) (Model, error)
+
+// ErrInsufficientData indicates a series too short to fit a model to.
+type ErrInsufficientData struct {
+	Have int
+	Need int
+}
+
+func (e ErrInsufficientData) Error() string {
+	return fmt.Sprintf("insufficient data: %d values, need at least %d", e.Have, e.Need)
+}
+
+// Minimum lengths of the series models are fitted to: their parameters plus
+// at least one residual to estimate the forecast variance from.
+const (
+	HoltMinValues  = 4
+	DriftMinValues = 3
+)
+
+// Holt is Holt's linear exponential smoothing: a level and a trend, each
+// updated by exponential smoothing of the observations.
+type Holt struct {
+	Alpha    float64 // smoothing of the level
+	Beta     float64 // smoothing of the trend by the change in level
+	Level    float64 // level at the last observation
+	Trend    float64 // trend per step at the last observation
+	Variance float64 // variance of the one-step-ahead errors
+}
+
+// FitHolt fits Holt's method to values, choosing the smoothing parameters
+// 0 < alpha, beta <= 1 that minimise the squared one-step-ahead errors.
+// The level starts at the first value and the trend at the first change.
+func FitHolt(values []float64) (*Holt, error) {
+	if len(values) < HoltMinValues {
+		return nil, ErrInsufficientData{Have: len(values), Need: HoltMinValues}
+	}
+
+	// A coarse search over the parameters, then a finer one around the best
+	best := &Holt{Variance: math.Inf(1)}
+	search := func(alphaFrom, alphaTo, betaFrom, betaTo, step float64) {
+		for i := 0.0; alphaFrom+i*step <= alphaTo+step/2; i++ {
+			alpha := min(alphaFrom+i*step, 1)
+			for j := 0.0; betaFrom+j*step <= betaTo+step/2; j++ {
+				beta := min(betaFrom+j*step, 1)
+				if m := runHolt(values, alpha, beta); m.Variance < best.Variance {
+					best = m
+				}
+			}
+		}
+	}
+	const coarse, fine = 0.05, 0.005
+	search(coarse, 1, coarse, 1, coarse)
+	a, b := best.Alpha, best.Beta
+	search(max(a-coarse, fine), min(a+coarse, 1), max(b-coarse, fine), min(b+coarse, 1), fine)
+
+	return best, nil
+}
+
+// runHolt smooths values with the given parameters.
+func runHolt(values []float64, alpha, beta float64) *Holt {
+	level, trend := values[0], values[1]-values[0]
+	var squares float64
+	for _, v := range values[1:] {
+		e := v - (level + trend)
+		squares += e * e
+		previous := level
+		level = alpha*v + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+	}
+
+	// Degrees of freedom: the one-step errors less the two parameters
+	dof := max(len(values)-3, 1)
+	return &Holt{Alpha: alpha, Beta: beta, Level: level, Trend: trend, Variance: squares / float64(dof)}
+}
+
+// Forecast returns the linear extrapolation of the level and trend. The
+// intervals widen with the uncertainty the smoothing carries forward, as for
+// the additive-error model ETS(A,A,N), whose trend smoothing by the one-step
+// error is alpha·beta.
+func (m *Holt) Forecast(horizon int, level float64) []Forecast {
+	z := zScore(level)
+	a, b := m.Alpha, m.Alpha*m.Beta
+	forecasts := make([]Forecast, horizon)
+	for i := range forecasts {
+		h := float64(i + 1)
+		variance := m.Variance * (1 + (h-1)*(a*a+a*b*h+b*b*h*(2*h-1)/6))
+		forecasts[i] = newForecast(i+1, m.Level+h*m.Trend, z*math.Sqrt(variance))
+	}
+	return forecasts
+}
+
+// Drift is a random walk with drift: each step adds the average change of the
+// series and noise.
+type Drift struct {
+	Last     float64 // last observation
+	Drift    float64 // average change per step
+	Variance float64 // variance of the changes around the drift
+	Changes  int     // changes the drift was estimated from
+}
+
+// FitDrift fits a random walk with drift to values.
+func FitDrift(values []float64) (*Drift, error) {
+	if len(values) < DriftMinValues {
+		return nil, ErrInsufficientData{Have: len(values), Need: DriftMinValues}
+	}
+
+	n := len(values) - 1
+	drift := (values[n] - values[0]) / float64(n)
+	var squares float64
+	for i := 1; i <= n; i++ {
+		e := values[i] - values[i-1] - drift
+		squares += e * e
+	}
+
+	return &Drift{Last: values[n], Drift: drift, Variance: squares / float64(n-1), Changes: n}, nil
+}
+
+// Forecast returns the last observation plus the drift per step. The
+// intervals account for the noise of each step and the error of the
+// estimated drift.
+func (m *Drift) Forecast(horizon int, level float64) []Forecast {
+	z := zScore(level)
+	forecasts := make([]Forecast, horizon)
+	for i := range forecasts {
+		h := float64(i + 1)
+		variance := m.Variance * h * (1 + h/float64(m.Changes))
+		forecasts[i] = newForecast(i+1, m.Last+h*m.Drift, z*math.Sqrt(variance))
+	}
+	return forecasts
+}
+
+// FitHoltModel is FitHolt as a Fitter.
+func FitHoltModel(values []float64) (Model, error) {
+	return FitHolt(values)
+}
+
+// FitDriftModel is FitDrift as a Fitter.
+func FitDriftModel(values []float64) (Model, error) {
+	return FitDrift(values)
+}
+
+func newForecast(step int, value, width float64) Forecast {
+	return Forecast{Step: step, Value: value, Lower: value - width, Upper: value + width}
+}
+
+// zScore returns the standard normal quantile bounding a central interval
+// of the given coverage.
+func zScore(level float64) float64 {
+	return math.Sqrt2 * math.Erfinv(level)
+}
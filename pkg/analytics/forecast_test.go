@@ -0,0 +1,164 @@
+package analytics_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/tyokyo320/rateflow/pkg/analytics"
+)
+
+// z95 is the standard normal quantile of a 95% central interval.
+const z95 = 1.959964
+
+func TestFitDrift(t *testing.T) {
+	// Changes of 2, -1 and 2 give a drift of 1 and a variance of (1+4+1)/2
+	m, err := analytics.FitDrift([]float64{10, 12, 11, 13})
+	if err != nil {
+		t.Fatalf("FitDrift() error = %v", err)
+	}
+	if m.Drift != 1 || m.Variance != 3 || m.Last != 13 {
+		t.Fatalf("FitDrift() = %+v, want drift 1, variance 3 and last 13", m)
+	}
+
+	got := m.Forecast(2, 0.95)
+	want := []analytics.Forecast{
+		{Step: 1, Value: 14, Lower: 14 - z95*2, Upper: 14 + z95*2},                         // 3·1·(1+1/3)
+		{Step: 2, Value: 15, Lower: 15 - z95*math.Sqrt(10), Upper: 15 + z95*math.Sqrt(10)}, // 3·2·(1+2/3)
+	}
+	assertForecasts(t, got, want)
+
+	if _, err := analytics.FitDrift([]float64{1, 2}); !errors.As(err, new(analytics.ErrInsufficientData)) {
+		t.Errorf("FitDrift() of 2 values error = %v, want ErrInsufficientData", err)
+	}
+}
+
+func TestHolt_Forecast(t *testing.T) {
+	m := &analytics.Holt{Alpha: 0.5, Beta: 0.2, Level: 10, Trend: 1, Variance: 4}
+
+	// The error-form trend smoothing is 0.5·0.2 = 0.1, so at
+	// h=2: 4·(1 + 0.25 + 0.5·0.1·2 + 0.01·2·3/6) = 5.44
+	want := []analytics.Forecast{
+		{Step: 1, Value: 11, Lower: 11 - z95*2, Upper: 11 + z95*2},
+		{Step: 2, Value: 12, Lower: 12 - z95*math.Sqrt(5.44), Upper: 12 + z95*math.Sqrt(5.44)},
+	}
+	assertForecasts(t, m.Forecast(2, 0.95), want)
+}
+
+func TestFitHolt(t *testing.T) {
+	t.Run("linear series", func(t *testing.T) {
+		m, err := analytics.FitHolt([]float64{20, 20.5, 21, 21.5, 22, 22.5})
+		if err != nil {
+			t.Fatalf("FitHolt() error = %v", err)
+		}
+		assertForecasts(t, m.Forecast(2, 0.95), []analytics.Forecast{
+			{Step: 1, Value: 23, Lower: 23, Upper: 23},
+			{Step: 2, Value: 23.5, Lower: 23.5, Upper: 23.5},
+		})
+	})
+
+	t.Run("noisy series", func(t *testing.T) {
+		values := []float64{
+			20.41, 20.55, 20.48, 20.62, 20.51, 20.45, 20.58, 20.71, 20.66, 20.79,
+			20.74, 20.92, 20.85, 20.81, 20.97, 21.05, 20.99, 21.12, 21.08, 21.21,
+		}
+		m, err := analytics.FitHolt(values)
+		if err != nil {
+			t.Fatalf("FitHolt() error = %v", err)
+		}
+		if m.Alpha <= 0 || m.Alpha > 1 || m.Beta <= 0 || m.Beta > 1 {
+			t.Fatalf("FitHolt() parameters alpha %v, beta %v out of range", m.Alpha, m.Beta)
+		}
+
+		// The search comes within 1% of the best fit on a fine grid
+		best := math.Inf(1)
+		for a := 1; a <= 100; a++ {
+			for b := 1; b <= 100; b++ {
+				fit := holtVariance(values, float64(a)/100, float64(b)/100)
+				best = min(best, fit)
+			}
+		}
+		if m.Variance > best*1.01 {
+			t.Errorf("FitHolt() variance = %v, want at most %v", m.Variance, best*1.01)
+		}
+		if m.Trend <= 0 {
+			t.Errorf("FitHolt() trend = %v, want the upward trend", m.Trend)
+		}
+	})
+
+	if _, err := analytics.FitHolt([]float64{1, 2, 3}); !errors.As(err, new(analytics.ErrInsufficientData)) {
+		t.Errorf("FitHolt() of 3 values error = %v, want ErrInsufficientData", err)
+	}
+}
+
+// holtVariance returns the variance of Holt's one-step errors over values.
+func holtVariance(values []float64, alpha, beta float64) float64 {
+	level, trend := values[0], values[1]-values[0]
+	var squares float64
+	for _, v := range values[1:] {
+		e := v - level - trend
+		squares += e * e
+		previous := level
+		level = alpha*v + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+	}
+	return squares / float64(len(values)-3)
+}
+
+func TestBacktest(t *testing.T) {
+	linear := make([]float64, 20)
+	for i := range linear {
+		linear[i] = float64(i + 1)
+	}
+
+	tests := []struct {
+		name          string
+		values        []float64
+		fit           analytics.Fitter
+		horizon       int
+		folds         int
+		wantFolds     int
+		wantForecasts int
+		wantMAE       float64
+		wantMAPE      float64
+	}{
+		{"exact drift", linear, analytics.FitDriftModel, 5, 3, 3, 15, 0, 0},
+		// The first window has no history and is skipped
+		{"folds capped by history", linear, analytics.FitDriftModel, 5, 10, 3, 15, 0, 0},
+		{"exact holt", linear, analytics.FitHoltModel, 4, 2, 2, 8, 0, 0},
+		// A flat series forecasts 10 where 12 follows
+		{"miss", []float64{10, 10, 10, 10, 12}, analytics.FitDriftModel, 1, 1, 1, 1, 2, 100.0 / 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analytics.Backtest(tt.values, tt.fit, tt.horizon, tt.folds)
+			if err != nil {
+				t.Fatalf("Backtest() error = %v", err)
+			}
+			if got.Folds != tt.wantFolds || got.Forecasts != tt.wantForecasts ||
+				math.Abs(got.MAE-tt.wantMAE) > 1e-9 || math.Abs(got.MAPE-tt.wantMAPE) > 1e-9 {
+				t.Errorf("Backtest() = %+v, want %d folds, %d forecasts, MAE %v and MAPE %v",
+					got, tt.wantFolds, tt.wantForecasts, tt.wantMAE, tt.wantMAPE)
+			}
+		})
+	}
+
+	if _, err := analytics.Backtest([]float64{1, 2, 3}, analytics.FitHoltModel, 2, 5); !errors.As(err, new(analytics.ErrInsufficientData)) {
+		t.Errorf("Backtest() of a short series error = %v, want ErrInsufficientData", err)
+	}
+}
+
+func assertForecasts(t *testing.T, got, want []analytics.Forecast) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d forecasts, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Step != w.Step || math.Abs(g.Value-w.Value) > 1e-5 ||
+			math.Abs(g.Lower-w.Lower) > 1e-5 || math.Abs(g.Upper-w.Upper) > 1e-5 {
+			t.Errorf("forecast %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
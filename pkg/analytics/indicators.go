@@ -0,0 +1,253 @@
+// Package analytics computes technical indicators, statistics and forecasts of time series.
+//
+// Indicators consume an iter.Seq of points, such as a rate stream mapped with
+// stream.Map, and yield their values lazily with O(window) memory. Each
+// indicator needs a warm-up of some points and then yields exactly one value
+// per input point, so its output lines up with the tail of its input.
+package analytics
+
+import (
+	"iter"
+	"math"
+	"time"
+)
+
+// daysPerYear annualises volatility by calendar days.
+const daysPerYear = 365.25
+
+// Point is a value of a time series.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Band is a value of Bollinger bands.
+type Band struct {
+	Time   time.Time
+	Middle float64 // simple moving average
+	Upper  float64
+	Lower  float64
+}
+
+// window is a fixed-size ring of the latest points.
+type window struct {
+	points []Point
+	next   int
+	full   bool
+}
+
+func newWindow(size int) *window {
+	return &window{points: make([]Point, size)}
+}
+
+// push adds p and returns the point it evicted, if the window was full.
+func (w *window) push(p Point) (Point, bool) {
+	evicted, full := w.points[w.next], w.full
+	w.points[w.next] = p
+	w.next = (w.next + 1) % len(w.points)
+	if w.next == 0 {
+		w.full = true
+	}
+	return evicted, full
+}
+
+// oldest returns the oldest point of a full window.
+func (w *window) oldest() Point {
+	return w.points[w.next]
+}
+
+// SMA yields the simple moving average over n points, from the n-th point on.
+func SMA(seq iter.Seq[Point], n int) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		if n < 1 {
+			return
+		}
+		w := newWindow(n)
+		var sum float64
+		for p := range seq {
+			if evicted, ok := w.push(p); ok {
+				sum -= evicted.Value
+			}
+			sum += p.Value
+			if w.full && !yield(Point{Time: p.Time, Value: sum / float64(n)}) {
+				return
+			}
+		}
+	}
+}
+
+// EMA yields the exponential moving average over n points, with a smoothing
+// factor of 2/(n+1). It is seeded with the simple average of the first n
+// points and yields from the n-th point on.
+func EMA(seq iter.Seq[Point], n int) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		if n < 1 {
+			return
+		}
+		alpha := 2 / float64(n+1)
+		var ema float64
+		count := 0
+		for p := range seq {
+			count++
+			switch {
+			case count < n:
+				ema += p.Value
+				continue
+			case count == n:
+				ema = (ema + p.Value) / float64(n)
+			default:
+				ema += alpha * (p.Value - ema)
+			}
+			if !yield(Point{Time: p.Time, Value: ema}) {
+				return
+			}
+		}
+	}
+}
+
+// Bollinger yields Bollinger bands k population standard deviations around
+// the simple moving average over n points, from the n-th point on.
+func Bollinger(seq iter.Seq[Point], n int, k float64) iter.Seq[Band] {
+	return func(yield func(Band) bool) {
+		if n < 1 {
+			return
+		}
+		w := newWindow(n)
+		for p := range seq {
+			w.push(p)
+			if !w.full {
+				continue
+			}
+
+			var sum, squares float64
+			for _, q := range w.points {
+				sum += q.Value
+			}
+			mean := sum / float64(n)
+			for _, q := range w.points {
+				squares += (q.Value - mean) * (q.Value - mean)
+			}
+			width := k * math.Sqrt(squares/float64(n))
+
+			if !yield(Band{Time: p.Time, Middle: mean, Upper: mean + width, Lower: mean - width}) {
+				return
+			}
+		}
+	}
+}
+
+// RSI yields Wilder's relative strength index over n changes, from the
+// (n+1)-th point on. Average gains and losses start as simple averages of
+// the first n changes and are then smoothed by (n-1)/n.
+func RSI(seq iter.Seq[Point], n int) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		if n < 1 {
+			return
+		}
+		var (
+			previous   Point
+			gain, loss float64
+			changes    int
+			started    bool
+			size       = float64(n)
+		)
+		for p := range seq {
+			if !started {
+				previous, started = p, true
+				continue
+			}
+
+			change := p.Value - previous.Value
+			previous = p
+			up, down := max(change, 0), max(-change, 0)
+
+			changes++
+			if changes <= n {
+				gain += up / size
+				loss += down / size
+				if changes < n {
+					continue
+				}
+			} else {
+				gain = (gain*(size-1) + up) / size
+				loss = (loss*(size-1) + down) / size
+			}
+
+			rsi := 100.0
+			if loss > 0 {
+				rsi = 100 - 100/(1+gain/loss)
+			}
+			if !yield(Point{Time: p.Time, Value: rsi}) {
+				return
+			}
+		}
+	}
+}
+
+// ROC yields the rate of change in percent against the value n points
+// earlier, from the (n+1)-th point on.
+func ROC(seq iter.Seq[Point], n int) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		if n < 1 {
+			return
+		}
+		w := newWindow(n + 1)
+		for p := range seq {
+			w.push(p)
+			if !w.full {
+				continue
+			}
+			base := w.oldest().Value
+			if !yield(Point{Time: p.Time, Value: (p.Value - base) / base * 100}) {
+				return
+			}
+		}
+	}
+}
+
+// RollingVolatility yields the sample standard deviation of the last n log
+// returns, annualised by the number of returns per calendar year within
+// them, from the (n+1)-th point on. Windows spanning less than a day yield
+// their unannualised volatility.
+func RollingVolatility(seq iter.Seq[Point], n int) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		if n < 2 {
+			return
+		}
+		w := newWindow(n + 1)
+		returns := make([]float64, 0, n)
+		for p := range seq {
+			w.push(p)
+			if !w.full {
+				continue
+			}
+
+			// Log returns between consecutive points of the window, oldest first
+			returns = returns[:0]
+			first := w.oldest()
+			previous := first
+			for i := 1; i <= n; i++ {
+				q := w.points[(w.next+i)%len(w.points)]
+				returns = append(returns, math.Log(q.Value/previous.Value))
+				previous = q
+			}
+
+			var sum, squares float64
+			for _, r := range returns {
+				sum += r
+			}
+			mean := sum / float64(n)
+			for _, r := range returns {
+				squares += (r - mean) * (r - mean)
+			}
+			volatility := math.Sqrt(squares / float64(n-1))
+			if days := p.Time.Sub(first.Time).Hours() / 24; days >= 1 {
+				volatility *= math.Sqrt(float64(n) / days * daysPerYear)
+			}
+
+			if !yield(Point{Time: p.Time, Value: volatility}) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package analytics_test
+
+import (
+	"iter"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tyokyo320/rateflow/pkg/analytics"
+	"github.com/tyokyo320/rateflow/pkg/stream"
+)
+
+// points returns daily points with the given values.
+func points(values ...float64) iter.Seq[analytics.Point] {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ps := make([]analytics.Point, len(values))
+	for i, v := range values {
+		ps[i] = analytics.Point{Time: start.AddDate(0, 0, i), Value: v}
+	}
+	return stream.FromSlice(ps)
+}
+
+func valuesOf(seq iter.Seq[analytics.Point]) []float64 {
+	return slices.Collect(stream.Map(seq, func(p analytics.Point) float64 { return p.Value }))
+}
+
+func near(got, want []float64, tolerance float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// Closing prices of the reference examples of StockCharts' ChartSchool. Its
+// RSI table was computed from unrounded prices, so the values below, from the
+// rounded ones, differ from it by up to 0.07.
+var (
+	emaPrices = []float64{
+		22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+		22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+	}
+	rsiPrices = []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	}
+)
+
+func TestIndicators(t *testing.T) {
+	tests := []struct {
+		name      string
+		got       iter.Seq[analytics.Point]
+		want      []float64
+		tolerance float64
+	}{
+		{"sma", analytics.SMA(points(1, 2, 3, 4, 5, 6), 3), []float64{2, 3, 4, 5}, 1e-12},
+		{"sma of a short series", analytics.SMA(points(1, 2), 3), []float64{}, 0},
+		{"ema", analytics.EMA(points(emaPrices...), 10),
+			[]float64{22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28, 23.34}, 0.01},
+		{"rsi", analytics.RSI(points(rsiPrices...), 14),
+			[]float64{70.46, 66.25, 66.48, 69.35, 66.29, 57.92}, 0.01},
+		{"rsi without losses", analytics.RSI(points(1, 2, 3, 4), 2), []float64{100, 100}, 0},
+		{"roc", analytics.ROC(points(10, 11, 12.1, 9.68), 1), []float64{10, 10, -20}, 1e-9},
+		{"roc over two points", analytics.ROC(points(10, 11, 12.1), 2), []float64{21}, 1e-9},
+		// Log returns of ±10% have a sample standard deviation of 0.1419 per day
+		{"rolling volatility", analytics.RollingVolatility(points(100, 110, 99), 2), []float64{0.141894 * math.Sqrt(365.25)}, 1e-4},
+		{"steady growth has no volatility", analytics.RollingVolatility(points(100, 110, 121, 133.1), 3), []float64{0}, 1e-12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := valuesOf(tt.got); !near(got, tt.want, tt.tolerance) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	bands := slices.Collect(analytics.Bollinger(points(1, 2, 3, 5), 3, 2))
+	if len(bands) != 2 {
+		t.Fatalf("got %d bands, want 2", len(bands))
+	}
+
+	// Population standard deviation of 1, 2, 3 is sqrt(2/3)
+	width := 2 * math.Sqrt(2.0/3)
+	got := []float64{bands[0].Middle, bands[0].Upper, bands[0].Lower}
+	if want := []float64{2, 2 + width, 2 - width}; !near(got, want, 1e-12) {
+		t.Errorf("first band = %v, want %v", got, want)
+	}
+	if !bands[1].Time.Equal(time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)) || math.Abs(bands[1].Middle-10.0/3) > 1e-12 {
+		t.Errorf("second band = %+v, want the average of 2, 3 and 5 on Jan 4", bands[1])
+	}
+}
+
+func TestIndicators_StopEarly(t *testing.T) {
+	for range analytics.SMA(points(1, 2, 3, 4, 5), 2) {
+		break
+	}
+	first, ok := stream.First(analytics.EMA(points(emaPrices...), 10))
+	if !ok || math.Abs(first.Value-22.22) > 0.01 {
+		t.Errorf("First(EMA) = %v, %v; want 22.22", first, ok)
+	}
+}
@@ -0,0 +1,286 @@
+// Package genericrepo provides typed, composable filters for repository queries.
+package genericrepo
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Operator is a comparison operator of a filter predicate.
+type Operator string
+
+const (
+	OpEq      Operator = "eq"
+	OpNe      Operator = "ne"
+	OpLt      Operator = "lt"
+	OpLte     Operator = "lte"
+	OpGt      Operator = "gt"
+	OpGte     Operator = "gte"
+	OpBetween Operator = "between"
+	OpIn      Operator = "in"
+	OpLike    Operator = "like"
+)
+
+// Logic combines the children of a filter group.
+type Logic string
+
+const (
+	LogicAnd Logic = "and"
+	LogicOr  Logic = "or"
+)
+
+// Filter is a node of a filter expression tree.
+// A leaf is a predicate on a single field; a group combines its children with AND or OR.
+type Filter struct {
+	Field  string
+	Op     Operator
+	Values []any
+
+	Logic    Logic
+	Children []Filter
+}
+
+// IsGroup reports whether the filter combines other filters.
+func (f Filter) IsGroup() bool {
+	return f.Logic != ""
+}
+
+// Eq matches entities whose field equals value.
+func Eq(field string, value any) Filter {
+	return Filter{Field: field, Op: OpEq, Values: []any{value}}
+}
+
+// Ne matches entities whose field does not equal value.
+func Ne(field string, value any) Filter {
+	return Filter{Field: field, Op: OpNe, Values: []any{value}}
+}
+
+// Lt matches entities whose field is less than value.
+func Lt(field string, value any) Filter {
+	return Filter{Field: field, Op: OpLt, Values: []any{value}}
+}
+
+// Lte matches entities whose field is less than or equal to value.
+func Lte(field string, value any) Filter {
+	return Filter{Field: field, Op: OpLte, Values: []any{value}}
+}
+
+// Gt matches entities whose field is greater than value.
+func Gt(field string, value any) Filter {
+	return Filter{Field: field, Op: OpGt, Values: []any{value}}
+}
+
+// Gte matches entities whose field is greater than or equal to value.
+func Gte(field string, value any) Filter {
+	return Filter{Field: field, Op: OpGte, Values: []any{value}}
+}
+
+// Between matches entities whose field lies in the inclusive range [low, high].
+func Between(field string, low, high any) Filter {
+	return Filter{Field: field, Op: OpBetween, Values: []any{low, high}}
+}
+
+// In matches entities whose field equals any of the values.
+func In[V any](field string, values ...V) Filter {
+	anyValues := make([]any, len(values))
+	for i, v := range values {
+		anyValues[i] = v
+	}
+	return Filter{Field: field, Op: OpIn, Values: anyValues}
+}
+
+// Like matches entities whose field matches an SQL LIKE pattern (% and _ wildcards).
+func Like(field, pattern string) Filter {
+	return Filter{Field: field, Op: OpLike, Values: []any{pattern}}
+}
+
+// And matches entities that satisfy all filters.
+func And(filters ...Filter) Filter {
+	return Filter{Logic: LogicAnd, Children: filters}
+}
+
+// Or matches entities that satisfy at least one filter.
+func Or(filters ...Filter) Filter {
+	return Filter{Logic: LogicOr, Children: filters}
+}
+
+// Where adds filter conditions; multiple conditions are combined with AND.
+func Where(filters ...Filter) QueryOption {
+	return func(c *QueryConfig) {
+		c.Conditions = append(c.Conditions, filters...)
+	}
+}
+
+// Condition returns all filter conditions of the query as a single AND group,
+// including equality filters added with WithFilter and WithFilters.
+func (c *QueryConfig) Condition() Filter {
+	children := make([]Filter, 0, len(c.Filters)+len(c.Conditions))
+	for _, key := range slices.Sorted(maps.Keys(c.Filters)) {
+		children = append(children, Eq(key, c.Filters[key]))
+	}
+	children = append(children, c.Conditions...)
+	return And(children...)
+}
+
+// ErrInvalidFilter indicates a filter that cannot be applied to an entity.
+type ErrInvalidFilter struct {
+	Field  string
+	Reason string
+}
+
+func (e ErrInvalidFilter) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("invalid filter on %q: %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("invalid filter: %s", e.Reason)
+}
+
+// Fields is the allow-list of filterable and sortable fields of an entity,
+// mapping each field name to its storage column.
+type Fields map[string]string
+
+// Column returns the storage column of an allow-listed field.
+func (f Fields) Column(field string) (string, error) {
+	column, ok := f[field]
+	if !ok {
+		return "", ErrInvalidFilter{Field: field, Reason: "unknown field"}
+	}
+	return column, nil
+}
+
+// SQL renders a filter as a SQL boolean expression with ? placeholders.
+// Field names are resolved through the allow-list; values are never
+// interpolated into the expression. An empty group renders as "1 = 1".
+func (f Fields) SQL(filter Filter) (string, []any, error) {
+	if filter.IsGroup() {
+		return f.groupSQL(filter)
+	}
+
+	column, err := f.Column(filter.Field)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch filter.Op {
+	case OpEq, OpNe, OpLt, OpLte, OpGt, OpGte, OpLike:
+		if len(filter.Values) != 1 {
+			return "", nil, ErrInvalidFilter{Field: filter.Field, Reason: fmt.Sprintf("%s needs exactly one value", filter.Op)}
+		}
+		return column + " " + comparisonSQL[filter.Op] + " ?", filter.Values, nil
+	case OpBetween:
+		if len(filter.Values) != 2 {
+			return "", nil, ErrInvalidFilter{Field: filter.Field, Reason: "between needs exactly two values"}
+		}
+		return column + " BETWEEN ? AND ?", filter.Values, nil
+	case OpIn:
+		if len(filter.Values) == 0 {
+			// An empty IN list matches nothing
+			return "1 = 0", nil, nil
+		}
+		return column + " IN ?", []any{filter.Values}, nil
+	default:
+		return "", nil, ErrInvalidFilter{Field: filter.Field, Reason: fmt.Sprintf("unsupported operator %q", filter.Op)}
+	}
+}
+
+func (f Fields) groupSQL(group Filter) (string, []any, error) {
+	var joiner string
+	switch group.Logic {
+	case LogicAnd:
+		joiner = " AND "
+	case LogicOr:
+		joiner = " OR "
+	default:
+		return "", nil, ErrInvalidFilter{Reason: fmt.Sprintf("unsupported logic %q", group.Logic)}
+	}
+
+	if len(group.Children) == 0 {
+		return "1 = 1", nil, nil
+	}
+
+	parts := make([]string, 0, len(group.Children))
+	var args []any
+	for _, child := range group.Children {
+		sql, childArgs, err := f.SQL(child)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, "("+sql+")")
+		args = append(args, childArgs...)
+	}
+
+	return strings.Join(parts, joiner), args, nil
+}
+
+var comparisonSQL = map[Operator]string{
+	OpEq:   "=",
+	OpNe:   "<>",
+	OpLt:   "<",
+	OpLte:  "<=",
+	OpGt:   ">",
+	OpGte:  ">=",
+	OpLike: "LIKE",
+}
+
+// OrderTerm is a single validated term of an ORDER BY clause.
+type OrderTerm struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+// Order parses an ORDER BY clause such as "effective_date DESC, id DESC"
+// and validates each field against the allow-list.
+func (f Fields) Order(orderBy string) ([]OrderTerm, error) {
+	terms := strings.Split(orderBy, ",")
+	result := make([]OrderTerm, 0, len(terms))
+
+	for _, term := range terms {
+		parts := strings.Fields(term)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, ErrInvalidFilter{Reason: fmt.Sprintf("invalid order term %q", strings.TrimSpace(term))}
+		}
+
+		column, err := f.Column(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		desc := false
+		if len(parts) == 2 {
+			switch strings.ToUpper(parts[1]) {
+			case "ASC":
+			case "DESC":
+				desc = true
+			default:
+				return nil, ErrInvalidFilter{Field: parts[0], Reason: fmt.Sprintf("invalid order direction %q", parts[1])}
+			}
+		}
+
+		result = append(result, OrderTerm{Field: parts[0], Column: column, Desc: desc})
+	}
+
+	return result, nil
+}
+
+// OrderSQL validates an ORDER BY clause against the allow-list
+// and returns it with fields replaced by their columns.
+func (f Fields) OrderSQL(orderBy string) (string, error) {
+	terms, err := f.Order(orderBy)
+	if err != nil {
+		return "", err
+	}
+
+	clauses := make([]string, len(terms))
+	for i, term := range terms {
+		direction := "ASC"
+		if term.Desc {
+			direction = "DESC"
+		}
+		clauses[i] = term.Column + " " + direction
+	}
+
+	return strings.Join(clauses, ", "), nil
+}
@@ -0,0 +1,166 @@
+package genericrepo_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/tyokyo320/rateflow/pkg/genericrepo"
+)
+
+var testFields = genericrepo.Fields{
+	"source":         "source",
+	"value":          "value",
+	"effective_date": "effective_date",
+	"id":             "id",
+}
+
+func TestFields_SQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   genericrepo.Filter
+		wantSQL  string
+		wantArgs []any
+		wantErr  bool
+	}{
+		{
+			name:     "eq",
+			filter:   genericrepo.Eq("source", "unionpay"),
+			wantSQL:  "source = ?",
+			wantArgs: []any{"unionpay"},
+		},
+		{
+			name:     "ne",
+			filter:   genericrepo.Ne("source", "manual"),
+			wantSQL:  "source <> ?",
+			wantArgs: []any{"manual"},
+		},
+		{
+			name:     "between",
+			filter:   genericrepo.Between("value", 19.5, 21.0),
+			wantSQL:  "value BETWEEN ? AND ?",
+			wantArgs: []any{19.5, 21.0},
+		},
+		{
+			name:     "in",
+			filter:   genericrepo.In("source", "unionpay", "ecb"),
+			wantSQL:  "source IN ?",
+			wantArgs: []any{[]any{"unionpay", "ecb"}},
+		},
+		{
+			name:    "empty in matches nothing",
+			filter:  genericrepo.In[string]("source"),
+			wantSQL: "1 = 0",
+		},
+		{
+			name:     "like",
+			filter:   genericrepo.Like("source", "union%"),
+			wantSQL:  "source LIKE ?",
+			wantArgs: []any{"union%"},
+		},
+		{
+			name: "and with nested or",
+			filter: genericrepo.And(
+				genericrepo.Gte("effective_date", "2025-01-01"),
+				genericrepo.Or(genericrepo.Lt("value", 19.0), genericrepo.Gt("value", 21.0)),
+			),
+			wantSQL:  "(effective_date >= ?) AND ((value < ?) OR (value > ?))",
+			wantArgs: []any{"2025-01-01", 19.0, 21.0},
+		},
+		{
+			name:    "empty group matches everything",
+			filter:  genericrepo.And(),
+			wantSQL: "1 = 1",
+		},
+		{
+			name:    "unknown field is rejected",
+			filter:  genericrepo.Eq("value; DROP TABLE exchange_rates; --", 1),
+			wantErr: true,
+		},
+		{
+			name:    "unknown field in nested group is rejected",
+			filter:  genericrepo.And(genericrepo.Or(genericrepo.Eq("password", "x"))),
+			wantErr: true,
+		},
+		{
+			name:    "between with one value is rejected",
+			filter:  genericrepo.Filter{Field: "value", Op: genericrepo.OpBetween, Values: []any{1.0}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := testFields.SQL(tt.filter)
+
+			if tt.wantErr {
+				var invalid genericrepo.ErrInvalidFilter
+				if !errors.As(err, &invalid) {
+					t.Errorf("SQL() error = %v, want ErrInvalidFilter", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SQL() unexpected error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if len(args) != 0 || len(tt.wantArgs) != 0 {
+				if !reflect.DeepEqual(args, tt.wantArgs) {
+					t.Errorf("SQL() args = %v, want %v", args, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
+
+func TestFields_OrderSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		orderBy string
+		want    string
+		wantErr bool
+	}{
+		{name: "single field", orderBy: "effective_date", want: "effective_date ASC"},
+		{name: "multiple fields", orderBy: "effective_date desc, id DESC", want: "effective_date DESC, id DESC"},
+		{name: "unknown field", orderBy: "effective_date DESC, (SELECT 1)", wantErr: true},
+		{name: "invalid direction", orderBy: "id SIDEWAYS", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testFields.OrderSQL(tt.orderBy)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("OrderSQL() expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OrderSQL() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("OrderSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryConfig_Condition(t *testing.T) {
+	cfg := genericrepo.BuildQueryConfig(
+		genericrepo.WithFilter("source", "unionpay"),
+		genericrepo.Where(genericrepo.Gt("value", 20.0)),
+	)
+
+	sql, args, err := testFields.SQL(cfg.Condition())
+	if err != nil {
+		t.Fatalf("unexpected error = %v", err)
+	}
+	if want := "(source = ?) AND (value > ?)"; sql != want {
+		t.Errorf("Condition() SQL = %q, want %q", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{"unionpay", 20.0}) {
+		t.Errorf("Condition() args = %v", args)
+	}
+}
@@ -0,0 +1,192 @@
+package genericrepo
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tyokyo320/rateflow/pkg/timeutil"
+)
+
+// Match evaluates a filter in memory against an entity whose field values
+// are returned by get. It accepts the same filters as SQL, so in-memory
+// repositories behave like their database counterparts.
+//
+// Values are compared as numbers when both sides are numeric, as times when
+// either side is a time.Time (strings are parsed with timeutil.ParseFlexible),
+// and as strings otherwise.
+func (f Fields) Match(filter Filter, get func(field string) any) (bool, error) {
+	if filter.IsGroup() {
+		return f.matchGroup(filter, get)
+	}
+
+	if _, err := f.Column(filter.Field); err != nil {
+		return false, err
+	}
+	actual := get(filter.Field)
+
+	switch filter.Op {
+	case OpEq, OpNe, OpLt, OpLte, OpGt, OpGte:
+		if len(filter.Values) != 1 {
+			return false, ErrInvalidFilter{Field: filter.Field, Reason: fmt.Sprintf("%s needs exactly one value", filter.Op)}
+		}
+		n, err := CompareValues(actual, filter.Values[0])
+		if err != nil {
+			return false, ErrInvalidFilter{Field: filter.Field, Reason: err.Error()}
+		}
+		switch filter.Op {
+		case OpEq:
+			return n == 0, nil
+		case OpNe:
+			return n != 0, nil
+		case OpLt:
+			return n < 0, nil
+		case OpLte:
+			return n <= 0, nil
+		case OpGt:
+			return n > 0, nil
+		default:
+			return n >= 0, nil
+		}
+	case OpBetween:
+		if len(filter.Values) != 2 {
+			return false, ErrInvalidFilter{Field: filter.Field, Reason: "between needs exactly two values"}
+		}
+		low, err := CompareValues(actual, filter.Values[0])
+		if err != nil {
+			return false, ErrInvalidFilter{Field: filter.Field, Reason: err.Error()}
+		}
+		high, err := CompareValues(actual, filter.Values[1])
+		if err != nil {
+			return false, ErrInvalidFilter{Field: filter.Field, Reason: err.Error()}
+		}
+		return low >= 0 && high <= 0, nil
+	case OpIn:
+		for _, value := range filter.Values {
+			n, err := CompareValues(actual, value)
+			if err != nil {
+				return false, ErrInvalidFilter{Field: filter.Field, Reason: err.Error()}
+			}
+			if n == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case OpLike:
+		if len(filter.Values) != 1 {
+			return false, ErrInvalidFilter{Field: filter.Field, Reason: "like needs exactly one value"}
+		}
+		pattern, ok := filter.Values[0].(string)
+		if !ok {
+			return false, ErrInvalidFilter{Field: filter.Field, Reason: "like needs a string pattern"}
+		}
+		return likeRegexp(pattern).MatchString(fmt.Sprint(actual)), nil
+	default:
+		return false, ErrInvalidFilter{Field: filter.Field, Reason: fmt.Sprintf("unsupported operator %q", filter.Op)}
+	}
+}
+
+func (f Fields) matchGroup(group Filter, get func(field string) any) (bool, error) {
+	switch group.Logic {
+	case LogicAnd:
+		for _, child := range group.Children {
+			ok, err := f.Match(child, get)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case LogicOr:
+		if len(group.Children) == 0 {
+			return true, nil
+		}
+		for _, child := range group.Children {
+			ok, err := f.Match(child, get)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, ErrInvalidFilter{Reason: fmt.Sprintf("unsupported logic %q", group.Logic)}
+	}
+}
+
+// CompareValues compares two field values and returns -1, 0 or +1.
+func CompareValues(a, b any) (int, error) {
+	if af, ok := toFloat(a); ok {
+		if bf, ok := toFloat(b); ok {
+			switch {
+			case af < bf:
+				return -1, nil
+			case af > bf:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	}
+
+	at, aIsTime := a.(time.Time)
+	bt, bIsTime := b.(time.Time)
+	if aIsTime || bIsTime {
+		var err error
+		if !aIsTime {
+			if at, err = timeutil.ParseFlexible(fmt.Sprint(a)); err != nil {
+				return 0, err
+			}
+		}
+		if !bIsTime {
+			if bt, err = timeutil.ParseFlexible(fmt.Sprint(b)); err != nil {
+				return 0, err
+			}
+		}
+		return at.Compare(bt), nil
+	}
+
+	as, aIsString := a.(string)
+	bs, bIsString := b.(string)
+	if aIsString && bIsString {
+		return strings.Compare(as, bs), nil
+	}
+
+	return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+}
+
+// toFloat converts any numeric value to float64.
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// likeRegexp translates an SQL LIKE pattern into an anchored regular expression.
+func likeRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -4,7 +4,11 @@ package genericrepo
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"iter"
+	"time"
 )
 
 // Entity defines the interface that all domain entities must implement.
@@ -47,17 +51,22 @@ type Repository[T Entity] interface {
 
 // QueryConfig holds configuration for repository queries.
 type QueryConfig struct {
-	Filters  map[string]any
-	OrderBy  string
-	Limit    int
-	Offset   int
-	Preloads []string
+	Filters    map[string]any
+	Conditions []Filter // typed conditions added with Where, combined with AND
+	OrderBy    string
+	Limit      int
+	Offset     int
+	Preloads   []string
+	AsOf       *time.Time // transaction time to read at; nil means current state
+	Cursor     *Cursor    // keyset position to continue after; takes precedence over Offset
+	BatchSize  int        // rows fetched per round trip by Stream and StreamWithError
 }
 
 // QueryOption is a functional option for configuring queries.
 type QueryOption func(*QueryConfig)
 
-// WithFilter adds a filter condition.
+// WithFilter adds an equality filter condition.
+// Use Where for other operators and OR groups.
 func WithFilter(key string, value any) QueryOption {
 	return func(c *QueryConfig) {
 		if c.Filters == nil {
@@ -115,6 +124,29 @@ func WithPreload(relations ...string) QueryOption {
 	}
 }
 
+// WithAsOf reads entities as the repository knew them at the given time,
+// ignoring changes recorded afterwards. Repositories without history support
+// may ignore this option.
+func WithAsOf(asOf time.Time) QueryOption {
+	return func(c *QueryConfig) {
+		c.AsOf = &asOf
+	}
+}
+
+// WithCursor continues a keyset-ordered query after the given position.
+func WithCursor(cursor Cursor) QueryOption {
+	return func(c *QueryConfig) {
+		c.Cursor = &cursor
+	}
+}
+
+// WithBatchSize sets how many rows Stream fetches per round trip.
+func WithBatchSize(size int) QueryOption {
+	return func(c *QueryConfig) {
+		c.BatchSize = size
+	}
+}
+
 // BuildQueryConfig creates a QueryConfig from options.
 func BuildQueryConfig(opts ...QueryOption) *QueryConfig {
 	cfg := &QueryConfig{
@@ -161,3 +193,34 @@ func NewPagedResult[T any](items []T, page, pageSize int, total int64) PagedResu
 		Pagination: pagination,
 	}
 }
+
+// ErrInvalidCursor is returned when an encoded cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor identifies a position in a keyset-ordered result:
+// the sort key of the last item seen and its ID as a tie-breaker.
+type Cursor struct {
+	SortKey string `json:"k"`
+	ID      string `json:"id"`
+}
+
+// Encode returns the opaque, URL-safe representation of the cursor.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode.
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.SortKey == "" || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
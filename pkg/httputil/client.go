@@ -14,12 +14,22 @@ type Client struct {
 	client  *http.Client
 	retries int
 	timeout time.Duration
+	onRetry RetryHook
 }
 
+// RetryHook is called before a request is retried, with the number of the
+// upcoming attempt (starting at 2) and the error of the previous one.
+type RetryHook func(req *http.Request, attempt int, err error)
+
 // Config holds configuration for the HTTP client.
 type Config struct {
 	Timeout time.Duration
 	Retries int
+	OnRetry RetryHook // optional, e.g. to count retries
+
+	// Transport sends the requests; nil uses http.DefaultTransport.
+	// Wrap it to instrument requests, e.g. to propagate trace context.
+	Transport http.RoundTripper
 }
 
 // DefaultConfig returns the default HTTP client configuration.
@@ -34,10 +44,12 @@ func DefaultConfig() Config {
 func NewClient(cfg Config) *Client {
 	return &Client{
 		client: &http.Client{
-			Timeout: cfg.Timeout,
+			Timeout:   cfg.Timeout,
+			Transport: cfg.Transport,
 		},
 		retries: cfg.Retries,
 		timeout: cfg.Timeout,
+		onRetry: cfg.OnRetry,
 	}
 }
 
@@ -79,6 +91,10 @@ func (c *Client) doWithRetry(req *http.Request) ([]byte, error) {
 
 	for attempt := 0; attempt <= c.retries; attempt++ {
 		if attempt > 0 {
+			if c.onRetry != nil {
+				c.onRetry(req, attempt+1, lastErr)
+			}
+
 			// Exponential backoff
 			backoff := time.Duration(attempt) * time.Second
 			time.Sleep(backoff)
@@ -84,6 +84,15 @@ func IsWeekend(t time.Time) bool {
 	return weekday == time.Saturday || weekday == time.Sunday
 }
 
+// PreviousBusinessDay returns the start of the last weekday before t.
+func PreviousBusinessDay(t time.Time) time.Time {
+	day := StartOfDay(t).AddDate(0, 0, -1)
+	for IsWeekend(day) {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
 // DaysBetween returns the number of days between two dates.
 func DaysBetween(start, end time.Time) int {
 	duration := end.Sub(start)
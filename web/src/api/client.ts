@@ -1,7 +1,8 @@
 import axios from 'axios'
-import type { ApiResponse, Rate, RateHistoryData, HealthResponse } from '../types'
+import type { ApiResponse, Rate, RateHistoryData, RateStats, TimeSeriesData, FillMode, RateComparison, HealthResponse } from '../types'
 
 const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || ''
+const API_KEY = import.meta.env.VITE_API_KEY || ''
 
 const apiClient = axios.create({
   baseURL: API_BASE_URL,
@@ -14,6 +15,9 @@ const apiClient = axios.create({
 // Request interceptor
 apiClient.interceptors.request.use(
   (config) => {
+    if (API_KEY) {
+      config.headers['X-API-Key'] = API_KEY
+    }
     return config
   },
   (error) => {
@@ -41,6 +45,18 @@ apiClient.interceptors.response.use(
 )
 
 export const rateApi = {
+  /**
+   * URL of the Server-Sent Events stream of newly stored rates.
+   * EventSource cannot send headers, so the stream is only available
+   * without an API key.
+   */
+  streamUrl: (pair: string): string | null => {
+    if (API_KEY || typeof EventSource === 'undefined') return null
+    const url = new URL('/api/v1/rates/stream', API_BASE_URL || window.location.origin)
+    url.searchParams.set('pairs', pair)
+    return url.toString()
+  },
+
   /**
    * Get the latest exchange rate for a currency pair
    */
@@ -83,6 +99,58 @@ export const rateApi = {
     }
   },
 
+  /**
+   * Get statistics of a currency pair over a date range, computed on the server
+   */
+  getRateStats: async (
+    pair: string,
+    start?: string,
+    end?: string
+  ): Promise<RateStats> => {
+    const params: Record<string, string> = { pair }
+    if (start) params.start = start
+    if (end) params.end = end
+
+    const response = await apiClient.get<ApiResponse<RateStats>>(
+      `/api/v1/rates/stats`,
+      { params }
+    )
+    return response.data.data
+  },
+
+  /**
+   * Get aligned series of several currency pairs, one value per step
+   */
+  getTimeSeries: async (
+    pairs: string[],
+    start: string,
+    end: string,
+    fill: FillMode = 'previous',
+    step: string = '1d'
+  ): Promise<TimeSeriesData> => {
+    const response = await apiClient.get<ApiResponse<TimeSeriesData>>(
+      `/api/v1/rates/timeseries`,
+      { params: { pairs: pairs.join(','), start, end, fill, step } }
+    )
+    return response.data.data
+  },
+
+  /**
+   * Compare the pairs of one base currency over a date range, normalised to 100
+   */
+  compareRates: async (
+    base: string,
+    quotes: string[],
+    start: string,
+    end: string
+  ): Promise<RateComparison> => {
+    const response = await apiClient.get<ApiResponse<RateComparison>>(
+      `/api/v1/rates/compare`,
+      { params: { base, quotes: quotes.join(','), start, end } }
+    )
+    return response.data.data
+  },
+
   /**
    * Health check endpoint
    */
@@ -1,6 +1,7 @@
-import { useQuery, UseQueryResult } from '@tanstack/react-query'
+import { useEffect } from 'react'
+import { useQuery, useQueryClient, UseQueryResult } from '@tanstack/react-query'
 import { rateApi } from './client'
-import type { Rate, RateHistoryData, HealthResponse } from '../types'
+import type { Rate, RateHistoryData, RateStats, TimeSeriesData, FillMode, RateComparison, HealthResponse } from '../types'
 
 /**
  * Hook to fetch the latest exchange rate
@@ -16,6 +17,30 @@ export const useLatestRate = (
   })
 }
 
+/**
+ * Hook to receive newly stored rates of a pair as they arrive, instead of
+ * waiting for the next poll. Falls back to polling when streaming is unavailable.
+ */
+export const useRateStream = (pair: string): void => {
+  const queryClient = useQueryClient()
+
+  useEffect(() => {
+    const url = pair ? rateApi.streamUrl(pair) : null
+    if (!url) return
+
+    // EventSource reconnects by itself, resuming after the last event ID
+    const source = new EventSource(url)
+    source.addEventListener('rate', (event) => {
+      const rate = JSON.parse((event as MessageEvent).data) as Rate
+      queryClient.setQueryData(['latestRate', pair], rate)
+      queryClient.invalidateQueries({ queryKey: ['historicalRates', pair] })
+      queryClient.invalidateQueries({ queryKey: ['rateStats', pair] })
+      queryClient.invalidateQueries({ queryKey: ['timeSeries', pair] })
+    })
+    return () => source.close()
+  }, [pair, queryClient])
+}
+
 /**
  * Hook to fetch historical exchange rates
  */
@@ -33,6 +58,53 @@ export const useHistoricalRates = (
   })
 }
 
+/**
+ * Hook to fetch statistics of a currency pair over a date range
+ */
+export const useRateStats = (
+  pair: string,
+  start?: string,
+  end?: string
+): UseQueryResult<RateStats, Error> => {
+  return useQuery({
+    queryKey: ['rateStats', pair, start, end],
+    queryFn: () => rateApi.getRateStats(pair, start, end),
+    enabled: !!pair,
+  })
+}
+
+/**
+ * Hook to fetch the series of a currency pair between two dates, with gaps filled
+ */
+export const useTimeSeries = (
+  pair: string,
+  start: string,
+  end: string,
+  fill: FillMode = 'previous'
+): UseQueryResult<TimeSeriesData, Error> => {
+  return useQuery({
+    queryKey: ['timeSeries', pair, start, end, fill],
+    queryFn: () => rateApi.getTimeSeries([pair], start, end, fill),
+    enabled: !!pair && !!start && !!end,
+  })
+}
+
+/**
+ * Hook to compare the pairs of one base currency between two dates
+ */
+export const useRateComparison = (
+  base: string,
+  quotes: string[],
+  start: string,
+  end: string
+): UseQueryResult<RateComparison, Error> => {
+  return useQuery({
+    queryKey: ['rateComparison', base, quotes, start, end],
+    queryFn: () => rateApi.compareRates(base, quotes, start, end),
+    enabled: !!base && quotes.length > 0 && !!start && !!end,
+  })
+}
+
 /**
  * Hook for health check
  */
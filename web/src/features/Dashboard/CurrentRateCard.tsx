@@ -15,7 +15,7 @@ import TrendingDownIcon from '@mui/icons-material/TrendingDown'
 import TrendingFlatIcon from '@mui/icons-material/TrendingFlat'
 import { LineChart, Line, ResponsiveContainer } from 'recharts'
 import { useTranslation } from 'react-i18next'
-import { useLatestRate, useHistoricalRates } from '../../api/hooks'
+import { useLatestRate, useHistoricalRates, useRateStream } from '../../api/hooks'
 import { formatRate, formatRelativeTime, formatCurrencyPair, parseCurrencyPair } from '../../utils/formatters'
 import ErrorAlert from '../../components/ErrorAlert'
 
@@ -29,6 +29,7 @@ function CurrentRateCard({ pair }: CurrentRateCardProps) {
   const { isInverted, apiPair } = parseCurrencyPair(pair)
   const { data: rate, isLoading, error, refetch } = useLatestRate(apiPair)
   const { data: historyData } = useHistoricalRates(apiPair, 1, 8) // Last 7 days for sparkline
+  useRateStream(apiPair)
 
   // Calculate display rate based on whether the pair is inverted
   const displayRate = rate ? (isInverted ? 1 / rate.rate : rate.rate) : 0
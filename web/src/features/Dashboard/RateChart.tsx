@@ -26,8 +26,8 @@ import {
   Legend,
 } from 'recharts'
 import { useTranslation } from 'react-i18next'
-import { useHistoricalRates } from '../../api/hooks'
-import { formatDate, formatRate, parseCurrencyPair } from '../../utils/formatters'
+import { useTimeSeries } from '../../api/hooks'
+import { formatDate, formatRate } from '../../utils/formatters'
 import LoadingSpinner from '../../components/LoadingSpinner'
 import ErrorAlert from '../../components/ErrorAlert'
 import type { ChartDataPoint } from '../../types'
@@ -42,37 +42,35 @@ const dayOptions = [7, 14, 30, 60, 90]
 
 function RateChart({ pair, days, onDaysChange }: RateChartProps) {
   const { t, i18n } = useTranslation()
-  const { isInverted, apiPair } = parseCurrencyPair(pair)
   const [anchorEl, setAnchorEl] = useState<HTMLButtonElement | null>(null)
   const [startDate, setStartDate] = useState<Dayjs | null>(dayjs().subtract(days, 'day'))
   const [endDate, setEndDate] = useState<Dayjs | null>(dayjs())
   const [customDateRange, setCustomDateRange] = useState<[string, string] | null>(null)
 
-  // Use custom date range if set, otherwise use days parameter
-  const { data, isLoading, error, refetch } = useHistoricalRates(
-    apiPair,
-    1,
-    days + 1,
-    customDateRange?.[0],
-    customDateRange?.[1]
+  // Use custom date range if set, otherwise use days parameter. The server
+  // fills days without rates, such as weekends, with the previous rate and
+  // serves inverted pairs from the stored direction.
+  const [rangeStart, rangeEnd] = useMemo(
+    () => customDateRange ?? [
+      dayjs().subtract(days, 'day').format('YYYY-MM-DD'),
+      dayjs().format('YYYY-MM-DD'),
+    ],
+    [customDateRange, days]
   )
+  const { data, isLoading, error, refetch } = useTimeSeries(pair, rangeStart, rangeEnd)
 
   const chartData = useMemo<ChartDataPoint[]>(() => {
-    if (!data?.items) return []
+    const values = data?.series[0]?.values
+    if (!data || !values) return []
 
     // For long date ranges (> 90 days), show year in date label
     const dateFormat = days > 90 ? 'YY-MM-DD' : 'MM-DD'
 
-    // Backend returns data in descending order (newest first)
-    // We need to reverse it to show oldest to newest (left to right on chart)
-    return data.items
-      .map((rate) => ({
-        date: formatDate(rate.effectiveDate, dateFormat),
-        rate: isInverted ? 1 / rate.rate : rate.rate,
-        fullDate: rate.effectiveDate,
-      }))
-      .reverse()
-  }, [data, isInverted, days])
+    return data.dates.flatMap((date, i) => {
+      const rate = values[i]
+      return rate === null ? [] : [{ date: formatDate(date, dateFormat), rate, fullDate: date }]
+    })
+  }, [data, days])
 
   // Calculate Y-axis domain with appropriate scale based on rate range
   const yAxisConfig = useMemo(() => {
@@ -0,0 +1,157 @@
+import { useMemo } from 'react'
+import dayjs from 'dayjs'
+import {
+  Box,
+  Chip,
+  Stack,
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableRow,
+  Typography,
+} from '@mui/material'
+import {
+  LineChart,
+  Line,
+  XAxis,
+  YAxis,
+  CartesianGrid,
+  Tooltip,
+  ResponsiveContainer,
+  Legend,
+} from 'recharts'
+import { useTranslation } from 'react-i18next'
+import { useRateComparison } from '../../api/hooks'
+import { formatDate } from '../../utils/formatters'
+import LoadingSpinner from '../../components/LoadingSpinner'
+import ErrorAlert from '../../components/ErrorAlert'
+import type { Currency } from '../../types'
+
+interface RateComparisonProps {
+  base: Currency
+  days: number
+}
+
+const currencies: Currency[] = ['CNY', 'JPY', 'USD']
+const colors = ['#5e92f3', '#f57c00', '#43a047', '#8e24aa', '#e53935']
+
+function RateComparison({ base, days }: RateComparisonProps) {
+  const { t } = useTranslation()
+  const quotes = useMemo(() => currencies.filter((c) => c !== base), [base])
+  const [start, end] = useMemo(
+    () => [dayjs().subtract(days, 'day').format('YYYY-MM-DD'), dayjs().format('YYYY-MM-DD')],
+    [days]
+  )
+  const { data, isLoading, error, refetch } = useRateComparison(base, quotes, start, end)
+
+  // One row per date with a value per pair, as recharts expects
+  const chartData = useMemo(() => {
+    if (!data) return []
+    const dateFormat = days > 90 ? 'YY-MM-DD' : 'MM-DD'
+    return data.dates.map((date, i) => {
+      const row: Record<string, string | number | null> = { date: formatDate(date, dateFormat) }
+      data.series.forEach((s) => {
+        row[s.pair] = s.values[i]
+      })
+      return row
+    })
+  }, [data, days])
+
+  if (error) {
+    return (
+      <ErrorAlert
+        title={t('error.cannotLoadComparison')}
+        message={error.message}
+        onRetry={() => refetch()}
+      />
+    )
+  }
+
+  return (
+    <Box>
+      <Typography variant="h6">{t('comparison.title')}</Typography>
+      <Typography variant="body2" color="text.secondary" sx={{ mb: 2 }}>
+        {t('comparison.subtitle', { base })}
+      </Typography>
+
+      {isLoading ? (
+        <LoadingSpinner message={t('loading.loadingComparison')} />
+      ) : !data || chartData.length === 0 ? (
+        <Box sx={{ textAlign: 'center', py: 4 }}>
+          <Typography color="text.secondary">{t('dashboard.noData')}</Typography>
+        </Box>
+      ) : (
+        <>
+          <Stack direction="row" spacing={1} sx={{ mb: 2, flexWrap: 'wrap' }}>
+            {data.series.map((s) => (
+              <Chip
+                key={s.pair}
+                size="small"
+                variant="outlined"
+                color={s.performance === null ? 'default' : s.performance >= 0 ? 'success' : 'error'}
+                label={`${s.pair} ${t('comparison.performance')}: ${
+                  s.performance === null ? '—' : `${s.performance >= 0 ? '+' : ''}${s.performance.toFixed(2)}%`
+                }`}
+              />
+            ))}
+          </Stack>
+
+          <ResponsiveContainer width="100%" height={300}>
+            <LineChart data={chartData} margin={{ top: 10, right: 20, left: 5, bottom: 15 }}>
+              <CartesianGrid strokeDasharray="3 3" stroke="#e0e0e0" />
+              <XAxis dataKey="date" tick={{ fontSize: 11 }} tickMargin={8} minTickGap={20} />
+              <YAxis
+                domain={['auto', 'auto']}
+                tick={{ fontSize: 11 }}
+                tickFormatter={(value: number) => value.toFixed(1)}
+                width={55}
+              />
+              <Tooltip formatter={(value: number) => value.toFixed(2)} />
+              <Legend wrapperStyle={{ paddingTop: 10 }} iconType="line" />
+              {data.series.map((s, i) => (
+                <Line
+                  key={s.pair}
+                  type="monotone"
+                  dataKey={s.pair}
+                  stroke={colors[i % colors.length]}
+                  strokeWidth={2}
+                  dot={false}
+                  connectNulls
+                />
+              ))}
+            </LineChart>
+          </ResponsiveContainer>
+
+          <Typography variant="subtitle2" sx={{ mt: 3, mb: 1 }}>
+            {t('comparison.correlation')}
+          </Typography>
+          <Table size="small" sx={{ maxWidth: 480 }}>
+            <TableHead>
+              <TableRow>
+                <TableCell />
+                {data.series.map((s) => (
+                  <TableCell key={s.pair} align="right">{s.pair}</TableCell>
+                ))}
+              </TableRow>
+            </TableHead>
+            <TableBody>
+              {data.series.map((row, i) => (
+                <TableRow key={row.pair}>
+                  <TableCell component="th" scope="row">{row.pair}</TableCell>
+                  {data.correlation[i].map((value, j) => (
+                    <TableCell key={data.series[j].pair} align="right">
+                      {value === null ? '—' : value.toFixed(2)}
+                    </TableCell>
+                  ))}
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+        </>
+      )}
+    </Box>
+  )
+}
+
+export default RateComparison
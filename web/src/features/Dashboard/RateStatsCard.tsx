@@ -1,4 +1,5 @@
 import { useMemo } from 'react'
+import dayjs from 'dayjs'
 import {
   Card,
   CardContent,
@@ -11,7 +12,7 @@ import {
 import TrendingUpIcon from '@mui/icons-material/TrendingUp'
 import TrendingDownIcon from '@mui/icons-material/TrendingDown'
 import { useTranslation } from 'react-i18next'
-import { useHistoricalRates } from '../../api/hooks'
+import { useRateStats } from '../../api/hooks'
 import { formatRate, parseCurrencyPair } from '../../utils/formatters'
 import ErrorAlert from '../../components/ErrorAlert'
 
@@ -22,27 +23,21 @@ interface RateStatsCardProps {
 function RateStatsCard({ pair }: RateStatsCardProps) {
   const { t } = useTranslation()
   const theme = useTheme()
-  const { isInverted, apiPair } = parseCurrencyPair(pair)
-  const { data, isLoading, error, refetch } = useHistoricalRates(apiPair, 1, 30)
+  const { isInverted } = parseCurrencyPair(pair)
+  // The server computes the statistics over every rate of the last 30 days,
+  // serving inverted pairs from the stored direction
+  const start = useMemo(() => dayjs().subtract(30, 'day').format('YYYY-MM-DD'), [])
+  const { data, isLoading, error, refetch } = useRateStats(pair, start)
 
-  const stats = useMemo(() => {
-    if (!data?.items || data.items.length === 0) {
-      return { high: 0, low: 0, average: 0, change: 0, changePercent: 0 }
-    }
-
-    const rates = data.items.map(item => isInverted ? 1 / item.rate : item.rate)
-    const high = Math.max(...rates)
-    const low = Math.min(...rates)
-    const average = rates.reduce((sum, rate) => sum + rate, 0) / rates.length
-
-    // Calculate 24h change (compare latest with oldest in 30-day period)
-    const latestRate = rates[0]
-    const oldestRate = rates[rates.length - 1]
-    const change = latestRate - oldestRate
-    const changePercent = oldestRate !== 0 ? (change / oldestRate) * 100 : 0
-
-    return { high, low, average, change, changePercent }
-  }, [data, isInverted])
+  const stats = data
+    ? {
+        high: data.max.rate,
+        low: data.min.rate,
+        average: data.mean,
+        change: data.change,
+        changePercent: data.changePercent,
+      }
+    : { high: 0, low: 0, average: 0, change: 0, changePercent: 0 }
 
   if (error) {
     return (
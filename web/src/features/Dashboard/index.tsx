@@ -5,6 +5,7 @@ import CurrentRateCard from './CurrentRateCard'
 import RateChart from './RateChart'
 import RateHistoryTable from './RateHistoryTable'
 import RateStatsCard from './RateStatsCard'
+import RateComparison from './RateComparison'
 import CurrencyPairSelector from './CurrencyPairSelector'
 import CurrencyConverter from './CurrencyConverter'
 import type { Currency } from '../../types'
@@ -78,6 +79,13 @@ function Dashboard() {
             </Paper>
           </Grid>
 
+          {/* Comparison of the base currency against the others */}
+          <Grid item xs={12}>
+            <Paper sx={{ p: 3 }}>
+              <RateComparison base={baseCurrency} days={days} />
+            </Paper>
+          </Grid>
+
           {/* Historical Data Table */}
           <Grid item xs={12}>
             <Paper sx={{ p: 3 }}>
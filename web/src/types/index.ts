@@ -28,6 +28,57 @@ export interface RateHistoryData {
   pagination: PaginationMeta
 }
 
+export interface RatePoint {
+  date: string
+  rate: number
+}
+
+export interface RateStats {
+  pair: string
+  count: number
+  min: RatePoint
+  max: RatePoint
+  mean: number
+  median: number
+  stdDev: number
+  first: RatePoint
+  last: RatePoint
+  change: number
+  changePercent: number
+  volatility: number | null
+}
+
+export type FillMode = 'previous' | 'linear' | 'none'
+
+export interface TimeSeries {
+  pair: string
+  values: (number | null)[]
+}
+
+export interface TimeSeriesData {
+  start: string
+  end: string
+  stepDays: number
+  fill: FillMode
+  dates: string[]
+  series: TimeSeries[]
+}
+
+export interface ComparisonSeries {
+  pair: string
+  values: (number | null)[] // normalised to 100 at the first rate
+  performance: number | null // percent change over the window
+}
+
+export interface RateComparison {
+  base: string
+  start: string
+  end: string
+  dates: string[]
+  series: ComparisonSeries[]
+  correlation: (number | null)[][] // daily log returns, rows and columns in series order
+}
+
 export interface HealthResponse {
   status: string
 }